@@ -0,0 +1,131 @@
+// Package atomicx is a package that adds typed atomic values and helpers missing from sync/atomic.
+package atomicx
+
+import (
+	"cmp"
+	"math"
+	"sync/atomic"
+)
+
+// Atomic holds a value of type T that is loaded and stored atomically. The zero value holds the zero
+// value of T and is ready to use. An Atomic must not be copied after first use.
+type Atomic[T any] struct {
+	ptr atomic.Pointer[T]
+}
+
+// New creates an Atomic holding value.
+func New[T any](value T) *Atomic[T] {
+	a := &Atomic[T]{}
+	a.Store(value)
+	return a
+}
+
+// Load returns the current value.
+func (a *Atomic[T]) Load() T {
+	if p := a.ptr.Load(); p != nil {
+		return *p
+	}
+	var zero T
+	return zero
+}
+
+// Store sets the value.
+func (a *Atomic[T]) Store(value T) {
+	a.ptr.Store(&value)
+}
+
+// Swap sets the value and returns the previous one.
+func (a *Atomic[T]) Swap(value T) T {
+	if p := a.ptr.Swap(&value); p != nil {
+		return *p
+	}
+	var zero T
+	return zero
+}
+
+// CompareAndSwap sets a's value to new if it currently equals old, and reports whether it did.
+func CompareAndSwap[T comparable](a *Atomic[T], old, new T) bool {
+	for {
+		p := a.ptr.Load()
+		var current T
+		if p != nil {
+			current = *p
+		}
+		if current != old {
+			return false
+		}
+		if a.ptr.CompareAndSwap(p, &new) {
+			return true
+		}
+	}
+}
+
+// Float64 is a float64 that is updated atomically. The zero value is 0 and ready to use.
+type Float64 struct {
+	bits atomic.Uint64
+}
+
+// Load returns the current value.
+func (f *Float64) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+// Store sets the value.
+func (f *Float64) Store(value float64) {
+	f.bits.Store(math.Float64bits(value))
+}
+
+// Swap sets the value and returns the previous one.
+func (f *Float64) Swap(value float64) float64 {
+	return math.Float64frombits(f.bits.Swap(math.Float64bits(value)))
+}
+
+// CompareAndSwap sets the value to new if it is currently old, and reports whether it did.
+// Values are compared bit for bit, so NaN can be swapped out but 0 and -0 differ.
+func (f *Float64) CompareAndSwap(old, new float64) bool {
+	return f.bits.CompareAndSwap(math.Float64bits(old), math.Float64bits(new))
+}
+
+// Add adds delta and returns the new value.
+func (f *Float64) Add(delta float64) float64 {
+	for {
+		old := f.bits.Load()
+		value := math.Float64frombits(old) + delta
+		if f.bits.CompareAndSwap(old, math.Float64bits(value)) {
+			return value
+		}
+	}
+}
+
+// Number is an atomic number that can be compared and swapped, such as *atomic.Int64,
+// *atomic.Uint32 or *Float64.
+type Number[T cmp.Ordered] interface {
+	Load() T
+	CompareAndSwap(old, new T) bool
+}
+
+// Max raises a's value to value if it is smaller, and returns the resulting value.
+func Max[T cmp.Ordered, A Number[T]](a A, value T) T {
+	for {
+		current := a.Load()
+		if current >= value {
+			return current
+		}
+		if a.CompareAndSwap(current, value) {
+			return value
+		}
+	}
+}
+
+// Min lowers a's value to value if it is larger, and returns the resulting value.
+func Min[T cmp.Ordered, A Number[T]](a A, value T) T {
+	for {
+		current := a.Load()
+		if current <= value {
+			return current
+		}
+		if a.CompareAndSwap(current, value) {
+			return value
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package atomicx_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cjsaylor/goutil/atomicx"
+)
+
+type config struct {
+	Name string
+}
+
+func TestAtomic(t *testing.T) {
+	var a atomicx.Atomic[config]
+	if a.Load().Name != "" {
+		t.Error("Expected the zero value")
+	}
+	a.Store(config{Name: "a"})
+	if old := a.Swap(config{Name: "b"}); old.Name != "a" {
+		t.Errorf("Expected a got %s", old.Name)
+	}
+	if a.Load().Name != "b" {
+		t.Errorf("Expected b got %s", a.Load().Name)
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	a := atomicx.New("x")
+	if atomicx.CompareAndSwap(a, "y", "z") {
+		t.Error("Expected a mismatched swap to fail")
+	}
+	if !atomicx.CompareAndSwap(a, "x", "z") || a.Load() != "z" {
+		t.Errorf("Expected a matching swap to succeed got %s", a.Load())
+	}
+	var empty atomicx.Atomic[int]
+	if !atomicx.CompareAndSwap(&empty, 0, 1) || empty.Load() != 1 {
+		t.Error("Expected the zero value to compare equal to zero")
+	}
+}
+
+func TestFloat64Add(t *testing.T) {
+	var f atomicx.Float64
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.Add(0.5)
+		}()
+	}
+	wg.Wait()
+	if f.Load() != 50 {
+		t.Errorf("Expected 50 got %v", f.Load())
+	}
+	if !f.CompareAndSwap(50, 1) || f.Swap(2) != 1 {
+		t.Error("Expected compare and swap to replace the value")
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	var peak atomic.Int64
+	wg := &sync.WaitGroup{}
+	for i := int64(0); i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			atomicx.Max(&peak, i)
+		}()
+	}
+	wg.Wait()
+	if peak.Load() != 99 {
+		t.Errorf("Expected a max of 99 got %d", peak.Load())
+	}
+	var low atomicx.Float64
+	low.Store(10)
+	if got := atomicx.Min(&low, 2.5); got != 2.5 {
+		t.Errorf("Expected a min of 2.5 got %v", got)
+	}
+	if got := atomicx.Min(&low, 7.0); got != 2.5 {
+		t.Errorf("Expected the min to stay 2.5 got %v", got)
+	}
+}
@@ -0,0 +1,187 @@
+// Package batcher is a package that groups individually submitted items into batches.
+//
+// A batch is processed once it reaches its maximum size or once its oldest item has waited the
+// maximum delay, whichever comes first. Each caller receives the result for its own item.
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed is returned when adding to a batcher that has been closed.
+	ErrClosed = errors.New("batcher: closed")
+	// ErrResultCount is returned for every item of a batch whose function returned the wrong number
+	// of results.
+	ErrResultCount = errors.New("batcher: result count does not match batch size")
+)
+
+// Options configures a batcher.
+type Options struct {
+	// MaxSize is the largest number of items in a batch. Defaults to 100.
+	MaxSize int
+	// MaxDelay is the longest an item waits for its batch to fill. Defaults to 10 milliseconds.
+	MaxDelay time.Duration
+	// QueueSize is the number of items that may wait for the running batch to finish before Add
+	// blocks. Defaults to MaxSize.
+	QueueSize int
+}
+
+type result[R any] struct {
+	value R
+	err   error
+}
+
+type request[T, R any] struct {
+	item   T
+	result chan result[R]
+}
+
+// Batcher gathers items and hands them to a function in batches. Batches are processed one at a
+// time, so a slow function pushes back on callers through the queue. It is safe for concurrent use.
+type Batcher[T, R any] struct {
+	fn        func(items []T) ([]R, error)
+	options   Options
+	queue     chan request[T, R]
+	closing   chan struct{}
+	closed    bool
+	closeOnce *sync.Once
+	submit    *sync.RWMutex
+	done      chan struct{}
+}
+
+// New creates a batcher that runs fn for each batch. fn must return one result per item, in order,
+// or an error that is delivered to every item in the batch.
+func New[T, R any](fn func(items []T) ([]R, error), options Options) *Batcher[T, R] {
+	if options.MaxSize <= 0 {
+		options.MaxSize = 100
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = 10 * time.Millisecond
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = options.MaxSize
+	}
+	b := &Batcher[T, R]{
+		fn:        fn,
+		options:   options,
+		queue:     make(chan request[T, R], options.QueueSize),
+		closing:   make(chan struct{}),
+		closeOnce: &sync.Once{},
+		submit:    &sync.RWMutex{},
+		done:      make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+// Add submits item and waits for its result. If ctx is done first Add returns ctx.Err(), but an item
+// already queued is still processed.
+func (b *Batcher[T, R]) Add(ctx context.Context, item T) (R, error) {
+	req := request[T, R]{item: item, result: make(chan result[R], 1)}
+	if err := b.enqueue(ctx, req); err != nil {
+		var zero R
+		return zero, err
+	}
+	select {
+	case res := <-req.result:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
+
+// Close stops accepting items, processes every item already queued, and waits for it to finish.
+func (b *Batcher[T, R]) Close() {
+	b.closeOnce.Do(func() {
+		close(b.closing)
+		b.submit.Lock()
+		b.closed = true
+		close(b.queue)
+		b.submit.Unlock()
+	})
+	<-b.done
+}
+
+// Shutdown closes the batcher like Close, but stops waiting when ctx is done.
+func (b *Batcher[T, R]) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Batcher[T, R]) enqueue(ctx context.Context, req request[T, R]) error {
+	b.submit.RLock()
+	defer b.submit.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+	select {
+	case b.queue <- req:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.closing:
+		return ErrClosed
+	}
+}
+
+func (b *Batcher[T, R]) loop() {
+	defer close(b.done)
+	batch := make([]request[T, R], 0, b.options.MaxSize)
+	timer := time.NewTimer(b.options.MaxDelay)
+	timer.Stop()
+	for {
+		select {
+		case req, ok := <-b.queue:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(b.options.MaxDelay)
+			}
+			batch = append(batch, req)
+			if len(batch) < b.options.MaxSize {
+				continue
+			}
+			timer.Stop()
+		case <-timer.C:
+		}
+		b.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+func (b *Batcher[T, R]) flush(batch []request[T, R]) {
+	if len(batch) == 0 {
+		return
+	}
+	items := make([]T, len(batch))
+	for i, req := range batch {
+		items[i] = req.item
+	}
+	values, err := b.fn(items)
+	if err == nil && len(values) != len(batch) {
+		err = ErrResultCount
+	}
+	for i, req := range batch {
+		if err != nil {
+			req.result <- result[R]{err: err}
+		} else {
+			req.result <- result[R]{value: values[i]}
+		}
+	}
+}
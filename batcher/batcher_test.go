@@ -0,0 +1,126 @@
+package batcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/batcher"
+)
+
+func TestBatchBySize(t *testing.T) {
+	var mutex sync.Mutex
+	var sizes []int
+	b := batcher.New(func(items []int) ([]int, error) {
+		mutex.Lock()
+		sizes = append(sizes, len(items))
+		mutex.Unlock()
+		results := make([]int, len(items))
+		for i, item := range items {
+			results[i] = item * 10
+		}
+		return results, nil
+	}, batcher.Options{MaxSize: 5, MaxDelay: time.Hour})
+	defer b.Close()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if val, err := b.Add(context.Background(), i); val != i*10 || err != nil {
+				t.Errorf("Expected %d got %v (%v)", i*10, val, err)
+			}
+		}()
+	}
+	wg.Wait()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(sizes) != 2 || sizes[0] != 5 || sizes[1] != 5 {
+		t.Errorf("Expected two full batches got %v", sizes)
+	}
+}
+
+func TestBatchByDelay(t *testing.T) {
+	b := batcher.New(func(items []string) ([]int, error) {
+		results := make([]int, len(items))
+		for i, item := range items {
+			results[i] = len(item)
+		}
+		return results, nil
+	}, batcher.Options{MaxSize: 100, MaxDelay: 5 * time.Millisecond})
+	defer b.Close()
+	start := time.Now()
+	if val, err := b.Add(context.Background(), "abc"); val != 3 || err != nil {
+		t.Errorf("Expected 3 got %v (%v)", val, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Expected a partial batch to flush after the delay")
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	failure := errors.New("failure")
+	b := batcher.New(func(items []int) ([]int, error) {
+		return nil, failure
+	}, batcher.Options{MaxSize: 1})
+	defer b.Close()
+	if _, err := b.Add(context.Background(), 1); err != failure {
+		t.Errorf("Expected the batch error got %v", err)
+	}
+	short := batcher.New(func(items []int) ([]int, error) {
+		return nil, nil
+	}, batcher.Options{MaxSize: 1})
+	defer short.Close()
+	if _, err := short.Add(context.Background(), 1); err != batcher.ErrResultCount {
+		t.Errorf("Expected a result count error got %v", err)
+	}
+}
+
+func TestCloseFlushes(t *testing.T) {
+	var mutex sync.Mutex
+	processed := 0
+	b := batcher.New(func(items []int) ([]int, error) {
+		mutex.Lock()
+		processed += len(items)
+		mutex.Unlock()
+		return items, nil
+	}, batcher.Options{MaxSize: 100, MaxDelay: time.Hour})
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Add(context.Background(), i)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	b.Close()
+	wg.Wait()
+	if processed != 3 {
+		t.Errorf("Expected close to flush 3 items got %d", processed)
+	}
+	if _, err := b.Add(context.Background(), 4); err != batcher.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+}
+
+func TestBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	b := batcher.New(func(items []int) ([]int, error) {
+		<-release
+		return items, nil
+	}, batcher.Options{MaxSize: 1, QueueSize: 1})
+	go b.Add(context.Background(), 1)
+	time.Sleep(5 * time.Millisecond)
+	go b.Add(context.Background(), 2)
+	time.Sleep(5 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.Add(ctx, 3); err != context.DeadlineExceeded {
+		t.Errorf("Expected a full queue to block got %v", err)
+	}
+	close(release)
+	b.Close()
+}
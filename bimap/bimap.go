@@ -0,0 +1,86 @@
+// Package bimap is a package that implements a one-to-one map that can be looked up in either direction.
+package bimap
+
+// BiMap maintains a key to value index and a value to key index that are always consistent.
+// Each key maps to exactly one value and each value to exactly one key. It is not safe for concurrent use.
+type BiMap[K comparable, V comparable] struct {
+	forward map[K]V
+	inverse map[V]K
+}
+
+// New creates an empty bidirectional map.
+func New[K comparable, V comparable]() *BiMap[K, V] {
+	return &BiMap[K, V]{
+		forward: make(map[K]V),
+		inverse: make(map[V]K),
+	}
+}
+
+// Put associates key with value.
+// Any existing association of key, or of value, is removed first.
+func (m *BiMap[K, V]) Put(key K, value V) {
+	if oldValue, ok := m.forward[key]; ok {
+		delete(m.inverse, oldValue)
+	}
+	if oldKey, ok := m.inverse[value]; ok {
+		delete(m.forward, oldKey)
+	}
+	m.forward[key] = value
+	m.inverse[value] = key
+}
+
+// Get returns the value associated with key.
+func (m *BiMap[K, V]) Get(key K) (V, bool) {
+	value, ok := m.forward[key]
+	return value, ok
+}
+
+// GetKey returns the key associated with value.
+func (m *BiMap[K, V]) GetKey(value V) (K, bool) {
+	key, ok := m.inverse[value]
+	return key, ok
+}
+
+// RemoveKey removes key and its value, returning the value.
+func (m *BiMap[K, V]) RemoveKey(key K) (V, bool) {
+	value, ok := m.forward[key]
+	if ok {
+		delete(m.forward, key)
+		delete(m.inverse, value)
+	}
+	return value, ok
+}
+
+// RemoveValue removes value and its key, returning the key.
+func (m *BiMap[K, V]) RemoveValue(value V) (K, bool) {
+	key, ok := m.inverse[value]
+	if ok {
+		delete(m.inverse, value)
+		delete(m.forward, key)
+	}
+	return key, ok
+}
+
+// Len returns the number of associations.
+func (m *BiMap[K, V]) Len() int {
+	return len(m.forward)
+}
+
+// Inverse returns a view of the map with keys and values swapped.
+// Changes made through either view are visible in both.
+func (m *BiMap[K, V]) Inverse() *BiMap[V, K] {
+	return &BiMap[V, K]{
+		forward: m.inverse,
+		inverse: m.forward,
+	}
+}
+
+// Each calls fn for every association in no particular order.
+// Iteration stops early if fn returns false.
+func (m *BiMap[K, V]) Each(fn func(key K, value V) bool) {
+	for key, value := range m.forward {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
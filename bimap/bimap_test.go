@@ -0,0 +1,60 @@
+package bimap_test
+
+import (
+	"testing"
+
+	"github.com/cjsaylor/goutil/bimap"
+)
+
+func TestPutGet(t *testing.T) {
+	m := bimap.New[int, string]()
+	m.Put(1, "alice")
+	m.Put(2, "bob")
+	if value, ok := m.Get(1); !ok || value != "alice" {
+		t.Errorf("Expected 'alice' got %v", value)
+	}
+	if key, ok := m.GetKey("bob"); !ok || key != 2 {
+		t.Errorf("Expected 2 got %v", key)
+	}
+}
+
+func TestPutReplacesBothDirections(t *testing.T) {
+	m := bimap.New[int, string]()
+	m.Put(1, "alice")
+	m.Put(2, "bob")
+	m.Put(1, "bob")
+	if m.Len() != 1 {
+		t.Errorf("Expected 1 association got %d", m.Len())
+	}
+	if _, ok := m.Get(2); ok {
+		t.Error("Expected 2 to be removed when 'bob' was reassigned")
+	}
+	if _, ok := m.GetKey("alice"); ok {
+		t.Error("Expected 'alice' to be removed when 1 was reassigned")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := bimap.New[int, string]()
+	m.Put(1, "alice")
+	m.Put(2, "bob")
+	if value, ok := m.RemoveKey(1); !ok || value != "alice" {
+		t.Error("Expected to remove 1")
+	}
+	if key, ok := m.RemoveValue("bob"); !ok || key != 2 {
+		t.Error("Expected to remove 'bob'")
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expected empty map got %d", m.Len())
+	}
+}
+
+func TestInverse(t *testing.T) {
+	m := bimap.New[int, string]()
+	m.Put(1, "alice")
+	inverse := m.Inverse()
+	inverse.Put("bob", 2)
+	if value, ok := m.Get(2); !ok || value != "bob" {
+		t.Error("Expected changes through the inverse to be visible")
+	}
+}
@@ -0,0 +1,164 @@
+// Package bitset is a package that implements a growable set of non-negative integers stored as bits.
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// ErrInvalidData is returned when decoding malformed binary data.
+var ErrInvalidData = errors.New("bitset: invalid data")
+
+// BitSet is a set of bits that grows as higher bits are set. The zero value is an empty set.
+// It is not safe for concurrent use.
+type BitSet struct {
+	words []uint64
+}
+
+// New creates an empty bit set with room for n bits before growing.
+func New(n uint) *BitSet {
+	return &BitSet{words: make([]uint64, (n+63)/64)}
+}
+
+// Set bit i.
+func (b *BitSet) Set(i uint) *BitSet {
+	b.grow(i/64 + 1)
+	b.words[i/64] |= 1 << (i % 64)
+	return b
+}
+
+// Clear bit i.
+func (b *BitSet) Clear(i uint) *BitSet {
+	if i/64 < uint(len(b.words)) {
+		b.words[i/64] &^= 1 << (i % 64)
+	}
+	return b
+}
+
+// Test reports whether bit i is set.
+func (b *BitSet) Test(i uint) bool {
+	return i/64 < uint(len(b.words)) && b.words[i/64]&(1<<(i%64)) != 0
+}
+
+// Count returns the number of set bits.
+func (b *BitSet) Count() int {
+	total := 0
+	for _, word := range b.words {
+		total += bits.OnesCount64(word)
+	}
+	return total
+}
+
+// NextSet returns the first set bit at or after i.
+//
+//	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+//		...
+//	}
+func (b *BitSet) NextSet(i uint) (uint, bool) {
+	index := i / 64
+	if index >= uint(len(b.words)) {
+		return 0, false
+	}
+	if word := b.words[index] >> (i % 64); word != 0 {
+		return i + uint(bits.TrailingZeros64(word)), true
+	}
+	for index++; index < uint(len(b.words)); index++ {
+		if b.words[index] != 0 {
+			return index*64 + uint(bits.TrailingZeros64(b.words[index])), true
+		}
+	}
+	return 0, false
+}
+
+// And keeps only the bits also set in other.
+func (b *BitSet) And(other *BitSet) *BitSet {
+	for i := range b.words {
+		if i < len(other.words) {
+			b.words[i] &= other.words[i]
+		} else {
+			b.words[i] = 0
+		}
+	}
+	return b
+}
+
+// Or sets every bit set in other.
+func (b *BitSet) Or(other *BitSet) *BitSet {
+	b.grow(uint(len(other.words)))
+	for i, word := range other.words {
+		b.words[i] |= word
+	}
+	return b
+}
+
+// Xor toggles every bit set in other.
+func (b *BitSet) Xor(other *BitSet) *BitSet {
+	b.grow(uint(len(other.words)))
+	for i, word := range other.words {
+		b.words[i] ^= word
+	}
+	return b
+}
+
+// AndNot clears every bit set in other.
+func (b *BitSet) AndNot(other *BitSet) *BitSet {
+	for i := 0; i < len(b.words) && i < len(other.words); i++ {
+		b.words[i] &^= other.words[i]
+	}
+	return b
+}
+
+// Clone returns a copy of the bit set.
+func (b *BitSet) Clone() *BitSet {
+	return &BitSet{words: append([]uint64(nil), b.words...)}
+}
+
+// Equal reports whether both sets contain the same bits.
+func (b *BitSet) Equal(other *BitSet) bool {
+	long, short := b.words, other.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+	for i, word := range long {
+		if i < len(short) {
+			if word != short[i] {
+				return false
+			}
+		} else if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary encodes the bit set, omitting trailing empty words.
+func (b *BitSet) MarshalBinary() ([]byte, error) {
+	n := len(b.words)
+	for n > 0 && b.words[n-1] == 0 {
+		n--
+	}
+	data := make([]byte, 8*n)
+	for i := 0; i < n; i++ {
+		binary.LittleEndian.PutUint64(data[8*i:], b.words[i])
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a bit set encoded with MarshalBinary.
+func (b *BitSet) UnmarshalBinary(data []byte) error {
+	if len(data)%8 != 0 {
+		return ErrInvalidData
+	}
+	b.words = make([]uint64, len(data)/8)
+	for i := range b.words {
+		b.words[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	return nil
+}
+
+func (b *BitSet) grow(words uint) {
+	if words > uint(len(b.words)) {
+		b.words = append(b.words, make([]uint64, words-uint(len(b.words)))...)
+	}
+}
@@ -0,0 +1,66 @@
+package bitset_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cjsaylor/goutil/bitset"
+)
+
+func members(b *bitset.BitSet) []uint {
+	var ret []uint
+	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+func TestSetClearTest(t *testing.T) {
+	var b bitset.BitSet
+	b.Set(1).Set(64).Set(200)
+	b.Clear(64).Clear(1000)
+	if !b.Test(1) || b.Test(64) || !b.Test(200) || b.Test(5000) {
+		t.Error("Expected bits 1 and 200 to be set")
+	}
+	if b.Count() != 2 {
+		t.Errorf("Expected 2 bits got %d", b.Count())
+	}
+	if result := members(&b); !reflect.DeepEqual(result, []uint{1, 200}) {
+		t.Errorf("Expected [1 200] got %v", result)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	a := bitset.New(0).Set(1).Set(2).Set(100)
+	b := bitset.New(0).Set(2).Set(3)
+	if result := members(a.Clone().And(b)); !reflect.DeepEqual(result, []uint{2}) {
+		t.Errorf("Expected and [2] got %v", result)
+	}
+	if result := members(a.Clone().Or(b)); !reflect.DeepEqual(result, []uint{1, 2, 3, 100}) {
+		t.Errorf("Expected or [1 2 3 100] got %v", result)
+	}
+	if result := members(a.Clone().Xor(b)); !reflect.DeepEqual(result, []uint{1, 3, 100}) {
+		t.Errorf("Expected xor [1 3 100] got %v", result)
+	}
+	if result := members(a.Clone().AndNot(b)); !reflect.DeepEqual(result, []uint{1, 100}) {
+		t.Errorf("Expected and not [1 100] got %v", result)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	b := bitset.New(1024).Set(3).Set(70)
+	data, err := b.MarshalBinary()
+	if err != nil || len(data) != 16 {
+		t.Fatalf("Expected 16 bytes got %d (%v)", len(data), err)
+	}
+	var decoded bitset.BitSet
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(b) {
+		t.Errorf("Expected decoded set to equal original got %v", members(&decoded))
+	}
+	if err := decoded.UnmarshalBinary(data[:3]); err != bitset.ErrInvalidData {
+		t.Errorf("Expected invalid data error got %v", err)
+	}
+}
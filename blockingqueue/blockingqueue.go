@@ -0,0 +1,142 @@
+// Package blockingqueue is a package that implements a fixed-capacity FIFO queue
+// whose producers block while it is full and whose consumers block while it is empty.
+package blockingqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/cjsaylor/goutil/ringbuffer"
+)
+
+// ErrClosed is returned when putting into a closed queue, or taking from a closed and drained queue.
+var ErrClosed = errors.New("blockingqueue: queue closed")
+
+// Queue is a bounded FIFO queue that is safe for concurrent use.
+// Timeouts are expressed through the deadline of the context passed to Put and Take.
+type Queue[T any] struct {
+	items    *ringbuffer.Buffer[T]
+	closed   bool
+	notEmpty chan struct{}
+	notFull  chan struct{}
+	mutex    *sync.Mutex
+}
+
+// New creates an empty queue holding at most capacity items.
+func New[T any](capacity int) *Queue[T] {
+	return &Queue[T]{
+		items:    ringbuffer.New[T](capacity, ringbuffer.Reject),
+		notEmpty: make(chan struct{}),
+		notFull:  make(chan struct{}),
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// Put adds an item to the back of the queue, waiting for space if the queue is full.
+func (q *Queue[T]) Put(ctx context.Context, item T) error {
+	for {
+		q.mutex.Lock()
+		if q.closed {
+			q.mutex.Unlock()
+			return ErrClosed
+		}
+		if q.items.Push(item) {
+			q.notEmpty = broadcast(q.notEmpty)
+			q.mutex.Unlock()
+			return nil
+		}
+		wait := q.notFull
+		q.mutex.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Offer adds an item to the back of the queue without waiting, reporting whether it was added.
+func (q *Queue[T]) Offer(item T) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed || !q.items.Push(item) {
+		return false
+	}
+	q.notEmpty = broadcast(q.notEmpty)
+	return true
+}
+
+// Take removes and returns the item at the front of the queue, waiting for one if the queue is empty.
+// Items remaining when the queue is closed can still be taken.
+func (q *Queue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		q.mutex.Lock()
+		if item, ok := q.items.Pop(); ok {
+			q.notFull = broadcast(q.notFull)
+			q.mutex.Unlock()
+			return item, nil
+		}
+		if q.closed {
+			q.mutex.Unlock()
+			var zero T
+			return zero, ErrClosed
+		}
+		wait := q.notEmpty
+		q.mutex.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Poll removes and returns the item at the front of the queue without waiting.
+func (q *Queue[T]) Poll() (T, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	item, ok := q.items.Pop()
+	if ok {
+		q.notFull = broadcast(q.notFull)
+	}
+	return item, ok
+}
+
+// Peek returns the item at the front of the queue without removing it.
+func (q *Queue[T]) Peek() (T, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.items.Peek()
+}
+
+// Len returns the number of items in the queue.
+func (q *Queue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.items.Len()
+}
+
+// Cap returns the capacity of the queue.
+func (q *Queue[T]) Cap() int {
+	return q.items.Cap()
+}
+
+// Close stops the queue from accepting items and wakes every waiting caller.
+func (q *Queue[T]) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	q.notEmpty = broadcast(q.notEmpty)
+	q.notFull = broadcast(q.notFull)
+}
+
+// broadcast wakes every goroutine waiting on ch and returns a fresh channel for future waiters.
+func broadcast(ch chan struct{}) chan struct{} {
+	close(ch)
+	return make(chan struct{})
+}
@@ -0,0 +1,102 @@
+package blockingqueue_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/blockingqueue"
+)
+
+func TestPutTake(t *testing.T) {
+	q := blockingqueue.New[int](2)
+	ctx := context.Background()
+	q.Put(ctx, 1)
+	q.Put(ctx, 2)
+	if item, ok := q.Peek(); !ok || item != 1 || q.Len() != 2 {
+		t.Errorf("Expected to peek 1 got %v", item)
+	}
+	if item, err := q.Take(ctx); err != nil || item != 1 {
+		t.Errorf("Expected 1 got %v (%v)", item, err)
+	}
+}
+
+func TestPutBlocksWhenFull(t *testing.T) {
+	q := blockingqueue.New[int](1)
+	q.Put(context.Background(), 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Put(ctx, 2); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+	if q.Offer(2) {
+		t.Error("Expected offer into a full queue to fail")
+	}
+}
+
+func TestTakeWaitsForPut(t *testing.T) {
+	q := blockingqueue.New[int](1)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		q.Put(context.Background(), 42)
+	}()
+	if item, err := q.Take(context.Background()); err != nil || item != 42 {
+		t.Errorf("Expected 42 got %v (%v)", item, err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	q := blockingqueue.New[int](2)
+	q.Put(context.Background(), 1)
+	q.Close()
+	if err := q.Put(context.Background(), 2); err != blockingqueue.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+	if item, err := q.Take(context.Background()); err != nil || item != 1 {
+		t.Errorf("Expected remaining item to be taken got %v (%v)", item, err)
+	}
+	if _, err := q.Take(context.Background()); err != blockingqueue.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+}
+
+func TestCloseWakesWaiters(t *testing.T) {
+	q := blockingqueue.New[int](1)
+	done := make(chan error)
+	go func() {
+		_, err := q.Take(context.Background())
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	q.Close()
+	if err := <-done; err != blockingqueue.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+}
+
+func TestProducersConsumers(t *testing.T) {
+	q := blockingqueue.New[int](4)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				q.Put(ctx, i)
+			}
+		}()
+	}
+	total := 0
+	for i := 0; i < 400; i++ {
+		if _, err := q.Take(ctx); err != nil {
+			t.Fatal(err)
+		}
+		total++
+	}
+	wg.Wait()
+	if total != 400 || q.Len() != 0 {
+		t.Errorf("Expected 400 items taken got %d", total)
+	}
+}
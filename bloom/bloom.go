@@ -0,0 +1,166 @@
+// Package bloom is a package that implements probabilistic set membership filters.
+// A filter may report false positives at a configurable rate, but never false negatives.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/cjsaylor/goutil/internal/doublehash"
+)
+
+var (
+	// ErrIncompatible is returned when combining filters with different sizes or hash counts.
+	ErrIncompatible = errors.New("bloom: filters are incompatible")
+	// ErrInvalidData is returned when decoding malformed binary data.
+	ErrInvalidData = errors.New("bloom: invalid data")
+	// ErrRate is returned when a false positive rate is not strictly between 0 and 1.
+	ErrRate = errors.New("bloom: false positive rate must be between 0 and 1")
+)
+
+// Filter is a probabilistic set membership test.
+type Filter interface {
+	// Add inserts data into the filter, reporting whether it was stored.
+	Add(data []byte) bool
+	// Test reports whether data may have been added to the filter.
+	Test(data []byte) bool
+}
+
+// Standard is a bloom filter backed by a bit array. It is not safe for concurrent use.
+type Standard struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// New creates a bloom filter sized to hold n items at the false positive rate fpRate.
+func New(n uint64, fpRate float64) (*Standard, error) {
+	m, k, err := Estimate(n, fpRate)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithSize(m, k), nil
+}
+
+// NewWithSize creates a bloom filter with m bits and k hash functions.
+func NewWithSize(m, k uint64) *Standard {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+	return &Standard{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// Estimate returns the number of bits and hash functions needed to hold n items at the false positive rate
+// fpRate. It returns ErrRate unless 0 < fpRate < 1.
+func Estimate(n uint64, fpRate float64) (m, k uint64, err error) {
+	if !(fpRate > 0 && fpRate < 1) {
+		return 0, 0, ErrRate
+	}
+	if n == 0 {
+		n = 1
+	}
+	m = uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k = uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k == 0 {
+		k = 1
+	}
+	return m, k, nil
+}
+
+// Add inserts data into the filter. It always reports true.
+func (f *Standard) Add(data []byte) bool {
+	h1, h2 := doublehash.Sum(data)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	return true
+}
+
+// Test reports whether data may have been added to the filter.
+func (f *Standard) Test(data []byte) bool {
+	h1, h2 := doublehash.Sum(data)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Union adds every item in other to the filter.
+func (f *Standard) Union(other *Standard) error {
+	if f.m != other.m || f.k != other.k {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] |= other.bits[i]
+	}
+	return nil
+}
+
+// Intersection keeps only the bits set in both filters.
+// The result may report more false positives than a filter built from the true intersection.
+func (f *Standard) Intersection(other *Standard) error {
+	if f.m != other.m || f.k != other.k {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] &= other.bits[i]
+	}
+	return nil
+}
+
+// ApproximateCount estimates the number of distinct items added to the filter. A saturated filter,
+// with every bit set, reports the estimate for all but one bit set, the largest it can distinguish.
+func (f *Standard) ApproximateCount() uint64 {
+	var set uint64
+	for _, word := range f.bits {
+		set += uint64(bits.OnesCount64(word))
+	}
+	if set >= f.m {
+		set = f.m - 1
+	}
+	m, k := float64(f.m), float64(f.k)
+	return uint64(math.Round(-m / k * math.Log(1-float64(set)/m)))
+}
+
+// MarshalBinary encodes the filter.
+func (f *Standard) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 16+8*len(f.bits))
+	binary.BigEndian.PutUint64(data, f.m)
+	binary.BigEndian.PutUint64(data[8:], f.k)
+	for i, word := range f.bits {
+		binary.BigEndian.PutUint64(data[16+8*i:], word)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a filter encoded with MarshalBinary.
+func (f *Standard) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return ErrInvalidData
+	}
+	m := binary.BigEndian.Uint64(data)
+	k := binary.BigEndian.Uint64(data[8:])
+	words := (m + 63) / 64
+	if m == 0 || k == 0 || uint64(len(data)-16) != 8*words {
+		return ErrInvalidData
+	}
+	f.m, f.k = m, k
+	f.bits = make([]uint64, words)
+	for i := range f.bits {
+		f.bits[i] = binary.BigEndian.Uint64(data[16+8*i:])
+	}
+	return nil
+}
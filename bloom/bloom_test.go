@@ -0,0 +1,103 @@
+package bloom_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/bloom"
+)
+
+func TestAddTest(t *testing.T) {
+	f, _ := bloom.New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.Test([]byte(strconv.Itoa(i))) {
+			t.Fatalf("Expected %d to be in the filter", i)
+		}
+	}
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if f.Test([]byte(strconv.Itoa(i))) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 10000; rate > 0.02 {
+		t.Errorf("Expected false positive rate near 0.01 got %v", rate)
+	}
+}
+
+func TestUnionIntersection(t *testing.T) {
+	a, _ := bloom.New(100, 0.01)
+	b, _ := bloom.New(100, 0.01)
+	a.Add([]byte("foo"))
+	b.Add([]byte("bar"))
+	if err := a.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Test([]byte("foo")) || !a.Test([]byte("bar")) {
+		t.Error("Expected union to contain both items")
+	}
+	if err := b.Intersection(a); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Test([]byte("bar")) {
+		t.Error("Expected intersection to contain 'bar'")
+	}
+	other, _ := bloom.New(10, 0.1)
+	if err := a.Union(other); err != bloom.ErrIncompatible {
+		t.Errorf("Expected incompatible error got %v", err)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	f, _ := bloom.New(100, 0.01)
+	f.Add([]byte("foo"))
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded bloom.Standard
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Test([]byte("foo")) {
+		t.Error("Expected decoded filter to contain 'foo'")
+	}
+	if err := decoded.UnmarshalBinary(data[:20]); err != bloom.ErrInvalidData {
+		t.Errorf("Expected invalid data error got %v", err)
+	}
+}
+
+func TestApproximateCount(t *testing.T) {
+	f, _ := bloom.New(1000, 0.01)
+	for i := 0; i < 500; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	if count := f.ApproximateCount(); count < 450 || count > 550 {
+		t.Errorf("Expected approximately 500 got %d", count)
+	}
+	saturated, _ := bloom.New(10, 0.5)
+	for i := 0; i < 10000; i++ {
+		saturated.Add([]byte(strconv.Itoa(i)))
+	}
+	if count := saturated.ApproximateCount(); count == 0 || count > 1000 {
+		t.Errorf("Expected a saturated filter to report a finite count got %d", count)
+	}
+}
+
+func TestInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -0.1, 1, 2, math.NaN()} {
+		if _, _, err := bloom.Estimate(100, rate); err != bloom.ErrRate {
+			t.Errorf("Expected a rate of %v to be rejected got %v", rate, err)
+		}
+		if _, err := bloom.New(100, rate); err != bloom.ErrRate {
+			t.Errorf("Expected New to reject a rate of %v got %v", rate, err)
+		}
+		if _, err := bloom.NewCounting(100, rate); err != bloom.ErrRate {
+			t.Errorf("Expected NewCounting to reject a rate of %v got %v", rate, err)
+		}
+	}
+}
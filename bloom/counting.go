@@ -0,0 +1,104 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/cjsaylor/goutil/internal/doublehash"
+)
+
+// Counting is a bloom filter backed by 8-bit counters, which allows items to be removed.
+// Counters saturate rather than overflow, and a saturated counter is never decremented.
+// It is not safe for concurrent use.
+type Counting struct {
+	counters []uint8
+	m        uint64
+	k        uint64
+}
+
+// NewCounting creates a counting bloom filter sized to hold n items at the false positive rate fpRate.
+func NewCounting(n uint64, fpRate float64) (*Counting, error) {
+	m, k, err := Estimate(n, fpRate)
+	if err != nil {
+		return nil, err
+	}
+	return NewCountingWithSize(m, k), nil
+}
+
+// NewCountingWithSize creates a counting bloom filter with m counters and k hash functions.
+func NewCountingWithSize(m, k uint64) *Counting {
+	if m == 0 {
+		m = 1
+	}
+	if k == 0 {
+		k = 1
+	}
+	return &Counting{
+		counters: make([]uint8, m),
+		m:        m,
+		k:        k,
+	}
+}
+
+// Add inserts data into the filter. It always reports true.
+func (f *Counting) Add(data []byte) bool {
+	h1, h2 := doublehash.Sum(data)
+	for i := uint64(0); i < f.k; i++ {
+		index := (h1 + i*h2) % f.m
+		if f.counters[index] < math.MaxUint8 {
+			f.counters[index]++
+		}
+	}
+	return true
+}
+
+// Test reports whether data may have been added to the filter.
+func (f *Counting) Test(data []byte) bool {
+	h1, h2 := doublehash.Sum(data)
+	for i := uint64(0); i < f.k; i++ {
+		if f.counters[(h1+i*h2)%f.m] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove deletes data from the filter, reporting whether it may have been present.
+// Removing data that was never added can introduce false negatives for other items.
+func (f *Counting) Remove(data []byte) bool {
+	if !f.Test(data) {
+		return false
+	}
+	h1, h2 := doublehash.Sum(data)
+	for i := uint64(0); i < f.k; i++ {
+		index := (h1 + i*h2) % f.m
+		if f.counters[index] < math.MaxUint8 {
+			f.counters[index]--
+		}
+	}
+	return true
+}
+
+// MarshalBinary encodes the filter.
+func (f *Counting) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 16+len(f.counters))
+	binary.BigEndian.PutUint64(data, f.m)
+	binary.BigEndian.PutUint64(data[8:], f.k)
+	copy(data[16:], f.counters)
+	return data, nil
+}
+
+// UnmarshalBinary decodes a filter encoded with MarshalBinary.
+func (f *Counting) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return ErrInvalidData
+	}
+	m := binary.BigEndian.Uint64(data)
+	k := binary.BigEndian.Uint64(data[8:])
+	if m == 0 || k == 0 || uint64(len(data)-16) != m {
+		return ErrInvalidData
+	}
+	f.m, f.k = m, k
+	f.counters = append([]uint8(nil), data[16:]...)
+	return nil
+}
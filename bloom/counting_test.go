@@ -0,0 +1,58 @@
+package bloom_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/bloom"
+)
+
+func TestCountingRemove(t *testing.T) {
+	f, _ := bloom.NewCounting(100, 0.01)
+	f.Add([]byte("foo"))
+	f.Add([]byte("bar"))
+	if !f.Remove([]byte("foo")) {
+		t.Error("Expected 'foo' to be removed")
+	}
+	if f.Test([]byte("foo")) {
+		t.Error("Expected 'foo' to no longer be in the filter")
+	}
+	if !f.Test([]byte("bar")) {
+		t.Error("Expected 'bar' to remain in the filter")
+	}
+	if f.Remove([]byte("baz")) {
+		t.Error("Expected removal of an absent item to report false")
+	}
+}
+
+func TestCountingManyItems(t *testing.T) {
+	f, _ := bloom.NewCounting(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	for i := 0; i < 500; i++ {
+		f.Remove([]byte(strconv.Itoa(i)))
+	}
+	for i := 500; i < 1000; i++ {
+		if !f.Test([]byte(strconv.Itoa(i))) {
+			t.Fatalf("Expected %d to remain in the filter", i)
+		}
+	}
+}
+
+func TestCountingMarshalBinary(t *testing.T) {
+	counting, _ := bloom.NewCounting(100, 0.01)
+	var filter bloom.Filter = counting
+	filter.Add([]byte("foo"))
+	data, err := filter.(*bloom.Counting).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded bloom.Counting
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Test([]byte("foo")) {
+		t.Error("Expected decoded filter to contain 'foo'")
+	}
+}
@@ -0,0 +1,285 @@
+// Package breaker is a package that implements the circuit breaker pattern.
+//
+// A breaker starts closed and lets calls through while recording their outcomes over a window of
+// recent calls. When too many of them fail or run slowly it opens and rejects calls outright. After a
+// cool-down it turns half-open and lets a few probe calls through: if they do well it closes again,
+// otherwise it reopens.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned for calls rejected because the breaker is open, or half-open with every probe
+// already in flight.
+var ErrOpen = errors.New("breaker: circuit open")
+
+// State is the state of a breaker.
+type State int
+
+const (
+	// Closed lets every call through.
+	Closed State = iota
+	// Open rejects every call.
+	Open
+	// HalfOpen lets a limited number of probe calls through.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Call describes a finished or rejected call, for metrics.
+type Call struct {
+	// State is the state the call was admitted or rejected in.
+	State    State
+	Duration time.Duration
+	Err      error
+	Failed   bool
+	Slow     bool
+	Rejected bool
+}
+
+// Options configures a breaker. Zero fields take the defaults noted on each.
+type Options struct {
+	// WindowSize is the number of recent calls whose outcomes are considered. Defaults to 100.
+	WindowSize int
+	// MinCalls is the number of calls needed in the window before the breaker may open. Defaults to 10.
+	MinCalls int
+	// FailureRate is the fraction of failed calls, from 0 to 1, at which the breaker opens.
+	// Defaults to 0.5.
+	FailureRate float64
+	// SlowCall is the duration above which a call counts as slow. Zero disables slow-call tracking.
+	SlowCall time.Duration
+	// SlowCallRate is the fraction of slow calls at which the breaker opens. Defaults to 1.
+	SlowCallRate float64
+	// OpenTimeout is how long the breaker stays open before turning half-open. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// Probes is the number of calls let through while half-open. Defaults to 1.
+	Probes int
+	// IsFailure decides whether an error counts as a failure. Defaults to any non-nil error.
+	IsFailure func(err error) bool
+	// OnStateChange is called, outside the breaker's lock, whenever the state changes.
+	OnStateChange func(from, to State)
+	// OnCall is called, outside the breaker's lock, for every finished or rejected call.
+	OnCall func(call Call)
+}
+
+type outcome struct {
+	failed bool
+	slow   bool
+}
+
+// Breaker guards calls to a dependency. It is safe for concurrent use.
+type Breaker struct {
+	options    Options
+	state      State
+	generation uint64
+	openedAt   time.Time
+	window     []outcome
+	next       int
+	count      int
+	failures   int
+	slow       int
+	inflight   int
+	mutex      *sync.Mutex
+}
+
+// New creates a closed breaker.
+func New(options Options) *Breaker {
+	if options.WindowSize <= 0 {
+		options.WindowSize = 100
+	}
+	if options.MinCalls <= 0 {
+		options.MinCalls = 10
+	}
+	if options.FailureRate <= 0 {
+		options.FailureRate = 0.5
+	}
+	if options.SlowCallRate <= 0 {
+		options.SlowCallRate = 1
+	}
+	if options.OpenTimeout <= 0 {
+		options.OpenTimeout = 30 * time.Second
+	}
+	if options.Probes <= 0 {
+		options.Probes = 1
+	}
+	if options.IsFailure == nil {
+		options.IsFailure = func(err error) bool { return err != nil }
+	}
+	return &Breaker{
+		options: options,
+		window:  make([]outcome, options.WindowSize),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Do runs fn if the breaker allows it and records the outcome. It returns ErrOpen without running fn
+// when the call is rejected. A panic in fn is recorded as a failure and then repanics.
+func (b *Breaker) Do(fn func() error) (err error) {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	finished := false
+	defer func() {
+		if !finished {
+			done(errors.New("breaker: call panicked"))
+		}
+	}()
+	err = fn()
+	finished = true
+	done(err)
+	return err
+}
+
+// Allow asks to make a call. If the call is allowed, done must be called with its result once it
+// finishes; otherwise ErrOpen is returned.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mutex.Lock()
+	from := b.state
+	b.tick(time.Now())
+	state := b.state
+	allowed := state == Closed || (state == HalfOpen && b.inflight < b.options.Probes)
+	if allowed && state == HalfOpen {
+		b.inflight++
+	}
+	generation := b.generation
+	b.mutex.Unlock()
+	b.changed(from, state)
+
+	if !allowed {
+		if b.options.OnCall != nil {
+			b.options.OnCall(Call{State: state, Err: ErrOpen, Rejected: true})
+		}
+		return nil, ErrOpen
+	}
+	start := time.Now()
+	once := &sync.Once{}
+	return func(err error) {
+		once.Do(func() {
+			b.record(generation, state, time.Since(start), err)
+		})
+	}, nil
+}
+
+// State returns the breaker's current state.
+func (b *Breaker) State() State {
+	b.mutex.Lock()
+	from := b.state
+	b.tick(time.Now())
+	state := b.state
+	b.mutex.Unlock()
+	b.changed(from, state)
+	return state
+}
+
+// Reset closes the breaker and forgets every recorded outcome.
+func (b *Breaker) Reset() {
+	b.mutex.Lock()
+	from := b.state
+	b.transition(Closed, time.Now())
+	b.mutex.Unlock()
+	b.changed(from, Closed)
+}
+
+func (b *Breaker) record(generation uint64, state State, duration time.Duration, err error) {
+	call := Call{
+		State:    state,
+		Duration: duration,
+		Err:      err,
+		Failed:   b.options.IsFailure(err),
+		Slow:     b.options.SlowCall > 0 && duration > b.options.SlowCall,
+	}
+	b.mutex.Lock()
+	from := b.state
+	// Outcomes from before the last state change describe a dependency that has moved on.
+	if generation == b.generation {
+		b.push(outcome{failed: call.Failed, slow: call.Slow})
+		now := time.Now()
+		switch b.state {
+		case Closed:
+			if b.count >= b.options.MinCalls && b.tripped() {
+				b.transition(Open, now)
+			}
+		case HalfOpen:
+			b.inflight--
+			if b.tripped() {
+				b.transition(Open, now)
+			} else if b.count >= b.options.Probes {
+				b.transition(Closed, now)
+			}
+		}
+	}
+	to := b.state
+	b.mutex.Unlock()
+	b.changed(from, to)
+	if b.options.OnCall != nil {
+		b.options.OnCall(call)
+	}
+}
+
+func (b *Breaker) tripped() bool {
+	if b.count == 0 {
+		return false
+	}
+	if float64(b.failures)/float64(b.count) >= b.options.FailureRate {
+		return true
+	}
+	return b.options.SlowCall > 0 && float64(b.slow)/float64(b.count) >= b.options.SlowCallRate
+}
+
+func (b *Breaker) push(o outcome) {
+	if b.count == len(b.window) {
+		old := b.window[b.next]
+		if old.failed {
+			b.failures--
+		}
+		if old.slow {
+			b.slow--
+		}
+	} else {
+		b.count++
+	}
+	b.window[b.next] = o
+	b.next = (b.next + 1) % len(b.window)
+	if o.failed {
+		b.failures++
+	}
+	if o.slow {
+		b.slow++
+	}
+}
+
+func (b *Breaker) tick(now time.Time) {
+	if b.state == Open && now.Sub(b.openedAt) >= b.options.OpenTimeout {
+		b.transition(HalfOpen, now)
+	}
+}
+
+func (b *Breaker) transition(to State, now time.Time) {
+	b.state = to
+	b.generation++
+	b.next, b.count, b.failures, b.slow, b.inflight = 0, 0, 0, 0, 0
+	if to == Open {
+		b.openedAt = now
+	}
+}
+
+func (b *Breaker) changed(from, to State) {
+	if from != to && b.options.OnStateChange != nil {
+		b.options.OnStateChange(from, to)
+	}
+}
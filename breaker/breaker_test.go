@@ -0,0 +1,134 @@
+package breaker_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/breaker"
+)
+
+var errFailed = errors.New("failed")
+
+func fail() error    { return errFailed }
+func succeed() error { return nil }
+
+func TestOpensOnFailureRate(t *testing.T) {
+	b := breaker.New(breaker.Options{WindowSize: 10, MinCalls: 4, FailureRate: 0.5})
+	b.Do(succeed)
+	b.Do(fail)
+	b.Do(succeed)
+	if b.State() != breaker.Closed {
+		t.Error("Expected the breaker to stay closed below MinCalls")
+	}
+	b.Do(fail)
+	if b.State() != breaker.Open {
+		t.Errorf("Expected the breaker to open got %v", b.State())
+	}
+	if err := b.Do(succeed); err != breaker.ErrOpen {
+		t.Errorf("Expected open error got %v", err)
+	}
+}
+
+func TestOpensOnSlowCalls(t *testing.T) {
+	b := breaker.New(breaker.Options{MinCalls: 2, SlowCall: time.Millisecond, SlowCallRate: 0.5})
+	b.Do(succeed)
+	b.Do(func() error {
+		time.Sleep(3 * time.Millisecond)
+		return nil
+	})
+	if b.State() != breaker.Open {
+		t.Errorf("Expected slow calls to open the breaker got %v", b.State())
+	}
+}
+
+func TestHalfOpenProbes(t *testing.T) {
+	b := breaker.New(breaker.Options{MinCalls: 1, OpenTimeout: 10 * time.Millisecond, Probes: 2})
+	b.Do(fail)
+	time.Sleep(15 * time.Millisecond)
+	if b.State() != breaker.HalfOpen {
+		t.Fatalf("Expected half-open got %v", b.State())
+	}
+	first, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Allow(); err != breaker.ErrOpen {
+		t.Errorf("Expected probes beyond the limit to be rejected got %v", err)
+	}
+	first(nil)
+	second(nil)
+	if b.State() != breaker.Closed {
+		t.Errorf("Expected successful probes to close got %v", b.State())
+	}
+}
+
+func TestHalfOpenReopens(t *testing.T) {
+	b := breaker.New(breaker.Options{MinCalls: 1, OpenTimeout: 10 * time.Millisecond})
+	b.Do(fail)
+	time.Sleep(15 * time.Millisecond)
+	b.Do(fail)
+	if b.State() != breaker.Open {
+		t.Errorf("Expected a failed probe to reopen got %v", b.State())
+	}
+}
+
+func TestCallbacks(t *testing.T) {
+	var mutex sync.Mutex
+	var changes []string
+	var calls, rejected int
+	b := breaker.New(breaker.Options{
+		MinCalls: 1,
+		OnStateChange: func(from, to breaker.State) {
+			mutex.Lock()
+			changes = append(changes, from.String()+">"+to.String())
+			mutex.Unlock()
+		},
+		OnCall: func(call breaker.Call) {
+			mutex.Lock()
+			calls++
+			if call.Rejected {
+				rejected++
+			}
+			mutex.Unlock()
+		},
+	})
+	b.Do(fail)
+	b.Do(succeed)
+	b.Reset()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(changes) != 2 || changes[0] != "closed>open" || changes[1] != "open>closed" {
+		t.Errorf("Expected [closed>open open>closed] got %v", changes)
+	}
+	if calls != 2 || rejected != 1 {
+		t.Errorf("Expected 2 calls with 1 rejected got %d and %d", calls, rejected)
+	}
+}
+
+func TestIsFailure(t *testing.T) {
+	b := breaker.New(breaker.Options{
+		MinCalls:  1,
+		IsFailure: func(err error) bool { return err != nil && err != errFailed },
+	})
+	b.Do(fail)
+	if b.State() != breaker.Closed {
+		t.Error("Expected ignored errors not to count as failures")
+	}
+}
+
+func TestPanicCountsAsFailure(t *testing.T) {
+	b := breaker.New(breaker.Options{MinCalls: 1})
+	func() {
+		defer func() { recover() }()
+		b.Do(func() error { panic("boom") })
+	}()
+	if b.State() != breaker.Open {
+		t.Errorf("Expected a panic to count as a failure got %v", b.State())
+	}
+}
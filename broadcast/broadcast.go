@@ -0,0 +1,212 @@
+// Package broadcast is a package that delivers every message from one sender to every subscriber.
+//
+// Sending never blocks. Each subscriber has its own fixed-size buffer; a subscriber that falls behind
+// loses its oldest unread messages and is told how many it missed on its next receive.
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cjsaylor/goutil/ringbuffer"
+)
+
+// ErrClosed is returned by Recv once the broadcaster is closed and the receiver's buffer is drained,
+// or after the receiver itself is closed.
+var ErrClosed = errors.New("broadcast: closed")
+
+// LagError is returned by Recv when messages were overwritten before the receiver read them.
+// The next Recv continues with the oldest message still buffered.
+type LagError struct {
+	Missed uint64
+}
+
+func (e *LagError) Error() string {
+	return fmt.Sprintf("broadcast: receiver lagged by %d messages", e.Missed)
+}
+
+// Broadcaster sends messages to its receivers. It is safe for concurrent use.
+type Broadcaster[T any] struct {
+	receivers map[*Receiver[T]]struct{}
+	closed    bool
+	mutex     *sync.Mutex
+}
+
+// New creates a broadcaster with no receivers.
+func New[T any]() *Broadcaster[T] {
+	return &Broadcaster[T]{
+		receivers: make(map[*Receiver[T]]struct{}),
+		mutex:     &sync.Mutex{},
+	}
+}
+
+// Subscribe adds a receiver that buffers up to buffer unread messages. It receives every message sent
+// after it subscribed.
+func (b *Broadcaster[T]) Subscribe(buffer int) *Receiver[T] {
+	if buffer < 1 {
+		buffer = 1
+	}
+	r := &Receiver[T]{
+		broadcaster: b,
+		buffer:      ringbuffer.New[T](buffer, ringbuffer.Overwrite),
+		notify:      make(chan struct{}, 1),
+		mutex:       &sync.Mutex{},
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		r.closed = true
+	} else {
+		b.receivers[r] = struct{}{}
+	}
+	return r
+}
+
+// Send delivers message to every current receiver and returns how many there were. Sending on a
+// closed broadcaster does nothing.
+func (b *Broadcaster[T]) Send(message T) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return 0
+	}
+	for r := range b.receivers {
+		r.push(message)
+	}
+	return len(b.receivers)
+}
+
+// Len returns the number of receivers.
+func (b *Broadcaster[T]) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.receivers)
+}
+
+// Close stops the broadcaster. Receivers can still read what is buffered before getting ErrClosed.
+func (b *Broadcaster[T]) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for r := range b.receivers {
+		r.mutex.Lock()
+		r.closed = true
+		r.mutex.Unlock()
+		r.wake()
+	}
+	b.receivers = nil
+}
+
+// Receiver reads the messages sent after it subscribed. It is safe for concurrent use, though each
+// message is delivered to only one Recv call.
+type Receiver[T any] struct {
+	broadcaster *Broadcaster[T]
+	buffer      *ringbuffer.Buffer[T]
+	missed      uint64
+	lagged      uint64
+	closed      bool
+	notify      chan struct{}
+	mutex       *sync.Mutex
+}
+
+// Recv returns the next message, blocking until one is sent or ctx is done. If messages were lost
+// since the last call, it returns a *LagError instead.
+func (r *Receiver[T]) Recv(ctx context.Context) (T, error) {
+	for {
+		r.mutex.Lock()
+		if r.missed > 0 {
+			missed := r.missed
+			r.missed = 0
+			r.mutex.Unlock()
+			var zero T
+			return zero, &LagError{Missed: missed}
+		}
+		if message, ok := r.buffer.Pop(); ok {
+			r.mutex.Unlock()
+			return message, nil
+		}
+		closed := r.closed
+		r.mutex.Unlock()
+		if closed {
+			var zero T
+			return zero, ErrClosed
+		}
+		select {
+		case <-r.notify:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// TryRecv returns the next buffered message without blocking. It reports false when there is none,
+// and like Recv returns a *LagError when messages were lost.
+func (r *Receiver[T]) TryRecv() (T, bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var zero T
+	if r.missed > 0 {
+		missed := r.missed
+		r.missed = 0
+		return zero, false, &LagError{Missed: missed}
+	}
+	if message, ok := r.buffer.Pop(); ok {
+		return message, true, nil
+	}
+	if r.closed {
+		return zero, false, ErrClosed
+	}
+	return zero, false, nil
+}
+
+// Len returns the number of unread buffered messages.
+func (r *Receiver[T]) Len() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.buffer.Len()
+}
+
+// Lagged returns the total number of messages this receiver has lost to overflow.
+func (r *Receiver[T]) Lagged() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.lagged
+}
+
+// Close unsubscribes the receiver and discards its buffer.
+func (r *Receiver[T]) Close() {
+	b := r.broadcaster
+	b.mutex.Lock()
+	delete(b.receivers, r)
+	b.mutex.Unlock()
+	r.mutex.Lock()
+	r.closed = true
+	r.buffer.Clear()
+	r.missed = 0
+	r.mutex.Unlock()
+	r.wake()
+}
+
+func (r *Receiver[T]) push(message T) {
+	r.mutex.Lock()
+	if r.buffer.Len() == r.buffer.Cap() {
+		r.missed++
+		r.lagged++
+	}
+	r.buffer.Push(message)
+	r.mutex.Unlock()
+	r.wake()
+}
+
+func (r *Receiver[T]) wake() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
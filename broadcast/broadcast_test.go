@@ -0,0 +1,101 @@
+package broadcast_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/broadcast"
+)
+
+func TestEveryReceiverGetsEveryMessage(t *testing.T) {
+	b := broadcast.New[int]()
+	first := b.Subscribe(10)
+	second := b.Subscribe(10)
+	if n := b.Send(1); n != 2 {
+		t.Errorf("Expected 2 receivers got %d", n)
+	}
+	b.Send(2)
+	for _, r := range []*broadcast.Receiver[int]{first, second} {
+		for expected := 1; expected <= 2; expected++ {
+			if val, err := r.Recv(context.Background()); val != expected || err != nil {
+				t.Errorf("Expected %d got %v (%v)", expected, val, err)
+			}
+		}
+	}
+}
+
+func TestLateSubscriber(t *testing.T) {
+	b := broadcast.New[int]()
+	b.Send(1)
+	r := b.Subscribe(10)
+	b.Send(2)
+	if val, _ := r.Recv(context.Background()); val != 2 {
+		t.Errorf("Expected only messages after subscribing got %d", val)
+	}
+}
+
+func TestLag(t *testing.T) {
+	b := broadcast.New[int]()
+	r := b.Subscribe(2)
+	for i := 1; i <= 5; i++ {
+		b.Send(i)
+	}
+	_, err := r.Recv(context.Background())
+	var lag *broadcast.LagError
+	if !errors.As(err, &lag) || lag.Missed != 3 {
+		t.Fatalf("Expected a lag of 3 got %v", err)
+	}
+	if val, err := r.Recv(context.Background()); val != 4 || err != nil {
+		t.Errorf("Expected to resume at 4 got %v (%v)", val, err)
+	}
+	if r.Lagged() != 3 {
+		t.Errorf("Expected a total lag of 3 got %d", r.Lagged())
+	}
+}
+
+func TestRecvBlocks(t *testing.T) {
+	b := broadcast.New[string]()
+	r := b.Subscribe(1)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		b.Send("hello")
+	}()
+	if val, err := r.Recv(context.Background()); val != "hello" || err != nil {
+		t.Errorf("Expected hello got %v (%v)", val, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := r.Recv(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	b := broadcast.New[int]()
+	r := b.Subscribe(10)
+	b.Send(1)
+	b.Close()
+	if val, err := r.Recv(context.Background()); val != 1 || err != nil {
+		t.Errorf("Expected buffered messages to survive close got %v (%v)", val, err)
+	}
+	if _, err := r.Recv(context.Background()); err != broadcast.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+	if _, _, err := b.Subscribe(1).TryRecv(); err != broadcast.ErrClosed {
+		t.Errorf("Expected a late subscriber to be closed got %v", err)
+	}
+}
+
+func TestReceiverClose(t *testing.T) {
+	b := broadcast.New[int]()
+	r := b.Subscribe(1)
+	r.Close()
+	if b.Len() != 0 {
+		t.Errorf("Expected no receivers got %d", b.Len())
+	}
+	if _, _, err := r.TryRecv(); err != broadcast.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+}
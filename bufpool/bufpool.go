@@ -0,0 +1,156 @@
+// Package bufpool is a package that pools byte buffers in power-of-two size classes.
+//
+// Keeping one sync.Pool per size class means a rare large buffer is only reused for large requests
+// instead of being pinned by, and handed to, every small one. Buffers above the largest class are
+// never pooled.
+package bufpool
+
+import (
+	"bytes"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats counts pool activity.
+type Stats struct {
+	// Gets is the number of buffers handed out.
+	Gets uint64
+	// Hits is the number of buffers handed out that were reused from the pool.
+	Hits uint64
+	// Puts is the number of buffers accepted back into the pool.
+	Puts uint64
+	// Dropped is the number of buffers given back but not pooled because of their size.
+	Dropped uint64
+}
+
+// HitRate returns the fraction of gets served from the pool.
+func (s Stats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
+
+// Pool hands out byte slices and buffers by size. It is safe for concurrent use.
+type Pool struct {
+	minShift int
+	maxShift int
+	slices   []sync.Pool
+	buffers  []sync.Pool
+	gets     atomic.Uint64
+	hits     atomic.Uint64
+	puts     atomic.Uint64
+	dropped  atomic.Uint64
+}
+
+// New creates a pool with size classes from minSize to maxSize, each rounded up to a power of two.
+func New(minSize, maxSize int) *Pool {
+	minShift := shift(minSize)
+	maxShift := shift(maxSize)
+	if maxShift < minShift {
+		maxShift = minShift
+	}
+	classes := maxShift - minShift + 1
+	return &Pool{
+		minShift: minShift,
+		maxShift: maxShift,
+		slices:   make([]sync.Pool, classes),
+		buffers:  make([]sync.Pool, classes),
+	}
+}
+
+// Get returns a slice of length size. Its capacity is the size's class, so it may be larger.
+func (p *Pool) Get(size int) []byte {
+	p.gets.Add(1)
+	class, ok := p.classFor(size)
+	if !ok {
+		return make([]byte, size)
+	}
+	if b, ok := p.slices[class].Get().(*[]byte); ok {
+		p.hits.Add(1)
+		return (*b)[:size]
+	}
+	return make([]byte, size, 1<<(class+p.minShift))
+}
+
+// Put returns a slice to the pool. The slice must not be used afterwards.
+func (p *Pool) Put(b []byte) {
+	class, ok := p.classOf(cap(b))
+	if !ok {
+		p.dropped.Add(1)
+		return
+	}
+	p.puts.Add(1)
+	b = b[:0]
+	p.slices[class].Put(&b)
+}
+
+// GetBuffer returns an empty buffer with room for at least size bytes.
+func (p *Pool) GetBuffer(size int) *bytes.Buffer {
+	p.gets.Add(1)
+	class, ok := p.classFor(size)
+	if !ok {
+		return bytes.NewBuffer(make([]byte, 0, size))
+	}
+	if buf, ok := p.buffers[class].Get().(*bytes.Buffer); ok {
+		p.hits.Add(1)
+		return buf
+	}
+	return bytes.NewBuffer(make([]byte, 0, 1<<(class+p.minShift)))
+}
+
+// PutBuffer resets buf and returns it to the pool, filed under its current capacity, so a buffer
+// that grew large is only reused for large requests. buf must not be used afterwards.
+func (p *Pool) PutBuffer(buf *bytes.Buffer) {
+	class, ok := p.classOf(buf.Cap())
+	if !ok {
+		p.dropped.Add(1)
+		return
+	}
+	p.puts.Add(1)
+	buf.Reset()
+	p.buffers[class].Put(buf)
+}
+
+// Stats returns the pool's counters.
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Gets:    p.gets.Load(),
+		Hits:    p.hits.Load(),
+		Puts:    p.puts.Load(),
+		Dropped: p.dropped.Load(),
+	}
+}
+
+// classFor returns the smallest class that can hold size bytes.
+func (p *Pool) classFor(size int) (int, bool) {
+	s := shift(size)
+	if s < p.minShift {
+		s = p.minShift
+	}
+	if s > p.maxShift {
+		return 0, false
+	}
+	return s - p.minShift, true
+}
+
+// classOf returns the largest class a buffer with the given capacity can serve.
+func (p *Pool) classOf(capacity int) (int, bool) {
+	if capacity <= 0 {
+		return 0, false
+	}
+	s := bits.Len(uint(capacity)) - 1
+	if s < p.minShift || s > p.maxShift {
+		return 0, false
+	}
+	return s - p.minShift, true
+}
+
+// shift returns the exponent of the smallest power of two at least n.
+func shift(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
@@ -0,0 +1,75 @@
+package bufpool_test
+
+import (
+	"testing"
+
+	"github.com/cjsaylor/goutil/bufpool"
+)
+
+func TestGetSizes(t *testing.T) {
+	p := bufpool.New(64, 4096)
+	for _, c := range []struct{ size, capacity int }{{1, 64}, {64, 64}, {65, 128}, {1000, 1024}, {4096, 4096}, {5000, 5000}} {
+		b := p.Get(c.size)
+		if len(b) != c.size || cap(b) != c.capacity {
+			t.Errorf("Expected len %d cap %d got len %d cap %d", c.size, c.capacity, len(b), cap(b))
+		}
+	}
+}
+
+func TestPutClassifiesByCapacity(t *testing.T) {
+	p := bufpool.New(64, 4096)
+	p.Put(make([]byte, 10, 100))
+	p.Put(make([]byte, 10, 10000))
+	p.Put(make([]byte, 10, 10))
+	if stats := p.Stats(); stats.Puts != 1 || stats.Dropped != 2 {
+		t.Errorf("Expected 1 put and 2 dropped got %+v", stats)
+	}
+	// A 100-byte buffer may only serve requests of up to 64 bytes.
+	for i := 0; i < 10; i++ {
+		if b := p.Get(100); cap(b) < 100 {
+			t.Fatalf("Expected capacity for 100 bytes got %d", cap(b))
+		}
+	}
+}
+
+func TestReuse(t *testing.T) {
+	p := bufpool.New(64, 4096)
+	for i := 0; i < 100; i++ {
+		p.Put(p.Get(500))
+	}
+	if p.Stats().Hits == 0 {
+		t.Error("Expected buffers to be reused")
+	}
+	if rate := p.Stats().HitRate(); rate <= 0 || rate > 1 {
+		t.Errorf("Expected a hit rate in (0, 1] got %v", rate)
+	}
+}
+
+func TestBuffers(t *testing.T) {
+	p := bufpool.New(64, 1024)
+	buf := p.GetBuffer(100)
+	if buf.Len() != 0 || buf.Cap() < 100 {
+		t.Errorf("Expected an empty buffer with room for 100 got len %d cap %d", buf.Len(), buf.Cap())
+	}
+	buf.WriteString("hello")
+	p.PutBuffer(buf)
+	again := p.GetBuffer(100)
+	if again.Len() != 0 {
+		t.Errorf("Expected a reset buffer got %q", again.String())
+	}
+	large := p.GetBuffer(10)
+	large.Write(make([]byte, 5000))
+	p.PutBuffer(large)
+	if p.Stats().Dropped != 1 {
+		t.Error("Expected a buffer that outgrew the classes to be dropped")
+	}
+}
+
+func BenchmarkGetPut(b *testing.B) {
+	p := bufpool.New(64, 1<<16)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.Put(p.Get(1500))
+		}
+	})
+}
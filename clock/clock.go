@@ -0,0 +1,95 @@
+// Package clock is a package that abstracts time so code that waits on it can be tested.
+//
+// Code takes a Clock and uses Real in production. Tests pass a Fake and move its time forward
+// with Advance, firing timers deterministically instead of sleeping.
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the time and creates timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// After waits for d to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// Sleep pauses the current goroutine for d.
+	Sleep(d time.Duration)
+	// NewTimer creates a timer that sends the current time on its channel after d.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc calls f in its own goroutine after d. The returned timer's channel is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+	// NewTicker creates a ticker that sends the current time on its channel every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event, like time.Timer.
+type Timer interface {
+	// C returns the channel the time is delivered on.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing and reports whether it was pending.
+	Stop() bool
+	// Reset changes the timer to fire after d and reports whether it was pending.
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker interface {
+	// C returns the channel the ticks are delivered on.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+	// Reset changes the ticker's period to d.
+	Reset(d time.Duration)
+}
+
+// Real returns a Clock backed by the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+// OrReal returns c, or the real clock if c is nil. It lets options structs leave their Clock unset.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real()
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,34 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+func TestReal(t *testing.T) {
+	c := clock.Real()
+	start := c.Now()
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	if c.Since(start) < time.Millisecond {
+		t.Error("Expected the timer to wait")
+	}
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+	fired := make(chan struct{})
+	c.AfterFunc(time.Millisecond, func() { close(fired) })
+	<-fired
+}
+
+func TestOrReal(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	if clock.OrReal(fake) != fake {
+		t.Error("Expected a given clock to be kept")
+	}
+	if clock.OrReal(nil) == nil {
+		t.Error("Expected the real clock for nil")
+	}
+}
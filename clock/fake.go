@@ -0,0 +1,217 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when told to. It is safe for concurrent use.
+type Fake struct {
+	mutex   *sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	changed chan struct{}
+}
+
+type waiter struct {
+	fake   *Fake
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+	fn     func()
+}
+
+// NewFake creates a fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{
+		mutex:   &sync.Mutex{},
+		now:     start,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now returns the fake's current time.
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel that receives the fake time once it has advanced by d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the fake time has advanced by d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTimer creates a timer that fires once the fake time has advanced by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &waiter{fake: f, ch: make(chan time.Time, 1)}
+	f.schedule(w, d)
+	return w
+}
+
+// AfterFunc calls fn once the fake time has advanced by d. Unlike the real clock, fn runs on the
+// goroutine that advanced the time, before Advance returns.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &waiter{fake: f, fn: fn}
+	f.schedule(w, d)
+	return w
+}
+
+// NewTicker creates a ticker that fires every d of fake time. It panics if d is not positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &waiter{fake: f, period: d, ch: make(chan time.Time, 1)}
+	f.schedule(w, d)
+	return fakeTicker{w}
+}
+
+// Advance moves the fake time forward by d, firing every timer and tick that falls due along the
+// way in order. Channels are buffered like the real ones, so a tick nobody reads is dropped.
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	target := f.now.Add(d)
+	for {
+		w := f.earliest()
+		if w == nil || w.at.After(target) {
+			break
+		}
+		if w.at.After(f.now) {
+			f.now = w.at
+		}
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			f.remove(w)
+		}
+		if w.fn != nil {
+			f.mutex.Unlock()
+			w.fn()
+			f.mutex.Lock()
+			continue
+		}
+		select {
+		case w.ch <- f.now:
+		default:
+		}
+	}
+	if target.After(f.now) {
+		f.now = target
+	}
+	f.mutex.Unlock()
+}
+
+// Set moves the fake time forward to t, like Advance. Times in the past are ignored.
+func (f *Fake) Set(t time.Time) {
+	f.Advance(t.Sub(f.Now()))
+}
+
+// Waiters returns the number of pending timers and tickers, including sleeping goroutines.
+func (f *Fake) Waiters() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least n timers and tickers are pending, or ctx is done. Tests use it
+// to be sure the code under test is waiting before they advance the time.
+func (f *Fake) BlockUntil(ctx context.Context, n int) error {
+	for {
+		f.mutex.Lock()
+		if len(f.waiters) >= n {
+			f.mutex.Unlock()
+			return nil
+		}
+		changed := f.changed
+		f.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (f *Fake) schedule(w *waiter, d time.Duration) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	pending := f.remove(w)
+	w.at = f.now.Add(d)
+	f.waiters = append(f.waiters, w)
+	f.broadcast()
+	return pending
+}
+
+// earliest returns the waiter due first, preferring the one scheduled first on ties.
+// It must be called with the lock held.
+func (f *Fake) earliest() *waiter {
+	var first *waiter
+	for _, w := range f.waiters {
+		if first == nil || w.at.Before(first.at) {
+			first = w
+		}
+	}
+	return first
+}
+
+// remove unschedules w and reports whether it was pending. It must be called with the lock held.
+func (f *Fake) remove(w *waiter) bool {
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// broadcast wakes BlockUntil callers. It must be called with the lock held.
+func (f *Fake) broadcast() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (w *waiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *waiter) Stop() bool {
+	w.fake.mutex.Lock()
+	defer w.fake.mutex.Unlock()
+	return w.fake.remove(w)
+}
+
+func (w *waiter) Reset(d time.Duration) bool {
+	return w.fake.schedule(w, d)
+}
+
+type fakeTicker struct {
+	*waiter
+}
+
+func (t fakeTicker) Stop() {
+	t.waiter.Stop()
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.fake.mutex.Lock()
+	t.period = d
+	t.fake.mutex.Unlock()
+	t.fake.schedule(t.waiter, d)
+}
@@ -0,0 +1,120 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeNow(t *testing.T) {
+	f := clock.NewFake(epoch)
+	f.Advance(time.Hour)
+	if !f.Now().Equal(epoch.Add(time.Hour)) || f.Since(epoch) != time.Hour {
+		t.Errorf("Expected an hour to pass got %v", f.Now())
+	}
+	f.Set(epoch)
+	if !f.Now().Equal(epoch.Add(time.Hour)) {
+		t.Error("Expected Set to ignore times in the past")
+	}
+}
+
+func TestFakeTimer(t *testing.T) {
+	f := clock.NewFake(epoch)
+	timer := f.NewTimer(10 * time.Second)
+	f.Advance(9 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("Expected the timer not to fire early")
+	default:
+	}
+	f.Advance(5 * time.Second)
+	select {
+	case at := <-timer.C():
+		if !at.Equal(epoch.Add(10 * time.Second)) {
+			t.Errorf("Expected the timer to fire at its deadline got %v", at)
+		}
+	default:
+		t.Fatal("Expected the timer to fire")
+	}
+	if timer.Stop() {
+		t.Error("Expected Stop to report a fired timer as not pending")
+	}
+	if timer.Reset(time.Second) {
+		t.Error("Expected Reset to report a fired timer as not pending")
+	}
+	if !timer.Stop() {
+		t.Error("Expected Stop to report a reset timer as pending")
+	}
+	f.Advance(time.Minute)
+	select {
+	case <-timer.C():
+		t.Error("Expected a stopped timer not to fire")
+	default:
+	}
+}
+
+func TestFakeAfterFunc(t *testing.T) {
+	f := clock.NewFake(epoch)
+	var order []int
+	f.AfterFunc(2*time.Second, func() { order = append(order, 2) })
+	f.AfterFunc(time.Second, func() {
+		order = append(order, 1)
+		f.AfterFunc(time.Second, func() { order = append(order, 3) })
+	})
+	f.Advance(3 * time.Second)
+	if len(order) != 3 || order[0] != 1 || order[1] != 2 || order[2] != 3 {
+		t.Errorf("Expected callbacks in deadline order got %v", order)
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := clock.NewFake(epoch)
+	ticker := f.NewTicker(time.Second)
+	ticks := 0
+	for i := 0; i < 3; i++ {
+		f.Advance(time.Second)
+		select {
+		case <-ticker.C():
+			ticks++
+		default:
+		}
+	}
+	if ticks != 3 {
+		t.Errorf("Expected 3 ticks got %d", ticks)
+	}
+	ticker.Reset(time.Minute)
+	f.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Error("Expected the reset period to apply")
+	default:
+	}
+	ticker.Stop()
+	if f.Waiters() != 0 {
+		t.Errorf("Expected no waiters got %d", f.Waiters())
+	}
+}
+
+func TestFakeSleep(t *testing.T) {
+	f := clock.NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		f.Sleep(time.Minute)
+		close(done)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := f.BlockUntil(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	f.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the sleeper to wake")
+	}
+}
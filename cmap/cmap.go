@@ -0,0 +1,160 @@
+// Package cmap is a package that implements a concurrent map split into independently locked shards.
+//
+// Spreading keys over shards lets goroutines working on different keys proceed without contending
+// for one lock. ShardStats and Balance report how evenly keys and traffic are spread, to detect hot
+// shards caused by poor key hashing.
+package cmap
+
+import (
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+// shard is padded to a 64-byte cache line, so the counters of neighbouring shards in the slice do not
+// share a line and bounce between cores.
+type shard[K comparable, V any] struct {
+	items     map[K]V
+	mutex     *sync.RWMutex
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	contended atomic.Uint64
+	_         [24]byte
+}
+
+// Map is a sharded map. It is safe for concurrent use.
+type Map[K comparable, V any] struct {
+	shards []shard[K, V]
+	seed   maphash.Seed
+}
+
+// New creates a map with the given number of shards, rounded up to a power of two. A shard count
+// below one uses 32.
+func New[K comparable, V any](shards int) *Map[K, V] {
+	if shards < 1 {
+		shards = 32
+	}
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	m := &Map[K, V]{
+		shards: make([]shard[K, V], n),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range m.shards {
+		m.shards[i].items = make(map[K]V)
+		m.shards[i].mutex = &sync.RWMutex{}
+	}
+	return m
+}
+
+// Get returns the value for key.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	s := m.shard(key)
+	s.rlock()
+	defer s.mutex.RUnlock()
+	value, ok := s.items[key]
+	s.record(ok)
+	return value, ok
+}
+
+// Set stores value for key.
+func (m *Map[K, V]) Set(key K, value V) {
+	s := m.shard(key)
+	s.lock()
+	defer s.mutex.Unlock()
+	s.items[key] = value
+}
+
+// GetOrInsert returns the value for key if present. Otherwise it stores value and returns it.
+// The boolean reports whether the value was already present.
+func (m *Map[K, V]) GetOrInsert(key K, value V) (V, bool) {
+	s := m.shard(key)
+	s.lock()
+	defer s.mutex.Unlock()
+	existing, ok := s.items[key]
+	s.record(ok)
+	if ok {
+		return existing, true
+	}
+	s.items[key] = value
+	return value, false
+}
+
+// Upsert replaces the value for key with the result of fn, which receives the current value and
+// whether it exists. fn runs with the key's shard locked, so it must not use the map.
+func (m *Map[K, V]) Upsert(key K, fn func(value V, ok bool) V) V {
+	s := m.shard(key)
+	s.lock()
+	defer s.mutex.Unlock()
+	current, ok := s.items[key]
+	value := fn(current, ok)
+	s.items[key] = value
+	return value
+}
+
+// Delete removes key and returns its value.
+func (m *Map[K, V]) Delete(key K) (V, bool) {
+	s := m.shard(key)
+	s.lock()
+	defer s.mutex.Unlock()
+	value, ok := s.items[key]
+	delete(s.items, key)
+	return value, ok
+}
+
+// Len returns the number of entries. Shards are counted one at a time, so the result may not reflect
+// any single moment while the map is being modified.
+func (m *Map[K, V]) Len() int {
+	n := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.rlock()
+		n += len(s.items)
+		s.mutex.RUnlock()
+	}
+	return n
+}
+
+// Range calls fn for every entry until fn returns false. Each shard is read-locked while it is
+// visited, so fn must not modify the map.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.rlock()
+		for key, value := range s.items {
+			if !fn(key, value) {
+				s.mutex.RUnlock()
+				return
+			}
+		}
+		s.mutex.RUnlock()
+	}
+}
+
+func (m *Map[K, V]) shard(key K) *shard[K, V] {
+	return &m.shards[maphash.Comparable(m.seed, key)&uint64(len(m.shards)-1)]
+}
+
+func (s *shard[K, V]) lock() {
+	if !s.mutex.TryLock() {
+		s.contended.Add(1)
+		s.mutex.Lock()
+	}
+}
+
+func (s *shard[K, V]) rlock() {
+	if !s.mutex.TryRLock() {
+		s.contended.Add(1)
+		s.mutex.RLock()
+	}
+}
+
+func (s *shard[K, V]) record(hit bool) {
+	if hit {
+		s.hits.Add(1)
+	} else {
+		s.misses.Add(1)
+	}
+}
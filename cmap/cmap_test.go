@@ -0,0 +1,71 @@
+package cmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/cmap"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	m := cmap.New[string, int](4)
+	m.Set("a", 1)
+	if val, ok := m.Get("a"); val != 1 || !ok {
+		t.Errorf("Expected 1 got %v (%v)", val, ok)
+	}
+	if val, ok := m.Delete("a"); val != 1 || !ok {
+		t.Errorf("Expected to delete 1 got %v (%v)", val, ok)
+	}
+	if _, ok := m.Get("a"); ok {
+		t.Error("Expected a to be deleted")
+	}
+}
+
+func TestGetOrInsert(t *testing.T) {
+	m := cmap.New[string, int](0)
+	if val, loaded := m.GetOrInsert("a", 1); val != 1 || loaded {
+		t.Errorf("Expected to insert 1 got %v (%v)", val, loaded)
+	}
+	if val, loaded := m.GetOrInsert("a", 2); val != 1 || !loaded {
+		t.Errorf("Expected the existing 1 got %v (%v)", val, loaded)
+	}
+}
+
+func TestUpsertConcurrent(t *testing.T) {
+	m := cmap.New[int, int](8)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Upsert(i%10, func(value int, ok bool) int {
+				return value + 1
+			})
+		}()
+	}
+	wg.Wait()
+	if m.Len() != 10 {
+		t.Errorf("Expected 10 keys got %d", m.Len())
+	}
+	m.Range(func(key, value int) bool {
+		if value != 10 {
+			t.Errorf("Expected 10 increments for %d got %d", key, value)
+		}
+		return true
+	})
+}
+
+func TestRangeStops(t *testing.T) {
+	m := cmap.New[int, int](2)
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	visited := 0
+	m.Range(func(key, value int) bool {
+		visited++
+		return visited < 3
+	})
+	if visited != 3 {
+		t.Errorf("Expected Range to stop after 3 got %d", visited)
+	}
+}
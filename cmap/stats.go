@@ -0,0 +1,82 @@
+package cmap
+
+// ShardStats are counters for one shard.
+type ShardStats struct {
+	// Len is the number of entries in the shard.
+	Len int
+	// Hits and Misses count lookups by Get and GetOrInsert.
+	Hits   uint64
+	Misses uint64
+	// Contended counts lock acquisitions that had to wait for another goroutine.
+	Contended uint64
+}
+
+// HitRatio returns the fraction of lookups that found their key, or zero if there were none.
+func (s ShardStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Balance summarizes how evenly entries and traffic are spread over the shards. A skew is the busiest
+// shard's value divided by the mean, so 1 is perfectly even and the shard count is the worst case.
+type Balance struct {
+	// LenSkew compares shard sizes.
+	LenSkew float64
+	// LookupSkew compares shard lookups.
+	LookupSkew float64
+	// ContentionSkew compares shard lock contention.
+	ContentionSkew float64
+	// Largest is the index of the shard with the most entries.
+	Largest int
+	// Hottest is the index of the shard with the most lookups.
+	Hottest int
+}
+
+// ShardStats returns the counters of each shard in order. Shards are read one at a time, so the
+// result may not reflect any single moment while the map is being modified.
+func (m *Map[K, V]) ShardStats() []ShardStats {
+	stats := make([]ShardStats, len(m.shards))
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mutex.RLock()
+		stats[i].Len = len(s.items)
+		s.mutex.RUnlock()
+		stats[i].Hits = s.hits.Load()
+		stats[i].Misses = s.misses.Load()
+		stats[i].Contended = s.contended.Load()
+	}
+	return stats
+}
+
+// Balance reports the skew of the map's shards.
+func (m *Map[K, V]) Balance() Balance {
+	return Report(m.ShardStats())
+}
+
+// Report computes the balance of stats, such as a result of ShardStats saved earlier.
+func Report(stats []ShardStats) Balance {
+	var b Balance
+	b.LenSkew, b.Largest = skew(stats, func(s ShardStats) float64 { return float64(s.Len) })
+	b.LookupSkew, b.Hottest = skew(stats, func(s ShardStats) float64 { return float64(s.Hits + s.Misses) })
+	b.ContentionSkew, _ = skew(stats, func(s ShardStats) float64 { return float64(s.Contended) })
+	return b
+}
+
+// skew returns the maximum of value over stats divided by its mean, and the index of the maximum.
+// It returns a skew of zero when every value is zero.
+func skew(stats []ShardStats, value func(ShardStats) float64) (float64, int) {
+	total, max, index := 0.0, 0.0, 0
+	for i, s := range stats {
+		v := value(s)
+		total += v
+		if v > max {
+			max, index = v, i
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return max / (total / float64(len(stats))), index
+}
@@ -0,0 +1,64 @@
+package cmap_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/cmap"
+)
+
+func TestShardStats(t *testing.T) {
+	m := cmap.New[string, int](1)
+	m.Set("a", 1)
+	m.Get("a")
+	m.Get("b")
+	m.GetOrInsert("a", 2)
+	stats := m.ShardStats()
+	if len(stats) != 1 || stats[0].Len != 1 || stats[0].Hits != 2 || stats[0].Misses != 1 {
+		t.Errorf("Expected 1 entry with 2 hits and 1 miss got %+v", stats)
+	}
+	if ratio := stats[0].HitRatio(); ratio < 0.66 || ratio > 0.67 {
+		t.Errorf("Expected a hit ratio of 2/3 got %v", ratio)
+	}
+	if (cmap.ShardStats{}).HitRatio() != 0 {
+		t.Error("Expected no lookups to have a zero hit ratio")
+	}
+}
+
+func TestContention(t *testing.T) {
+	m := cmap.New[string, int](1)
+	wg := &sync.WaitGroup{}
+	m.Upsert("a", func(value int, ok bool) int {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Get("a")
+		}()
+		time.Sleep(10 * time.Millisecond)
+		return 1
+	})
+	wg.Wait()
+	if stats := m.ShardStats(); stats[0].Contended == 0 {
+		t.Errorf("Expected the blocked read to count as contended got %+v", stats[0])
+	}
+}
+
+func TestBalance(t *testing.T) {
+	m := cmap.New[int, int](4)
+	if b := m.Balance(); b.LenSkew != 0 || b.LookupSkew != 0 {
+		t.Errorf("Expected an empty map to have no skew got %+v", b)
+	}
+	m.Set(1, 1)
+	for i := 0; i < 10; i++ {
+		m.Get(1)
+	}
+	b := m.Balance()
+	if b.LenSkew != 4 || b.LookupSkew != 4 || b.Largest != b.Hottest {
+		t.Errorf("Expected all entries and traffic on one of 4 shards got %+v", b)
+	}
+	even := cmap.Report([]cmap.ShardStats{{Len: 5, Hits: 3}, {Len: 5, Hits: 3}})
+	if even.LenSkew != 1 || even.LookupSkew != 1 {
+		t.Errorf("Expected even shards to have a skew of 1 got %+v", even)
+	}
+}
@@ -0,0 +1,112 @@
+// Package cmpx is a package of composable comparators for sorting by several keys.
+//
+// A comparator built as
+//
+//	cmpx.By(func(p Person) string { return p.Last }).ThenBy(cmpx.By(func(p Person) int { return p.Age }).Reverse())
+//
+// replaces a sort.Slice closure that compares each field by hand.
+package cmpx
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Comparator returns a negative number when a sorts before b, a positive number when it sorts
+// after, and zero when they are equal.
+type Comparator[T any] func(a, b T) int
+
+// Natural compares values by their natural order.
+func Natural[T cmp.Ordered]() Comparator[T] {
+	return cmp.Compare[T]
+}
+
+// By compares values by the key fn extracts from them.
+func By[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+	return func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	}
+}
+
+// ByFunc compares values by the key fn extracts from them, using compare for the keys.
+func ByFunc[T, K any](key func(T) K, compare Comparator[K]) Comparator[T] {
+	return func(a, b T) int {
+		return compare(key(a), key(b))
+	}
+}
+
+// ThenBy returns a comparator that breaks ties in c with next.
+func (c Comparator[T]) ThenBy(next Comparator[T]) Comparator[T] {
+	return func(a, b T) int {
+		if result := c(a, b); result != 0 {
+			return result
+		}
+		return next(a, b)
+	}
+}
+
+// Reverse returns a comparator with the opposite order to c.
+func (c Comparator[T]) Reverse() Comparator[T] {
+	return func(a, b T) int {
+		return c(b, a)
+	}
+}
+
+// Less reports whether a sorts before b.
+func (c Comparator[T]) Less(a, b T) bool {
+	return c(a, b) < 0
+}
+
+// NilsLast compares pointers by the values they point to with c, sorting nil pointers after the rest.
+func NilsLast[T any](c Comparator[T]) Comparator[*T] {
+	return nils(c, 1)
+}
+
+// NilsFirst compares pointers by the values they point to with c, sorting nil pointers before the rest.
+func NilsFirst[T any](c Comparator[T]) Comparator[*T] {
+	return nils(c, -1)
+}
+
+func nils[T any](c Comparator[T], nilOrder int) Comparator[*T] {
+	return func(a, b *T) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return nilOrder
+		case b == nil:
+			return -nilOrder
+		}
+		return c(*a, *b)
+	}
+}
+
+// Chain returns a comparator that tries each of comparators in turn until one finds a difference.
+func Chain[T any](comparators ...Comparator[T]) Comparator[T] {
+	return func(a, b T) int {
+		for _, c := range comparators {
+			if result := c(a, b); result != 0 {
+				return result
+			}
+		}
+		return 0
+	}
+}
+
+// Sort sorts s in place by comparators, the first being the primary key. The sort is stable, so
+// values that compare equal on every key keep their order.
+func Sort[T any](s []T, comparators ...Comparator[T]) {
+	slices.SortStableFunc(s, Chain(comparators...))
+}
+
+// Sorted returns a sorted copy of s. See Sort.
+func Sorted[T any](s []T, comparators ...Comparator[T]) []T {
+	sorted := append([]T(nil), s...)
+	Sort(sorted, comparators...)
+	return sorted
+}
+
+// IsSorted reports whether s is sorted by comparators.
+func IsSorted[T any](s []T, comparators ...Comparator[T]) bool {
+	return slices.IsSortedFunc(s, Chain(comparators...))
+}
@@ -0,0 +1,65 @@
+package cmpx_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cjsaylor/goutil/cmpx"
+)
+
+type person struct {
+	name string
+	age  int
+}
+
+var people = []person{{"carol", 30}, {"alice", 25}, {"bob", 30}, {"alice", 40}}
+
+func TestBy(t *testing.T) {
+	byName := cmpx.By(func(p person) string { return p.name })
+	sorted := cmpx.Sorted(people, byName)
+	if sorted[0].age != 25 || sorted[1].age != 40 || sorted[3].name != "carol" {
+		t.Errorf("Expected a stable sort by name got %v", sorted)
+	}
+	if people[0].name != "carol" {
+		t.Error("Expected Sorted to leave its input alone")
+	}
+}
+
+func TestThenByAndReverse(t *testing.T) {
+	byAge := cmpx.By(func(p person) int { return p.age })
+	byName := cmpx.By(func(p person) string { return p.name })
+	sorted := cmpx.Sorted(people, byAge.Reverse().ThenBy(byName))
+	expected := []person{{"alice", 40}, {"bob", 30}, {"carol", 30}, {"alice", 25}}
+	if !reflect.DeepEqual(sorted, expected) {
+		t.Errorf("Expected %v got %v", expected, sorted)
+	}
+	if !cmpx.IsSorted(sorted, byAge.Reverse(), byName) {
+		t.Error("Expected the result to be sorted by the same keys given separately")
+	}
+}
+
+func TestByFunc(t *testing.T) {
+	caseless := func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) }
+	words := []string{"b", "A", "c"}
+	cmpx.Sort(words, cmpx.ByFunc(func(s string) string { return s }, caseless))
+	if !reflect.DeepEqual(words, []string{"A", "b", "c"}) {
+		t.Errorf("Expected [A b c] got %v", words)
+	}
+	if !cmpx.Natural[int]().Less(1, 2) {
+		t.Error("Expected 1 before 2")
+	}
+}
+
+func TestNils(t *testing.T) {
+	one, two := 1, 2
+	values := []*int{nil, &two, &one, nil}
+	cmpx.Sort(values, cmpx.NilsLast(cmpx.Natural[int]()))
+	if *values[0] != 1 || *values[1] != 2 || values[2] != nil || values[3] != nil {
+		t.Errorf("Expected [1 2 nil nil] got %v", values)
+	}
+	cmpx.Sort(values, cmpx.NilsFirst(cmpx.Natural[int]()))
+	if values[0] != nil || values[1] != nil || *values[2] != 1 {
+		t.Errorf("Expected [nil nil 1 2] got %v", values)
+	}
+}
@@ -0,0 +1,192 @@
+// Package cmsketch is a package that implements a count-min sketch for approximate frequency counting.
+// Estimates never undercount, and overcount by at most a small fraction of the total additions.
+//
+// Counters are updated conservatively, only raising the ones that equal the current estimate.
+// An optional sample size enables aging: once that many additions are seen, every counter is halved
+// so that the sketch favors recent frequency over all-time frequency.
+package cmsketch
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/cjsaylor/goutil/internal/doublehash"
+)
+
+const (
+	formatVersion = 1
+	headerSize    = 29
+)
+
+// ErrInvalidData is returned when decoding malformed binary data.
+var ErrInvalidData = errors.New("cmsketch: invalid data")
+
+// Sketch is a matrix of counters with one row per hash function. It is safe for concurrent use.
+type Sketch struct {
+	rows       [][]uint32
+	width      uint64
+	additions  uint64
+	sampleSize uint64
+	mutex      *sync.Mutex
+}
+
+// New creates a sketch with depth rows of width counters.
+func New(width uint64, depth int) *Sketch {
+	if width == 0 {
+		width = 1
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	rows := make([][]uint32, depth)
+	for i := range rows {
+		rows[i] = make([]uint32, width)
+	}
+	return &Sketch{
+		rows:  rows,
+		width: width,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// NewWithError creates a sketch whose estimates exceed the true count by at most epsilon times
+// the total additions, with probability 1 - delta. It panics if epsilon is not positive or delta is
+// not between 0 and 1.
+func NewWithError(epsilon, delta float64) *Sketch {
+	if !(epsilon > 0) || math.IsInf(epsilon, 1) {
+		panic("cmsketch: epsilon must be positive")
+	}
+	if !(delta > 0 && delta < 1) {
+		panic("cmsketch: delta must be between 0 and 1")
+	}
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	return New(width, depth)
+}
+
+// SetSampleSize enables aging after every n additions. A sample size of zero disables aging.
+func (s *Sketch) SetSampleSize(n uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sampleSize = n
+}
+
+// Add increments the count of data by one.
+func (s *Sketch) Add(data []byte) {
+	s.AddCount(data, 1)
+}
+
+// AddCount increments the count of data by n.
+func (s *Sketch) AddCount(data []byte, n uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	h1, h2 := doublehash.Sum(data)
+	target := s.estimate(h1, h2)
+	if target > math.MaxUint32-n {
+		target = math.MaxUint32
+	} else {
+		target += n
+	}
+	for i, row := range s.rows {
+		index := (h1 + uint64(i)*h2) % s.width
+		if row[index] < target {
+			row[index] = target
+		}
+	}
+	s.additions += uint64(n)
+	if s.sampleSize > 0 && s.additions >= s.sampleSize {
+		s.halve()
+	}
+}
+
+// Estimate returns the approximate count of data.
+func (s *Sketch) Estimate(data []byte) uint32 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	h1, h2 := doublehash.Sum(data)
+	return s.estimate(h1, h2)
+}
+
+// Halve divides every counter by two.
+func (s *Sketch) Halve() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.halve()
+}
+
+// Reset sets every counter to zero.
+func (s *Sketch) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, row := range s.rows {
+		clear(row)
+	}
+	s.additions = 0
+}
+
+// MarshalBinary encodes the counters, the addition count and the sample size.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	data := make([]byte, headerSize, headerSize+4*len(s.rows)*int(s.width))
+	data[0] = formatVersion
+	binary.BigEndian.PutUint64(data[1:], s.width)
+	binary.BigEndian.PutUint32(data[9:], uint32(len(s.rows)))
+	binary.BigEndian.PutUint64(data[13:], s.additions)
+	binary.BigEndian.PutUint64(data[21:], s.sampleSize)
+	for _, row := range s.rows {
+		for _, count := range row {
+			data = binary.BigEndian.AppendUint32(data, count)
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a sketch encoded with MarshalBinary, replacing its dimensions and counters.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || data[0] != formatVersion {
+		return ErrInvalidData
+	}
+	width := binary.BigEndian.Uint64(data[1:])
+	depth := uint64(binary.BigEndian.Uint32(data[9:]))
+	counters := uint64(len(data)-headerSize) / 4
+	if width == 0 || depth == 0 || (len(data)-headerSize)%4 != 0 || counters%width != 0 || counters/width != depth {
+		return ErrInvalidData
+	}
+	rows := make([][]uint32, depth)
+	offset := headerSize
+	for i := range rows {
+		rows[i] = make([]uint32, width)
+		for j := range rows[i] {
+			rows[i][j] = binary.BigEndian.Uint32(data[offset:])
+			offset += 4
+		}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rows, s.width = rows, width
+	s.additions = binary.BigEndian.Uint64(data[13:])
+	s.sampleSize = binary.BigEndian.Uint64(data[21:])
+	return nil
+}
+
+func (s *Sketch) estimate(h1, h2 uint64) uint32 {
+	lowest := uint32(math.MaxUint32)
+	for i, row := range s.rows {
+		if count := row[(h1+uint64(i)*h2)%s.width]; count < lowest {
+			lowest = count
+		}
+	}
+	return lowest
+}
+
+func (s *Sketch) halve() {
+	for _, row := range s.rows {
+		for i := range row {
+			row[i] >>= 1
+		}
+	}
+	s.additions /= 2
+}
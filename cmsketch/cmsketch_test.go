@@ -0,0 +1,92 @@
+package cmsketch_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/cmsketch"
+)
+
+func TestEstimate(t *testing.T) {
+	s := cmsketch.NewWithError(0.001, 0.01)
+	for i := 0; i < 100; i++ {
+		s.Add([]byte("hot"))
+	}
+	for i := 0; i < 1000; i++ {
+		s.Add([]byte(strconv.Itoa(i)))
+	}
+	if count := s.Estimate([]byte("hot")); count < 100 || count > 102 {
+		t.Errorf("Expected approximately 100 got %d", count)
+	}
+	if count := s.Estimate([]byte("cold")); count > 2 {
+		t.Errorf("Expected approximately 0 got %d", count)
+	}
+}
+
+func TestAddCount(t *testing.T) {
+	s := cmsketch.New(64, 4)
+	s.AddCount([]byte("a"), 5)
+	s.Add([]byte("a"))
+	if count := s.Estimate([]byte("a")); count != 6 {
+		t.Errorf("Expected 6 got %d", count)
+	}
+}
+
+func TestAging(t *testing.T) {
+	s := cmsketch.New(1024, 4)
+	s.SetSampleSize(100)
+	for i := 0; i < 99; i++ {
+		s.Add([]byte("a"))
+	}
+	if count := s.Estimate([]byte("a")); count != 99 {
+		t.Errorf("Expected 99 before aging got %d", count)
+	}
+	s.Add([]byte("a"))
+	if count := s.Estimate([]byte("a")); count != 50 {
+		t.Errorf("Expected counters to be halved to 50 got %d", count)
+	}
+}
+
+func TestReset(t *testing.T) {
+	s := cmsketch.New(64, 4)
+	s.Add([]byte("a"))
+	s.Reset()
+	if count := s.Estimate([]byte("a")); count != 0 {
+		t.Errorf("Expected 0 after reset got %d", count)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	s := cmsketch.New(64, 3)
+	s.SetSampleSize(1000)
+	s.AddCount([]byte("a"), 7)
+	s.Add([]byte("b"))
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := cmsketch.New(1, 1)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Estimate([]byte("a")) != 7 || decoded.Estimate([]byte("b")) != 1 {
+		t.Errorf("Expected the counts to survive got %d and %d", decoded.Estimate([]byte("a")), decoded.Estimate([]byte("b")))
+	}
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); err != cmsketch.ErrInvalidData {
+		t.Errorf("Expected truncated data to be rejected got %v", err)
+	}
+}
+
+func TestNewWithInvalidError(t *testing.T) {
+	for _, args := range [][2]float64{{0, 0.01}, {-1, 0.01}, {math.NaN(), 0.01}, {0.01, 0}, {0.01, 1}, {0.01, math.NaN()}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected epsilon %v and delta %v to panic", args[0], args[1])
+				}
+			}()
+			cmsketch.NewWithError(args[0], args[1])
+		}()
+	}
+}
@@ -0,0 +1,141 @@
+// Package consistenthash is a package that maps keys to nodes so that adding or removing a node
+// only moves the keys owned by that node.
+package consistenthash
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Ring places each node at many points (virtual nodes) on a hash ring.
+// A key belongs to the first node found walking clockwise from the key's hash. It is safe for concurrent use.
+type Ring struct {
+	replicas int
+	points   []uint64
+	owners   map[uint64]string
+	weights  map[string]int
+	mutex    *sync.RWMutex
+}
+
+// New creates an empty ring placing each node at replicas points per unit of weight.
+func New(replicas int) *Ring {
+	if replicas < 1 {
+		replicas = 1
+	}
+	return &Ring{
+		replicas: replicas,
+		owners:   make(map[uint64]string),
+		weights:  make(map[string]int),
+		mutex:    &sync.RWMutex{},
+	}
+}
+
+// Add nodes to the ring with a weight of one.
+func (r *Ring) Add(nodes ...string) {
+	for _, node := range nodes {
+		r.AddWeighted(node, 1)
+	}
+}
+
+// AddWeighted adds a node owning a share of keys proportional to weight.
+// Adding an existing node replaces its weight.
+func (r *Ring) AddWeighted(node string, weight int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.remove(node)
+	if weight < 1 {
+		return
+	}
+	r.weights[node] = weight
+	for i := 0; i < r.replicas*weight; i++ {
+		point := hash(node + "#" + strconv.Itoa(i))
+		if _, taken := r.owners[point]; taken {
+			continue
+		}
+		r.owners[point] = node
+		r.points = append(r.points, point)
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+}
+
+// Remove a node from the ring.
+func (r *Ring) Remove(node string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.remove(node)
+}
+
+// Get returns the node owning key.
+func (r *Ring) Get(key string) (string, bool) {
+	nodes := r.GetN(key, 1)
+	if len(nodes) == 0 {
+		return "", false
+	}
+	return nodes[0], true
+}
+
+// GetN returns up to n distinct nodes for key in preference order, for choosing replicas.
+func (r *Ring) GetN(key string, n int) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if n > len(r.weights) {
+		n = len(r.weights)
+	}
+	if n <= 0 {
+		return nil
+	}
+	h := hash(key)
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	ret := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	for i := 0; i < len(r.points) && len(ret) < n; i++ {
+		node := r.owners[r.points[(start+i)%len(r.points)]]
+		if _, ok := seen[node]; !ok {
+			seen[node] = struct{}{}
+			ret = append(ret, node)
+		}
+	}
+	return ret
+}
+
+// Nodes returns the nodes in the ring in no particular order.
+func (r *Ring) Nodes() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	ret := make([]string, 0, len(r.weights))
+	for node := range r.weights {
+		ret = append(ret, node)
+	}
+	return ret
+}
+
+func (r *Ring) remove(node string) {
+	if _, ok := r.weights[node]; !ok {
+		return
+	}
+	delete(r.weights, node)
+	points := r.points[:0]
+	for _, point := range r.points {
+		if r.owners[point] == node {
+			delete(r.owners, point)
+		} else {
+			points = append(points, point)
+		}
+	}
+	r.points = points
+}
+
+// hash is FNV-1a with a murmur3 finalizer to spread similar keys around the ring.
+func hash(key string) uint64 {
+	f := fnv.New64a()
+	f.Write([]byte(key))
+	h := f.Sum64()
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,75 @@
+package consistenthash_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/consistenthash"
+)
+
+func TestGet(t *testing.T) {
+	r := consistenthash.New(50)
+	if _, ok := r.Get("a"); ok {
+		t.Error("Expected no node from an empty ring")
+	}
+	r.Add("node1", "node2", "node3")
+	first, _ := r.Get("some-key")
+	for i := 0; i < 10; i++ {
+		if node, _ := r.Get("some-key"); node != first {
+			t.Error("Expected lookups to be stable")
+		}
+	}
+}
+
+func TestRemoveOnlyMovesOwnedKeys(t *testing.T) {
+	r := consistenthash.New(100)
+	r.Add("node1", "node2", "node3")
+	before := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		before[key], _ = r.Get(key)
+	}
+	r.Remove("node2")
+	for key, owner := range before {
+		after, _ := r.Get(key)
+		if owner != "node2" && after != owner {
+			t.Fatalf("Expected %s to stay on %s got %s", key, owner, after)
+		}
+		if after == "node2" {
+			t.Fatal("Expected removed node to own no keys")
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	r := consistenthash.New(100)
+	r.AddWeighted("big", 3)
+	r.AddWeighted("small", 1)
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		node, _ := r.Get(strconv.Itoa(i))
+		counts[node]++
+	}
+	if ratio := float64(counts["big"]) / float64(counts["small"]); ratio < 2 || ratio > 4.5 {
+		t.Errorf("Expected roughly a 3:1 split got %v", counts)
+	}
+}
+
+func TestGetN(t *testing.T) {
+	r := consistenthash.New(10)
+	r.Add("a", "b", "c")
+	nodes := r.GetN("key", 5)
+	if len(nodes) != 3 {
+		t.Fatalf("Expected 3 distinct nodes got %v", nodes)
+	}
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		if seen[node] {
+			t.Errorf("Expected distinct nodes got %v", nodes)
+		}
+		seen[node] = true
+	}
+	if first, _ := r.Get("key"); nodes[0] != first {
+		t.Error("Expected the first replica to be the owner")
+	}
+}
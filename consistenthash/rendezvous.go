@@ -0,0 +1,131 @@
+package consistenthash
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Picker chooses nodes for keys. It is implemented by Ring and Rendezvous so callers can swap strategies.
+type Picker interface {
+	// Add nodes with a weight of one.
+	Add(nodes ...string)
+	// AddWeighted adds a node owning a share of keys proportional to weight.
+	AddWeighted(node string, weight int)
+	// Remove a node.
+	Remove(node string)
+	// Get returns the node owning key.
+	Get(key string) (string, bool)
+	// GetN returns up to n distinct nodes for key in preference order.
+	GetN(key string, n int) []string
+	// Nodes returns every node in no particular order.
+	Nodes() []string
+}
+
+var (
+	_ Picker = (*Ring)(nil)
+	_ Picker = (*Rendezvous)(nil)
+)
+
+// Rendezvous assigns each key to the node with the highest hash score for that key
+// (highest random weight hashing). It balances keys more evenly than a ring when there are few nodes,
+// at the cost of O(n) lookups. It is safe for concurrent use.
+type Rendezvous struct {
+	weights map[string]int
+	mutex   *sync.RWMutex
+}
+
+// NewRendezvous creates an empty rendezvous hasher.
+func NewRendezvous() *Rendezvous {
+	return &Rendezvous{
+		weights: make(map[string]int),
+		mutex:   &sync.RWMutex{},
+	}
+}
+
+// Add nodes with a weight of one.
+func (r *Rendezvous) Add(nodes ...string) {
+	for _, node := range nodes {
+		r.AddWeighted(node, 1)
+	}
+}
+
+// AddWeighted adds a node owning a share of keys proportional to weight.
+// Adding an existing node replaces its weight.
+func (r *Rendezvous) AddWeighted(node string, weight int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if weight < 1 {
+		delete(r.weights, node)
+		return
+	}
+	r.weights[node] = weight
+}
+
+// Remove a node.
+func (r *Rendezvous) Remove(node string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.weights, node)
+}
+
+// Get returns the node owning key.
+func (r *Rendezvous) Get(key string) (string, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	best, bestScore := "", math.Inf(-1)
+	for node, weight := range r.weights {
+		if s := score(node, key, weight); s > bestScore || (s == bestScore && node < best) {
+			best, bestScore = node, s
+		}
+	}
+	return best, len(r.weights) > 0
+}
+
+// GetN returns up to n distinct nodes for key, highest score first.
+func (r *Rendezvous) GetN(key string, n int) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	type scored struct {
+		node  string
+		score float64
+	}
+	all := make([]scored, 0, len(r.weights))
+	for node, weight := range r.weights {
+		all = append(all, scored{node, score(node, key, weight)})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].node < all[j].node
+	})
+	if n > len(all) {
+		n = len(all)
+	}
+	if n <= 0 {
+		return nil
+	}
+	ret := make([]string, n)
+	for i := range ret {
+		ret[i] = all[i].node
+	}
+	return ret
+}
+
+// Nodes returns every node in no particular order.
+func (r *Rendezvous) Nodes() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	ret := make([]string, 0, len(r.weights))
+	for node := range r.weights {
+		ret = append(ret, node)
+	}
+	return ret
+}
+
+// score computes the weighted rendezvous score -weight / ln(u) for a uniform u in (0, 1).
+func score(node, key string, weight int) float64 {
+	u := (float64(hash(node+"\x00"+key)>>11) + 0.5) / (1 << 53)
+	return -float64(weight) / math.Log(u)
+}
@@ -0,0 +1,52 @@
+package consistenthash_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/consistenthash"
+)
+
+func TestRendezvousRemoveOnlyMovesOwnedKeys(t *testing.T) {
+	r := consistenthash.NewRendezvous()
+	r.Add("node1", "node2", "node3")
+	before := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		before[key], _ = r.Get(key)
+	}
+	r.Remove("node2")
+	for key, owner := range before {
+		after, _ := r.Get(key)
+		if owner != "node2" && after != owner {
+			t.Fatalf("Expected %s to stay on %s got %s", key, owner, after)
+		}
+	}
+}
+
+func TestRendezvousWeights(t *testing.T) {
+	r := consistenthash.NewRendezvous()
+	r.AddWeighted("big", 3)
+	r.AddWeighted("small", 1)
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		node, _ := r.Get(strconv.Itoa(i))
+		counts[node]++
+	}
+	if ratio := float64(counts["big"]) / float64(counts["small"]); ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("Expected roughly a 3:1 split got %v", counts)
+	}
+}
+
+func TestPickersAgreeOnReplicaCount(t *testing.T) {
+	for _, p := range []consistenthash.Picker{consistenthash.New(10), consistenthash.NewRendezvous()} {
+		p.Add("a", "b", "c")
+		nodes := p.GetN("key", 2)
+		if len(nodes) != 2 || nodes[0] == nodes[1] {
+			t.Errorf("Expected 2 distinct nodes from %T got %v", p, nodes)
+		}
+		if first, _ := p.Get("key"); nodes[0] != first {
+			t.Errorf("Expected the first replica from %T to be the owner", p)
+		}
+	}
+}
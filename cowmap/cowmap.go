@@ -0,0 +1,75 @@
+// Package cowmap is a package that implements a copy-on-write map for read-mostly workloads.
+//
+// Reads load the current map atomically and never lock. Writes clone the map, modify the clone
+// and swap it in, so every write costs O(n); group writes with Update to pay that cost once.
+package cowmap
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// Map is a key-value store that is safe for concurrent use.
+type Map[K comparable, V any] struct {
+	current atomic.Pointer[map[K]V]
+	mutex   *sync.Mutex
+}
+
+// New creates an empty map.
+func New[K comparable, V any]() *Map[K, V] {
+	m := &Map[K, V]{mutex: &sync.Mutex{}}
+	empty := make(map[K]V)
+	m.current.Store(&empty)
+	return m
+}
+
+// Get will retrieve a value by key.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	value, ok := (*m.current.Load())[key]
+	return value, ok
+}
+
+// Len returns the number of entries in the map.
+func (m *Map[K, V]) Len() int {
+	return len(*m.current.Load())
+}
+
+// Range calls fn for every entry of the current version in no particular order.
+// Iteration stops early if fn returns false. Writes made during iteration are not observed.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	for key, value := range *m.current.Load() {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// Snapshot returns the current version of the map. The returned map must not be modified.
+func (m *Map[K, V]) Snapshot() map[K]V {
+	return *m.current.Load()
+}
+
+// Set a key/value into the map.
+func (m *Map[K, V]) Set(key K, value V) {
+	m.Update(func(entries map[K]V) {
+		entries[key] = value
+	})
+}
+
+// Delete an entry from the map.
+func (m *Map[K, V]) Delete(key K) {
+	m.Update(func(entries map[K]V) {
+		delete(entries, key)
+	})
+}
+
+// Update applies a batch of modifications with a single copy.
+// fn receives a private clone of the map which is published once fn returns.
+func (m *Map[K, V]) Update(fn func(entries map[K]V)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	next := maps.Clone(*m.current.Load())
+	fn(next)
+	m.current.Store(&next)
+}
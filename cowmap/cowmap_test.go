@@ -0,0 +1,62 @@
+package cowmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/cowmap"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	m := cowmap.New[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Delete("a")
+	if _, ok := m.Get("a"); ok {
+		t.Error("Expected 'a' to be deleted")
+	}
+	if val, ok := m.Get("b"); !ok || val != 2 || m.Len() != 1 {
+		t.Errorf("Expected 'b' to be 2 got %v", val)
+	}
+}
+
+func TestSnapshotIsStable(t *testing.T) {
+	m := cowmap.New[string, int]()
+	m.Set("a", 1)
+	snapshot := m.Snapshot()
+	m.Update(func(entries map[string]int) {
+		entries["a"] = 2
+		entries["b"] = 3
+	})
+	if snapshot["a"] != 1 || len(snapshot) != 1 {
+		t.Error("Expected snapshot to be unaffected by later writes")
+	}
+	if m.Len() != 2 {
+		t.Errorf("Expected batch to apply both writes got %d entries", m.Len())
+	}
+}
+
+func TestConcurrentReadersAndWriters(t *testing.T) {
+	m := cowmap.New[int, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				m.Set(g*50+i, i)
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				m.Get(i)
+				m.Range(func(key, value int) bool { return true })
+			}
+		}()
+	}
+	wg.Wait()
+	if m.Len() != 200 {
+		t.Errorf("Expected 200 entries got %d", m.Len())
+	}
+}
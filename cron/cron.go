@@ -0,0 +1,259 @@
+// Package cron is a package that runs jobs on cron schedules.
+package cron
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+// Overlap decides what happens when a job is due while its previous run is still going.
+type Overlap int
+
+const (
+	// Concurrent starts the new run alongside the old one.
+	Concurrent Overlap = iota
+	// Skip drops the new run.
+	Skip
+	// Queue runs the new run once the old one finishes. Runs missed while queued are coalesced, so at
+	// most one run waits at a time.
+	Queue
+)
+
+// EntryID identifies a scheduled job.
+type EntryID uint64
+
+// Options configures a scheduler.
+type Options struct {
+	// Location is the time zone expressions are evaluated in unless they carry their own.
+	// Defaults to time.Local.
+	Location *time.Location
+	// OnPanic is called when a job panics. The panic is recovered either way, so one failing job
+	// cannot take down the scheduler.
+	OnPanic func(id EntryID, value interface{}, stack []byte)
+	// Clock decides when jobs are due. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+type entry struct {
+	id       EntryID
+	schedule Schedule
+	job      func(ctx context.Context)
+	overlap  Overlap
+	next     time.Time
+	running  int
+	queued   bool
+}
+
+// Scheduler runs jobs at the times their schedules give. It is safe for concurrent use.
+type Scheduler struct {
+	options Options
+	entries map[EntryID]*entry
+	nextID  EntryID
+	started bool
+	stopped bool
+	wake    chan struct{}
+	done    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running *sync.WaitGroup
+	mutex   *sync.Mutex
+}
+
+// New creates a scheduler. Jobs do not run until Start is called.
+func New(options Options) *Scheduler {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+	options.Clock = clock.OrReal(options.Clock)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		options: options,
+		entries: make(map[EntryID]*entry),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		running: &sync.WaitGroup{},
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Add parses expr and schedules job on it. The job's context is cancelled if Stop gives up waiting.
+func (s *Scheduler) Add(expr string, overlap Overlap, job func(ctx context.Context)) (EntryID, error) {
+	schedule, err := Parse(expr)
+	if err != nil {
+		return 0, err
+	}
+	return s.AddSchedule(schedule, overlap, job), nil
+}
+
+// AddSchedule schedules job on schedule.
+func (s *Scheduler) AddSchedule(schedule Schedule, overlap Overlap, job func(ctx context.Context)) EntryID {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.nextID++
+	e := &entry{id: s.nextID, schedule: schedule, job: job, overlap: overlap}
+	if s.started {
+		e.next = schedule.Next(s.now())
+	}
+	s.entries[e.id] = e
+	s.notify()
+	return e.id
+}
+
+// Remove unschedules a job. A run already in progress is not interrupted.
+func (s *Scheduler) Remove(id EntryID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.entries, id)
+	s.notify()
+}
+
+// Next returns when the job runs next, or the zero time if it is not scheduled or the scheduler has
+// not started.
+func (s *Scheduler) Next(id EntryID) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if e, ok := s.entries[id]; ok {
+		return e.next
+	}
+	return time.Time{}
+}
+
+// Start begins running jobs. Calling Start more than once, or after Stop, does nothing.
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	now := s.now()
+	for _, e := range s.entries {
+		e.next = e.schedule.Next(now)
+	}
+	go s.loop()
+}
+
+// Stop stops scheduling runs and waits for runs in progress to finish. If ctx is done first, the
+// running jobs' context is cancelled and Stop returns ctx.Err() without waiting further.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mutex.Lock()
+	wasStarted := s.started && !s.stopped
+	s.stopped = true
+	s.notify()
+	s.mutex.Unlock()
+	if wasStarted {
+		<-s.done
+	}
+	finished := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+	for {
+		s.mutex.Lock()
+		if s.stopped {
+			s.mutex.Unlock()
+			return
+		}
+		now := s.now()
+		var earliest time.Time
+		for _, e := range s.entries {
+			if e.next.IsZero() {
+				continue
+			}
+			if !e.next.After(now) {
+				s.dispatch(e)
+				e.next = e.schedule.Next(now)
+				if e.next.IsZero() {
+					continue
+				}
+			}
+			if earliest.IsZero() || e.next.Before(earliest) {
+				earliest = e.next
+			}
+		}
+		s.mutex.Unlock()
+
+		wait := time.Hour
+		if !earliest.IsZero() {
+			wait = earliest.Sub(now)
+		}
+		timer := s.options.Clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-s.wake:
+			timer.Stop()
+		}
+	}
+}
+
+// dispatch starts a run of e according to its overlap policy. It must be called with the lock held.
+func (s *Scheduler) dispatch(e *entry) {
+	if e.running > 0 {
+		switch e.overlap {
+		case Skip:
+			return
+		case Queue:
+			e.queued = true
+			return
+		}
+	}
+	e.running++
+	s.running.Add(1)
+	go s.run(e)
+}
+
+func (s *Scheduler) run(e *entry) {
+	defer s.running.Done()
+	for {
+		s.invoke(e)
+		s.mutex.Lock()
+		if e.queued && !s.stopped {
+			e.queued = false
+			s.mutex.Unlock()
+			continue
+		}
+		e.queued = false
+		e.running--
+		s.mutex.Unlock()
+		return
+	}
+}
+
+func (s *Scheduler) invoke(e *entry) {
+	defer func() {
+		if r := recover(); r != nil && s.options.OnPanic != nil {
+			s.options.OnPanic(e.id, r, debug.Stack())
+		}
+	}()
+	e.job(s.ctx)
+}
+
+func (s *Scheduler) now() time.Time {
+	return s.options.Clock.Now().In(s.options.Location)
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,148 @@
+package cron_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/cron"
+	"github.com/cjsaylor/goutil/wait"
+)
+
+func waitFor(condition func() bool) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return wait.Until(ctx, 10*time.Millisecond, wait.Bool(condition)) == nil
+}
+
+func TestRunsJobs(t *testing.T) {
+	s := cron.New(cron.Options{})
+	var runs atomic.Int32
+	if _, err := s.Add("* * * * * *", cron.Concurrent, func(ctx context.Context) { runs.Add(1) }); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	defer s.Stop(context.Background())
+	if !waitFor(func() bool { return runs.Load() >= 2 }) {
+		t.Errorf("Expected the job to run every second got %d runs", runs.Load())
+	}
+}
+
+func TestSkipOverlap(t *testing.T) {
+	s := cron.New(cron.Options{})
+	var runs atomic.Int32
+	release := make(chan struct{})
+	s.Add("* * * * * *", cron.Skip, func(ctx context.Context) {
+		runs.Add(1)
+		<-release
+	})
+	s.Start()
+	time.Sleep(2500 * time.Millisecond)
+	close(release)
+	s.Stop(context.Background())
+	if runs.Load() != 1 {
+		t.Errorf("Expected overlapping runs to be skipped got %d runs", runs.Load())
+	}
+}
+
+func TestQueueOverlap(t *testing.T) {
+	s := cron.New(cron.Options{})
+	var runs, running, peak atomic.Int32
+	s.Add("* * * * * *", cron.Queue, func(ctx context.Context) {
+		n := running.Add(1)
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		runs.Add(1)
+		time.Sleep(1500 * time.Millisecond)
+		running.Add(-1)
+	})
+	s.Start()
+	ok := waitFor(func() bool { return runs.Load() >= 2 })
+	s.Stop(context.Background())
+	if !ok || peak.Load() != 1 {
+		t.Errorf("Expected queued runs one at a time got %d runs with peak %d", runs.Load(), peak.Load())
+	}
+}
+
+func TestPanicIsolation(t *testing.T) {
+	var panics atomic.Int32
+	s := cron.New(cron.Options{OnPanic: func(id cron.EntryID, value interface{}, stack []byte) {
+		panics.Add(1)
+	}})
+	var runs atomic.Int32
+	s.Add("* * * * * *", cron.Concurrent, func(ctx context.Context) { panic("boom") })
+	s.Add("* * * * * *", cron.Concurrent, func(ctx context.Context) { runs.Add(1) })
+	s.Start()
+	defer s.Stop(context.Background())
+	if !waitFor(func() bool { return panics.Load() >= 1 && runs.Load() >= 2 }) {
+		t.Errorf("Expected panics to be isolated got %d panics and %d runs", panics.Load(), runs.Load())
+	}
+}
+
+func TestStopCancelsAfterDeadline(t *testing.T) {
+	s := cron.New(cron.Options{})
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	s.Add("* * * * * *", cron.Skip, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+	})
+	s.Start()
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected the job context to be cancelled")
+	}
+}
+
+func TestRemoveAndNext(t *testing.T) {
+	s := cron.New(cron.Options{})
+	id, _ := s.Add("@hourly", cron.Skip, func(ctx context.Context) {})
+	if !s.Next(id).IsZero() {
+		t.Error("Expected no next run before Start")
+	}
+	s.Start()
+	defer s.Stop(context.Background())
+	if next := s.Next(id); next.IsZero() || next.Minute() != 0 {
+		t.Errorf("Expected the next run on the hour got %v", next)
+	}
+	s.Remove(id)
+	if !s.Next(id).IsZero() {
+		t.Error("Expected a removed job to have no next run")
+	}
+}
+
+func TestClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC))
+	s := cron.New(cron.Options{Location: time.UTC, Clock: fake})
+	runs := make(chan time.Time, 10)
+	s.Add("*/5 * * * *", cron.Concurrent, func(ctx context.Context) { runs <- fake.Now() })
+	s.Start()
+	defer s.Stop(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		if err := fake.BlockUntil(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		fake.Advance(5 * time.Minute)
+		select {
+		case at := <-runs:
+			if at.Minute()%5 != 0 {
+				t.Errorf("Expected a run on a five minute mark got %v", at)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the job to run")
+		}
+	}
+}
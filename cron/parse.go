@@ -0,0 +1,239 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExpression is wrapped by every error returned for an expression that cannot be parsed.
+var ErrInvalidExpression = errors.New("cron: invalid expression")
+
+// Schedule computes when a job next runs.
+type Schedule interface {
+	// Next returns the first activation time strictly after t, or the zero time if there is none.
+	Next(t time.Time) time.Time
+}
+
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	seconds = bounds{0, 59, nil}
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	days    = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	weekdays = bounds{0, 7, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// Parse parses a cron expression.
+//
+// Expressions have five fields (minute, hour, day of month, month, day of week) or six with a leading
+// seconds field. Fields accept "*", "?", values, ranges "a-b", steps "*/n" and "a-b/n", and
+// comma-separated lists; months and weekdays also accept three-letter names, and 7 means Sunday.
+// When both day fields are restricted a time matches if either does, as in standard cron.
+//
+// The descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are supported,
+// as is "@every <duration>". A "TZ=<zone>" or "CRON_TZ=<zone>" prefix evaluates the expression in
+// that time zone instead of the zone of the time passed to Next.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	var location *time.Location
+	if strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		zone, rest, _ := strings.Cut(expr, " ")
+		_, name, _ := strings.Cut(zone, "=")
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: time zone %q: %v", ErrInvalidExpression, name, err)
+		}
+		location = loc
+		expr = strings.TrimSpace(rest)
+	}
+	if strings.HasPrefix(expr, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("%w: %q needs a duration of at least one second", ErrInvalidExpression, expr)
+		}
+		return every{interval: interval.Truncate(time.Second)}, nil
+	}
+	if full, ok := descriptors[expr]; ok {
+		expr = full
+	}
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: %q has %d fields, expected 5 or 6", ErrInvalidExpression, expr, len(fields))
+	}
+	s := &spec{location: location}
+	var err error
+	for i, field := range []struct {
+		bits *uint64
+		b    bounds
+	}{
+		{&s.second, seconds},
+		{&s.minute, minutes},
+		{&s.hour, hours},
+		{&s.day, days},
+		{&s.month, months},
+		{&s.weekday, weekdays},
+	} {
+		if *field.bits, err = parseField(fields[i], field.b); err != nil {
+			return nil, err
+		}
+	}
+	if s.weekday&(1<<7) != 0 {
+		s.weekday |= 1
+	}
+	s.anyDay = fields[3] == "*" || fields[3] == "?"
+	s.anyWeekday = fields[5] == "*" || fields[5] == "?"
+	return s, nil
+}
+
+// MustParse is like Parse but panics if the expression cannot be parsed.
+func MustParse(expr string) Schedule {
+	s, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		low, high := b.min, b.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(lowPart, b); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highPart, b); err != nil {
+				return 0, err
+			}
+		default:
+			value, err := parseValue(rangePart, b)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if !hasStep {
+				high = value
+			}
+		}
+		if low > high {
+			return 0, fmt.Errorf("%w: range %q is backwards", ErrInvalidExpression, part)
+		}
+		step := uint(1)
+		if hasStep {
+			n, err := strconv.ParseUint(stepPart, 10, 8)
+			if err != nil || n == 0 {
+				return 0, fmt.Errorf("%w: step %q", ErrInvalidExpression, part)
+			}
+			step = uint(n)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	if value, ok := b.names[strings.ToLower(s)]; ok {
+		return value, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || uint(n) < b.min || uint(n) > b.max {
+		return 0, fmt.Errorf("%w: value %q out of range %d-%d", ErrInvalidExpression, s, b.min, b.max)
+	}
+	return uint(n), nil
+}
+
+type every struct {
+	interval time.Duration
+}
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(e.interval)
+}
+
+type spec struct {
+	second, minute, hour, day, month, weekday uint64
+	anyDay, anyWeekday                        bool
+	location                                  *time.Location
+}
+
+func (s *spec) Next(t time.Time) time.Time {
+	original := t.Location()
+	if s.location != nil {
+		t = t.In(s.location)
+	}
+	loc := t.Location()
+	t = t.Truncate(time.Second).Add(time.Second)
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if s.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t.In(original)
+	}
+	return time.Time{}
+}
+
+func (s *spec) matchesDay(t time.Time) bool {
+	day := s.day&(1<<uint(t.Day())) != 0
+	weekday := s.weekday&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
@@ -0,0 +1,65 @@
+package cron_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/cron"
+)
+
+func at(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	cases := []struct {
+		expr, from, next string
+	}{
+		{"* * * * *", "2024-01-01 10:00:30", "2024-01-01 10:01:00"},
+		{"*/15 * * * * *", "2024-01-01 10:00:31", "2024-01-01 10:00:45"},
+		{"30 9 * * mon-fri", "2024-01-05 10:00:00", "2024-01-08 09:30:00"},
+		{"0 0 1 jan *", "2024-03-01 00:00:00", "2025-01-01 00:00:00"},
+		{"0 12 29 2 *", "2024-03-01 00:00:00", "2028-02-29 12:00:00"},
+		{"0 0 13 * 5", "2024-01-01 00:00:00", "2024-01-05 00:00:00"},
+		{"0 0 * * 7", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"0 8-10/2 * * *", "2024-01-01 08:00:00", "2024-01-01 10:00:00"},
+		{"0,30 * * * *", "2024-01-01 08:10:00", "2024-01-01 08:30:00"},
+		{"@daily", "2024-01-01 08:10:00", "2024-01-02 00:00:00"},
+		{"@hourly", "2024-01-01 08:10:00", "2024-01-01 09:00:00"},
+		{"@every 90s", "2024-01-01 08:10:00", "2024-01-01 08:11:30"},
+	}
+	for _, c := range cases {
+		schedule, err := cron.Parse(c.expr)
+		if err != nil {
+			t.Errorf("Expected %q to parse got %v", c.expr, err)
+			continue
+		}
+		if next := schedule.Next(at(c.from)); !next.Equal(at(c.next)) {
+			t.Errorf("Expected %q after %s to be %s got %s", c.expr, c.from, c.next, next)
+		}
+	}
+}
+
+func TestTimeZone(t *testing.T) {
+	schedule, err := cron.Parse("CRON_TZ=America/New_York 0 9 * * *")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+	next := schedule.Next(at("2024-01-01 00:00:00"))
+	if !next.Equal(at("2024-01-01 14:00:00")) {
+		t.Errorf("Expected 09:00 New York to be 14:00 UTC got %s", next.UTC())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * *", "60 * * * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "@every 1ms", "TZ=Nowhere/Nothing * * * * *"} {
+		if _, err := cron.Parse(expr); !errors.Is(err, cron.ErrInvalidExpression) {
+			t.Errorf("Expected %q to be invalid got %v", expr, err)
+		}
+	}
+}
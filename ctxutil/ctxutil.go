@@ -0,0 +1,65 @@
+// Package ctxutil is a package that combines and reshapes contexts.
+package ctxutil
+
+import (
+	"context"
+	"time"
+)
+
+// Detach returns a context that carries ctx's values but is never cancelled and has no deadline.
+// Use it for work that must outlive the request that started it, such as writing an audit log
+// after the response has been sent.
+func Detach(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
+type merged struct {
+	context.Context
+	first  context.Context
+	second context.Context
+}
+
+// Merge returns a context that is done as soon as either parent is. Its deadline is the earlier of
+// the parents' deadlines, and values are looked up in first and then second. Err and Cause report
+// the parent that finished first. The returned cancel function must be called to release
+// resources once the context is no longer needed.
+func Merge(first, second context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(first)
+	stop := context.AfterFunc(second, func() {
+		cancel(context.Cause(second))
+	})
+	return &merged{Context: ctx, first: first, second: second}, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+func (m *merged) Deadline() (time.Time, bool) {
+	deadline, ok := m.first.Deadline()
+	if other, otherOK := m.second.Deadline(); otherOK && (!ok || other.Before(deadline)) {
+		return other, true
+	}
+	return deadline, ok
+}
+
+func (m *merged) Err() error {
+	err := m.Context.Err()
+	if err == nil {
+		return nil
+	}
+	// The underlying context reports Canceled when second expires; prefer the parent's own error.
+	if firstErr := m.first.Err(); firstErr != nil {
+		return firstErr
+	}
+	if secondErr := m.second.Err(); secondErr != nil {
+		return secondErr
+	}
+	return err
+}
+
+func (m *merged) Value(key interface{}) interface{} {
+	if value := m.Context.Value(key); value != nil {
+		return value
+	}
+	return m.second.Value(key)
+}
@@ -0,0 +1,88 @@
+package ctxutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/ctxutil"
+)
+
+type key string
+
+func TestDetach(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), key("user"), "alice"), time.Millisecond)
+	defer cancel()
+	detached := ctxutil.Detach(parent)
+	<-parent.Done()
+	if detached.Err() != nil {
+		t.Errorf("Expected the detached context to outlive its parent got %v", detached.Err())
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("Expected no deadline")
+	}
+	if detached.Value(key("user")) != "alice" {
+		t.Error("Expected values to be kept")
+	}
+}
+
+func TestMergeCancel(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		first, cancelFirst := context.WithCancel(context.Background())
+		second, cancelSecond := context.WithCancel(context.Background())
+		ctx, cancel := ctxutil.Merge(first, second)
+		if ctx.Err() != nil {
+			t.Fatal("Expected the merged context to be live")
+		}
+		if i == 0 {
+			cancelFirst()
+		} else {
+			cancelSecond()
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("Expected cancelling parent %d to cancel the merge", i)
+		}
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Errorf("Expected Canceled got %v", ctx.Err())
+		}
+		cancel()
+		cancelFirst()
+		cancelSecond()
+	}
+}
+
+func TestMergeDeadline(t *testing.T) {
+	first, cancelFirst := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelFirst()
+	second, cancelSecond := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelSecond()
+	ctx, cancel := ctxutil.Merge(first, second)
+	defer cancel()
+	secondDeadline, _ := second.Deadline()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(secondDeadline) {
+		t.Errorf("Expected the earlier deadline %v got %v", secondDeadline, deadline)
+	}
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded got %v", ctx.Err())
+	}
+	if !errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		t.Errorf("Expected the cause to be DeadlineExceeded got %v", context.Cause(ctx))
+	}
+}
+
+func TestMergeValues(t *testing.T) {
+	first := context.WithValue(context.Background(), key("a"), 1)
+	second := context.WithValue(context.WithValue(context.Background(), key("a"), 2), key("b"), 3)
+	ctx, cancel := ctxutil.Merge(first, second)
+	if ctx.Value(key("a")) != 1 || ctx.Value(key("b")) != 3 || ctx.Value(key("c")) != nil {
+		t.Errorf("Expected values from first then second got %v and %v", ctx.Value(key("a")), ctx.Value(key("b")))
+	}
+	cancel()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("Expected Canceled after cancel got %v", ctx.Err())
+	}
+}
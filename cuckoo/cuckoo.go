@@ -0,0 +1,164 @@
+// Package cuckoo is a package that implements a cuckoo filter,
+// a probabilistic set membership test that supports deletion.
+// At low false positive rates it uses less space than a bloom filter.
+package cuckoo
+
+import (
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/cjsaylor/goutil/bloom"
+)
+
+const (
+	bucketSize = 4
+	maxKicks   = 500
+)
+
+type bucket [bucketSize]uint16
+
+// Filter is a cuckoo filter storing 16-bit fingerprints in buckets of four.
+// It is not safe for concurrent use.
+type Filter struct {
+	buckets []bucket
+	mask    uint64
+	count   int
+	victim  *victim
+	random  *rand.Rand
+}
+
+type victim struct {
+	index       uint64
+	fingerprint uint16
+}
+
+var _ bloom.Filter = (*Filter)(nil)
+
+// New creates a cuckoo filter able to hold approximately capacity items.
+func New(capacity int) *Filter {
+	buckets := uint64(1)
+	for buckets*bucketSize < uint64(capacity) {
+		buckets <<= 1
+	}
+	return &Filter{
+		buckets: make([]bucket, buckets),
+		mask:    buckets - 1,
+		random:  rand.New(rand.NewSource(1)),
+	}
+}
+
+// Add inserts data into the filter.
+// It reports false if the filter is too full to store data.
+func (f *Filter) Add(data []byte) bool {
+	if f.victim != nil {
+		return false
+	}
+	i1, fp := f.locate(data)
+	i2 := f.altIndex(i1, fp)
+	if f.insert(i1, fp) || f.insert(i2, fp) {
+		f.count++
+		return true
+	}
+	index := i1
+	if f.random.Intn(2) == 0 {
+		index = i2
+	}
+	for kick := 0; kick < maxKicks; kick++ {
+		slot := f.random.Intn(bucketSize)
+		fp, f.buckets[index][slot] = f.buckets[index][slot], fp
+		index = f.altIndex(index, fp)
+		if f.insert(index, fp) {
+			f.count++
+			return true
+		}
+	}
+	// The displaced fingerprint is kept aside so no previously added item is lost.
+	f.victim = &victim{index: index, fingerprint: fp}
+	f.count++
+	return true
+}
+
+// Test reports whether data may have been added to the filter.
+func (f *Filter) Test(data []byte) bool {
+	i1, fp := f.locate(data)
+	i2 := f.altIndex(i1, fp)
+	if f.victim != nil && f.victim.fingerprint == fp && (f.victim.index == i1 || f.victim.index == i2) {
+		return true
+	}
+	return f.find(i1, fp) >= 0 || f.find(i2, fp) >= 0
+}
+
+// Remove deletes data from the filter, reporting whether it may have been present.
+// Removing data that was never added can remove another item with the same fingerprint.
+func (f *Filter) Remove(data []byte) bool {
+	i1, fp := f.locate(data)
+	i2 := f.altIndex(i1, fp)
+	if f.victim != nil && f.victim.fingerprint == fp && (f.victim.index == i1 || f.victim.index == i2) {
+		f.victim = nil
+		f.count--
+		return true
+	}
+	for _, index := range []uint64{i1, i2} {
+		if slot := f.find(index, fp); slot >= 0 {
+			f.buckets[index][slot] = 0
+			f.count--
+			f.reinsertVictim()
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of items in the filter.
+func (f *Filter) Count() int {
+	return f.count
+}
+
+// LoadFactor returns the fraction of fingerprint slots in use.
+func (f *Filter) LoadFactor() float64 {
+	return float64(f.count) / float64(len(f.buckets)*bucketSize)
+}
+
+func (f *Filter) reinsertVictim() {
+	if f.victim == nil {
+		return
+	}
+	v := f.victim
+	if f.insert(v.index, v.fingerprint) || f.insert(f.altIndex(v.index, v.fingerprint), v.fingerprint) {
+		f.victim = nil
+	}
+}
+
+func (f *Filter) locate(data []byte) (uint64, uint16) {
+	h := fnv.New64a()
+	h.Write(data)
+	sum := h.Sum64()
+	fp := uint16(sum >> 48)
+	if fp == 0 {
+		fp = 1
+	}
+	return sum & f.mask, fp
+}
+
+func (f *Filter) altIndex(index uint64, fp uint16) uint64 {
+	return (index ^ (uint64(fp) * 0x5bd1e995)) & f.mask
+}
+
+func (f *Filter) insert(index uint64, fp uint16) bool {
+	for slot, existing := range f.buckets[index] {
+		if existing == 0 {
+			f.buckets[index][slot] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) find(index uint64, fp uint16) int {
+	for slot, existing := range f.buckets[index] {
+		if existing == fp {
+			return slot
+		}
+	}
+	return -1
+}
@@ -0,0 +1,80 @@
+package cuckoo_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/bloom"
+	"github.com/cjsaylor/goutil/cuckoo"
+)
+
+func TestAddTestRemove(t *testing.T) {
+	f := cuckoo.New(1000)
+	for i := 0; i < 900; i++ {
+		if !f.Add([]byte(strconv.Itoa(i))) {
+			t.Fatalf("Expected %d to be added", i)
+		}
+	}
+	for i := 0; i < 900; i++ {
+		if !f.Test([]byte(strconv.Itoa(i))) {
+			t.Fatalf("Expected %d to be in the filter", i)
+		}
+	}
+	for i := 0; i < 450; i++ {
+		if !f.Remove([]byte(strconv.Itoa(i))) {
+			t.Fatalf("Expected %d to be removed", i)
+		}
+	}
+	for i := 450; i < 900; i++ {
+		if !f.Test([]byte(strconv.Itoa(i))) {
+			t.Fatalf("Expected %d to remain in the filter", i)
+		}
+	}
+	if f.Count() != 450 {
+		t.Errorf("Expected 450 items got %d", f.Count())
+	}
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	f := cuckoo.New(1000)
+	for i := 0; i < 900; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if f.Test([]byte(strconv.Itoa(i))) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 10000; rate > 0.01 {
+		t.Errorf("Expected a low false positive rate got %v", rate)
+	}
+}
+
+func TestFull(t *testing.T) {
+	f := cuckoo.New(8)
+	added := 0
+	for i := 0; i < 100; i++ {
+		if f.Add([]byte(strconv.Itoa(i))) {
+			added++
+		}
+	}
+	if added == 100 {
+		t.Error("Expected the filter to reject items once full")
+	}
+	for i := 0; i < added; i++ {
+		if !f.Test([]byte(strconv.Itoa(i))) {
+			t.Fatalf("Expected added item %d to be in the filter", i)
+		}
+	}
+}
+
+func TestFilterInterface(t *testing.T) {
+	standard, _ := bloom.New(100, 0.01)
+	for _, f := range []bloom.Filter{standard, cuckoo.New(100)} {
+		f.Add([]byte("foo"))
+		if !f.Test([]byte("foo")) {
+			t.Errorf("Expected %T to contain 'foo'", f)
+		}
+	}
+}
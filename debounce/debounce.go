@@ -0,0 +1,187 @@
+// Package debounce is a package that limits how often a function runs in response to bursts of calls.
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// Edge selects which end of a burst of calls invokes the function.
+type Edge int
+
+const (
+	// Trailing invokes the function once the burst has been quiet for the wait duration.
+	Trailing Edge = iota
+	// Leading invokes the function on the first call of a burst and ignores the rest.
+	Leading
+	// Both invokes the function on the first call of a burst and again after it if more calls followed.
+	Both
+)
+
+// Debouncer wraps a function so that bursts of calls collapse into fewer invocations.
+// It is safe for concurrent use. The function is never run concurrently with itself by a Debouncer.
+type Debouncer struct {
+	fn       func()
+	wait     time.Duration
+	leading  bool
+	trailing bool
+	throttle bool
+	timer    *time.Timer
+	gen      uint64
+	pending  bool
+	stopped  bool
+	mutex    *sync.Mutex
+	running  *sync.Mutex
+}
+
+// New creates a debouncer that runs fn once calls have stopped for wait, on the given edge.
+// Every call during the wait restarts it.
+func New(wait time.Duration, edge Edge, fn func()) *Debouncer {
+	return &Debouncer{
+		fn:       fn,
+		wait:     wait,
+		leading:  edge == Leading || edge == Both,
+		trailing: edge == Trailing || edge == Both,
+		mutex:    &sync.Mutex{},
+		running:  &sync.Mutex{},
+	}
+}
+
+// Throttle creates a debouncer that runs fn at most once per interval. The first call of a burst runs
+// immediately and, if more calls arrive within the interval, one more run follows at its end.
+func Throttle(interval time.Duration, fn func()) *Debouncer {
+	d := New(interval, Both, fn)
+	d.throttle = true
+	return d
+}
+
+// Call records a call, running the function now or later depending on the edge.
+func (d *Debouncer) Call() {
+	d.mutex.Lock()
+	if d.stopped {
+		d.mutex.Unlock()
+		return
+	}
+	if d.timer == nil {
+		d.gen++
+		gen := d.gen
+		d.timer = time.AfterFunc(d.wait, func() { d.fire(gen) })
+		if d.leading {
+			d.mutex.Unlock()
+			d.invoke()
+			return
+		}
+		d.pending = true
+		d.mutex.Unlock()
+		return
+	}
+	d.pending = d.trailing
+	if !d.throttle {
+		d.timer.Reset(d.wait)
+	}
+	d.mutex.Unlock()
+}
+
+// Flush runs a pending trailing invocation now instead of waiting, and ends the current burst.
+func (d *Debouncer) Flush() {
+	d.mutex.Lock()
+	pending := d.pending
+	d.reset()
+	d.mutex.Unlock()
+	if pending {
+		d.invoke()
+	}
+}
+
+// Stop drops any pending invocation and ignores all later calls.
+func (d *Debouncer) Stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stopped = true
+	d.reset()
+}
+
+func (d *Debouncer) reset() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.pending = false
+}
+
+func (d *Debouncer) fire(gen uint64) {
+	d.mutex.Lock()
+	if d.timer == nil || gen != d.gen {
+		// Flushed or stopped after the timer had already fired.
+		d.mutex.Unlock()
+		return
+	}
+	pending := d.pending
+	d.pending = false
+	if pending && d.throttle {
+		d.timer.Reset(d.wait)
+	} else {
+		d.timer = nil
+	}
+	d.mutex.Unlock()
+	if pending {
+		d.invoke()
+	}
+}
+
+func (d *Debouncer) invoke() {
+	d.running.Lock()
+	defer d.running.Unlock()
+	d.fn()
+}
+
+// Coalescer gathers values added in a burst and hands them to a function together.
+// It is safe for concurrent use.
+type Coalescer[T any] struct {
+	fn        func([]T)
+	items     []T
+	mutex     *sync.Mutex
+	debouncer *Debouncer
+}
+
+// Coalesce creates a coalescer that runs fn with every value added since its last run, once adds have
+// stopped for wait.
+func Coalesce[T any](wait time.Duration, fn func([]T)) *Coalescer[T] {
+	c := &Coalescer[T]{
+		fn:    fn,
+		mutex: &sync.Mutex{},
+	}
+	c.debouncer = New(wait, Trailing, c.run)
+	return c
+}
+
+// Add records a value for the next run.
+func (c *Coalescer[T]) Add(item T) {
+	c.mutex.Lock()
+	c.items = append(c.items, item)
+	c.mutex.Unlock()
+	c.debouncer.Call()
+}
+
+// Flush runs the function now with the values gathered so far.
+func (c *Coalescer[T]) Flush() {
+	c.debouncer.Flush()
+}
+
+// Stop discards gathered values and ignores later adds.
+func (c *Coalescer[T]) Stop() {
+	c.debouncer.Stop()
+	c.mutex.Lock()
+	c.items = nil
+	c.mutex.Unlock()
+}
+
+func (c *Coalescer[T]) run() {
+	c.mutex.Lock()
+	items := c.items
+	c.items = nil
+	c.mutex.Unlock()
+	if len(items) > 0 {
+		c.fn(items)
+	}
+}
@@ -0,0 +1,112 @@
+package debounce_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/debounce"
+)
+
+func TestTrailing(t *testing.T) {
+	var calls atomic.Int32
+	d := debounce.New(20*time.Millisecond, debounce.Trailing, func() { calls.Add(1) })
+	for i := 0; i < 5; i++ {
+		d.Call()
+		time.Sleep(2 * time.Millisecond)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("Expected no calls during the burst got %d", calls.Load())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 call after the burst got %d", calls.Load())
+	}
+}
+
+func TestLeading(t *testing.T) {
+	var calls atomic.Int32
+	d := debounce.New(20*time.Millisecond, debounce.Leading, func() { calls.Add(1) })
+	for i := 0; i < 5; i++ {
+		d.Call()
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected the first call to run immediately got %d", calls.Load())
+	}
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 1 {
+		t.Errorf("Expected no trailing call got %d", calls.Load())
+	}
+	d.Call()
+	if calls.Load() != 2 {
+		t.Errorf("Expected a new burst to run immediately got %d", calls.Load())
+	}
+}
+
+func TestBoth(t *testing.T) {
+	var calls atomic.Int32
+	d := debounce.New(20*time.Millisecond, debounce.Both, func() { calls.Add(1) })
+	d.Call()
+	d.Call()
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 2 {
+		t.Errorf("Expected a leading and trailing call got %d", calls.Load())
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	var calls atomic.Int32
+	d := debounce.Throttle(20*time.Millisecond, func() { calls.Add(1) })
+	deadline := time.Now().Add(90 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		d.Call()
+		time.Sleep(time.Millisecond)
+	}
+	d.Stop()
+	if n := calls.Load(); n < 3 || n > 6 {
+		t.Errorf("Expected about one call per interval got %d", n)
+	}
+}
+
+func TestFlushAndStop(t *testing.T) {
+	var calls atomic.Int32
+	d := debounce.New(time.Hour, debounce.Trailing, func() { calls.Add(1) })
+	d.Call()
+	d.Flush()
+	if calls.Load() != 1 {
+		t.Errorf("Expected flush to run the pending call got %d", calls.Load())
+	}
+	d.Flush()
+	if calls.Load() != 1 {
+		t.Errorf("Expected flush with nothing pending to do nothing got %d", calls.Load())
+	}
+	d.Call()
+	d.Stop()
+	d.Call()
+	d.Flush()
+	if calls.Load() != 1 {
+		t.Errorf("Expected stop to drop calls got %d", calls.Load())
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][]int
+	c := debounce.Coalesce(20*time.Millisecond, func(items []int) {
+		mutex.Lock()
+		batches = append(batches, items)
+		mutex.Unlock()
+	})
+	c.Add(1)
+	c.Add(2)
+	c.Add(3)
+	time.Sleep(50 * time.Millisecond)
+	c.Add(4)
+	c.Flush()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 1 || batches[1][0] != 4 {
+		t.Errorf("Expected batches [[1 2 3] [4]] got %v", batches)
+	}
+}
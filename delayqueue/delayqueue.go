@@ -0,0 +1,141 @@
+// Package delayqueue is a package that implements a queue whose items become available at a deadline.
+package delayqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/pqueue"
+)
+
+// ID identifies a queued item so it can be cancelled.
+type ID uint64
+
+type deadline struct {
+	at  time.Time
+	seq uint64
+}
+
+// Queue holds items until their deadlines pass. Items with equal deadlines are taken in the order
+// they were put. It is safe for concurrent use.
+type Queue[T any] struct {
+	heap    *pqueue.Indexed[ID, deadline]
+	items   map[ID]T
+	next    uint64
+	changed chan struct{}
+	mutex   *sync.Mutex
+}
+
+// New creates an empty delay queue.
+func New[T any]() *Queue[T] {
+	return &Queue[T]{
+		heap: pqueue.NewIndexed[ID](func(a, b deadline) bool {
+			if a.at.Equal(b.at) {
+				return a.seq < b.seq
+			}
+			return a.at.Before(b.at)
+		}),
+		items:   make(map[ID]T),
+		changed: make(chan struct{}),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Put adds item to become available at the given time, returning an ID that can cancel it.
+func (q *Queue[T]) Put(item T, at time.Time) ID {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.next++
+	id := ID(q.next)
+	q.items[id] = item
+	q.heap.Push(id, deadline{at: at, seq: q.next})
+	if head, _, _ := q.heap.Peek(); head == id {
+		q.changed = broadcast(q.changed)
+	}
+	return id
+}
+
+// PutAfter adds item to become available after delay.
+func (q *Queue[T]) PutAfter(item T, delay time.Duration) ID {
+	return q.Put(item, time.Now().Add(delay))
+}
+
+// Cancel removes a pending item, reporting whether it was still queued.
+func (q *Queue[T]) Cancel(id ID) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if _, ok := q.heap.Remove(id); !ok {
+		return false
+	}
+	delete(q.items, id)
+	return true
+}
+
+// Take removes and returns the next item, waiting until its deadline passes or ctx is done.
+func (q *Queue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		q.mutex.Lock()
+		item, wait, ok := q.poll(time.Now())
+		changed := q.changed
+		q.mutex.Unlock()
+		if ok {
+			return item, nil
+		}
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-timeout:
+		case <-changed:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+}
+
+// Poll removes and returns the next item if its deadline has passed, without waiting.
+func (q *Queue[T]) Poll() (T, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	item, _, ok := q.poll(time.Now())
+	return item, ok
+}
+
+// Len returns the number of pending items, due or not.
+func (q *Queue[T]) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.heap.Len()
+}
+
+// poll pops the head if due. Otherwise it returns how long until the head is due, or zero if the
+// queue is empty.
+func (q *Queue[T]) poll(now time.Time) (T, time.Duration, bool) {
+	var zero T
+	id, d, ok := q.heap.Peek()
+	if !ok {
+		return zero, 0, false
+	}
+	if wait := d.at.Sub(now); wait > 0 {
+		return zero, wait, false
+	}
+	q.heap.Pop()
+	item := q.items[id]
+	delete(q.items, id)
+	return item, 0, true
+}
+
+func broadcast(ch chan struct{}) chan struct{} {
+	close(ch)
+	return make(chan struct{})
+}
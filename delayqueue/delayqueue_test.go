@@ -0,0 +1,75 @@
+package delayqueue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/delayqueue"
+)
+
+func TestOrder(t *testing.T) {
+	q := delayqueue.New[string]()
+	now := time.Now()
+	q.Put("c", now.Add(-time.Millisecond))
+	q.Put("a", now.Add(-3*time.Millisecond))
+	q.Put("b", now.Add(-2*time.Millisecond))
+	for _, expected := range []string{"a", "b", "c"} {
+		if val, ok := q.Poll(); val != expected || !ok {
+			t.Errorf("Expected %s got %v (%v)", expected, val, ok)
+		}
+	}
+}
+
+func TestNotAvailableEarly(t *testing.T) {
+	q := delayqueue.New[int]()
+	q.PutAfter(1, time.Hour)
+	if _, ok := q.Poll(); ok {
+		t.Error("Expected an item before its deadline to be unavailable")
+	}
+	if q.Len() != 1 {
+		t.Errorf("Expected 1 pending item got %d", q.Len())
+	}
+}
+
+func TestTakeWaits(t *testing.T) {
+	q := delayqueue.New[int]()
+	start := time.Now()
+	q.PutAfter(1, 10*time.Millisecond)
+	if val, err := q.Take(context.Background()); val != 1 || err != nil {
+		t.Errorf("Expected 1 got %v (%v)", val, err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("Expected Take to wait for the deadline got %v", elapsed)
+	}
+}
+
+func TestTakeWakesForEarlierItem(t *testing.T) {
+	q := delayqueue.New[string]()
+	q.PutAfter("late", time.Hour)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		q.PutAfter("soon", time.Millisecond)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if val, err := q.Take(ctx); val != "soon" || err != nil {
+		t.Errorf("Expected soon got %v (%v)", val, err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	q := delayqueue.New[int]()
+	id := q.PutAfter(1, 0)
+	if !q.Cancel(id) {
+		t.Error("Expected a pending item to be cancelled")
+	}
+	if q.Cancel(id) {
+		t.Error("Expected a second cancel to fail")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := q.Take(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected nothing to take got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package deque is a package that implements a double-ended queue backed by a growable ring buffer.
+// Pushes and pops at either end are amortized O(1) and do not allocate per item.
+package deque
+
+const minCapacity = 16
+
+// Deque is a double-ended queue. The zero value is ready to use. It is not safe for concurrent use.
+type Deque[T any] struct {
+	items []T
+	head  int
+	size  int
+}
+
+// New creates an empty deque with room for capacity items before growing.
+func New[T any](capacity int) *Deque[T] {
+	size := minCapacity
+	for size < capacity {
+		size <<= 1
+	}
+	return &Deque[T]{items: make([]T, size)}
+}
+
+// PushFront adds an item to the front of the deque.
+func (d *Deque[T]) PushFront(item T) {
+	d.grow()
+	d.head = (d.head - 1) & (len(d.items) - 1)
+	d.items[d.head] = item
+	d.size++
+}
+
+// PushBack adds an item to the back of the deque.
+func (d *Deque[T]) PushBack(item T) {
+	d.grow()
+	d.items[(d.head+d.size)&(len(d.items)-1)] = item
+	d.size++
+}
+
+// PopFront removes and returns the item at the front of the deque.
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+	item := d.items[d.head]
+	d.items[d.head] = zero
+	d.head = (d.head + 1) & (len(d.items) - 1)
+	d.size--
+	return item, true
+}
+
+// PopBack removes and returns the item at the back of the deque.
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.size == 0 {
+		return zero, false
+	}
+	index := (d.head + d.size - 1) & (len(d.items) - 1)
+	item := d.items[index]
+	d.items[index] = zero
+	d.size--
+	return item, true
+}
+
+// Front returns the item at the front of the deque without removing it.
+func (d *Deque[T]) Front() (T, bool) {
+	return d.At(0)
+}
+
+// Back returns the item at the back of the deque without removing it.
+func (d *Deque[T]) Back() (T, bool) {
+	return d.At(d.size - 1)
+}
+
+// At returns the item at position i, counting from the front.
+func (d *Deque[T]) At(i int) (T, bool) {
+	if i < 0 || i >= d.size {
+		var zero T
+		return zero, false
+	}
+	return d.items[(d.head+i)&(len(d.items)-1)], true
+}
+
+// Len returns the number of items in the deque.
+func (d *Deque[T]) Len() int {
+	return d.size
+}
+
+// Clear removes every item from the deque, keeping its allocated capacity.
+func (d *Deque[T]) Clear() {
+	clear(d.items)
+	d.head = 0
+	d.size = 0
+}
+
+// grow doubles the ring when it is full. The ring length is always a power of two.
+func (d *Deque[T]) grow() {
+	if d.size < len(d.items) {
+		return
+	}
+	size := minCapacity
+	if len(d.items) > 0 {
+		size = len(d.items) << 1
+	}
+	items := make([]T, size)
+	n := copy(items, d.items[d.head:])
+	copy(items[n:], d.items[:d.head])
+	d.items = items
+	d.head = 0
+}
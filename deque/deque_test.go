@@ -0,0 +1,79 @@
+package deque_test
+
+import (
+	"testing"
+
+	"github.com/cjsaylor/goutil/deque"
+)
+
+func TestPushPop(t *testing.T) {
+	var d deque.Deque[int]
+	d.PushBack(2)
+	d.PushBack(3)
+	d.PushFront(1)
+	if front, _ := d.Front(); front != 1 {
+		t.Errorf("Expected front 1 got %d", front)
+	}
+	if back, _ := d.Back(); back != 3 {
+		t.Errorf("Expected back 3 got %d", back)
+	}
+	if item, ok := d.PopFront(); !ok || item != 1 {
+		t.Errorf("Expected 1 got %d", item)
+	}
+	if item, ok := d.PopBack(); !ok || item != 3 {
+		t.Errorf("Expected 3 got %d", item)
+	}
+	if d.Len() != 1 {
+		t.Errorf("Expected 1 item got %d", d.Len())
+	}
+}
+
+func TestGrowWrapped(t *testing.T) {
+	d := deque.New[int](0)
+	for i := 0; i < 10; i++ {
+		d.PushBack(i)
+	}
+	for i := 0; i < 10; i++ {
+		d.PopFront()
+	}
+	for i := 0; i < 100; i++ {
+		d.PushBack(i)
+		d.PushFront(-i)
+	}
+	for i := 99; i >= 0; i-- {
+		if item, _ := d.PopFront(); item != -i {
+			t.Fatalf("Expected %d got %d", -i, item)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if item, _ := d.At(i); item != i {
+			t.Fatalf("Expected %d at %d got %d", i, i, item)
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	d := deque.New[string](4)
+	if _, ok := d.PopFront(); ok {
+		t.Error("Expected pop from empty deque to fail")
+	}
+	if _, ok := d.PopBack(); ok {
+		t.Error("Expected pop from empty deque to fail")
+	}
+	if _, ok := d.Back(); ok {
+		t.Error("Expected back of empty deque to fail")
+	}
+	d.PushBack("a")
+	d.Clear()
+	if d.Len() != 0 {
+		t.Error("Expected deque to be empty after clear")
+	}
+}
+
+func BenchmarkPushPop(b *testing.B) {
+	var d deque.Deque[int]
+	for i := 0; i < b.N; i++ {
+		d.PushBack(i)
+		d.PopFront()
+	}
+}
@@ -0,0 +1,87 @@
+// Package dsu is a package that implements a disjoint set union (union-find) structure.
+package dsu
+
+// DSU partitions elements into disjoint sets using path compression and union by rank.
+// Elements are added implicitly the first time they are seen. It is not safe for concurrent use.
+type DSU[T comparable] struct {
+	parent map[T]T
+	rank   map[T]int
+	sets   int
+}
+
+// New creates an empty disjoint set union.
+func New[T comparable]() *DSU[T] {
+	return &DSU[T]{
+		parent: make(map[T]T),
+		rank:   make(map[T]int),
+	}
+}
+
+// Add elements as singleton sets. Elements that already exist are unchanged.
+func (d *DSU[T]) Add(items ...T) {
+	for _, item := range items {
+		if _, ok := d.parent[item]; !ok {
+			d.parent[item] = item
+			d.sets++
+		}
+	}
+}
+
+// Find returns the representative element of the set containing item.
+func (d *DSU[T]) Find(item T) T {
+	d.Add(item)
+	root := item
+	for d.parent[root] != root {
+		root = d.parent[root]
+	}
+	for item != root {
+		next := d.parent[item]
+		d.parent[item] = root
+		item = next
+	}
+	return root
+}
+
+// Union merges the sets containing a and b, reporting whether they were previously separate.
+func (d *DSU[T]) Union(a, b T) bool {
+	rootA, rootB := d.Find(a), d.Find(b)
+	if rootA == rootB {
+		return false
+	}
+	switch {
+	case d.rank[rootA] < d.rank[rootB]:
+		d.parent[rootA] = rootB
+	case d.rank[rootA] > d.rank[rootB]:
+		d.parent[rootB] = rootA
+	default:
+		d.parent[rootB] = rootA
+		d.rank[rootA]++
+	}
+	d.sets--
+	return true
+}
+
+// Connected reports whether a and b are in the same set.
+func (d *DSU[T]) Connected(a, b T) bool {
+	return d.Find(a) == d.Find(b)
+}
+
+// Len returns the number of elements.
+func (d *DSU[T]) Len() int {
+	return len(d.parent)
+}
+
+// Sets returns the number of disjoint sets.
+func (d *DSU[T]) Sets() int {
+	return d.sets
+}
+
+// Components returns the elements of every set, keyed by each set's representative.
+func (d *DSU[T]) Components() map[T][]T {
+	ret := make(map[T][]T, d.sets)
+	for item := range d.parent {
+		root := d.Find(item)
+		ret[root] = append(ret[root], item)
+	}
+	return ret
+}
@@ -0,0 +1,44 @@
+package dsu_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cjsaylor/goutil/dsu"
+)
+
+func TestUnionFind(t *testing.T) {
+	d := dsu.New[string]()
+	d.Add("e")
+	if !d.Union("a", "b") || !d.Union("c", "d") || !d.Union("b", "d") {
+		t.Error("Expected unions of separate sets to succeed")
+	}
+	if d.Union("a", "c") {
+		t.Error("Expected union within the same set to report false")
+	}
+	if !d.Connected("a", "d") || d.Connected("a", "e") {
+		t.Error("Expected a-d to be connected and a-e not")
+	}
+	if d.Sets() != 2 || d.Len() != 5 {
+		t.Errorf("Expected 2 sets of 5 elements got %d sets of %d", d.Sets(), d.Len())
+	}
+}
+
+func TestComponents(t *testing.T) {
+	d := dsu.New[int]()
+	for i := 0; i < 10; i++ {
+		d.Union(i, i%3)
+	}
+	var sizes []int
+	for root, members := range d.Components() {
+		if d.Find(members[0]) != root {
+			t.Errorf("Expected members to share the representative %d", root)
+		}
+		sizes = append(sizes, len(members))
+	}
+	sort.Ints(sizes)
+	if !reflect.DeepEqual(sizes, []int{3, 3, 4}) {
+		t.Errorf("Expected components of sizes [3 3 4] got %v", sizes)
+	}
+}
@@ -0,0 +1,94 @@
+package errs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BatchError reports which operations of a batch failed. Operations are identified by their
+// index in the batch.
+type BatchError struct {
+	// Total is the number of operations in the batch.
+	Total int
+	// Errors maps the index of each failed operation to its error.
+	Errors map[int]error
+}
+
+// Failed returns the indexes of the failed operations in ascending order.
+func (b *BatchError) Failed() []int {
+	indexes := make([]int, 0, len(b.Errors))
+	for i := range b.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// Err returns the error of operation i, or nil if it succeeded.
+func (b *BatchError) Err(i int) error {
+	return b.Errors[i]
+}
+
+func (b *BatchError) Error() string {
+	s := strings.Builder{}
+	fmt.Fprintf(&s, "%d of %d operations failed", len(b.Errors), b.Total)
+	for n, i := range b.Failed() {
+		if n == 0 {
+			s.WriteString(": ")
+		} else {
+			s.WriteString("; ")
+		}
+		fmt.Fprintf(&s, "[%d] %v", i, b.Errors[i])
+	}
+	return s.String()
+}
+
+// Unwrap returns the errors of the failed operations in index order.
+func (b *BatchError) Unwrap() []error {
+	failed := b.Failed()
+	errs := make([]error, len(failed))
+	for n, i := range failed {
+		errs[n] = b.Errors[i]
+	}
+	return errs
+}
+
+// Batch records the outcome of each operation in a batch. It is safe for concurrent use, so the
+// operations can run in parallel.
+type Batch struct {
+	mutex  *sync.Mutex
+	total  int
+	errors map[int]error
+}
+
+// NewBatch creates a batch of total operations.
+func NewBatch(total int) *Batch {
+	return &Batch{mutex: &sync.Mutex{}, total: total, errors: make(map[int]error)}
+}
+
+// Set records the error of operation i. A nil error records success, clearing any earlier failure.
+func (b *Batch) Set(i int, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err == nil {
+		delete(b.errors, i)
+		return
+	}
+	b.errors[i] = err
+}
+
+// Err returns a *BatchError describing the failed operations, or nil if none failed.
+func (b *Batch) Err() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if len(b.errors) == 0 {
+		return nil
+	}
+	errors := make(map[int]error, len(b.errors))
+	for i, err := range b.errors {
+		errors[i] = err
+	}
+	return &BatchError{Total: b.total, Errors: errors}
+}
@@ -0,0 +1,54 @@
+package errs_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/errs"
+)
+
+func TestBatch(t *testing.T) {
+	b := errs.NewBatch(5)
+	if b.Err() != nil {
+		t.Error("Expected no error when nothing failed")
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			switch i {
+			case 1:
+				b.Set(i, errA)
+			case 3:
+				b.Set(i, errB)
+			default:
+				b.Set(i, nil)
+			}
+		}(i)
+	}
+	wg.Wait()
+	err := b.Err()
+	if err.Error() != "2 of 5 operations failed: [1] a; [3] b" {
+		t.Errorf("Expected a summary of the failures got %q", err.Error())
+	}
+	var batchErr *errs.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatal("Expected a *BatchError")
+	}
+	if failed := batchErr.Failed(); len(failed) != 2 || failed[0] != 1 || failed[1] != 3 {
+		t.Errorf("Expected operations 1 and 3 to fail got %v", failed)
+	}
+	if batchErr.Err(3) != errB || batchErr.Err(0) != nil {
+		t.Error("Expected per-operation errors")
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Error("Expected Is to see the operations' errors")
+	}
+	b.Set(1, nil)
+	b.Set(3, nil)
+	if b.Err() != nil {
+		t.Error("Expected success to clear earlier failures")
+	}
+}
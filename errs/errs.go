@@ -0,0 +1,127 @@
+// Package errs is a package that aggregates multiple errors.
+//
+// Aggregated errors implement Unwrap() []error, so errors.Is and errors.As see through them to
+// the errors they hold.
+package errs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Formatter renders a list of errors as a single message.
+type Formatter func(errs []error) string
+
+// Lines puts each error's message on its own line, like errors.Join.
+func Lines(errs []error) string {
+	return Separated("\n")(errs)
+}
+
+// Separated joins the errors' messages with sep.
+func Separated(sep string) Formatter {
+	return func(errs []error) string {
+		messages := make([]string, len(errs))
+		for i, err := range errs {
+			messages[i] = err.Error()
+		}
+		return strings.Join(messages, sep)
+	}
+}
+
+// Bulleted lists the errors under a count, one per indented bullet.
+func Bulleted(errs []error) string {
+	if len(errs) == 1 {
+		return "1 error occurred:\n\t* " + errs[0].Error()
+	}
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "%d errors occurred:", len(errs))
+	for _, err := range errs {
+		b.WriteString("\n\t* ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Multi is an error made of several errors.
+type Multi struct {
+	Errors []error
+	// Format renders the message. Defaults to Lines.
+	Format Formatter
+}
+
+// Join returns an error holding the non-nil errs, or nil if there are none. Its message has one
+// line per error.
+func Join(errs ...error) error {
+	return JoinFormat(Lines, errs...)
+}
+
+// JoinFormat is like Join with the message rendered by format.
+func JoinFormat(format Formatter, errs ...error) error {
+	var kept []error
+	for _, err := range errs {
+		if err != nil {
+			kept = append(kept, err)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return &Multi{Errors: kept, Format: format}
+}
+
+func (m *Multi) Error() string {
+	if m.Format == nil {
+		return Lines(m.Errors)
+	}
+	return m.Format(m.Errors)
+}
+
+// Unwrap returns the errors held.
+func (m *Multi) Unwrap() []error {
+	return m.Errors
+}
+
+// Collector gathers errors from concurrent work. It is safe for concurrent use.
+type Collector struct {
+	mutex  *sync.Mutex
+	errors []error
+	format Formatter
+}
+
+// NewCollector creates a collector whose combined error is rendered by format, or Lines if nil.
+func NewCollector(format Formatter) *Collector {
+	if format == nil {
+		format = Lines
+	}
+	return &Collector{mutex: &sync.Mutex{}, format: format}
+}
+
+// Add records err if it is not nil.
+func (c *Collector) Add(err error) {
+	if err == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.errors = append(c.errors, err)
+}
+
+// Len returns the number of errors recorded.
+func (c *Collector) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.errors)
+}
+
+// Errors returns a copy of the errors recorded, in the order they were added.
+func (c *Collector) Errors() []error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]error(nil), c.errors...)
+}
+
+// Err returns the recorded errors as one error, or nil if there are none.
+func (c *Collector) Err() error {
+	return JoinFormat(c.format, c.Errors()...)
+}
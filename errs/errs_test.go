@@ -0,0 +1,74 @@
+package errs_test
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/errs"
+)
+
+var (
+	errA = errors.New("a")
+	errB = errors.New("b")
+)
+
+func TestJoin(t *testing.T) {
+	if errs.Join(nil, nil) != nil {
+		t.Error("Expected nil when every error is nil")
+	}
+	err := errs.Join(errA, nil, errB)
+	if err.Error() != "a\nb" {
+		t.Errorf("Expected one line per error got %q", err.Error())
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Error("Expected Is to see the joined errors")
+	}
+}
+
+func TestFormats(t *testing.T) {
+	if err := errs.JoinFormat(errs.Separated("; "), errA, errB); err.Error() != "a; b" {
+		t.Errorf("Expected a; b got %q", err.Error())
+	}
+	if err := errs.JoinFormat(errs.Bulleted, errA, errB); err.Error() != "2 errors occurred:\n\t* a\n\t* b" {
+		t.Errorf("Expected a bulleted list got %q", err.Error())
+	}
+	if err := errs.JoinFormat(errs.Bulleted, errA); err.Error() != "1 error occurred:\n\t* a" {
+		t.Errorf("Expected a single bullet got %q", err.Error())
+	}
+}
+
+func TestAs(t *testing.T) {
+	err := errs.Join(errA, &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist})
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "x" {
+		t.Error("Expected As to find the path error")
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Error("Expected Is to see through nested wrapping")
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c := errs.NewCollector(nil)
+	if c.Err() != nil {
+		t.Error("Expected no error from an empty collector")
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				c.Add(errA)
+			} else {
+				c.Add(nil)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if c.Len() != 5 || len(c.Errors()) != 5 || !errors.Is(c.Err(), errA) {
+		t.Errorf("Expected 5 errors got %d", c.Len())
+	}
+}
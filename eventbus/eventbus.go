@@ -0,0 +1,270 @@
+// Package eventbus is a package that delivers published events to subscribers within one process.
+//
+// Topics are dot-separated names such as "orders.created". Subscription patterns may use "*" to match
+// exactly one segment and a final ">" to match one or more remaining segments, so "orders.*" matches
+// "orders.created" and "orders.>" also matches "orders.created.eu".
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned when publishing to a closed bus.
+	ErrClosed = errors.New("eventbus: bus closed")
+	// ErrSlowSubscriber is the error of a subscription disconnected for falling behind.
+	ErrSlowSubscriber = errors.New("eventbus: subscriber too slow")
+)
+
+// Policy decides what happens when an event is published to a subscriber whose buffer is full.
+type Policy int
+
+const (
+	// Block makes the publisher wait until the subscriber has room.
+	Block Policy = iota
+	// Drop discards the event for that subscriber.
+	Drop
+	// Disconnect unsubscribes the subscriber and closes its channel.
+	Disconnect
+)
+
+// Options configures a subscription.
+type Options struct {
+	// Buffer is the number of events held for the subscriber before its policy applies.
+	Buffer int
+	// Policy is what happens when the buffer is full.
+	Policy Policy
+}
+
+// Event is a published payload and the topic it was published to.
+type Event[T any] struct {
+	Topic   string
+	Payload T
+}
+
+type subscriber interface {
+	matches(topic []string) bool
+	deliver(ctx context.Context, topic string, payload interface{}) error
+	close(err error)
+}
+
+// Bus routes events from publishers to matching subscribers. It is safe for concurrent use.
+type Bus struct {
+	subscribers map[subscriber]struct{}
+	closed      bool
+	mutex       *sync.RWMutex
+}
+
+// New creates an empty bus.
+func New() *Bus {
+	return &Bus{
+		subscribers: make(map[subscriber]struct{}),
+		mutex:       &sync.RWMutex{},
+	}
+}
+
+// Publish delivers payload to every subscriber whose pattern matches topic and who accepts the
+// payload's type. It returns ctx.Err() if ctx is done while waiting on a blocking subscriber.
+func (b *Bus) Publish(ctx context.Context, topic string, payload interface{}) error {
+	segments := strings.Split(topic, ".")
+	b.mutex.RLock()
+	if b.closed {
+		b.mutex.RUnlock()
+		return ErrClosed
+	}
+	var targets []subscriber
+	for s := range b.subscribers {
+		if s.matches(segments) {
+			targets = append(targets, s)
+		}
+	}
+	b.mutex.RUnlock()
+	for _, s := range targets {
+		if err := s.deliver(ctx, topic, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close unsubscribes every subscriber and rejects later publishes.
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	b.closed = true
+	subscribers := b.subscribers
+	b.subscribers = make(map[subscriber]struct{})
+	b.mutex.Unlock()
+	for s := range subscribers {
+		s.close(nil)
+	}
+}
+
+func (b *Bus) add(s subscriber) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.closed {
+		return false
+	}
+	b.subscribers[s] = struct{}{}
+	return true
+}
+
+func (b *Bus) remove(s subscriber) {
+	b.mutex.Lock()
+	delete(b.subscribers, s)
+	b.mutex.Unlock()
+}
+
+// Topic is a topic name bound to the payload type published on it.
+type Topic[T any] struct {
+	bus  *Bus
+	name string
+}
+
+// NewTopic binds name on bus to payloads of type T.
+func NewTopic[T any](bus *Bus, name string) Topic[T] {
+	return Topic[T]{bus: bus, name: name}
+}
+
+// Name returns the topic's name.
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+// Publish delivers payload to the topic's subscribers. See Bus.Publish.
+func (t Topic[T]) Publish(ctx context.Context, payload T) error {
+	return t.bus.Publish(ctx, t.name, payload)
+}
+
+// Subscribe subscribes to the topic.
+func (t Topic[T]) Subscribe(options Options) *Subscription[T] {
+	return Subscribe[T](t.bus, t.name, options)
+}
+
+// Subscription receives the events that match its pattern. Subscriptions to a pattern receive only
+// payloads of type T; subscribe with interface{} to receive every payload.
+type Subscription[T any] struct {
+	bus      *Bus
+	pattern  []string
+	options  Options
+	events   chan Event[T]
+	done     chan struct{}
+	err      error
+	dropped  uint64
+	stopOnce *sync.Once
+	mutex    *sync.RWMutex
+}
+
+// Subscribe subscribes to the topics matching pattern on bus. Subscribing to a closed bus returns a
+// subscription whose channel is already closed.
+func Subscribe[T any](bus *Bus, pattern string, options Options) *Subscription[T] {
+	s := &Subscription[T]{
+		bus:      bus,
+		pattern:  strings.Split(pattern, "."),
+		options:  options,
+		events:   make(chan Event[T], options.Buffer),
+		done:     make(chan struct{}),
+		stopOnce: &sync.Once{},
+		mutex:    &sync.RWMutex{},
+	}
+	if !bus.add(s) {
+		s.close(ErrClosed)
+	}
+	return s
+}
+
+// C returns the channel events are delivered on. It is closed once the subscription ends.
+func (s *Subscription[T]) C() <-chan Event[T] {
+	return s.events
+}
+
+// Unsubscribe ends the subscription.
+func (s *Subscription[T]) Unsubscribe() {
+	s.bus.remove(s)
+	s.close(nil)
+}
+
+// Err returns why the subscription ended: ErrSlowSubscriber if it was disconnected for falling
+// behind, ErrClosed if the bus was already closed, and nil otherwise.
+func (s *Subscription[T]) Err() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.err
+}
+
+// Dropped returns the number of events discarded because the buffer was full.
+func (s *Subscription[T]) Dropped() uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.dropped
+}
+
+func (s *Subscription[T]) matches(topic []string) bool {
+	for i, segment := range s.pattern {
+		if segment == ">" && i == len(s.pattern)-1 {
+			return len(topic) > i
+		}
+		if i >= len(topic) || (segment != "*" && segment != topic[i]) {
+			return false
+		}
+	}
+	return len(topic) == len(s.pattern)
+}
+
+func (s *Subscription[T]) deliver(ctx context.Context, topic string, payload interface{}) error {
+	value, ok := payload.(T)
+	if !ok {
+		return nil
+	}
+	event := Event[T]{Topic: topic, Payload: value}
+	s.mutex.RLock()
+	select {
+	case <-s.done:
+		s.mutex.RUnlock()
+		return nil
+	default:
+	}
+	switch s.options.Policy {
+	case Drop, Disconnect:
+		select {
+		case s.events <- event:
+			s.mutex.RUnlock()
+			return nil
+		default:
+		}
+		s.mutex.RUnlock()
+		if s.options.Policy == Disconnect {
+			s.bus.remove(s)
+			s.close(ErrSlowSubscriber)
+		} else {
+			s.mutex.Lock()
+			s.dropped++
+			s.mutex.Unlock()
+		}
+		return nil
+	default:
+		defer s.mutex.RUnlock()
+		select {
+		case s.events <- event:
+			return nil
+		case <-s.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Subscription[T]) close(err error) {
+	s.stopOnce.Do(func() {
+		// Release blocked deliveries before taking the write lock.
+		close(s.done)
+		s.mutex.Lock()
+		s.err = err
+		close(s.events)
+		s.mutex.Unlock()
+	})
+}
@@ -0,0 +1,121 @@
+package eventbus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/eventbus"
+)
+
+type order struct {
+	ID int
+}
+
+func TestTypedTopic(t *testing.T) {
+	bus := eventbus.New()
+	created := eventbus.NewTopic[order](bus, "orders.created")
+	sub := created.Subscribe(eventbus.Options{Buffer: 1})
+	if err := created.Publish(context.Background(), order{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	event := <-sub.C()
+	if event.Topic != "orders.created" || event.Payload.ID != 1 {
+		t.Errorf("Expected order 1 on orders.created got %+v", event)
+	}
+}
+
+func TestWildcards(t *testing.T) {
+	bus := eventbus.New()
+	single := eventbus.Subscribe[order](bus, "orders.*", eventbus.Options{Buffer: 10})
+	multi := eventbus.Subscribe[order](bus, "orders.>", eventbus.Options{Buffer: 10})
+	everything := eventbus.Subscribe[interface{}](bus, ">", eventbus.Options{Buffer: 10})
+	ctx := context.Background()
+	bus.Publish(ctx, "orders.created", order{ID: 1})
+	bus.Publish(ctx, "orders.created.eu", order{ID: 2})
+	bus.Publish(ctx, "orders", order{ID: 3})
+	bus.Publish(ctx, "orders.note", "not an order")
+	bus.Close()
+	counts := []int{0, 0, 0}
+	for range single.C() {
+		counts[0]++
+	}
+	for range multi.C() {
+		counts[1]++
+	}
+	for range everything.C() {
+		counts[2]++
+	}
+	if counts[0] != 1 || counts[1] != 2 || counts[2] != 4 {
+		t.Errorf("Expected [1 2 4] matching events got %v", counts)
+	}
+}
+
+func TestDropPolicy(t *testing.T) {
+	bus := eventbus.New()
+	sub := eventbus.Subscribe[int](bus, "n", eventbus.Options{Buffer: 1, Policy: eventbus.Drop})
+	for i := 0; i < 3; i++ {
+		bus.Publish(context.Background(), "n", i)
+	}
+	if sub.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped events got %d", sub.Dropped())
+	}
+	if event := <-sub.C(); event.Payload != 0 {
+		t.Errorf("Expected the first event to be kept got %d", event.Payload)
+	}
+}
+
+func TestDisconnectPolicy(t *testing.T) {
+	bus := eventbus.New()
+	sub := eventbus.Subscribe[int](bus, "n", eventbus.Options{Buffer: 1, Policy: eventbus.Disconnect})
+	bus.Publish(context.Background(), "n", 1)
+	bus.Publish(context.Background(), "n", 2)
+	count := 0
+	for range sub.C() {
+		count++
+	}
+	if count != 1 || sub.Err() != eventbus.ErrSlowSubscriber {
+		t.Errorf("Expected a disconnect after 1 event got %d events and %v", count, sub.Err())
+	}
+}
+
+func TestBlockPolicy(t *testing.T) {
+	bus := eventbus.New()
+	sub := eventbus.Subscribe[int](bus, "n", eventbus.Options{Policy: eventbus.Block})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bus.Publish(ctx, "n", 1); err != context.DeadlineExceeded {
+		t.Errorf("Expected a blocked publish to time out got %v", err)
+	}
+	go bus.Publish(context.Background(), "n", 2)
+	if event := <-sub.C(); event.Payload != 2 {
+		t.Errorf("Expected 2 got %d", event.Payload)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	bus := eventbus.New()
+	sub := eventbus.Subscribe[int](bus, "n", eventbus.Options{})
+	done := make(chan error)
+	go func() { done <- bus.Publish(context.Background(), "n", 1) }()
+	time.Sleep(5 * time.Millisecond)
+	sub.Unsubscribe()
+	if err := <-done; err != nil {
+		t.Errorf("Expected unsubscribe to release a blocked publish got %v", err)
+	}
+	if _, ok := <-sub.C(); ok {
+		t.Error("Expected the channel to be closed")
+	}
+}
+
+func TestClosedBus(t *testing.T) {
+	bus := eventbus.New()
+	bus.Close()
+	if err := bus.Publish(context.Background(), "n", 1); err != eventbus.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+	sub := eventbus.Subscribe[int](bus, "n", eventbus.Options{})
+	if _, ok := <-sub.C(); ok || sub.Err() != eventbus.ErrClosed {
+		t.Errorf("Expected a closed subscription got %v", sub.Err())
+	}
+}
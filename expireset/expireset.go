@@ -0,0 +1,123 @@
+// Package expireset is a package that implements a set whose elements expire, for dedup windows such as
+// "has this message ID been seen in the last ten minutes".
+//
+// The set holds at most a fixed number of elements. When a full set needs room, expired elements are removed
+// first and then the element closest to expiring, so an element is never dropped while one that will leave
+// sooner stays. Otherwise expired elements are removed when they are looked up.
+package expireset
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/pqueue"
+)
+
+// Options configures a set. Zero fields take the defaults noted on each.
+type Options struct {
+	// TTL is how long elements added with Add or Seen stay in the set. Defaults to one minute.
+	TTL time.Duration
+	// Capacity bounds the number of elements. Defaults to 1024.
+	Capacity int
+	// Clock decides when elements expire. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Set is a set of elements that expire. It is safe for concurrent use.
+type Set[T comparable] struct {
+	options Options
+	expiry  *pqueue.Indexed[T, time.Time]
+	mutex   *sync.Mutex
+}
+
+// New creates an empty set.
+func New[T comparable](options Options) *Set[T] {
+	if options.TTL <= 0 {
+		options.TTL = time.Minute
+	}
+	if options.Capacity <= 0 {
+		options.Capacity = 1024
+	}
+	options.Clock = clock.OrReal(options.Clock)
+	return &Set[T]{
+		options: options,
+		expiry:  pqueue.NewIndexed[T](func(a, b time.Time) bool { return a.Before(b) }),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Add inserts item with the set's TTL, restarting its expiry if it is already present.
+func (s *Set[T]) Add(item T) {
+	s.AddTTL(item, s.options.TTL)
+}
+
+// AddTTL inserts item to expire after ttl, restarting its expiry if it is already present.
+func (s *Set[T]) AddTTL(item T, ttl time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.add(item, s.options.Clock.Now(), ttl)
+}
+
+// Contains reports whether item is in the set and has not expired.
+func (s *Set[T]) Contains(item T) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.contains(item, s.options.Clock.Now())
+}
+
+// Seen reports whether item is in the set and adds it with the set's TTL if it is not. An item that is
+// already present keeps its original expiry, so the window is measured from when it was first seen.
+func (s *Set[T]) Seen(item T) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.options.Clock.Now()
+	if s.contains(item, now) {
+		return true
+	}
+	s.add(item, now, s.options.TTL)
+	return false
+}
+
+// Remove deletes item, reporting whether it was in the set.
+func (s *Set[T]) Remove(item T) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.expiry.Remove(item)
+	return ok
+}
+
+// Len returns the number of elements, including expired ones that have not been looked up since.
+func (s *Set[T]) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.expiry.Len()
+}
+
+func (s *Set[T]) add(item T, now time.Time, ttl time.Duration) {
+	if !s.expiry.Contains(item) && s.expiry.Len() >= s.options.Capacity {
+		for {
+			_, at, ok := s.expiry.Peek()
+			if !ok || now.Before(at) {
+				break
+			}
+			s.expiry.Pop()
+		}
+		if s.expiry.Len() >= s.options.Capacity {
+			s.expiry.Pop()
+		}
+	}
+	s.expiry.Push(item, now.Add(ttl))
+}
+
+func (s *Set[T]) contains(item T, now time.Time) bool {
+	expires, ok := s.expiry.Priority(item)
+	if !ok {
+		return false
+	}
+	if now.Before(expires) {
+		return true
+	}
+	s.expiry.Remove(item)
+	return false
+}
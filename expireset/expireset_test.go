@@ -0,0 +1,70 @@
+package expireset_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/expireset"
+)
+
+func TestExpiry(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	s := expireset.New[string](expireset.Options{TTL: time.Minute, Clock: fake})
+	s.Add("a")
+	s.AddTTL("b", time.Hour)
+	if !s.Contains("a") || !s.Contains("b") {
+		t.Error("Expected both elements to be present")
+	}
+	fake.Advance(time.Minute)
+	if s.Contains("a") {
+		t.Error("Expected a to expire after its TTL")
+	}
+	if !s.Contains("b") || s.Len() != 1 {
+		t.Errorf("Expected only b to remain got %d elements", s.Len())
+	}
+	if !s.Remove("b") || s.Remove("b") || s.Len() != 0 {
+		t.Error("Expected b to be removed once")
+	}
+}
+
+func TestSeen(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	s := expireset.New[int](expireset.Options{TTL: 10 * time.Minute, Clock: fake})
+	if s.Seen(1) {
+		t.Error("Expected the first sighting to be new")
+	}
+	fake.Advance(9 * time.Minute)
+	if !s.Seen(1) {
+		t.Error("Expected a repeat within the window to be seen")
+	}
+	fake.Advance(time.Minute)
+	if s.Seen(1) {
+		t.Error("Expected the window to be measured from the first sighting")
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	s := expireset.New[int](expireset.Options{TTL: time.Minute, Capacity: 2, Clock: fake})
+	s.Add(1)
+	fake.Advance(time.Second)
+	s.Add(2)
+	s.Contains(1)
+	s.Add(3)
+	if s.Contains(1) || !s.Contains(2) || !s.Contains(3) || s.Len() != 2 {
+		t.Errorf("Expected the element closest to expiring to be evicted got %d elements", s.Len())
+	}
+}
+
+func TestCapacityDropsExpiredFirst(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	s := expireset.New[int](expireset.Options{TTL: 10 * time.Minute, Capacity: 2, Clock: fake})
+	s.Seen(1)
+	s.AddTTL(2, time.Minute)
+	fake.Advance(2 * time.Minute)
+	s.Seen(3)
+	if !s.Seen(1) || s.Len() != 2 {
+		t.Errorf("Expected the expired element to make room got %d elements", s.Len())
+	}
+}
@@ -0,0 +1,135 @@
+// Package filecache is a package that caches file contents keyed by path.
+// Entries are validated against the file's modification time, size and identity on every access,
+// and re-read when the file on disk has changed.
+package filecache
+
+import (
+	"container/list"
+	"os"
+	"sync"
+)
+
+// DecodeFunc converts raw file contents into the value stored in the cache.
+type DecodeFunc func(path string, data []byte) (interface{}, error)
+
+type entry struct {
+	path  string
+	info  os.FileInfo
+	value interface{}
+	size  int64
+}
+
+// Cache holds file contents up to a fixed number of bytes.
+// The least recently used files are evicted once the byte capacity is exceeded.
+type Cache struct {
+	queue    *list.List
+	lookup   map[string]*list.Element
+	capacity int64
+	size     int64
+	decode   DecodeFunc
+	mutex    *sync.Mutex
+}
+
+// NewCache creates a file cache bounded to capacity bytes of file content.
+func NewCache(capacity int64, decode DecodeFunc) *Cache {
+	cache := Cache{
+		queue:    list.New(),
+		lookup:   make(map[string]*list.Element),
+		capacity: capacity,
+		decode:   decode,
+		mutex:    &sync.Mutex{},
+	}
+	return &cache
+}
+
+// Raw returns a decode function that stores file contents as a []byte.
+func Raw() DecodeFunc {
+	return func(path string, data []byte) (interface{}, error) {
+		return data, nil
+	}
+}
+
+// Get returns the decoded contents of the file at path.
+// The file is re-read if it has been modified, resized or replaced since it was cached.
+func (c *Cache) Get(path string) (interface{}, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		c.Remove(path)
+		return nil, err
+	}
+	if value, ok := c.lookupFresh(path, info); ok {
+		return value, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.Remove(path)
+		return nil, err
+	}
+	value, err := c.decode(path, data)
+	if err != nil {
+		c.Remove(path)
+		return nil, err
+	}
+	c.store(&entry{
+		path:  path,
+		info:  info,
+		value: value,
+		size:  int64(len(data)),
+	})
+	return value, nil
+}
+
+// Remove a file from the cache.
+func (c *Cache) Remove(path string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if item, ok := c.lookup[path]; ok {
+		c.remove(item)
+	}
+}
+
+// Size returns the number of bytes of file content currently cached.
+func (c *Cache) Size() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.size
+}
+
+func (c *Cache) lookupFresh(path string, info os.FileInfo) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	item, ok := c.lookup[path]
+	if !ok {
+		return nil, false
+	}
+	cached := item.Value.(*entry)
+	if !cached.info.ModTime().Equal(info.ModTime()) || cached.info.Size() != info.Size() || !os.SameFile(cached.info, info) {
+		c.remove(item)
+		return nil, false
+	}
+	c.queue.MoveToFront(item)
+	return cached.value, true
+}
+
+func (c *Cache) store(e *entry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if item, ok := c.lookup[e.path]; ok {
+		c.remove(item)
+	}
+	if e.size > c.capacity {
+		return
+	}
+	c.lookup[e.path] = c.queue.PushFront(e)
+	c.size += e.size
+	for c.size > c.capacity {
+		c.remove(c.queue.Back())
+	}
+}
+
+func (c *Cache) remove(item *list.Element) {
+	e := item.Value.(*entry)
+	c.queue.Remove(item)
+	delete(c.lookup, e.path)
+	c.size -= e.size
+}
@@ -0,0 +1,93 @@
+package filecache_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/filecache"
+)
+
+func writeFile(t *testing.T, path, contents string, modTime time.Time) {
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "foo", time.Unix(1000, 0))
+	cache := filecache.NewCache(1024, filecache.Raw())
+	val, err := cache.Get(path)
+	if err != nil || string(val.([]byte)) != "foo" {
+		t.Errorf("Expected 'foo' got %v (%v)", val, err)
+	}
+}
+
+func TestGetReloadsWhenModified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "foo", time.Unix(1000, 0))
+	cache := filecache.NewCache(1024, filecache.Raw())
+	cache.Get(path)
+	writeFile(t, path, "bar", time.Unix(2000, 0))
+	val, err := cache.Get(path)
+	if err != nil || string(val.([]byte)) != "bar" {
+		t.Errorf("Expected 'bar' after modification got %v (%v)", val, err)
+	}
+}
+
+func TestGetRemovedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "foo", time.Unix(1000, 0))
+	cache := filecache.NewCache(1024, filecache.Raw())
+	cache.Get(path)
+	os.Remove(path)
+	if _, err := cache.Get(path); !os.IsNotExist(err) {
+		t.Errorf("Expected not exist error got %v", err)
+	}
+	if cache.Size() != 0 {
+		t.Error("Expected removed file to be dropped from the cache")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "a,b,c", time.Unix(1000, 0))
+	decodes := 0
+	cache := filecache.NewCache(1024, func(path string, data []byte) (interface{}, error) {
+		decodes++
+		return strings.Split(string(data), ","), nil
+	})
+	cache.Get(path)
+	val, _ := cache.Get(path)
+	if len(val.([]string)) != 3 {
+		t.Errorf("Expected decoded value got %v", val)
+	}
+	if decodes != 1 {
+		t.Errorf("Expected a single decode got %d", decodes)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	dir := t.TempDir()
+	cache := filecache.NewCache(6, filecache.Raw())
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, "foo", time.Unix(1000, 0))
+		cache.Get(path)
+	}
+	if cache.Size() != 6 {
+		t.Errorf("Expected 6 bytes cached got %d", cache.Size())
+	}
+	big := filepath.Join(dir, "big")
+	writeFile(t, big, "foobarbaz", time.Unix(1000, 0))
+	cache.Get(big)
+	if cache.Size() != 6 {
+		t.Errorf("Expected oversized file to not be cached got %d", cache.Size())
+	}
+}
@@ -0,0 +1,184 @@
+// Package future is a package that represents results which will be available later.
+package future
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/cjsaylor/goutil/workerpool"
+)
+
+// Future is a result that is set once and can be waited on by any number of goroutines.
+// It is safe for concurrent use.
+type Future[T any] struct {
+	done      chan struct{}
+	value     T
+	err       error
+	completed bool
+	callbacks []func(T, error)
+	mutex     *sync.Mutex
+}
+
+// New creates a pending future and the function that completes it. Only the first call to complete
+// has any effect.
+func New[T any]() (*Future[T], func(T, error)) {
+	f := &Future[T]{
+		done:  make(chan struct{}),
+		mutex: &sync.Mutex{},
+	}
+	return f, f.complete
+}
+
+// Resolved creates a future that is already complete.
+func Resolved[T any](value T, err error) *Future[T] {
+	f, complete := New[T]()
+	complete(value, err)
+	return f
+}
+
+// Go runs fn in a new goroutine and returns a future for its result.
+func Go[T any](fn func() (T, error)) *Future[T] {
+	f, complete := New[T]()
+	go func() {
+		complete(fn())
+	}()
+	return f
+}
+
+// Submit runs task on pool and returns a future for its result. See workerpool.Submit.
+func Submit[T any](ctx context.Context, pool *workerpool.Pool, task func(ctx context.Context) (T, error)) (*Future[T], error) {
+	handle, err := workerpool.Submit(ctx, pool, task)
+	if err != nil {
+		return nil, err
+	}
+	f, complete := New[T]()
+	go func() {
+		<-handle.Done()
+		complete(handle.Wait(context.Background()))
+	}()
+	return f, nil
+}
+
+// Get waits for the result or for ctx to be done.
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Done returns a channel that is closed once the future is complete.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// OnComplete registers fn to be called with the result once the future is complete. If it already
+// is, fn is called immediately. Callbacks run on the completing goroutine, in registration order.
+func (f *Future[T]) OnComplete(fn func(T, error)) {
+	f.mutex.Lock()
+	if !f.completed {
+		f.callbacks = append(f.callbacks, fn)
+		f.mutex.Unlock()
+		return
+	}
+	f.mutex.Unlock()
+	fn(f.value, f.err)
+}
+
+func (f *Future[T]) complete(value T, err error) {
+	f.mutex.Lock()
+	if f.completed {
+		f.mutex.Unlock()
+		return
+	}
+	f.value, f.err = value, err
+	f.completed = true
+	callbacks := f.callbacks
+	f.callbacks = nil
+	close(f.done)
+	f.mutex.Unlock()
+	for _, fn := range callbacks {
+		fn(value, err)
+	}
+}
+
+// Then returns a future for fn applied to f's value. If f fails, fn is not called and the returned
+// future fails with the same error.
+func Then[T, U any](f *Future[T], fn func(T) (U, error)) *Future[U] {
+	next, complete := New[U]()
+	f.OnComplete(func(value T, err error) {
+		if err != nil {
+			var zero U
+			complete(zero, err)
+			return
+		}
+		complete(fn(value))
+	})
+	return next
+}
+
+// All returns a future for the values of every future, in order. It fails with the first error to
+// occur without waiting for the rest.
+func All[T any](futures ...*Future[T]) *Future[[]T] {
+	all, complete := New[[]T]()
+	if len(futures) == 0 {
+		complete([]T{}, nil)
+		return all
+	}
+	values := make([]T, len(futures))
+	mutex := &sync.Mutex{}
+	remaining := len(futures)
+	for i, f := range futures {
+		f.OnComplete(func(value T, err error) {
+			if err != nil {
+				complete(nil, err)
+				return
+			}
+			mutex.Lock()
+			values[i] = value
+			remaining--
+			finished := remaining == 0
+			mutex.Unlock()
+			if finished {
+				complete(values, nil)
+			}
+		})
+	}
+	return all
+}
+
+// Any returns a future for the first value to succeed. If every future fails, it fails with all of
+// their errors joined.
+func Any[T any](futures ...*Future[T]) *Future[T] {
+	first, complete := New[T]()
+	if len(futures) == 0 {
+		var zero T
+		complete(zero, errors.New("future: no futures given"))
+		return first
+	}
+	errs := make([]error, len(futures))
+	mutex := &sync.Mutex{}
+	remaining := len(futures)
+	for i, f := range futures {
+		f.OnComplete(func(value T, err error) {
+			if err == nil {
+				complete(value, nil)
+				return
+			}
+			mutex.Lock()
+			errs[i] = err
+			remaining--
+			failed := remaining == 0
+			mutex.Unlock()
+			if failed {
+				var zero T
+				complete(zero, errors.Join(errs...))
+			}
+		})
+	}
+	return first
+}
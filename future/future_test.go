@@ -0,0 +1,106 @@
+package future_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/future"
+	"github.com/cjsaylor/goutil/workerpool"
+)
+
+var errFailed = errors.New("failed")
+
+func TestGet(t *testing.T) {
+	f := future.Go(func() (int, error) {
+		time.Sleep(time.Millisecond)
+		return 1, nil
+	})
+	if val, err := f.Get(context.Background()); val != 1 || err != nil {
+		t.Errorf("Expected 1 got %v (%v)", val, err)
+	}
+	pending, _ := future.New[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := pending.Get(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+}
+
+func TestCompleteOnce(t *testing.T) {
+	f, complete := future.New[int]()
+	complete(1, nil)
+	complete(2, errFailed)
+	if val, err := f.Get(context.Background()); val != 1 || err != nil {
+		t.Errorf("Expected the first result got %v (%v)", val, err)
+	}
+}
+
+func TestOnComplete(t *testing.T) {
+	f, complete := future.New[int]()
+	var got []int
+	f.OnComplete(func(value int, err error) { got = append(got, value) })
+	complete(5, nil)
+	f.OnComplete(func(value int, err error) { got = append(got, value*2) })
+	if len(got) != 2 || got[0] != 5 || got[1] != 10 {
+		t.Errorf("Expected [5 10] got %v", got)
+	}
+}
+
+func TestThen(t *testing.T) {
+	f := future.Then(future.Resolved(42, nil), func(n int) (string, error) {
+		return strconv.Itoa(n), nil
+	})
+	if val, err := f.Get(context.Background()); val != "42" || err != nil {
+		t.Errorf("Expected \"42\" got %v (%v)", val, err)
+	}
+	called := false
+	failed := future.Then(future.Resolved(0, errFailed), func(n int) (string, error) {
+		called = true
+		return "", nil
+	})
+	if _, err := failed.Get(context.Background()); err != errFailed || called {
+		t.Errorf("Expected the error to pass through got %v", err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	all := future.All(future.Resolved(1, nil), future.Go(func() (int, error) { return 2, nil }))
+	values, err := all.Get(context.Background())
+	if err != nil || len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Errorf("Expected [1 2] got %v (%v)", values, err)
+	}
+	pending, _ := future.New[int]()
+	if _, err := future.All(pending, future.Resolved(0, errFailed)).Get(context.Background()); err != errFailed {
+		t.Errorf("Expected All to fail fast got %v", err)
+	}
+}
+
+func TestAny(t *testing.T) {
+	pending, _ := future.New[int]()
+	if val, err := future.Any(pending, future.Resolved(0, errFailed), future.Resolved(3, nil)).Get(context.Background()); val != 3 || err != nil {
+		t.Errorf("Expected the first success got %v (%v)", val, err)
+	}
+	other := errors.New("other")
+	_, err := future.Any(future.Resolved(0, errFailed), future.Resolved(0, other)).Get(context.Background())
+	if !errors.Is(err, errFailed) || !errors.Is(err, other) {
+		t.Errorf("Expected every error got %v", err)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{MaxWorkers: 2})
+	defer pool.Close()
+	f, err := future.Submit(context.Background(), pool, func(ctx context.Context) (int, error) {
+		return 7, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doubled := future.Then(f, func(n int) (int, error) { return n * 2, nil })
+	if val, err := doubled.Get(context.Background()); val != 14 || err != nil {
+		t.Errorf("Expected 14 got %v (%v)", val, err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package graph is a package that implements a directed graph with dependency ordering algorithms.
+// Results are deterministic: ties are broken by the order nodes and edges were added.
+package graph
+
+import (
+	"fmt"
+)
+
+// CycleError is returned when an operation requires an acyclic graph.
+type CycleError[T comparable] struct {
+	// Cycle lists the nodes of one cycle, starting and ending with the same node.
+	Cycle []T
+}
+
+func (e *CycleError[T]) Error() string {
+	return fmt.Sprintf("graph: cycle detected: %v", e.Cycle)
+}
+
+// Graph is a directed graph. It is not safe for concurrent use.
+type Graph[T comparable] struct {
+	nodes []T
+	index map[T]int
+	edges [][]int
+	seen  map[[2]int]struct{}
+}
+
+// New creates an empty directed graph.
+func New[T comparable]() *Graph[T] {
+	return &Graph[T]{
+		index: make(map[T]int),
+		seen:  make(map[[2]int]struct{}),
+	}
+}
+
+// AddNode adds a node with no edges. Adding an existing node has no effect.
+func (g *Graph[T]) AddNode(node T) {
+	g.id(node)
+}
+
+// AddEdge adds an edge from one node to another, adding either node if necessary.
+// For dependency ordering, an edge from a to b means a comes before b.
+func (g *Graph[T]) AddEdge(from, to T) {
+	a, b := g.id(from), g.id(to)
+	if _, ok := g.seen[[2]int{a, b}]; ok {
+		return
+	}
+	g.seen[[2]int{a, b}] = struct{}{}
+	g.edges[a] = append(g.edges[a], b)
+}
+
+// HasEdge reports whether there is an edge from one node to another.
+func (g *Graph[T]) HasEdge(from, to T) bool {
+	a, okA := g.index[from]
+	b, okB := g.index[to]
+	if !okA || !okB {
+		return false
+	}
+	_, ok := g.seen[[2]int{a, b}]
+	return ok
+}
+
+// Nodes returns every node in the order added.
+func (g *Graph[T]) Nodes() []T {
+	return append([]T(nil), g.nodes...)
+}
+
+// Neighbors returns the nodes that node has edges to.
+func (g *Graph[T]) Neighbors(node T) []T {
+	id, ok := g.index[node]
+	if !ok {
+		return nil
+	}
+	return g.resolve(g.edges[id])
+}
+
+// TopologicalSort returns the nodes ordered so that every edge points forward.
+// A *CycleError is returned if the graph has a cycle.
+func (g *Graph[T]) TopologicalSort() ([]T, error) {
+	indegree := make([]int, len(g.nodes))
+	for _, targets := range g.edges {
+		for _, target := range targets {
+			indegree[target]++
+		}
+	}
+	queue := make([]int, 0, len(g.nodes))
+	for id, degree := range indegree {
+		if degree == 0 {
+			queue = append(queue, id)
+		}
+	}
+	for i := 0; i < len(queue); i++ {
+		for _, target := range g.edges[queue[i]] {
+			if indegree[target]--; indegree[target] == 0 {
+				queue = append(queue, target)
+			}
+		}
+	}
+	if len(queue) != len(g.nodes) {
+		return nil, &CycleError[T]{Cycle: g.resolve(g.findCycle())}
+	}
+	return g.resolve(queue), nil
+}
+
+// HasCycle reports whether the graph has a cycle.
+func (g *Graph[T]) HasCycle() bool {
+	return g.findCycle() != nil
+}
+
+// StronglyConnectedComponents returns groups of nodes that can all reach each other,
+// in reverse topological order of the condensed graph.
+func (g *Graph[T]) StronglyConnectedComponents() [][]T {
+	t := tarjan{
+		edges:   g.edges,
+		index:   make([]int, len(g.nodes)),
+		lowlink: make([]int, len(g.nodes)),
+		onStack: make([]bool, len(g.nodes)),
+	}
+	for i := range t.index {
+		t.index[i] = -1
+	}
+	for id := range g.nodes {
+		if t.index[id] < 0 {
+			t.visit(id)
+		}
+	}
+	ret := make([][]T, len(t.components))
+	for i, component := range t.components {
+		ret[i] = g.resolve(component)
+	}
+	return ret
+}
+
+func (g *Graph[T]) id(node T) int {
+	if id, ok := g.index[node]; ok {
+		return id
+	}
+	id := len(g.nodes)
+	g.index[node] = id
+	g.nodes = append(g.nodes, node)
+	g.edges = append(g.edges, nil)
+	return id
+}
+
+func (g *Graph[T]) resolve(ids []int) []T {
+	if ids == nil {
+		return nil
+	}
+	ret := make([]T, len(ids))
+	for i, id := range ids {
+		ret[i] = g.nodes[id]
+	}
+	return ret
+}
+
+// findCycle returns the node ids of a cycle, or nil if the graph is acyclic.
+func (g *Graph[T]) findCycle() []int {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(g.nodes))
+	var path []int
+	var visit func(id int) []int
+	visit = func(id int) []int {
+		state[id] = visiting
+		path = append(path, id)
+		for _, target := range g.edges[id] {
+			switch state[target] {
+			case visiting:
+				for i, p := range path {
+					if p == target {
+						return append(append([]int(nil), path[i:]...), target)
+					}
+				}
+			case unvisited:
+				if cycle := visit(target); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+	for id := range g.nodes {
+		if state[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+type tarjan struct {
+	edges      [][]int
+	index      []int
+	lowlink    []int
+	onStack    []bool
+	stack      []int
+	counter    int
+	components [][]int
+}
+
+func (t *tarjan) visit(id int) {
+	t.index[id] = t.counter
+	t.lowlink[id] = t.counter
+	t.counter++
+	t.stack = append(t.stack, id)
+	t.onStack[id] = true
+	for _, target := range t.edges[id] {
+		if t.index[target] < 0 {
+			t.visit(target)
+			t.lowlink[id] = min(t.lowlink[id], t.lowlink[target])
+		} else if t.onStack[target] {
+			t.lowlink[id] = min(t.lowlink[id], t.index[target])
+		}
+	}
+	if t.lowlink[id] != t.index[id] {
+		return
+	}
+	var component []int
+	for {
+		top := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[top] = false
+		component = append(component, top)
+		if top == id {
+			break
+		}
+	}
+	t.components = append(t.components, component)
+}
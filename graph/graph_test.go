@@ -0,0 +1,77 @@
+package graph_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cjsaylor/goutil/graph"
+)
+
+func TestTopologicalSort(t *testing.T) {
+	g := graph.New[string]()
+	g.AddEdge("compile", "link")
+	g.AddEdge("fetch", "compile")
+	g.AddEdge("link", "package")
+	g.AddEdge("fetch", "lint")
+	g.AddNode("docs")
+	order, err := g.TopologicalSort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"fetch", "docs", "compile", "lint", "link", "package"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected %v got %v", expected, order)
+	}
+}
+
+func TestCycle(t *testing.T) {
+	g := graph.New[int]()
+	g.AddEdge(1, 2)
+	g.AddEdge(2, 3)
+	g.AddEdge(3, 2)
+	if !g.HasCycle() {
+		t.Error("Expected a cycle")
+	}
+	_, err := g.TopologicalSort()
+	var cycleErr *graph.CycleError[int]
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("Expected a cycle error got %v", err)
+	}
+	if !reflect.DeepEqual(cycleErr.Cycle, []int{2, 3, 2}) {
+		t.Errorf("Expected cycle [2 3 2] got %v", cycleErr.Cycle)
+	}
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	g := graph.New[string]()
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.AddEdge("c", "a")
+	g.AddEdge("c", "d")
+	g.AddEdge("d", "e")
+	g.AddEdge("e", "d")
+	var components [][]string
+	for _, component := range g.StronglyConnectedComponents() {
+		sort.Strings(component)
+		components = append(components, component)
+	}
+	expected := [][]string{{"d", "e"}, {"a", "b", "c"}}
+	if !reflect.DeepEqual(components, expected) {
+		t.Errorf("Expected %v got %v", expected, components)
+	}
+}
+
+func TestEdges(t *testing.T) {
+	g := graph.New[int]()
+	g.AddEdge(1, 2)
+	g.AddEdge(1, 2)
+	g.AddEdge(1, 3)
+	if !g.HasEdge(1, 2) || g.HasEdge(2, 1) {
+		t.Error("Expected a directed edge from 1 to 2")
+	}
+	if neighbors := g.Neighbors(1); !reflect.DeepEqual(neighbors, []int{2, 3}) {
+		t.Errorf("Expected [2 3] got %v", neighbors)
+	}
+}
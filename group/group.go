@@ -0,0 +1,156 @@
+// Package group is a package that fans work out to goroutines and waits for all of it, in the
+// manner of errgroup, but bounds concurrency, paces task starts, and collects every error.
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// PanicError is the error recorded for a task that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("group: task panicked: %v", e.Value)
+}
+
+// Options configures a group.
+type Options struct {
+	// Limit is the largest number of tasks running at once. Zero means no limit.
+	Limit int
+	// Interval is the least time between task starts. Zero means tasks start as soon as allowed.
+	Interval time.Duration
+	// FailFast cancels the group's context when a task fails, so remaining tasks are skipped.
+	FailFast bool
+}
+
+// Group runs tasks and collects their errors. A group must not be reused after Wait returns.
+type Group struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	options   Options
+	slots     chan struct{}
+	wg        *sync.WaitGroup
+	mutex     *sync.Mutex
+	errs      []error
+	cancelled bool
+	next      time.Time
+}
+
+// New creates a group and the context its tasks receive. The context is cancelled when Wait returns,
+// or on the first failure when FailFast is set.
+func New(ctx context.Context, options Options) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{
+		ctx:     ctx,
+		cancel:  cancel,
+		options: options,
+		wg:      &sync.WaitGroup{},
+		mutex:   &sync.Mutex{},
+	}
+	if options.Limit > 0 {
+		g.slots = make(chan struct{}, options.Limit)
+	}
+	return g, ctx
+}
+
+// Go starts fn in a new goroutine. It blocks while the group is at its limit or waiting out the
+// start interval. If the group's context is done first, fn is skipped and the context error is
+// recorded once for the group.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if g.slots != nil {
+		select {
+		case g.slots <- struct{}{}:
+		case <-g.ctx.Done():
+			g.skip()
+			return
+		}
+	}
+	if !g.pace() {
+		g.release()
+		g.skip()
+		return
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer g.release()
+		if err := run(g.ctx, fn); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Wait blocks until every started task has returned, then returns all task errors joined together,
+// or nil if every task succeeded.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return errors.Join(g.errs...)
+}
+
+func (g *Group) pace() bool {
+	if g.options.Interval <= 0 {
+		return g.ctx.Err() == nil
+	}
+	g.mutex.Lock()
+	now := time.Now()
+	start := g.next
+	if start.Before(now) {
+		start = now
+	}
+	g.next = start.Add(g.options.Interval)
+	g.mutex.Unlock()
+	if delay := start.Sub(now); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-g.ctx.Done():
+			return false
+		}
+	}
+	return g.ctx.Err() == nil
+}
+
+func (g *Group) release() {
+	if g.slots != nil {
+		<-g.slots
+	}
+}
+
+func (g *Group) skip() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if !g.cancelled {
+		g.cancelled = true
+		g.errs = append(g.errs, g.ctx.Err())
+	}
+}
+
+func (g *Group) fail(err error) {
+	g.mutex.Lock()
+	g.errs = append(g.errs, err)
+	g.mutex.Unlock()
+	if g.options.FailFast {
+		g.cancel()
+	}
+}
+
+func run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,101 @@
+package group_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/group"
+)
+
+func TestCollectsAllErrors(t *testing.T) {
+	g, _ := group.New(context.Background(), group.Options{})
+	first := errors.New("first")
+	second := errors.New("second")
+	g.Go(func(ctx context.Context) error { return first })
+	g.Go(func(ctx context.Context) error { return nil })
+	g.Go(func(ctx context.Context) error { return second })
+	err := g.Wait()
+	if !errors.Is(err, first) || !errors.Is(err, second) {
+		t.Errorf("Expected both errors got %v", err)
+	}
+}
+
+func TestWaitNil(t *testing.T) {
+	g, _ := group.New(context.Background(), group.Options{Limit: 2})
+	for i := 0; i < 5; i++ {
+		g.Go(func(ctx context.Context) error { return nil })
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Expected nil got %v", err)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	g, _ := group.New(context.Background(), group.Options{Limit: 3})
+	var running, peak atomic.Int32
+	for i := 0; i < 20; i++ {
+		g.Go(func(ctx context.Context) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+	g.Wait()
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 concurrent tasks got %d", peak.Load())
+	}
+}
+
+func TestInterval(t *testing.T) {
+	g, _ := group.New(context.Background(), group.Options{Interval: 10 * time.Millisecond})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		g.Go(func(ctx context.Context) error { return nil })
+	}
+	g.Wait()
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected starts to be paced got %v", elapsed)
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	g, _ := group.New(context.Background(), group.Options{})
+	g.Go(func(ctx context.Context) error { panic("boom") })
+	var panicErr *group.PanicError
+	if err := g.Wait(); !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Expected a panic error got %v", err)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	g, ctx := group.New(context.Background(), group.Options{Limit: 1, FailFast: true})
+	failure := errors.New("failure")
+	g.Go(func(ctx context.Context) error { return failure })
+	var ran atomic.Bool
+	for i := 0; i < 5; i++ {
+		g.Go(func(ctx context.Context) error {
+			ran.Store(true)
+			return nil
+		})
+	}
+	err := g.Wait()
+	if !errors.Is(err, failure) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the failure and a cancellation got %v", err)
+	}
+	if ran.Load() {
+		t.Error("Expected remaining tasks to be skipped")
+	}
+	if ctx.Err() == nil {
+		t.Error("Expected the group context to be cancelled")
+	}
+}
@@ -0,0 +1,186 @@
+// Package health is a package that runs liveness and readiness checks for a service.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kind says what a check reports on.
+type Kind int
+
+const (
+	// Liveness checks report whether the process is working at all. A failing liveness check usually
+	// means the process should be restarted.
+	Liveness Kind = iota
+	// Readiness checks report whether the process can serve traffic right now.
+	Readiness
+)
+
+// Status is the outcome of a check or of a set of checks.
+type Status string
+
+const (
+	// StatusUp means every check passed.
+	StatusUp Status = "up"
+	// StatusDown means at least one check failed.
+	StatusDown Status = "down"
+)
+
+// CheckFunc reports a problem by returning an error.
+type CheckFunc func(ctx context.Context) error
+
+// Options configures a registered check.
+type Options struct {
+	// Timeout bounds how long the check may run. Defaults to five seconds.
+	Timeout time.Duration
+	// CacheFor reuses the last result for this long instead of running the check again.
+	// Zero runs the check every time.
+	CacheFor time.Duration
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Status    Status        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report is the outcome of every check of a kind.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type check struct {
+	name    string
+	kind    Kind
+	fn      CheckFunc
+	options Options
+	last    Result
+	mutex   *sync.Mutex
+}
+
+// Registry holds checks. It is safe for concurrent use.
+type Registry struct {
+	checks map[string]*check
+	mutex  *sync.RWMutex
+}
+
+// New creates an empty registry.
+func New() *Registry {
+	return &Registry{
+		checks: make(map[string]*check),
+		mutex:  &sync.RWMutex{},
+	}
+}
+
+// Register adds a check under name, replacing any check already registered with that name.
+func (r *Registry) Register(name string, kind Kind, fn CheckFunc, options Options) {
+	if options.Timeout <= 0 {
+		options.Timeout = 5 * time.Second
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.checks[name] = &check{name: name, kind: kind, fn: fn, options: options, mutex: &sync.Mutex{}}
+}
+
+// Unregister removes the check registered under name.
+func (r *Registry) Unregister(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.checks, name)
+}
+
+// Check runs every check of the given kind concurrently and reports on them. A report with no checks
+// is up.
+func (r *Registry) Check(ctx context.Context, kind Kind) Report {
+	r.mutex.RLock()
+	var checks []*check
+	for _, c := range r.checks {
+		if c.kind == kind {
+			checks = append(checks, c)
+		}
+	}
+	r.mutex.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	results := make([]Result, len(checks))
+	wg := &sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// Handler returns an http.Handler that runs the checks of kind and writes the report as JSON, with
+// status 200 when up and 503 when down.
+func (r *Registry) Handler(kind Kind) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context(), kind)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == StatusUp {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+func (c *check) run(ctx context.Context) Result {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.options.CacheFor > 0 && !c.last.CheckedAt.IsZero() && time.Since(c.last.CheckedAt) < c.options.CacheFor {
+		return c.last
+	}
+	timeout, cancel := context.WithTimeout(ctx, c.options.Timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		// A panicking check is reported as failing rather than taking down the service it checks.
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("health: check panicked: %v", r)
+			}
+		}()
+		done <- c.fn(timeout)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-timeout.Done():
+		err = timeout.Err()
+	}
+	result := Result{Status: StatusUp, Duration: time.Since(start), CheckedAt: start}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	// A failure caused by the caller giving up says nothing about the dependency, so later callers
+	// should not see it.
+	if ctx.Err() == nil {
+		c.last = result
+	}
+	return result
+}
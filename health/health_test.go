@@ -0,0 +1,110 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/health"
+)
+
+func up(ctx context.Context) error { return nil }
+
+func TestCheck(t *testing.T) {
+	r := health.New()
+	r.Register("db", health.Readiness, up, health.Options{})
+	r.Register("cache", health.Readiness, func(ctx context.Context) error {
+		return errors.New("unreachable")
+	}, health.Options{})
+	r.Register("loop", health.Liveness, up, health.Options{})
+	ready := r.Check(context.Background(), health.Readiness)
+	if ready.Status != health.StatusDown || len(ready.Checks) != 2 {
+		t.Errorf("Expected a down readiness report with 2 checks got %+v", ready)
+	}
+	if ready.Checks["cache"].Error != "unreachable" {
+		t.Errorf("Expected the check error got %q", ready.Checks["cache"].Error)
+	}
+	if live := r.Check(context.Background(), health.Liveness); live.Status != health.StatusUp {
+		t.Errorf("Expected liveness to be up got %+v", live)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	r := health.New()
+	r.Register("slow", health.Readiness, func(ctx context.Context) error {
+		select {}
+	}, health.Options{Timeout: 5 * time.Millisecond})
+	report := r.Check(context.Background(), health.Readiness)
+	if report.Checks["slow"].Status != health.StatusDown {
+		t.Errorf("Expected a timed out check to be down got %+v", report)
+	}
+}
+
+func TestCache(t *testing.T) {
+	r := health.New()
+	calls := 0
+	r.Register("counted", health.Liveness, func(ctx context.Context) error {
+		calls++
+		return nil
+	}, health.Options{CacheFor: time.Hour})
+	r.Check(context.Background(), health.Liveness)
+	r.Check(context.Background(), health.Liveness)
+	if calls != 1 {
+		t.Errorf("Expected a cached result got %d calls", calls)
+	}
+	r.Unregister("counted")
+	if report := r.Check(context.Background(), health.Liveness); len(report.Checks) != 0 {
+		t.Errorf("Expected no checks after unregister got %+v", report)
+	}
+}
+
+func TestCancelledNotCached(t *testing.T) {
+	r := health.New()
+	r.Register("db", health.Readiness, func(ctx context.Context) error {
+		return ctx.Err()
+	}, health.Options{CacheFor: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if report := r.Check(ctx, health.Readiness); report.Status != health.StatusDown {
+		t.Errorf("Expected a cancelled check to be down got %+v", report)
+	}
+	if report := r.Check(context.Background(), health.Readiness); report.Status != health.StatusUp {
+		t.Errorf("Expected the cancelled result not to be cached got %+v", report)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := health.New()
+	r.Register("db", health.Readiness, up, health.Options{})
+	recorder := httptest.NewRecorder()
+	r.Handler(health.Readiness).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected 200 got %d", recorder.Code)
+	}
+	var report health.Report
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil || report.Checks["db"].Status != health.StatusUp {
+		t.Errorf("Expected a JSON report got %+v (%v)", report, err)
+	}
+
+	r.Register("db", health.Readiness, func(ctx context.Context) error { return errors.New("down") }, health.Options{})
+	recorder = httptest.NewRecorder()
+	r.Handler(health.Readiness).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 got %d", recorder.Code)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	r := health.New()
+	r.Register("broken", health.Liveness, func(ctx context.Context) error {
+		panic("boom")
+	}, health.Options{})
+	report := r.Check(context.Background(), health.Liveness)
+	if result := report.Checks["broken"]; result.Status != health.StatusDown || result.Error != "health: check panicked: boom" {
+		t.Errorf("Expected the panic to be reported as a failure got %+v", result)
+	}
+}
@@ -0,0 +1,196 @@
+// Package histogram is a package that records the distribution of values in fixed buckets.
+//
+// Recording is lock free, so a histogram can sit on a hot path such as request latency tracking.
+// Snapshots are taken without stopping writers, so a snapshot taken during recording may count a
+// value in Count before its bucket.
+package histogram
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync/atomic"
+
+	"github.com/cjsaylor/goutil/atomicx"
+)
+
+// ErrBoundsMismatch is returned when merging snapshots with different bucket bounds.
+var ErrBoundsMismatch = errors.New("histogram: bucket bounds differ")
+
+// LinearBuckets returns count upper bounds starting at start and spaced width apart.
+func LinearBuckets(start, width float64, count int) []float64 {
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start + float64(i)*width
+	}
+	return bounds
+}
+
+// ExponentialBuckets returns count upper bounds starting at start, each factor times the last.
+// They suit values such as latencies that span several orders of magnitude.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+// Histogram counts values into buckets. It is safe for concurrent use.
+type Histogram struct {
+	bounds []float64
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomicx.Float64
+	min    atomicx.Float64
+	max    atomicx.Float64
+}
+
+// New creates a histogram with the given bucket upper bounds, which are sorted and deduplicated.
+// A value v falls in the first bucket whose bound is at least v; values above every bound fall in
+// a final overflow bucket.
+func New(bounds []float64) *Histogram {
+	sorted := append([]float64(nil), bounds...)
+	sort.Float64s(sorted)
+	unique := sorted[:0]
+	for i, b := range sorted {
+		if i == 0 || b != sorted[i-1] {
+			unique = append(unique, b)
+		}
+	}
+	h := &Histogram{
+		bounds: unique,
+		counts: make([]atomic.Uint64, len(unique)+1),
+	}
+	h.min.Store(math.Inf(1))
+	h.max.Store(math.Inf(-1))
+	return h
+}
+
+// Observe records a value. NaN is ignored.
+func (h *Histogram) Observe(value float64) {
+	if math.IsNaN(value) {
+		return
+	}
+	h.counts[sort.SearchFloat64s(h.bounds, value)].Add(1)
+	h.count.Add(1)
+	h.sum.Add(value)
+	atomicx.Min(&h.min, value)
+	atomicx.Max(&h.max, value)
+}
+
+// Merge adds a snapshot's values to the histogram.
+func (h *Histogram) Merge(s Snapshot) error {
+	if !equal(h.bounds, s.Bounds) {
+		return ErrBoundsMismatch
+	}
+	if s.Count == 0 {
+		return nil
+	}
+	for i, c := range s.Counts {
+		h.counts[i].Add(c)
+	}
+	h.count.Add(s.Count)
+	h.sum.Add(s.Sum)
+	atomicx.Min(&h.min, s.Min)
+	atomicx.Max(&h.max, s.Max)
+	return nil
+}
+
+// Snapshot returns a copy of the histogram's current state.
+func (h *Histogram) Snapshot() Snapshot {
+	s := Snapshot{
+		Bounds: h.bounds,
+		Counts: make([]uint64, len(h.counts)),
+		Count:  h.count.Load(),
+		Sum:    h.sum.Load(),
+		Min:    h.min.Load(),
+		Max:    h.max.Load(),
+	}
+	for i := range h.counts {
+		s.Counts[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+// Snapshot is a copy of a histogram's state. Counts has one more entry than Bounds, for values
+// above the last bound. Min and Max are infinite when Count is zero.
+type Snapshot struct {
+	Bounds []float64
+	Counts []uint64
+	Count  uint64
+	Sum    float64
+	Min    float64
+	Max    float64
+}
+
+// Mean returns the average recorded value, or zero if none were recorded.
+func (s Snapshot) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / float64(s.Count)
+}
+
+// Quantile estimates the value below which the fraction q of recorded values fall, by linear
+// interpolation within the bucket that holds it. It returns zero if no values were recorded.
+func (s Snapshot) Quantile(q float64) float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return s.Min
+	}
+	if q >= 1 {
+		return s.Max
+	}
+	rank := q * float64(s.Count)
+	var seen float64
+	for i, c := range s.Counts {
+		if c == 0 || seen+float64(c) < rank {
+			seen += float64(c)
+			continue
+		}
+		lower, upper := s.Min, s.Max
+		if i > 0 && s.Bounds[i-1] > lower {
+			lower = s.Bounds[i-1]
+		}
+		if i < len(s.Bounds) && s.Bounds[i] < upper {
+			upper = s.Bounds[i]
+		}
+		return lower + (upper-lower)*(rank-seen)/float64(c)
+	}
+	return s.Max
+}
+
+// Merge returns the combination of two snapshots with the same bounds.
+func (s Snapshot) Merge(other Snapshot) (Snapshot, error) {
+	if !equal(s.Bounds, other.Bounds) {
+		return Snapshot{}, ErrBoundsMismatch
+	}
+	merged := Snapshot{
+		Bounds: s.Bounds,
+		Counts: make([]uint64, len(s.Counts)),
+		Count:  s.Count + other.Count,
+		Sum:    s.Sum + other.Sum,
+		Min:    math.Min(s.Min, other.Min),
+		Max:    math.Max(s.Max, other.Max),
+	}
+	for i := range merged.Counts {
+		merged.Counts[i] = s.Counts[i] + other.Counts[i]
+	}
+	return merged, nil
+}
+
+func equal(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
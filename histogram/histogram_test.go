@@ -0,0 +1,102 @@
+package histogram_test
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/histogram"
+)
+
+func TestBuckets(t *testing.T) {
+	linear := histogram.LinearBuckets(10, 5, 3)
+	if len(linear) != 3 || linear[0] != 10 || linear[2] != 20 {
+		t.Errorf("Expected [10 15 20] got %v", linear)
+	}
+	exponential := histogram.ExponentialBuckets(1, 10, 4)
+	if len(exponential) != 4 || exponential[3] != 1000 {
+		t.Errorf("Expected [1 10 100 1000] got %v", exponential)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	h := histogram.New([]float64{10, 1, 5, 5})
+	for _, v := range []float64{0.5, 1, 3, 7, 100, math.NaN()} {
+		h.Observe(v)
+	}
+	s := h.Snapshot()
+	expected := []uint64{2, 1, 1, 1}
+	if len(s.Bounds) != 3 || len(s.Counts) != len(expected) {
+		t.Fatalf("Expected 3 bounds and 4 buckets got %v and %v", s.Bounds, s.Counts)
+	}
+	for i := range expected {
+		if s.Counts[i] != expected[i] {
+			t.Errorf("Expected bucket %d to hold %d got %d", i, expected[i], s.Counts[i])
+		}
+	}
+	if s.Count != 5 || s.Sum != 111.5 || s.Min != 0.5 || s.Max != 100 {
+		t.Errorf("Expected count 5, sum 111.5, min 0.5, max 100 got %+v", s)
+	}
+	if s.Mean() != 22.3 {
+		t.Errorf("Expected a mean of 22.3 got %v", s.Mean())
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	h := histogram.New(histogram.LinearBuckets(10, 10, 10))
+	for i := 1; i <= 100; i++ {
+		h.Observe(float64(i))
+	}
+	s := h.Snapshot()
+	for _, c := range []struct{ q, expected float64 }{{0, 1}, {0.5, 50}, {0.95, 95}, {1, 100}} {
+		if got := s.Quantile(c.q); math.Abs(got-c.expected) > 1 {
+			t.Errorf("Expected quantile %v near %v got %v", c.q, c.expected, got)
+		}
+	}
+	if (histogram.Snapshot{}).Quantile(0.5) != 0 {
+		t.Error("Expected zero for an empty snapshot")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	bounds := histogram.ExponentialBuckets(1, 2, 8)
+	a, b := histogram.New(bounds), histogram.New(bounds)
+	a.Observe(3)
+	b.Observe(50)
+	merged, err := a.Snapshot().Merge(b.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Count != 2 || merged.Min != 3 || merged.Max != 50 {
+		t.Errorf("Expected count 2 between 3 and 50 got %+v", merged)
+	}
+	if err := a.Merge(b.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if s := a.Snapshot(); s.Count != 2 || s.Max != 50 {
+		t.Errorf("Expected the merge to be recorded got %+v", s)
+	}
+	other := histogram.New([]float64{1})
+	if err := a.Merge(other.Snapshot()); !errors.Is(err, histogram.ErrBoundsMismatch) {
+		t.Errorf("Expected ErrBoundsMismatch got %v", err)
+	}
+}
+
+func TestConcurrentObserve(t *testing.T) {
+	h := histogram.New(histogram.LinearBuckets(0, 1, 10))
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				h.Observe(float64(j % 10))
+			}
+		}()
+	}
+	wg.Wait()
+	if s := h.Snapshot(); s.Count != 8000 || s.Sum != 36000 {
+		t.Errorf("Expected 8000 values summing to 36000 got %d and %v", s.Count, s.Sum)
+	}
+}
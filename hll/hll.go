@@ -0,0 +1,235 @@
+// Package hll is a package that implements a HyperLogLog cardinality estimator.
+//
+// Sketches start in a sparse representation that only stores registers that have been set,
+// and convert to a dense register array once that becomes the smaller of the two.
+package hll
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+const (
+	// MinPrecision is the smallest supported precision.
+	MinPrecision = 4
+	// MaxPrecision is the largest supported precision.
+	MaxPrecision = 18
+
+	formatVersion = 1
+	formatSparse  = 0
+	formatDense   = 1
+)
+
+var (
+	// ErrPrecision is returned when a precision outside [MinPrecision, MaxPrecision] is requested.
+	ErrPrecision = errors.New("hll: precision out of range")
+	// ErrIncompatible is returned when merging sketches with different precisions.
+	ErrIncompatible = errors.New("hll: sketches are incompatible")
+	// ErrInvalidData is returned when decoding malformed binary data.
+	ErrInvalidData = errors.New("hll: invalid data")
+)
+
+// Sketch estimates the number of distinct items added to it.
+// The standard error is approximately 1.04 / sqrt(2^precision). It is safe for concurrent use.
+type Sketch struct {
+	precision uint8
+	sparse    map[uint32]uint8
+	dense     []uint8
+	mutex     *sync.Mutex
+}
+
+// New creates an empty sketch with 2^precision registers.
+func New(precision uint8) (*Sketch, error) {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return nil, ErrPrecision
+	}
+	return &Sketch{
+		precision: precision,
+		sparse:    make(map[uint32]uint8),
+		mutex:     &sync.Mutex{},
+	}, nil
+}
+
+// Add an item to the sketch.
+func (s *Sketch) Add(data []byte) {
+	h := fnv.New64a()
+	h.Write(data)
+	s.AddHash(mix(h.Sum64()))
+}
+
+// AddHash adds an item by its 64-bit hash. The hash must be uniformly distributed.
+func (s *Sketch) AddHash(hash uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index := uint32(hash >> (64 - s.precision))
+	rank := uint8(bits.LeadingZeros64(hash<<s.precision|1<<(s.precision-1))) + 1
+	s.set(index, rank)
+}
+
+// Estimate returns the approximate number of distinct items added.
+func (s *Sketch) Estimate() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	m := float64(uint64(1) << s.precision)
+	sum := 0.0
+	zeros := 0
+	if s.dense != nil {
+		for _, rank := range s.dense {
+			sum += math.Ldexp(1, -int(rank))
+			if rank == 0 {
+				zeros++
+			}
+		}
+	} else {
+		zeros = int(m) - len(s.sparse)
+		sum = float64(zeros)
+		for _, rank := range s.sparse {
+			sum += math.Ldexp(1, -int(rank))
+		}
+	}
+	estimate := alpha(m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Merge folds other into the sketch, so the result estimates the union of both.
+func (s *Sketch) Merge(other *Sketch) error {
+	if s == other {
+		return nil
+	}
+	other.mutex.Lock()
+	precision := other.precision
+	registers := make(map[uint32]uint8)
+	if other.dense != nil {
+		for index, rank := range other.dense {
+			if rank > 0 {
+				registers[uint32(index)] = rank
+			}
+		}
+	} else {
+		for index, rank := range other.sparse {
+			registers[index] = rank
+		}
+	}
+	other.mutex.Unlock()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.precision != precision {
+		return ErrIncompatible
+	}
+	for index, rank := range registers {
+		s.set(index, rank)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the sketch in its current representation.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.dense != nil {
+		data := append([]byte{formatVersion, s.precision, formatDense}, s.dense...)
+		return data, nil
+	}
+	indexes := make([]uint32, 0, len(s.sparse))
+	for index := range s.sparse {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	data := make([]byte, 3, 3+5*len(indexes))
+	data[0], data[1], data[2] = formatVersion, s.precision, formatSparse
+	for _, index := range indexes {
+		data = binary.BigEndian.AppendUint32(data, index)
+		data = append(data, s.sparse[index])
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a sketch encoded with MarshalBinary.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 || data[0] != formatVersion || data[1] < MinPrecision || data[1] > MaxPrecision {
+		return ErrInvalidData
+	}
+	precision := data[1]
+	m := 1 << precision
+	var sparse map[uint32]uint8
+	var dense []uint8
+	switch data[2] {
+	case formatDense:
+		if len(data)-3 != m {
+			return ErrInvalidData
+		}
+		dense = append([]uint8(nil), data[3:]...)
+	case formatSparse:
+		if (len(data)-3)%5 != 0 {
+			return ErrInvalidData
+		}
+		sparse = make(map[uint32]uint8)
+		for offset := 3; offset < len(data); offset += 5 {
+			index := binary.BigEndian.Uint32(data[offset:])
+			if int(index) >= m {
+				return ErrInvalidData
+			}
+			sparse[index] = data[offset+4]
+		}
+	default:
+		return ErrInvalidData
+	}
+	if s.mutex == nil {
+		s.mutex = &sync.Mutex{}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.precision, s.sparse, s.dense = precision, sparse, dense
+	return nil
+}
+
+// set raises a register to rank, converting to the dense representation when the sparse one grows too large.
+func (s *Sketch) set(index uint32, rank uint8) {
+	if s.dense != nil {
+		if rank > s.dense[index] {
+			s.dense[index] = rank
+		}
+		return
+	}
+	if rank > s.sparse[index] {
+		s.sparse[index] = rank
+	}
+	// A sparse entry costs roughly five bytes against one per dense register.
+	if len(s.sparse)*5 > 1<<s.precision {
+		s.dense = make([]uint8, 1<<s.precision)
+		for i, r := range s.sparse {
+			s.dense[i] = r
+		}
+		s.sparse = nil
+	}
+}
+
+func alpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/m)
+}
+
+// mix applies the murmur3 finalizer to spread the bits of a weak hash.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,95 @@
+package hll_test
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/hll"
+)
+
+func withinError(estimate uint64, actual int, tolerance float64) bool {
+	return math.Abs(float64(estimate)-float64(actual)) <= tolerance*float64(actual)
+}
+
+func TestEstimate(t *testing.T) {
+	for _, n := range []int{10, 1000, 100000} {
+		s, _ := hll.New(14)
+		for i := 0; i < n; i++ {
+			s.Add([]byte(strconv.Itoa(i)))
+			s.Add([]byte(strconv.Itoa(i)))
+		}
+		if estimate := s.Estimate(); !withinError(estimate, n, 0.03) {
+			t.Errorf("Expected approximately %d got %d", n, estimate)
+		}
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	if _, err := hll.New(2); err != hll.ErrPrecision {
+		t.Errorf("Expected precision error got %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, _ := hll.New(12)
+	b, _ := hll.New(12)
+	for i := 0; i < 5000; i++ {
+		a.Add([]byte(strconv.Itoa(i)))
+		b.Add([]byte(strconv.Itoa(i + 2500)))
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if estimate := a.Estimate(); !withinError(estimate, 7500, 0.05) {
+		t.Errorf("Expected approximately 7500 got %d", estimate)
+	}
+	c, _ := hll.New(10)
+	if err := a.Merge(c); err != hll.ErrIncompatible {
+		t.Errorf("Expected incompatible error got %v", err)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for _, n := range []int{10, 10000} {
+		s, _ := hll.New(12)
+		for i := 0; i < n; i++ {
+			s.Add([]byte(strconv.Itoa(i)))
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded hll.Sketch
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Estimate() != s.Estimate() {
+			t.Errorf("Expected decoded estimate %d got %d", s.Estimate(), decoded.Estimate())
+		}
+	}
+	var decoded hll.Sketch
+	if err := decoded.UnmarshalBinary([]byte{1, 12, 1, 0}); err != hll.ErrInvalidData {
+		t.Errorf("Expected invalid data error got %v", err)
+	}
+}
+
+func TestUnmarshalConcurrent(t *testing.T) {
+	s, _ := hll.New(10)
+	s.Add([]byte("a"))
+	data, _ := s.MarshalBinary()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			s.Add([]byte(strconv.Itoa(i)))
+			s.Estimate()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		s.UnmarshalBinary(data)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,146 @@
+// Package id is a package that generates sortable, unique 64-bit IDs without coordination.
+//
+// IDs follow the Snowflake layout: 41 bits of milliseconds since an epoch, 10 bits of node number
+// and 12 bits of sequence within the millisecond. IDs from one generator always increase, and IDs
+// from different nodes sort by the time they were made, give or take clock skew between nodes.
+//
+// Where a string is preferred, ULID and KSUID are 128 and 160-bit IDs whose string forms sort by
+// time and need no node numbers, relying on random bits for uniqueness instead.
+package id
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+const (
+	timeBits     = 41
+	nodeBits     = 10
+	sequenceBits = 12
+
+	// MaxNode is the largest node number.
+	MaxNode = 1<<nodeBits - 1
+	// MaxSequence is the largest sequence number; a generator makes at most MaxSequence+1 IDs per
+	// millisecond.
+	MaxSequence = 1<<sequenceBits - 1
+
+	maxTime = 1<<timeBits - 1
+)
+
+var (
+	// DefaultEpoch is the epoch used when Options.Epoch is zero.
+	DefaultEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// ErrNode is returned when a node number is outside [0, MaxNode].
+	ErrNode = errors.New("id: node out of range")
+	// ErrClockBackwards is returned when the clock has moved back further than Options.MaxDrift.
+	ErrClockBackwards = errors.New("id: clock moved backwards")
+	// ErrExhausted is returned once the bits for the time run out: about 69 years after the epoch
+	// for generated IDs, and the year 10889 for ULIDs.
+	ErrExhausted = errors.New("id: time since epoch too large")
+)
+
+// ID is a generated identifier.
+type ID int64
+
+// String returns the ID in decimal.
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// Parts is an ID broken into its fields.
+type Parts struct {
+	Time     time.Time
+	Node     int64
+	Sequence int64
+}
+
+// Options configures a generator.
+type Options struct {
+	// Node distinguishes generators running at the same time. It must be unique among them.
+	Node int64
+	// Epoch is the time IDs count from. Every generator sharing IDs must use the same epoch.
+	// Defaults to DefaultEpoch.
+	Epoch time.Time
+	// MaxDrift is how far the clock may move backwards, such as during an NTP correction, before
+	// Next fails. Smaller moves are absorbed by continuing from the last time used. Defaults to one
+	// second.
+	MaxDrift time.Duration
+	// Clock supplies the time. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Generator makes IDs. It is safe for concurrent use.
+type Generator struct {
+	options  Options
+	mutex    *sync.Mutex
+	last     int64
+	sequence int64
+}
+
+// New creates a generator.
+func New(options Options) (*Generator, error) {
+	if options.Node < 0 || options.Node > MaxNode {
+		return nil, ErrNode
+	}
+	if options.Epoch.IsZero() {
+		options.Epoch = DefaultEpoch
+	}
+	if options.MaxDrift <= 0 {
+		options.MaxDrift = time.Second
+	}
+	options.Clock = clock.OrReal(options.Clock)
+	return &Generator{options: options, mutex: &sync.Mutex{}}, nil
+}
+
+// Next returns a new ID. If the millisecond's sequence is used up, it waits for the next millisecond.
+func (g *Generator) Next() (ID, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	now := g.millis()
+	if now < g.last {
+		if time.Duration(g.last-now)*time.Millisecond > g.options.MaxDrift {
+			return 0, ErrClockBackwards
+		}
+		now = g.last
+	}
+	if now == g.last {
+		g.sequence = (g.sequence + 1) & MaxSequence
+		if g.sequence == 0 {
+			for now <= g.last {
+				g.options.Clock.Sleep(time.Duration(g.last-now+1) * time.Millisecond)
+				now = g.millis()
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	if now > maxTime {
+		return 0, ErrExhausted
+	}
+	g.last = now
+	return ID(now<<(nodeBits+sequenceBits) | g.options.Node<<sequenceBits | g.sequence), nil
+}
+
+// Parts splits id into its time, node and sequence using the generator's epoch.
+func (g *Generator) Parts(id ID) Parts {
+	return Decode(id, g.options.Epoch)
+}
+
+// Decode splits id into its time, node and sequence, given the epoch it was generated with.
+func Decode(id ID, epoch time.Time) Parts {
+	millis := int64(id) >> (nodeBits + sequenceBits)
+	return Parts{
+		Time:     epoch.Add(time.Duration(millis) * time.Millisecond),
+		Node:     int64(id) >> sequenceBits & MaxNode,
+		Sequence: int64(id) & MaxSequence,
+	}
+}
+
+func (g *Generator) millis() int64 {
+	return g.options.Clock.Since(g.options.Epoch).Milliseconds()
+}
@@ -0,0 +1,110 @@
+package id_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/id"
+)
+
+func TestUnique(t *testing.T) {
+	g, err := id.New(id.Options{Node: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[id.ID]bool{}
+	mutex := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5000; j++ {
+				next, err := g.Next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mutex.Lock()
+				if seen[next] {
+					t.Errorf("Expected unique IDs got %v twice", next)
+				}
+				seen[next] = true
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIncreasing(t *testing.T) {
+	g, _ := id.New(id.Options{})
+	last, _ := g.Next()
+	for i := 0; i < 10000; i++ {
+		next, _ := g.Next()
+		if next <= last {
+			t.Fatalf("Expected %v to be greater than %v", next, last)
+		}
+		last = next
+	}
+}
+
+func TestParts(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(epoch.Add(time.Hour))
+	g, _ := id.New(id.Options{Node: 42, Epoch: epoch, Clock: fake})
+	g.Next()
+	second, _ := g.Next()
+	parts := g.Parts(second)
+	if !parts.Time.Equal(epoch.Add(time.Hour)) || parts.Node != 42 || parts.Sequence != 1 {
+		t.Errorf("Expected an hour after the epoch on node 42 with sequence 1 got %+v", parts)
+	}
+	if decoded := id.Decode(second, epoch); decoded != parts {
+		t.Errorf("Expected %+v got %+v", parts, decoded)
+	}
+	if second.String() == "" {
+		t.Error("Expected a decimal string")
+	}
+}
+
+// skewed is a clock that can be moved backwards.
+type skewed struct {
+	*clock.Fake
+	offset time.Duration
+}
+
+func (s *skewed) Now() time.Time {
+	return s.Fake.Now().Add(s.offset)
+}
+
+func (s *skewed) Since(t time.Time) time.Duration {
+	return s.Now().Sub(t)
+}
+
+func TestClockBackwards(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &skewed{Fake: clock.NewFake(epoch.Add(time.Minute))}
+	g, _ := id.New(id.Options{Epoch: epoch, MaxDrift: 10 * time.Millisecond, Clock: c})
+	first, _ := g.Next()
+	c.offset = -5 * time.Millisecond
+	next, err := g.Next()
+	if err != nil || next <= first {
+		t.Errorf("Expected a small step back to be absorbed got %v (%v)", next, err)
+	}
+	if parts := g.Parts(next); !parts.Time.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("Expected the last time to be reused got %v", parts.Time)
+	}
+	c.offset = -time.Second
+	if _, err := g.Next(); !errors.Is(err, id.ErrClockBackwards) {
+		t.Errorf("Expected ErrClockBackwards got %v", err)
+	}
+}
+
+func TestNode(t *testing.T) {
+	if _, err := id.New(id.Options{Node: id.MaxNode + 1}); !errors.Is(err, id.ErrNode) {
+		t.Errorf("Expected ErrNode got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package id
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+)
+
+const (
+	base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	ksuidLength = 27
+	// ksuidEpoch is the KSUID epoch, 2014-05-13, in Unix seconds.
+	ksuidEpoch = 1400000000
+)
+
+// ErrInvalidKSUID is returned when parsing a string that is not a KSUID.
+var ErrInvalidKSUID = errors.New("id: invalid KSUID")
+
+// maxKSUID is the largest 160-bit value.
+var maxKSUID = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+
+// KSUID is a 160-bit identifier made of a 32-bit timestamp in seconds and a 128-bit random
+// payload. Its 27 character base62 string form sorts in the same order as the IDs' times.
+// KSUIDs are not monotonic within a second; use ULIDs where that matters.
+type KSUID [20]byte
+
+// NewKSUID returns a KSUID for the current time with a payload from crypto/rand.
+func NewKSUID() (KSUID, error) {
+	return NewKSUIDAt(time.Now(), rand.Reader)
+}
+
+// NewKSUIDAt returns a KSUID for t with a payload read from entropy.
+func NewKSUIDAt(t time.Time, entropy io.Reader) (KSUID, error) {
+	var k KSUID
+	binary.BigEndian.PutUint32(k[:4], uint32(t.Unix()-ksuidEpoch))
+	if _, err := io.ReadFull(entropy, k[4:]); err != nil {
+		return KSUID{}, err
+	}
+	return k, nil
+}
+
+// ParseKSUID decodes a KSUID from its string form.
+func ParseKSUID(s string) (KSUID, error) {
+	var k KSUID
+	return k, k.UnmarshalText([]byte(s))
+}
+
+// Time returns the KSUID's timestamp.
+func (k KSUID) Time() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(k[:4]))+ksuidEpoch, 0)
+}
+
+// Payload returns the KSUID's 128 random bits.
+func (k KSUID) Payload() []byte {
+	return append([]byte(nil), k[4:]...)
+}
+
+// Compare returns -1, 0 or 1 as k sorts before, with or after other.
+func (k KSUID) Compare(other KSUID) int {
+	return bytes.Compare(k[:], other[:])
+}
+
+// String returns the 27 character base62 form.
+func (k KSUID) String() string {
+	text, _ := k.MarshalText()
+	return string(text)
+}
+
+// MarshalText encodes the KSUID in its string form.
+func (k KSUID) MarshalText() ([]byte, error) {
+	n := new(big.Int).SetBytes(k[:])
+	text := bytes.Repeat([]byte{'0'}, ksuidLength)
+	digit := new(big.Int)
+	base := big.NewInt(62)
+	for i := ksuidLength - 1; n.Sign() > 0; i-- {
+		n.DivMod(n, base, digit)
+		text[i] = base62[digit.Int64()]
+	}
+	return text, nil
+}
+
+// UnmarshalText decodes a KSUID from its string form.
+func (k *KSUID) UnmarshalText(text []byte) error {
+	if len(text) != ksuidLength {
+		return ErrInvalidKSUID
+	}
+	n := new(big.Int)
+	base := big.NewInt(62)
+	for _, c := range text {
+		v := bytes.IndexByte([]byte(base62), c)
+		if v < 0 {
+			return ErrInvalidKSUID
+		}
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(v)))
+	}
+	if n.Cmp(maxKSUID) > 0 {
+		return ErrInvalidKSUID
+	}
+	n.FillBytes(k[:])
+	return nil
+}
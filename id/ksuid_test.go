@@ -0,0 +1,60 @@
+package id_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/id"
+)
+
+func TestKSUIDRoundTrip(t *testing.T) {
+	k, err := id.NewKSUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := k.String()
+	if len(s) != 27 {
+		t.Fatalf("Expected 27 characters got %q", s)
+	}
+	parsed, err := id.ParseKSUID(s)
+	if err != nil || parsed != k {
+		t.Errorf("Expected %v got %v (%v)", k, parsed, err)
+	}
+	if time.Since(k.Time()) > time.Minute {
+		t.Errorf("Expected a recent time got %v", k.Time())
+	}
+}
+
+func TestKSUIDBounds(t *testing.T) {
+	var zero id.KSUID
+	if zero.String() != "000000000000000000000000000" {
+		t.Errorf("Expected all zeros got %s", zero)
+	}
+	var max id.KSUID
+	for i := range max {
+		max[i] = 0xff
+	}
+	if max.String() != "aWgEPTl1tmebfsQzFP4bxwgy80V" {
+		t.Errorf("Expected the largest KSUID got %s", max)
+	}
+	if _, err := id.ParseKSUID("aWgEPTl1tmebfsQzFP4bxwgy80W"); !errors.Is(err, id.ErrInvalidKSUID) {
+		t.Errorf("Expected ErrInvalidKSUID for an overflow got %v", err)
+	}
+	if _, err := id.ParseKSUID("!"); !errors.Is(err, id.ErrInvalidKSUID) {
+		t.Errorf("Expected ErrInvalidKSUID got %v", err)
+	}
+}
+
+func TestKSUIDOrder(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	earlier, _ := id.NewKSUIDAt(at, bytes.NewReader(bytes.Repeat([]byte{0xff}, 16)))
+	later, _ := id.NewKSUIDAt(at.Add(time.Second), bytes.NewReader(make([]byte, 16)))
+	if earlier.Compare(later) >= 0 || earlier.String() >= later.String() {
+		t.Errorf("Expected %v to sort before %v", earlier, later)
+	}
+	if !earlier.Time().Equal(at) || len(earlier.Payload()) != 16 {
+		t.Errorf("Expected %v with a 16 byte payload got %v", at, earlier.Time())
+	}
+}
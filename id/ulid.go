@@ -0,0 +1,173 @@
+package id
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+const (
+	// crockford is Crockford's base32 alphabet, which omits I, L, O and U.
+	crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+	ulidLength = 26
+	maxULIDMs  = 1<<48 - 1
+)
+
+var (
+	// ErrInvalidULID is returned when parsing a string that is not a ULID.
+	ErrInvalidULID = errors.New("id: invalid ULID")
+	// ErrMonotonicOverflow is returned when a generator makes more ULIDs in one millisecond than
+	// its entropy can count.
+	ErrMonotonicOverflow = errors.New("id: monotonic ULID entropy exhausted")
+)
+
+var decodeCrockford = func() [256]byte {
+	var table [256]byte
+	for i := range table {
+		table[i] = 0xff
+	}
+	for i := 0; i < len(crockford); i++ {
+		table[crockford[i]] = byte(i)
+		table[crockford[i]|0x20] = byte(i)
+	}
+	return table
+}()
+
+// ULID is a 128-bit identifier made of a 48-bit millisecond timestamp and 80 bits of entropy.
+// Its 26 character string form sorts in the same order as the IDs' times.
+type ULID [16]byte
+
+// ULIDGenerator makes ULIDs. Within a millisecond it increments the previous entropy instead of
+// drawing new entropy, so every ULID it makes sorts after the one before. It is safe for
+// concurrent use.
+type ULIDGenerator struct {
+	mutex   *sync.Mutex
+	entropy io.Reader
+	clock   clock.Clock
+	last    ULID
+}
+
+// NewULIDGenerator creates a generator reading entropy from entropy, crypto/rand if nil, and the
+// time from c, the real clock if nil.
+func NewULIDGenerator(entropy io.Reader, c clock.Clock) *ULIDGenerator {
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	return &ULIDGenerator{mutex: &sync.Mutex{}, entropy: entropy, clock: clock.OrReal(c)}
+}
+
+var defaultULIDs = NewULIDGenerator(nil, nil)
+
+// NewULID returns a ULID for the current time from a shared monotonic generator.
+func NewULID() (ULID, error) {
+	return defaultULIDs.Next()
+}
+
+// Next returns a new ULID.
+func (g *ULIDGenerator) Next() (ULID, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	ms := uint64(g.clock.Now().UnixMilli())
+	var u ULID
+	if ms <= g.last.ms() && g.last != (ULID{}) {
+		// Same millisecond, or the clock stepped back: stay on the last time and count up.
+		u = g.last
+		for i := len(u) - 1; ; i-- {
+			if i < 6 {
+				return ULID{}, ErrMonotonicOverflow
+			}
+			u[i]++
+			if u[i] != 0 {
+				break
+			}
+		}
+	} else {
+		if ms > maxULIDMs {
+			return ULID{}, ErrExhausted
+		}
+		u.setMs(ms)
+		if _, err := io.ReadFull(g.entropy, u[6:]); err != nil {
+			return ULID{}, err
+		}
+	}
+	g.last = u
+	return u, nil
+}
+
+// ParseULID decodes a ULID from its string form. Lowercase letters are accepted.
+func ParseULID(s string) (ULID, error) {
+	var u ULID
+	return u, u.UnmarshalText([]byte(s))
+}
+
+// Time returns the ULID's timestamp.
+func (u ULID) Time() time.Time {
+	return time.UnixMilli(int64(u.ms()))
+}
+
+// Entropy returns the ULID's 80 bits of entropy.
+func (u ULID) Entropy() []byte {
+	return append([]byte(nil), u[6:]...)
+}
+
+// Compare returns -1, 0 or 1 as u sorts before, with or after other.
+func (u ULID) Compare(other ULID) int {
+	return bytes.Compare(u[:], other[:])
+}
+
+// String returns the 26 character Crockford base32 form.
+func (u ULID) String() string {
+	text, _ := u.MarshalText()
+	return string(text)
+}
+
+// MarshalText encodes the ULID in its string form.
+func (u ULID) MarshalText() ([]byte, error) {
+	// 128 bits in 26 five-bit characters leaves two spare bits at the top of the first character.
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	text := make([]byte, ulidLength)
+	for i := ulidLength - 1; i >= 0; i-- {
+		text[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return text, nil
+}
+
+// UnmarshalText decodes a ULID from its string form.
+func (u *ULID) UnmarshalText(text []byte) error {
+	if len(text) != ulidLength || decodeCrockford[text[0]] > 7 {
+		return ErrInvalidULID
+	}
+	var hi, lo uint64
+	for _, c := range text {
+		v := decodeCrockford[c]
+		if v == 0xff {
+			return ErrInvalidULID
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	binary.BigEndian.PutUint64(u[:8], hi)
+	binary.BigEndian.PutUint64(u[8:], lo)
+	return nil
+}
+
+func (u ULID) ms() uint64 {
+	return uint64(u[0])<<40 | uint64(u[1])<<32 | uint64(u[2])<<24 | uint64(u[3])<<16 | uint64(u[4])<<8 | uint64(u[5])
+}
+
+func (u *ULID) setMs(ms uint64) {
+	for i := 5; i >= 0; i-- {
+		u[i] = byte(ms)
+		ms >>= 8
+	}
+}
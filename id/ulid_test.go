@@ -0,0 +1,81 @@
+package id_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/id"
+)
+
+func TestULIDRoundTrip(t *testing.T) {
+	u, err := id.NewULID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := u.String()
+	if len(s) != 26 {
+		t.Fatalf("Expected 26 characters got %q", s)
+	}
+	parsed, err := id.ParseULID(strings.ToLower(s))
+	if err != nil || parsed != u {
+		t.Errorf("Expected %v got %v (%v)", u, parsed, err)
+	}
+	if time.Since(u.Time()) > time.Minute {
+		t.Errorf("Expected a recent time got %v", u.Time())
+	}
+}
+
+func TestULIDKnownValue(t *testing.T) {
+	fake := clock.NewFake(time.UnixMilli(1469918176385))
+	g := id.NewULIDGenerator(bytes.NewReader(make([]byte, 10)), fake)
+	u, _ := g.Next()
+	if u.String() != "01ARYZ6S410000000000000000" {
+		t.Errorf("Expected 01ARYZ6S410000000000000000 got %s", u)
+	}
+}
+
+func TestULIDMonotonic(t *testing.T) {
+	fake := clock.NewFake(time.UnixMilli(1700000000000))
+	g := id.NewULIDGenerator(nil, fake)
+	last, _ := g.Next()
+	for i := 0; i < 1000; i++ {
+		next, err := g.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next.Compare(last) <= 0 || next.String() <= last.String() {
+			t.Fatalf("Expected %v to sort after %v", next, last)
+		}
+		if !next.Time().Equal(last.Time()) {
+			t.Fatalf("Expected the same millisecond got %v and %v", next.Time(), last.Time())
+		}
+		last = next
+	}
+	fake.Advance(time.Millisecond)
+	if next, _ := g.Next(); next.Time().Sub(last.Time()) != time.Millisecond {
+		t.Errorf("Expected the next millisecond got %v", next.Time())
+	}
+}
+
+func TestULIDOverflow(t *testing.T) {
+	fake := clock.NewFake(time.UnixMilli(1700000000000))
+	g := id.NewULIDGenerator(bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)), fake)
+	if _, err := g.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Next(); !errors.Is(err, id.ErrMonotonicOverflow) {
+		t.Errorf("Expected ErrMonotonicOverflow got %v", err)
+	}
+}
+
+func TestParseULIDInvalid(t *testing.T) {
+	for _, s := range []string{"", "01ARYZ6S41", "01ARYZ6S41000000000000000U", "81ARYZ6S410000000000000000"} {
+		if _, err := id.ParseULID(s); !errors.Is(err, id.ErrInvalidULID) {
+			t.Errorf("Expected ErrInvalidULID for %q got %v", s, err)
+		}
+	}
+}
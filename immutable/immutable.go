@@ -0,0 +1,247 @@
+// Package immutable is a package that implements a persistent hash map.
+//
+// Every modification returns a new Map that shares all untouched structure with the previous version,
+// so old versions remain valid snapshots and can be read concurrently without locks.
+// The map is a hash array mapped trie (HAMT) consuming five bits of the key's hash per level.
+package immutable
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+const (
+	bitsPerLevel = 5
+	levelMask    = 1<<bitsPerLevel - 1
+)
+
+type leaf[K comparable, V any] struct {
+	hash  uint64
+	key   K
+	value V
+}
+
+// collision holds leaves whose keys have identical hashes.
+type collision[K comparable, V any] struct {
+	hash   uint64
+	leaves []*leaf[K, V]
+}
+
+// node is a bitmap-indexed branch. Each entry is a *leaf, *collision or *node.
+type node[K comparable, V any] struct {
+	bitmap  uint32
+	entries []any
+}
+
+// Map is an immutable hash map. The zero value is not usable; create maps with New.
+type Map[K comparable, V any] struct {
+	root *node[K, V]
+	size int
+	seed maphash.Seed
+}
+
+// New creates an empty map.
+func New[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{
+		root: &node[K, V]{},
+		seed: maphash.MakeSeed(),
+	}
+}
+
+// Len returns the number of entries in the map.
+func (m *Map[K, V]) Len() int {
+	return m.size
+}
+
+// Get will retrieve a value by key.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	hash := maphash.Comparable(m.seed, key)
+	n := m.root
+	for shift := uint(0); ; shift += bitsPerLevel {
+		bit := uint32(1) << ((hash >> shift) & levelMask)
+		if n.bitmap&bit == 0 {
+			break
+		}
+		switch e := n.entries[n.position(bit)].(type) {
+		case *leaf[K, V]:
+			if e.key == key {
+				return e.value, true
+			}
+		case *collision[K, V]:
+			for _, l := range e.leaves {
+				if l.key == key {
+					return l.value, true
+				}
+			}
+		case *node[K, V]:
+			n = e
+			continue
+		}
+		break
+	}
+	var zero V
+	return zero, false
+}
+
+// Set returns a new map with key set to value. The receiver is unchanged.
+func (m *Map[K, V]) Set(key K, value V) *Map[K, V] {
+	l := &leaf[K, V]{hash: maphash.Comparable(m.seed, key), key: key, value: value}
+	root, added := m.root.set(0, l)
+	size := m.size
+	if added {
+		size++
+	}
+	return &Map[K, V]{root: root, size: size, seed: m.seed}
+}
+
+// Delete returns a new map without key. The receiver is unchanged.
+func (m *Map[K, V]) Delete(key K) *Map[K, V] {
+	root, removed := m.root.remove(0, maphash.Comparable(m.seed, key), key)
+	if !removed {
+		return m
+	}
+	if root == nil {
+		root = &node[K, V]{}
+	}
+	return &Map[K, V]{root: root, size: m.size - 1, seed: m.seed}
+}
+
+// Each calls fn for every entry in no particular order.
+// Iteration stops early if fn returns false.
+func (m *Map[K, V]) Each(fn func(key K, value V) bool) {
+	m.root.each(fn)
+}
+
+func (n *node[K, V]) position(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *node[K, V]) withEntry(pos int, entry any) *node[K, V] {
+	entries := append([]any(nil), n.entries...)
+	entries[pos] = entry
+	return &node[K, V]{bitmap: n.bitmap, entries: entries}
+}
+
+func (n *node[K, V]) set(shift uint, l *leaf[K, V]) (*node[K, V], bool) {
+	bit := uint32(1) << ((l.hash >> shift) & levelMask)
+	pos := n.position(bit)
+	if n.bitmap&bit == 0 {
+		entries := make([]any, len(n.entries)+1)
+		copy(entries, n.entries[:pos])
+		entries[pos] = l
+		copy(entries[pos+1:], n.entries[pos:])
+		return &node[K, V]{bitmap: n.bitmap | bit, entries: entries}, true
+	}
+	switch e := n.entries[pos].(type) {
+	case *leaf[K, V]:
+		if e.key == l.key {
+			return n.withEntry(pos, l), false
+		}
+		if e.hash == l.hash {
+			return n.withEntry(pos, &collision[K, V]{hash: l.hash, leaves: []*leaf[K, V]{e, l}}), true
+		}
+		return n.withEntry(pos, branch(shift+bitsPerLevel, e, e.hash, l)), true
+	case *collision[K, V]:
+		if e.hash != l.hash {
+			return n.withEntry(pos, branch(shift+bitsPerLevel, e, e.hash, l)), true
+		}
+		leaves := append([]*leaf[K, V](nil), e.leaves...)
+		for i, existing := range leaves {
+			if existing.key == l.key {
+				leaves[i] = l
+				return n.withEntry(pos, &collision[K, V]{hash: e.hash, leaves: leaves}), false
+			}
+		}
+		return n.withEntry(pos, &collision[K, V]{hash: e.hash, leaves: append(leaves, l)}), true
+	case *node[K, V]:
+		child, added := e.set(shift+bitsPerLevel, l)
+		return n.withEntry(pos, child), added
+	}
+	panic("immutable: unknown entry type")
+}
+
+// branch creates a node holding an existing entry and a new leaf whose hashes differ.
+func branch[K comparable, V any](shift uint, existing any, existingHash uint64, l *leaf[K, V]) *node[K, V] {
+	n := &node[K, V]{}
+	n.bitmap = uint32(1) << ((existingHash >> shift) & levelMask)
+	n.entries = []any{existing}
+	child, _ := n.set(shift, l)
+	return child
+}
+
+func (n *node[K, V]) remove(shift uint, hash uint64, key K) (*node[K, V], bool) {
+	bit := uint32(1) << ((hash >> shift) & levelMask)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	pos := n.position(bit)
+	var replacement any
+	switch e := n.entries[pos].(type) {
+	case *leaf[K, V]:
+		if e.key != key {
+			return n, false
+		}
+	case *collision[K, V]:
+		index := -1
+		for i, l := range e.leaves {
+			if l.key == key {
+				index = i
+			}
+		}
+		if index < 0 {
+			return n, false
+		}
+		leaves := append(append([]*leaf[K, V](nil), e.leaves[:index]...), e.leaves[index+1:]...)
+		if len(leaves) == 1 {
+			replacement = leaves[0]
+		} else {
+			replacement = &collision[K, V]{hash: e.hash, leaves: leaves}
+		}
+	case *node[K, V]:
+		child, removed := e.remove(shift+bitsPerLevel, hash, key)
+		if !removed {
+			return n, false
+		}
+		if child != nil {
+			replacement = child
+			// A branch left with a single leaf or collision can be inlined into this node.
+			if len(child.entries) == 1 {
+				if _, isNode := child.entries[0].(*node[K, V]); !isNode {
+					replacement = child.entries[0]
+				}
+			}
+		}
+	}
+	if replacement != nil {
+		return n.withEntry(pos, replacement), true
+	}
+	if len(n.entries) == 1 {
+		return nil, true
+	}
+	entries := make([]any, 0, len(n.entries)-1)
+	entries = append(entries, n.entries[:pos]...)
+	entries = append(entries, n.entries[pos+1:]...)
+	return &node[K, V]{bitmap: n.bitmap &^ bit, entries: entries}, true
+}
+
+func (n *node[K, V]) each(fn func(key K, value V) bool) bool {
+	for _, entry := range n.entries {
+		switch e := entry.(type) {
+		case *leaf[K, V]:
+			if !fn(e.key, e.value) {
+				return false
+			}
+		case *collision[K, V]:
+			for _, l := range e.leaves {
+				if !fn(l.key, l.value) {
+					return false
+				}
+			}
+		case *node[K, V]:
+			if !e.each(fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
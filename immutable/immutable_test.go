@@ -0,0 +1,97 @@
+package immutable_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/immutable"
+)
+
+func TestSetGet(t *testing.T) {
+	empty := immutable.New[string, int]()
+	a := empty.Set("a", 1)
+	b := a.Set("b", 2).Set("a", 3)
+	if _, ok := empty.Get("a"); ok || empty.Len() != 0 {
+		t.Error("Expected the empty map to be unchanged")
+	}
+	if val, _ := a.Get("a"); val != 1 || a.Len() != 1 {
+		t.Errorf("Expected earlier version to keep 'a' as 1 got %d", val)
+	}
+	if val, _ := b.Get("a"); val != 3 || b.Len() != 2 {
+		t.Errorf("Expected 'a' to be 3 got %d", val)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	m := immutable.New[int, int]().Set(1, 1).Set(2, 2)
+	deleted := m.Delete(1)
+	if _, ok := deleted.Get(1); ok || deleted.Len() != 1 {
+		t.Error("Expected 1 to be deleted")
+	}
+	if _, ok := m.Get(1); !ok {
+		t.Error("Expected the original version to keep 1")
+	}
+	if deleted.Delete(42) != deleted {
+		t.Error("Expected deleting a missing key to return the same map")
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	m := immutable.New[int, int]()
+	expected := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		key := r.Intn(3000)
+		if r.Intn(3) == 0 {
+			m = m.Delete(key)
+			delete(expected, key)
+		} else {
+			m = m.Set(key, i)
+			expected[key] = i
+		}
+	}
+	if m.Len() != len(expected) {
+		t.Fatalf("Expected %d entries got %d", len(expected), m.Len())
+	}
+	for key, value := range expected {
+		if val, ok := m.Get(key); !ok || val != value {
+			t.Fatalf("Expected %d for %d got %d", value, key, val)
+		}
+	}
+	count := 0
+	m.Each(func(key, value int) bool {
+		if expected[key] != value {
+			t.Fatalf("Unexpected entry %d=%d", key, value)
+		}
+		count++
+		return true
+	})
+	if count != len(expected) {
+		t.Errorf("Expected to iterate %d entries got %d", len(expected), count)
+	}
+}
+
+func TestConcurrentReaders(t *testing.T) {
+	m := immutable.New[int, int]()
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i)
+	}
+	snapshot := m
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if val, ok := snapshot.Get(i); !ok || val != i {
+					t.Errorf("Expected %d got %d", i, val)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		m = m.Delete(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,48 @@
+// Package intern is a package that deduplicates equal strings through a bounded LRU cache.
+package intern
+
+import (
+	"strings"
+
+	"github.com/cjsaylor/goutil/lru"
+)
+
+// Pool holds canonical copies of recently seen strings.
+// Only the most recently used strings are retained, so memory stays bounded by the capacity.
+type Pool struct {
+	cache *lru.Cache
+}
+
+// NewPool creates a string interning pool that retains at most capacity distinct strings.
+func NewPool(capacity int) *Pool {
+	return &Pool{
+		cache: lru.NewCache(capacity, lru.Noop()),
+	}
+}
+
+// String returns the canonical copy of s.
+// If an equal string is already in the pool it is returned, otherwise a copy of s is added and
+// returned. The copy keeps a substring from pinning the whole string it was cut from.
+func (p *Pool) String(s string) string {
+	return p.intern(s, true)
+}
+
+// Bytes returns the canonical string equal to b.
+// b is copied into a string for the lookup, so it allocates even when an equal string is already
+// in the pool.
+func (p *Pool) Bytes(b []byte) string {
+	return p.intern(string(b), false)
+}
+
+// intern returns the pooled string equal to s, adding s, or a copy of it if clone is set, when there
+// is none.
+func (p *Pool) intern(s string, clone bool) string {
+	if val, ok := p.cache.Get(s); ok {
+		return val.(string)
+	}
+	if clone {
+		s = strings.Clone(s)
+	}
+	p.cache.Set(s, s)
+	return s
+}
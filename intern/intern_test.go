@@ -0,0 +1,44 @@
+package intern_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/cjsaylor/goutil/intern"
+)
+
+func TestString(t *testing.T) {
+	pool := intern.NewPool(2)
+	a := pool.String(string([]byte("foo")))
+	b := pool.String(string([]byte("foo")))
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("Expected equal strings to share the same backing data")
+	}
+}
+
+func TestSubstringCopied(t *testing.T) {
+	pool := intern.NewPool(2)
+	parent := "foo and a long tail"
+	if s := pool.String(parent[:3]); s != "foo" || unsafe.StringData(s) == unsafe.StringData(parent) {
+		t.Error("Expected a substring to be copied rather than pin its parent")
+	}
+}
+
+func TestBytes(t *testing.T) {
+	pool := intern.NewPool(2)
+	a := pool.String("foo")
+	b := pool.Bytes([]byte("foo"))
+	if b != "foo" || unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("Expected bytes to resolve to the interned string")
+	}
+}
+
+func TestEviction(t *testing.T) {
+	pool := intern.NewPool(1)
+	a := pool.String(string([]byte("foo")))
+	pool.String("bar")
+	b := pool.String(string([]byte("foo")))
+	if unsafe.StringData(a) == unsafe.StringData(b) {
+		t.Error("Expected 'foo' to have been evicted from the pool")
+	}
+}
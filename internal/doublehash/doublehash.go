@@ -0,0 +1,17 @@
+// Package doublehash is a package that computes the base hashes the probabilistic filters and sketches
+// combine by double hashing.
+package doublehash
+
+import (
+	"hash/fnv"
+)
+
+// Sum returns two independent hashes of data. The i-th derived hash is h1 + i*h2. The second hash is odd
+// so the derived indexes cover every slot of a power-of-two sized table.
+func Sum(data []byte) (h1, h2 uint64) {
+	a := fnv.New64a()
+	a.Write(data)
+	b := fnv.New64()
+	b.Write(data)
+	return a.Sum64(), b.Sum64() | 1
+}
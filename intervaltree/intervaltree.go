@@ -0,0 +1,183 @@
+// Package intervaltree is a package that implements a tree of half-open intervals
+// supporting stabbing and overlap queries.
+//
+// The tree is a treap ordered by interval start, where every node also records the largest end
+// in its subtree so that queries can skip subtrees that cannot overlap.
+package intervaltree
+
+import (
+	"cmp"
+	"math/rand"
+)
+
+// Interval is the half-open range [Start, End).
+type Interval[K cmp.Ordered] struct {
+	Start K
+	End   K
+}
+
+type node[K cmp.Ordered, V any] struct {
+	interval Interval[K]
+	value    V
+	maxEnd   K
+	priority int64
+	left     *node[K, V]
+	right    *node[K, V]
+}
+
+// Tree holds intervals and their values. Intervals may overlap or repeat.
+// It is not safe for concurrent use.
+type Tree[K cmp.Ordered, V any] struct {
+	root   *node[K, V]
+	size   int
+	random *rand.Rand
+}
+
+// New creates an empty interval tree.
+func New[K cmp.Ordered, V any]() *Tree[K, V] {
+	return &Tree[K, V]{random: rand.New(rand.NewSource(1))}
+}
+
+// Insert the interval [start, end) with a value.
+func (t *Tree[K, V]) Insert(start, end K, value V) {
+	n := &node[K, V]{
+		interval: Interval[K]{Start: start, End: end},
+		value:    value,
+		maxEnd:   end,
+		priority: t.random.Int63(),
+	}
+	t.root = insert(t.root, n)
+	t.size++
+}
+
+// Delete one interval matching [start, end) exactly, reporting whether one was found.
+func (t *Tree[K, V]) Delete(start, end K) bool {
+	var deleted bool
+	t.root, deleted = remove(t.root, Interval[K]{Start: start, End: end})
+	if deleted {
+		t.size--
+	}
+	return deleted
+}
+
+// Len returns the number of intervals in the tree.
+func (t *Tree[K, V]) Len() int {
+	return t.size
+}
+
+// Stab calls fn for every interval containing point, in order of start.
+// Iteration stops early if fn returns false.
+func (t *Tree[K, V]) Stab(point K, fn func(interval Interval[K], value V) bool) {
+	search(t.root, func(i Interval[K]) bool {
+		return i.Start <= point && point < i.End
+	}, point, point, true, fn)
+}
+
+// Overlaps calls fn for every interval overlapping [start, end), in order of start.
+// Iteration stops early if fn returns false.
+func (t *Tree[K, V]) Overlaps(start, end K, fn func(interval Interval[K], value V) bool) {
+	search(t.root, func(i Interval[K]) bool {
+		return i.Start < end && start < i.End
+	}, start, end, false, fn)
+}
+
+// search visits nodes in order, skipping subtrees whose intervals all end at or before from,
+// and right subtrees whose intervals all start after to (or at to, unless inclusive).
+func search[K cmp.Ordered, V any](n *node[K, V], match func(Interval[K]) bool, from, to K, inclusive bool, fn func(Interval[K], V) bool) bool {
+	if n == nil || n.maxEnd <= from {
+		return true
+	}
+	if !search(n.left, match, from, to, inclusive, fn) {
+		return false
+	}
+	if n.interval.Start > to || (!inclusive && n.interval.Start == to) {
+		return true
+	}
+	if match(n.interval) && !fn(n.interval, n.value) {
+		return false
+	}
+	return search(n.right, match, from, to, inclusive, fn)
+}
+
+func compare[K cmp.Ordered](a, b Interval[K]) int {
+	if c := cmp.Compare(a.Start, b.Start); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.End, b.End)
+}
+
+func insert[K cmp.Ordered, V any](root, n *node[K, V]) *node[K, V] {
+	if root == nil {
+		return n
+	}
+	if compare(n.interval, root.interval) < 0 {
+		root.left = insert(root.left, n)
+		if root.left.priority > root.priority {
+			root = rotateRight(root)
+		}
+	} else {
+		root.right = insert(root.right, n)
+		if root.right.priority > root.priority {
+			root = rotateLeft(root)
+		}
+	}
+	update(root)
+	return root
+}
+
+func remove[K cmp.Ordered, V any](root *node[K, V], interval Interval[K]) (*node[K, V], bool) {
+	if root == nil {
+		return nil, false
+	}
+	var deleted bool
+	switch c := compare(interval, root.interval); {
+	case c < 0:
+		root.left, deleted = remove(root.left, interval)
+	case c > 0:
+		root.right, deleted = remove(root.right, interval)
+	default:
+		if root.left == nil {
+			return root.right, true
+		}
+		if root.right == nil {
+			return root.left, true
+		}
+		if root.left.priority > root.right.priority {
+			root = rotateRight(root)
+			root.right, deleted = remove(root.right, interval)
+		} else {
+			root = rotateLeft(root)
+			root.left, deleted = remove(root.left, interval)
+		}
+	}
+	update(root)
+	return root, deleted
+}
+
+func rotateLeft[K cmp.Ordered, V any](n *node[K, V]) *node[K, V] {
+	x := n.right
+	n.right = x.left
+	x.left = n
+	update(n)
+	update(x)
+	return x
+}
+
+func rotateRight[K cmp.Ordered, V any](n *node[K, V]) *node[K, V] {
+	x := n.left
+	n.left = x.right
+	x.right = n
+	update(n)
+	update(x)
+	return x
+}
+
+func update[K cmp.Ordered, V any](n *node[K, V]) {
+	n.maxEnd = n.interval.End
+	if n.left != nil && n.left.maxEnd > n.maxEnd {
+		n.maxEnd = n.left.maxEnd
+	}
+	if n.right != nil && n.right.maxEnd > n.maxEnd {
+		n.maxEnd = n.right.maxEnd
+	}
+}
@@ -0,0 +1,86 @@
+package intervaltree_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cjsaylor/goutil/intervaltree"
+)
+
+func collect(query func(fn func(intervaltree.Interval[int], string) bool)) []string {
+	var ret []string
+	query(func(interval intervaltree.Interval[int], value string) bool {
+		ret = append(ret, value)
+		return true
+	})
+	return ret
+}
+
+func TestStab(t *testing.T) {
+	tree := intervaltree.New[int, string]()
+	tree.Insert(9, 12, "standup")
+	tree.Insert(10, 11, "review")
+	tree.Insert(13, 14, "lunch")
+	result := collect(func(fn func(intervaltree.Interval[int], string) bool) { tree.Stab(10, fn) })
+	if !reflect.DeepEqual(result, []string{"standup", "review"}) {
+		t.Errorf("Expected [standup review] got %v", result)
+	}
+	result = collect(func(fn func(intervaltree.Interval[int], string) bool) { tree.Stab(12, fn) })
+	if len(result) != 0 {
+		t.Errorf("Expected end to be exclusive got %v", result)
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	tree := intervaltree.New[int, string]()
+	tree.Insert(1, 3, "a")
+	tree.Insert(3, 5, "b")
+	tree.Insert(6, 8, "c")
+	result := collect(func(fn func(intervaltree.Interval[int], string) bool) { tree.Overlaps(2, 6, fn) })
+	if !reflect.DeepEqual(result, []string{"a", "b"}) {
+		t.Errorf("Expected [a b] got %v", result)
+	}
+}
+
+func TestRandomAgainstBruteForce(t *testing.T) {
+	type interval struct{ start, end int }
+	tree := intervaltree.New[int, int]()
+	r := rand.New(rand.NewSource(1))
+	var intervals []interval
+	for i := 0; i < 500; i++ {
+		start := r.Intn(1000)
+		iv := interval{start, start + 1 + r.Intn(50)}
+		intervals = append(intervals, iv)
+		tree.Insert(iv.start, iv.end, i)
+	}
+	for i := 0; i < 100; i++ {
+		iv := intervals[i]
+		if !tree.Delete(iv.start, iv.end) {
+			t.Fatalf("Expected %v to be deleted", iv)
+		}
+	}
+	intervals = intervals[100:]
+	if tree.Len() != len(intervals) {
+		t.Fatalf("Expected %d intervals got %d", len(intervals), tree.Len())
+	}
+	for q := 0; q < 200; q++ {
+		start := r.Intn(1000)
+		end := start + 1 + r.Intn(30)
+		expected := 0
+		for _, iv := range intervals {
+			if iv.start < end && start < iv.end {
+				expected++
+			}
+		}
+		var starts []int
+		tree.Overlaps(start, end, func(i intervaltree.Interval[int], value int) bool {
+			starts = append(starts, i.Start)
+			return true
+		})
+		if len(starts) != expected || !sort.IntsAreSorted(starts) {
+			t.Fatalf("Expected %d sorted overlaps for [%d, %d) got %v", expected, start, end, starts)
+		}
+	}
+}
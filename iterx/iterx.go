@@ -0,0 +1,200 @@
+// Package iterx is a package of combinators for iter.Seq and iter.Seq2 sequences.
+//
+// Combinators are lazy: they do no work until the sequence they return is ranged over, and stop
+// pulling from their input as soon as the consumer stops.
+package iterx
+
+import (
+	"iter"
+)
+
+// Map returns a sequence of fn applied to each value of seq.
+func Map[T, R any](seq iter.Seq[T], fn func(T) R) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		for v := range seq {
+			if !yield(fn(v)) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns a sequence of the values of seq for which keep returns true.
+func Filter[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Filter2 returns a sequence of the pairs of seq for which keep returns true.
+func Filter2[K, V any](seq iter.Seq2[K, V], keep func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if keep(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Take returns a sequence of at most the first n values of seq.
+func Take[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			taken++
+			if taken == n {
+				return
+			}
+		}
+	}
+}
+
+// Skip returns a sequence of the values of seq after the first n.
+func Skip[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		skipped := 0
+		for v := range seq {
+			if skipped < n {
+				skipped++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk returns a sequence of slices of size consecutive values of seq; the last may be shorter.
+// It panics if size is less than one.
+func Chunk[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size < 1 {
+		panic("iterx: chunk size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		chunk := make([]T, 0, size)
+		for v := range seq {
+			chunk = append(chunk, v)
+			if len(chunk) == size {
+				if !yield(chunk) {
+					return
+				}
+				chunk = make([]T, 0, size)
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Zip returns a sequence pairing the values of a and b, ending when either does.
+func Zip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		next, stop := iter.Pull(b)
+		defer stop()
+		for va := range a {
+			vb, ok := next()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate returns a sequence pairing each value of seq with its index.
+func Enumerate[T any](seq iter.Seq[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for v := range seq {
+			if !yield(i, v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// Concat returns a sequence of the values of each of seqs in turn.
+func Concat[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, seq := range seqs {
+			for v := range seq {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Keys returns a sequence of the keys of seq.
+func Keys[K, V any](seq iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns a sequence of the values of seq.
+func Values[K, V any](seq iter.Seq2[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range seq {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Reduce folds the values of seq into an accumulator starting at initial.
+func Reduce[T, A any](seq iter.Seq[T], initial A, fn func(A, T) A) A {
+	acc := initial
+	for v := range seq {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+// Collect returns the values of seq as a slice.
+func Collect[T any](seq iter.Seq[T]) []T {
+	var result []T
+	for v := range seq {
+		result = append(result, v)
+	}
+	return result
+}
+
+// CollectMap returns the pairs of seq as a map. Later pairs overwrite earlier ones with the same key.
+func CollectMap[K comparable, V any](seq iter.Seq2[K, V]) map[K]V {
+	result := make(map[K]V)
+	for k, v := range seq {
+		result[k] = v
+	}
+	return result
+}
+
+// FromSlice returns a sequence of the items of s.
+func FromSlice[T any](s []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range s {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package iterx_test
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/cjsaylor/goutil/iterx"
+)
+
+// naturals yields 0, 1, 2, ... forever, so tests fail to finish if a combinator is not lazy.
+func naturals(yield func(int) bool) {
+	for i := 0; ; i++ {
+		if !yield(i) {
+			return
+		}
+	}
+}
+
+func TestMapFilterTake(t *testing.T) {
+	even := iterx.Filter(naturals, func(n int) bool { return n%2 == 0 })
+	squares := iterx.Map(even, func(n int) int { return n * n })
+	if got := iterx.Collect(iterx.Take(squares, 4)); !reflect.DeepEqual(got, []int{0, 4, 16, 36}) {
+		t.Errorf("Expected [0 4 16 36] got %v", got)
+	}
+	if got := iterx.Collect(iterx.Take(iterx.Skip(naturals, 3), 2)); !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Errorf("Expected [3 4] got %v", got)
+	}
+	if got := iterx.Collect(iterx.Take(naturals, 0)); len(got) != 0 {
+		t.Errorf("Expected nothing got %v", got)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	chunks := iterx.Collect(iterx.Chunk(iterx.FromSlice([]int{1, 2, 3, 4, 5}), 2))
+	if !reflect.DeepEqual(chunks, [][]int{{1, 2}, {3, 4}, {5}}) {
+		t.Errorf("Expected [[1 2] [3 4] [5]] got %v", chunks)
+	}
+	first := iterx.Collect(iterx.Take(iterx.Chunk(naturals, 3), 1))
+	if !reflect.DeepEqual(first, [][]int{{0, 1, 2}}) {
+		t.Errorf("Expected [[0 1 2]] got %v", first)
+	}
+}
+
+func TestZipAndEnumerate(t *testing.T) {
+	zipped := iterx.CollectMap(iterx.Zip(iterx.FromSlice([]string{"a", "b", "c"}), naturals))
+	if !reflect.DeepEqual(zipped, map[string]int{"a": 0, "b": 1, "c": 2}) {
+		t.Errorf("Expected letters paired with numbers got %v", zipped)
+	}
+	var indexes []int
+	for i, s := range iterx.Enumerate(iterx.FromSlice([]string{"x", "y"})) {
+		if s == "" {
+			t.Error("Expected a value")
+		}
+		indexes = append(indexes, i)
+	}
+	if !reflect.DeepEqual(indexes, []int{0, 1}) {
+		t.Errorf("Expected [0 1] got %v", indexes)
+	}
+}
+
+func TestSeq2Helpers(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	odd := iterx.Filter2(maps.All(m), func(k string, v int) bool { return v%2 == 1 })
+	keys := slices.Sorted(iterx.Keys(odd))
+	if !reflect.DeepEqual(keys, []string{"a", "c"}) {
+		t.Errorf("Expected [a c] got %v", keys)
+	}
+	if sum := iterx.Reduce(iterx.Values(maps.All(m)), 0, func(acc, v int) int { return acc + v }); sum != 6 {
+		t.Errorf("Expected 6 got %d", sum)
+	}
+}
+
+func TestConcat(t *testing.T) {
+	got := iterx.Collect(iterx.Take(iterx.Concat(iterx.FromSlice([]int{1}), naturals), 3))
+	if !reflect.DeepEqual(got, []int{1, 0, 1}) {
+		t.Errorf("Expected [1 0 1] got %v", got)
+	}
+}
@@ -0,0 +1,325 @@
+// Package jobqueue is a package that runs queued jobs on a worker pool with retries.
+//
+// Jobs run in priority order. A job that fails is retried after an exponentially growing delay until
+// it has used its attempts, after which it moves to a dead-letter list where it can be inspected and
+// requeued.
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/delayqueue"
+	"github.com/cjsaylor/goutil/pqueue"
+	"github.com/cjsaylor/goutil/workerpool"
+)
+
+// ErrClosed is returned when enqueuing to a closed queue.
+var ErrClosed = errors.New("jobqueue: queue closed")
+
+// Job is a unit of work and its delivery state.
+type Job[T any] struct {
+	// ID is assigned when the job is enqueued.
+	ID      uint64
+	Payload T
+	// Priority orders ready jobs; higher runs first and equal priorities run in enqueue order.
+	Priority int
+	// MaxAttempts is the number of times the job is tried. Zero uses the queue's default.
+	MaxAttempts int
+	// Attempts is the number of times the job has been tried.
+	Attempts int
+	// LastError is the error from the most recent failed attempt.
+	LastError error
+}
+
+// Options configures a queue.
+type Options struct {
+	// MaxAttempts is the default number of tries per job. Defaults to 3.
+	MaxAttempts int
+	// InitialDelay is the delay before the first retry. Defaults to one second.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between retries. Defaults to one minute.
+	MaxDelay time.Duration
+}
+
+// Stats is a point-in-time view of a queue.
+type Stats struct {
+	Ready     int
+	Delayed   int
+	Running   int
+	Completed int64
+	Dead      int
+}
+
+type item[T any] struct {
+	job *Job[T]
+	seq uint64
+}
+
+// Queue feeds jobs to a handler running on a worker pool. It is safe for concurrent use.
+type Queue[T any] struct {
+	handler   func(ctx context.Context, payload T) error
+	pool      *workerpool.Pool
+	options   Options
+	ready     *pqueue.Queue[item[T]]
+	delayed   *delayqueue.Queue[*Job[T]]
+	dead      []*Job[T]
+	nextID    uint64
+	seq       uint64
+	running   int
+	completed int64
+	closed    bool
+	err       error
+	changed   chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	workers   *sync.WaitGroup
+	inflight  *sync.WaitGroup
+	mutex     *sync.Mutex
+}
+
+// New creates a queue that runs handler for each job on pool. The pool is shared, not owned: closing
+// the queue does not close it.
+func New[T any](pool *workerpool.Pool, handler func(ctx context.Context, payload T) error, options Options) *Queue[T] {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 3
+	}
+	if options.InitialDelay <= 0 {
+		options.InitialDelay = time.Second
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = time.Minute
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue[T]{
+		handler: handler,
+		pool:    pool,
+		options: options,
+		ready: pqueue.New(func(a, b item[T]) bool {
+			if a.job.Priority != b.job.Priority {
+				return a.job.Priority > b.job.Priority
+			}
+			return a.seq < b.seq
+		}),
+		delayed:  delayqueue.New[*Job[T]](),
+		changed:  make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		workers:  &sync.WaitGroup{},
+		inflight: &sync.WaitGroup{},
+		mutex:    &sync.Mutex{},
+	}
+	q.workers.Add(2)
+	go q.dispatch()
+	go q.promote()
+	return q
+}
+
+// Enqueue adds a job with the given payload and priority and returns its ID.
+func (q *Queue[T]) Enqueue(payload T, priority int) (uint64, error) {
+	return q.EnqueueJob(Job[T]{Payload: payload, Priority: priority})
+}
+
+// EnqueueJob adds job and returns its assigned ID. Its ID, Attempts and LastError are reset. Once the
+// pool refuses a job, for example because it was closed, dispatch stops and the pool's error is
+// returned instead.
+func (q *Queue[T]) EnqueueJob(job Job[T]) (uint64, error) {
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = q.options.MaxAttempts
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return 0, ErrClosed
+	}
+	if q.err != nil {
+		return 0, q.err
+	}
+	q.nextID++
+	job.ID = q.nextID
+	job.Attempts = 0
+	job.LastError = nil
+	q.push(&job)
+	return job.ID, nil
+}
+
+// DeadLetters returns copies of the jobs that used all their attempts, oldest first.
+func (q *Queue[T]) DeadLetters() []Job[T] {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	jobs := make([]Job[T], len(q.dead))
+	for i, job := range q.dead {
+		jobs[i] = *job
+	}
+	return jobs
+}
+
+// Requeue moves a dead job back to the queue with its attempts reset, reporting whether it was found.
+func (q *Queue[T]) Requeue(id uint64) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed || q.err != nil {
+		return false
+	}
+	for i, job := range q.dead {
+		if job.ID == id {
+			q.dead = append(q.dead[:i], q.dead[i+1:]...)
+			job.Attempts = 0
+			job.LastError = nil
+			q.push(job)
+			return true
+		}
+	}
+	return false
+}
+
+// Stats returns the current state of the queue.
+func (q *Queue[T]) Stats() Stats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return Stats{
+		Ready:     q.ready.Len(),
+		Delayed:   q.delayed.Len(),
+		Running:   q.running,
+		Completed: q.completed,
+		Dead:      len(q.dead),
+	}
+}
+
+// Close stops starting jobs and waits for running ones to finish, or until ctx is done. Jobs still
+// waiting to run or to be retried are dropped. Running jobs see their context cancelled.
+func (q *Queue[T]) Close(ctx context.Context) error {
+	q.mutex.Lock()
+	q.closed = true
+	q.mutex.Unlock()
+	q.cancel()
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		q.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// push makes job ready. It must be called with the lock held.
+func (q *Queue[T]) push(job *Job[T]) {
+	q.seq++
+	q.ready.Push(item[T]{job: job, seq: q.seq})
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// dispatch hands the pool one task at a time and waits for a worker to start it before handing over
+// another. The task picks its job from the ready queue only once it starts, so jobs wait there in
+// priority order rather than in the pool's queue, and Running counts only jobs that are running.
+func (q *Queue[T]) dispatch() {
+	defer q.workers.Done()
+	for q.ctx.Err() == nil {
+		q.mutex.Lock()
+		ok := q.ready.Len() > 0
+		changed := q.changed
+		q.mutex.Unlock()
+		if !ok {
+			select {
+			case <-changed:
+				continue
+			case <-q.ctx.Done():
+				return
+			}
+		}
+		started := make(chan struct{})
+		q.inflight.Add(1)
+		handle, err := q.pool.Go(q.ctx, func(ctx context.Context) error {
+			q.mutex.Lock()
+			next, ok := q.ready.Pop()
+			if ok {
+				q.running++
+			}
+			q.mutex.Unlock()
+			close(started)
+			if ok {
+				q.attempt(ctx, next.job)
+			}
+			return nil
+		})
+		if err != nil {
+			q.inflight.Done()
+			q.mutex.Lock()
+			if q.ctx.Err() == nil {
+				// Nothing will run the jobs now, so stop accepting them.
+				q.err = err
+			}
+			q.mutex.Unlock()
+			return
+		}
+		go func() {
+			<-handle.Done()
+			q.inflight.Done()
+		}()
+		// The pool skips the task without starting it if the queue closes first.
+		select {
+		case <-started:
+		case <-handle.Done():
+		}
+	}
+}
+
+func (q *Queue[T]) promote() {
+	defer q.workers.Done()
+	for {
+		job, err := q.delayed.Take(q.ctx)
+		if err != nil {
+			return
+		}
+		q.mutex.Lock()
+		q.push(job)
+		q.mutex.Unlock()
+	}
+}
+
+func (q *Queue[T]) attempt(ctx context.Context, job *Job[T]) {
+	err := q.call(ctx, job.Payload)
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.running--
+	job.Attempts++
+	if err == nil {
+		q.completed++
+		return
+	}
+	job.LastError = err
+	if job.Attempts >= job.MaxAttempts {
+		q.dead = append(q.dead, job)
+		return
+	}
+	q.delayed.PutAfter(job, q.backoff(job.Attempts))
+}
+
+func (q *Queue[T]) call(ctx context.Context, payload T) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("jobqueue: job panicked: %v", r)
+		}
+	}()
+	return q.handler(ctx, payload)
+}
+
+func (q *Queue[T]) backoff(attempts int) time.Duration {
+	delay := q.options.InitialDelay
+	for i := 1; i < attempts && delay < q.options.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > q.options.MaxDelay {
+		delay = q.options.MaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,158 @@
+package jobqueue_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/jobqueue"
+	"github.com/cjsaylor/goutil/wait"
+	"github.com/cjsaylor/goutil/workerpool"
+)
+
+func waitFor(condition func() bool) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return wait.Until(ctx, time.Millisecond, wait.Bool(condition)) == nil
+}
+
+func TestRunsJobs(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{MaxWorkers: 2})
+	defer pool.Close()
+	var mutex sync.Mutex
+	var seen []int
+	q := jobqueue.New(pool, func(ctx context.Context, n int) error {
+		mutex.Lock()
+		seen = append(seen, n)
+		mutex.Unlock()
+		return nil
+	}, jobqueue.Options{})
+	defer q.Close(context.Background())
+	for i := 0; i < 5; i++ {
+		q.Enqueue(i, 0)
+	}
+	if !waitFor(func() bool { return q.Stats().Completed == 5 }) {
+		t.Errorf("Expected 5 completed jobs got %+v", q.Stats())
+	}
+}
+
+func TestPriority(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{MaxWorkers: 1, QueueSize: 8})
+	defer pool.Close()
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	var order []string
+	q := jobqueue.New(pool, func(ctx context.Context, name string) error {
+		if name == "blocker" {
+			<-release
+		}
+		mutex.Lock()
+		order = append(order, name)
+		mutex.Unlock()
+		return nil
+	}, jobqueue.Options{})
+	defer q.Close(context.Background())
+	q.Enqueue("blocker", 0)
+	waitFor(func() bool { return q.Stats().Running == 1 })
+	q.Enqueue("low", 1)
+	q.Enqueue("high", 10)
+	q.Enqueue("mid", 5)
+	if stats := q.Stats(); stats.Running != 1 || stats.Ready != 3 {
+		t.Errorf("Expected jobs to wait in the queue rather than the pool got %+v", stats)
+	}
+	close(release)
+	waitFor(func() bool { return q.Stats().Completed == 4 })
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !reflect.DeepEqual(order, []string{"blocker", "high", "mid", "low"}) {
+		t.Errorf("Expected jobs in priority order got %v", order)
+	}
+}
+
+func TestRetryThenDeadLetter(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{MaxWorkers: 1})
+	defer pool.Close()
+	failure := errors.New("failure")
+	var mutex sync.Mutex
+	attempts := 0
+	q := jobqueue.New(pool, func(ctx context.Context, n int) error {
+		mutex.Lock()
+		attempts++
+		mutex.Unlock()
+		return failure
+	}, jobqueue.Options{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond})
+	defer q.Close(context.Background())
+	id, _ := q.Enqueue(1, 0)
+	if !waitFor(func() bool { return q.Stats().Dead == 1 }) {
+		t.Fatalf("Expected the job to be dead-lettered got %+v", q.Stats())
+	}
+	dead := q.DeadLetters()
+	if dead[0].ID != id || dead[0].Attempts != 3 || dead[0].LastError != failure {
+		t.Errorf("Expected 3 failed attempts got %+v", dead[0])
+	}
+	if !q.Requeue(id) {
+		t.Fatal("Expected the dead job to be requeued")
+	}
+	if !waitFor(func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return q.Stats().Dead == 1 && attempts == 6
+	}) {
+		t.Error("Expected the requeued job to be retried")
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{MaxWorkers: 1})
+	defer pool.Close()
+	var mutex sync.Mutex
+	attempts := 0
+	q := jobqueue.New(pool, func(ctx context.Context, n int) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		if attempts == 1 {
+			panic("boom")
+		}
+		return nil
+	}, jobqueue.Options{InitialDelay: time.Millisecond})
+	defer q.Close(context.Background())
+	q.Enqueue(1, 0)
+	if !waitFor(func() bool { return q.Stats().Completed == 1 }) {
+		t.Errorf("Expected a retry after a panic to succeed got %+v", q.Stats())
+	}
+}
+
+func TestClose(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{MaxWorkers: 1})
+	defer pool.Close()
+	q := jobqueue.New(pool, func(ctx context.Context, n int) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, jobqueue.Options{})
+	q.Enqueue(1, 0)
+	waitFor(func() bool { return q.Stats().Running == 1 })
+	if err := q.Close(context.Background()); err != nil {
+		t.Errorf("Expected close to cancel running jobs got %v", err)
+	}
+	if _, err := q.Enqueue(2, 0); err != jobqueue.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{MaxWorkers: 1})
+	pool.Close()
+	q := jobqueue.New(pool, func(ctx context.Context, n int) error { return nil }, jobqueue.Options{})
+	defer q.Close(context.Background())
+	q.Enqueue(1, 0)
+	if !waitFor(func() bool {
+		_, err := q.Enqueue(2, 0)
+		return errors.Is(err, workerpool.ErrClosed)
+	}) {
+		t.Errorf("Expected enqueue to report the closed pool got %+v", q.Stats())
+	}
+}
@@ -0,0 +1,90 @@
+// Package keylock is a package that provides a mutex per key.
+//
+// Locks that are held or waited on are tracked until released. Once a key is unlocked with no waiters
+// its lock is kept in a bounded LRU cache for reuse, so idle locks are cleaned up automatically.
+package keylock
+
+import (
+	"sync"
+
+	"github.com/cjsaylor/goutil/lru"
+)
+
+type entry struct {
+	mutex *sync.Mutex
+	refs  int
+}
+
+// KeyLock serializes work per key. It is safe for concurrent use.
+type KeyLock[K comparable] struct {
+	active map[K]*entry
+	idle   *lru.Cache
+	mutex  *sync.Mutex
+}
+
+// New creates a KeyLock that keeps at most idle unheld locks around for reuse.
+func New[K comparable](idle int) *KeyLock[K] {
+	return &KeyLock[K]{
+		active: make(map[K]*entry),
+		idle:   lru.NewCache(idle, lru.Noop()),
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// Lock blocks until the lock for key is acquired.
+func (l *KeyLock[K]) Lock(key K) {
+	l.acquire(key).mutex.Lock()
+}
+
+// TryLock acquires the lock for key if it is free and reports whether it did.
+func (l *KeyLock[K]) TryLock(key K) bool {
+	e := l.acquire(key)
+	if e.mutex.TryLock() {
+		return true
+	}
+	l.release(key)
+	return false
+}
+
+// Unlock releases the lock for key. It panics if key is not locked.
+func (l *KeyLock[K]) Unlock(key K) {
+	l.release(key).mutex.Unlock()
+}
+
+// Len returns the number of keys that are locked or being waited on.
+func (l *KeyLock[K]) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.active)
+}
+
+func (l *KeyLock[K]) acquire(key K) *entry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	e, ok := l.active[key]
+	if !ok {
+		if val, ok := l.idle.Remove(key); ok {
+			e = val.(*entry)
+		} else {
+			e = &entry{mutex: &sync.Mutex{}}
+		}
+		l.active[key] = e
+	}
+	e.refs++
+	return e
+}
+
+func (l *KeyLock[K]) release(key K) *entry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	e, ok := l.active[key]
+	if !ok {
+		panic("keylock: unlock of unlocked key")
+	}
+	e.refs--
+	if e.refs == 0 {
+		delete(l.active, key)
+		l.idle.Set(key, e)
+	}
+	return e
+}
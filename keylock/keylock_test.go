@@ -0,0 +1,76 @@
+package keylock_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/keylock"
+)
+
+func TestSerializesPerKey(t *testing.T) {
+	locks := keylock.New[string](10)
+	counts := map[string]int{}
+	wg := &sync.WaitGroup{}
+	guard := &sync.Mutex{}
+	for i := 0; i < 100; i++ {
+		key := []string{"a", "b"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			locks.Lock(key)
+			defer locks.Unlock(key)
+			guard.Lock()
+			n := counts[key]
+			guard.Unlock()
+			guard.Lock()
+			counts[key] = n + 1
+			guard.Unlock()
+		}()
+	}
+	wg.Wait()
+	if counts["a"] != 50 || counts["b"] != 50 {
+		t.Errorf("Expected 50 increments per key got %v", counts)
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	locks := keylock.New[int](10)
+	locks.Lock(1)
+	if locks.TryLock(1) {
+		t.Error("Expected a held key to fail TryLock")
+	}
+	if !locks.TryLock(2) {
+		t.Error("Expected a free key to succeed TryLock")
+	}
+	locks.Unlock(1)
+	locks.Unlock(2)
+	if locks.Len() != 0 {
+		t.Errorf("Expected no active locks got %d", locks.Len())
+	}
+}
+
+func TestIndependentKeys(t *testing.T) {
+	locks := keylock.New[string](1)
+	locks.Lock("a")
+	done := make(chan struct{})
+	go func() {
+		locks.Lock("b")
+		locks.Unlock("b")
+		close(done)
+	}()
+	<-done
+	if locks.Len() != 1 {
+		t.Errorf("Expected 1 active lock got %d", locks.Len())
+	}
+	locks.Unlock("a")
+}
+
+func TestUnlockUnlockedPanics(t *testing.T) {
+	locks := keylock.New[string](1)
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+	locks.Unlock("a")
+}
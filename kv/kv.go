@@ -0,0 +1,637 @@
+// Package kv is a package that implements an embedded persistent key-value store in the style of Bitcask.
+//
+// Writes are appended to a data file and an in-memory key directory maps every key to the location of its latest
+// value, so a read is one lookup and one disk read. Deletes append a tombstone. Once a data file reaches its size
+// limit a new one is started. Compact rewrites the live values into fresh files, dropping overwritten and deleted
+// ones, and writes a hint file next to each so that opening the store reads the small hints rather than the data.
+//
+// Values are kept on disk rather than on the heap, which makes a store a fit for the disk tier behind an LRU cache.
+package kv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	recordHeaderSize = 12
+	hintHeaderSize   = 16
+	tombstone        = math.MaxUint32
+	dataSuffix       = ".data"
+	hintSuffix       = ".hint"
+)
+
+var table = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// ErrNotFound is returned when a key is not in the store.
+	ErrNotFound = errors.New("kv: key not found")
+	// ErrClosed is returned when using a closed store.
+	ErrClosed = errors.New("kv: store closed")
+	// ErrCorrupt is returned when a data or hint file is damaged anywhere but the tail of the newest data file.
+	ErrCorrupt = errors.New("kv: corrupt data file")
+)
+
+// Options configures a store. Zero fields take the defaults noted on each.
+type Options struct {
+	// MaxFileSize starts a new data file once the current one reaches this size. Defaults to 64MB.
+	MaxFileSize int64
+	// SyncEveryWrite syncs the data file after every Put and Delete. Otherwise call Sync.
+	SyncEveryWrite bool
+}
+
+// Stats describe the space used by a store.
+type Stats struct {
+	// Keys is the number of keys.
+	Keys int
+	// Files is the number of data files.
+	Files int
+	// LiveBytes is the size of the records holding current values.
+	LiveBytes int64
+	// DeadBytes is the size of overwritten values and tombstones that Compact would reclaim.
+	DeadBytes int64
+}
+
+type entry struct {
+	file   uint32
+	offset int64
+	size   uint32
+	key    int
+}
+
+// Store is a persistent key-value store in a directory. It is safe for concurrent use.
+type Store struct {
+	dir     string
+	options Options
+	keydir  map[string]entry
+	files   map[uint32]*os.File
+	active  *os.File
+	id      uint32
+	size    int64
+	live    int64
+	dead    int64
+	err     error
+	closed  bool
+	mutex   *sync.RWMutex
+}
+
+// Open opens the store in dir, creating the directory if needed. A torn record at the end of the newest
+// data file is truncated.
+func Open(dir string, options Options) (*Store, error) {
+	if options.MaxFileSize <= 0 {
+		options.MaxFileSize = 64 << 20
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &Store{
+		dir:     dir,
+		options: options,
+		keydir:  make(map[string]entry),
+		files:   make(map[uint32]*os.File),
+		mutex:   &sync.RWMutex{},
+	}
+	if err := s.load(); err != nil {
+		s.closeFiles()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the value of key. It returns ErrCorrupt if the record fails its checksum.
+func (s *Store) Get(key string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	e, ok := s.keydir[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.read(e)
+}
+
+// Has reports whether key is in the store without reading its value.
+func (s *Store) Has(key string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	_, ok := s.keydir[key]
+	return ok
+}
+
+// Put stores value for key.
+func (s *Store) Put(key string, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	return s.write(key, value, false)
+}
+
+// Delete removes key. Deleting a missing key does nothing.
+func (s *Store) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if _, ok := s.keydir[key]; !ok {
+		return nil
+	}
+	return s.write(key, nil, true)
+}
+
+// Keys returns the keys in sorted order.
+func (s *Store) Keys() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	keys := make([]string, 0, len(s.keydir))
+	for key := range s.keydir {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len returns the number of keys.
+func (s *Store) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.keydir)
+}
+
+// Stats returns the space used by the store.
+func (s *Store) Stats() Stats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return Stats{Keys: len(s.keydir), Files: len(s.files), LiveBytes: s.live, DeadBytes: s.dead}
+}
+
+// Sync flushes the current data file to stable storage.
+func (s *Store) Sync() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	return s.active.Sync()
+}
+
+// Compact rewrites the live values into new data files with hint files and deletes the old files.
+// Reads and writes wait until it finishes.
+func (s *Store) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	keys := make([]string, 0, len(s.keydir))
+	for key := range s.keydir {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	keydir := make(map[string]entry, len(s.keydir))
+	files := make(map[uint32]*os.File)
+	abort := func(out *output, err error) error {
+		if out != nil {
+			out.discard()
+		}
+		for id, file := range files {
+			file.Close()
+			os.Remove(s.path(id, dataSuffix))
+			os.Remove(s.path(id, hintSuffix))
+		}
+		return err
+	}
+	out, err := s.newOutput(s.id + 1)
+	if err != nil {
+		return abort(nil, err)
+	}
+	var live int64
+	for _, key := range keys {
+		e := s.keydir[key]
+		value, err := s.read(e)
+		if err != nil {
+			return abort(out, err)
+		}
+		if out.size > 0 && out.size >= s.options.MaxFileSize {
+			reader, err := out.seal()
+			if err != nil {
+				return abort(out, err)
+			}
+			files[out.id] = reader
+			if out, err = s.newOutput(out.id + 1); err != nil {
+				return abort(nil, err)
+			}
+		}
+		record := encode(key, value, false)
+		out.data.Write(record)
+		out.hint.Write(encodeHint(key, uint32(len(value)), out.size))
+		keydir[key] = entry{file: out.id, offset: out.size + recordHeaderSize + int64(len(key)), size: e.size, key: e.key}
+		out.size += int64(len(record))
+		live += int64(len(record))
+	}
+	reader, err := out.seal()
+	if err != nil {
+		return abort(out, err)
+	}
+	files[out.id] = reader
+	syncDir(s.dir)
+	// The new active file is opened before anything is switched over, so a failure leaves the store
+	// writing to its current file with the compacted files removed again.
+	id := out.id + 1
+	active, reader, size, err := s.openActive(id)
+	if err != nil {
+		return abort(nil, err)
+	}
+	s.active.Sync()
+	s.active.Close()
+	old := s.files
+	s.active, s.id, s.size = active, id, size
+	files[id] = reader
+	s.files, s.keydir = files, keydir
+	s.live, s.dead = live, 0
+	// Old files are deleted oldest first. A crash part way through then leaves a suffix of the old files,
+	// which never holds a value without the tombstones or overwrites that followed it.
+	ids := make([]uint32, 0, len(old))
+	for id := range old {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		old[id].Close()
+		os.Remove(s.path(id, dataSuffix))
+		os.Remove(s.path(id, hintSuffix))
+	}
+	syncDir(s.dir)
+	return nil
+}
+
+// output is a data file and its hint file being written by Compact. Both are written under temporary names
+// and renamed into place once complete, so an interrupted compaction leaves nothing behind.
+type output struct {
+	id         uint32
+	dataFile   *os.File
+	hintFile   *os.File
+	data, hint *bufio.Writer
+	size       int64
+	store      *Store
+}
+
+func (s *Store) newOutput(id uint32) (*output, error) {
+	dataFile, err := os.OpenFile(s.path(id, dataSuffix)+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	hintFile, err := os.OpenFile(s.path(id, hintSuffix)+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		dataFile.Close()
+		os.Remove(dataFile.Name())
+		return nil, err
+	}
+	return &output{
+		id:       id,
+		dataFile: dataFile,
+		hintFile: hintFile,
+		data:     bufio.NewWriter(dataFile),
+		hint:     bufio.NewWriter(hintFile),
+		store:    s,
+	}, nil
+}
+
+// seal flushes, syncs and renames both files, and returns the data file opened for reading. The data file is
+// renamed first, since a data file without a hint is still loaded correctly.
+func (o *output) seal() (*os.File, error) {
+	for _, pair := range []struct {
+		file   *os.File
+		w      *bufio.Writer
+		suffix string
+	}{{o.dataFile, o.data, dataSuffix}, {o.hintFile, o.hint, hintSuffix}} {
+		err := pair.w.Flush()
+		if err == nil {
+			err = pair.file.Sync()
+		}
+		if closeErr := pair.file.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(pair.file.Name(), o.store.path(o.id, pair.suffix))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return os.Open(o.store.path(o.id, dataSuffix))
+}
+
+func (o *output) discard() {
+	o.dataFile.Close()
+	o.hintFile.Close()
+	os.Remove(o.dataFile.Name())
+	os.Remove(o.hintFile.Name())
+	os.Remove(o.store.path(o.id, dataSuffix))
+	os.Remove(o.store.path(o.id, hintSuffix))
+}
+
+// Close syncs and closes the store.
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	err := s.active.Sync()
+	if closeErr := s.closeFiles(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (s *Store) closeFiles() error {
+	var err error
+	for _, file := range s.files {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if s.active != nil {
+		if closeErr := s.active.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// read returns the value e points at after checking its record's checksum. It must be called with the lock held.
+func (s *Store) read(e entry) ([]byte, error) {
+	record := make([]byte, recordHeaderSize+int64(e.key)+int64(e.size))
+	if _, err := s.files[e.file].ReadAt(record, e.offset-recordHeaderSize-int64(e.key)); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(record[4:], table) != binary.BigEndian.Uint32(record) {
+		return nil, ErrCorrupt
+	}
+	return record[recordHeaderSize+e.key:], nil
+}
+
+// write appends a record for key and updates the key directory. A failed write is cut off again so the
+// next record lands where the key directory expects it; if that fails too, every later write returns the
+// error. A record that was written but failed to sync is still applied. It must be called with the lock
+// held.
+func (s *Store) write(key string, value []byte, deleted bool) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.size >= s.options.MaxFileSize {
+		if err := s.rotate(s.id + 1); err != nil {
+			return err
+		}
+	}
+	record := encode(key, value, deleted)
+	if _, err := s.active.Write(record); err != nil {
+		if truncateErr := s.active.Truncate(s.size); truncateErr != nil {
+			s.err = err
+		}
+		return err
+	}
+	offset := s.size
+	s.size += int64(len(record))
+	s.retire(key)
+	if deleted {
+		delete(s.keydir, key)
+		s.dead += int64(len(record))
+	} else {
+		s.keydir[key] = entry{file: s.id, offset: offset + recordHeaderSize + int64(len(key)), size: uint32(len(value)), key: len(key)}
+		s.live += int64(len(record))
+	}
+	if s.options.SyncEveryWrite {
+		return s.active.Sync()
+	}
+	return nil
+}
+
+// retire moves the space of key's current record from live to dead.
+func (s *Store) retire(key string) {
+	if e, ok := s.keydir[key]; ok {
+		size := recordHeaderSize + int64(e.key) + int64(e.size)
+		s.live -= size
+		s.dead += size
+	}
+}
+
+// rotate starts a new data file with the given id. It must be called with the lock held.
+func (s *Store) rotate(id uint32) error {
+	active, reader, size, err := s.openActive(id)
+	if err != nil {
+		return err
+	}
+	if s.active != nil {
+		s.active.Sync()
+		s.active.Close()
+	}
+	s.active, s.id, s.size = active, id, size
+	s.files[id] = reader
+	return nil
+}
+
+// openActive opens data file id for appending and for reading, and returns its current size.
+func (s *Store) openActive(id uint32) (active, reader *os.File, size int64, err error) {
+	path := s.path(id, dataSuffix)
+	if active, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600); err != nil {
+		return nil, nil, 0, err
+	}
+	info, err := active.Stat()
+	if err == nil {
+		reader, err = os.Open(path)
+	}
+	if err != nil {
+		active.Close()
+		return nil, nil, 0, err
+	}
+	return active, reader, info.Size(), nil
+}
+
+func (s *Store) path(id uint32, suffix string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%09d%s", id, suffix))
+}
+
+// load rebuilds the key directory from the hint or data files, oldest first, and opens the newest file
+// for appending.
+func (s *Store) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	var ids []uint32
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			// Left over from an interrupted compaction.
+			os.Remove(filepath.Join(s.dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, dataSuffix) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, dataSuffix), 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint32(id))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var total int64
+	for i, id := range ids {
+		file, err := os.Open(s.path(id, dataSuffix))
+		if err != nil {
+			return err
+		}
+		s.files[id] = file
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		if err := s.loadHint(id); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		valid, err := s.loadData(id, file, info.Size())
+		if err != nil {
+			return err
+		}
+		if valid < info.Size() {
+			if i != len(ids)-1 {
+				return ErrCorrupt
+			}
+			if err := os.Truncate(s.path(id, dataSuffix), valid); err != nil {
+				return err
+			}
+			total -= info.Size() - valid
+		}
+	}
+	s.dead = total - s.live
+	if len(ids) == 0 {
+		return s.rotate(0)
+	}
+	last := ids[len(ids)-1]
+	if _, err := os.Stat(s.path(last, hintSuffix)); err == nil {
+		// A compacted file's hint would not cover records appended to it.
+		return s.rotate(last + 1)
+	}
+	s.files[last].Close()
+	delete(s.files, last)
+	return s.rotate(last)
+}
+
+// loadHint applies the hint file of id to the key directory.
+func (s *Store) loadHint(id uint32) error {
+	data, err := os.ReadFile(s.path(id, hintSuffix))
+	if err != nil {
+		return err
+	}
+	for offset := 0; offset < len(data); {
+		if offset+hintHeaderSize > len(data) {
+			return ErrCorrupt
+		}
+		keyLen := int(binary.BigEndian.Uint32(data[offset:]))
+		valueLen := binary.BigEndian.Uint32(data[offset+4:])
+		position := int64(binary.BigEndian.Uint64(data[offset+8:]))
+		offset += hintHeaderSize
+		if keyLen > len(data)-offset {
+			return ErrCorrupt
+		}
+		key := string(data[offset : offset+keyLen])
+		offset += keyLen
+		s.retire(key)
+		s.keydir[key] = entry{file: id, offset: position + recordHeaderSize + int64(keyLen), size: valueLen, key: keyLen}
+		s.live += recordHeaderSize + int64(keyLen) + int64(valueLen)
+	}
+	return nil
+}
+
+// loadData applies the records of data file id to the key directory and returns the length of its valid prefix.
+func (s *Store) loadData(id uint32, file *os.File, size int64) (int64, error) {
+	r := bufio.NewReader(io.NewSectionReader(file, 0, size))
+	var valid int64
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return valid, nil
+		}
+		keyLen := int64(binary.BigEndian.Uint32(header[4:]))
+		valueLen := binary.BigEndian.Uint32(header[8:])
+		deleted := valueLen == tombstone
+		length := keyLen
+		if !deleted {
+			length += int64(valueLen)
+		}
+		if valid+recordHeaderSize+length > size {
+			return valid, nil
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return valid, nil
+		}
+		if crc32.Update(crc32.Checksum(header[4:], table), table, body) != binary.BigEndian.Uint32(header) {
+			return valid, nil
+		}
+		key := string(body[:keyLen])
+		s.retire(key)
+		if deleted {
+			delete(s.keydir, key)
+		} else {
+			s.keydir[key] = entry{file: id, offset: valid + recordHeaderSize + keyLen, size: valueLen, key: int(keyLen)}
+			s.live += recordHeaderSize + length
+		}
+		valid += recordHeaderSize + length
+	}
+}
+
+// encode frames a record as a checksum, the key and value lengths, the key and the value. A tombstone has no
+// value and the maximum value length.
+func encode(key string, value []byte, deleted bool) []byte {
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(key)+len(value))
+	binary.BigEndian.PutUint32(record[4:], uint32(len(key)))
+	valueLen := uint32(len(value))
+	if deleted {
+		valueLen = tombstone
+	}
+	binary.BigEndian.PutUint32(record[8:], valueLen)
+	record = append(record, key...)
+	record = append(record, value...)
+	binary.BigEndian.PutUint32(record, crc32.Checksum(record[4:], table))
+	return record
+}
+
+// encodeHint records where a compacted record starts.
+func encodeHint(key string, valueLen uint32, position int64) []byte {
+	hint := make([]byte, hintHeaderSize, hintHeaderSize+len(key))
+	binary.BigEndian.PutUint32(hint, uint32(len(key)))
+	binary.BigEndian.PutUint32(hint[4:], valueLen)
+	binary.BigEndian.PutUint64(hint[8:], uint64(position))
+	return append(hint, key...)
+}
+
+// syncDir makes renames and removals in dir durable. Errors are ignored since not every platform supports it.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
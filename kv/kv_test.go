@@ -0,0 +1,166 @@
+package kv_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cjsaylor/goutil/kv"
+)
+
+func open(t *testing.T, dir string, options kv.Options) *kv.Store {
+	t.Helper()
+	s, err := kv.Open(dir, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func expect(t *testing.T, s *kv.Store, key, value string) {
+	t.Helper()
+	if got, err := s.Get(key); err != nil || string(got) != value {
+		t.Errorf("Expected %q for %s got %q (%v)", value, key, got, err)
+	}
+}
+
+func TestPutGetDelete(t *testing.T) {
+	s := open(t, t.TempDir(), kv.Options{})
+	defer s.Close()
+	s.Put("a", []byte("1"))
+	s.Put("b", []byte("2"))
+	s.Put("a", []byte("3"))
+	expect(t, s, "a", "3")
+	if err := s.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("b"); err != kv.ErrNotFound {
+		t.Errorf("Expected a deleted key to be missing got %v", err)
+	}
+	if !s.Has("a") || s.Has("b") || s.Len() != 1 {
+		t.Errorf("Expected only a to remain got %v", s.Keys())
+	}
+	if stats := s.Stats(); stats.DeadBytes == 0 || stats.LiveBytes == 0 {
+		t.Errorf("Expected overwritten and deleted records to count as dead got %+v", stats)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir, kv.Options{MaxFileSize: 64})
+	for i := 0; i < 10; i++ {
+		s.Put(fmt.Sprintf("key%d", i), []byte(strings.Repeat("v", i)))
+	}
+	s.Delete("key3")
+	s.Close()
+	if _, err := s.Get("key1"); err != kv.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+
+	s = open(t, dir, kv.Options{MaxFileSize: 64})
+	defer s.Close()
+	if s.Stats().Files < 2 {
+		t.Errorf("Expected the data to span files got %+v", s.Stats())
+	}
+	if s.Has("key3") || s.Len() != 9 {
+		t.Errorf("Expected the tombstone to survive reopening got %v", s.Keys())
+	}
+	expect(t, s, "key9", "vvvvvvvvv")
+	s.Put("key10", []byte("after"))
+	expect(t, s, "key10", "after")
+}
+
+func TestCompact(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir, kv.Options{MaxFileSize: 64})
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 5; i++ {
+			s.Put(fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("round%d", round)))
+		}
+	}
+	s.Delete("key0")
+	if err := s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := s.Stats(); stats.DeadBytes != 0 || stats.Keys != 4 {
+		t.Errorf("Expected compaction to reclaim dead records got %+v", stats)
+	}
+	expect(t, s, "key1", "round2")
+	s.Put("key5", []byte("new"))
+	s.Close()
+
+	hints, _ := filepath.Glob(filepath.Join(dir, "*.hint"))
+	if len(hints) == 0 {
+		t.Error("Expected compaction to write hint files")
+	}
+	s = open(t, dir, kv.Options{MaxFileSize: 64})
+	defer s.Close()
+	if keys := s.Keys(); !reflect.DeepEqual(keys, []string{"key1", "key2", "key3", "key4", "key5"}) {
+		t.Errorf("Expected the compacted keys after reopening got %v", keys)
+	}
+	expect(t, s, "key4", "round2")
+	expect(t, s, "key5", "new")
+}
+
+func TestInterruptedCompact(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir, kv.Options{MaxFileSize: 32})
+	s.Put("k", []byte("v"))
+	s.Put("other", []byte("value"))
+	s.Delete("k")
+	old, _ := filepath.Glob(filepath.Join(dir, "*.data"))
+	saved := make(map[string][]byte)
+	for _, path := range old {
+		saved[path], _ = os.ReadFile(path)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	// Old files are deleted oldest first, so a crash can only leave the newest ones behind.
+	newest := old[len(old)-1]
+	os.WriteFile(newest, saved[newest], 0600)
+
+	s = open(t, dir, kv.Options{MaxFileSize: 32})
+	defer s.Close()
+	if s.Has("k") {
+		t.Error("Expected the deleted key to stay deleted")
+	}
+	expect(t, s, "other", "value")
+}
+
+func TestTornTail(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir, kv.Options{})
+	s.Put("whole", []byte("value"))
+	s.Put("torn", []byte("value"))
+	s.Close()
+	path := filepath.Join(dir, "000000000.data")
+	info, _ := os.Stat(path)
+	os.Truncate(path, info.Size()-2)
+
+	s = open(t, dir, kv.Options{})
+	defer s.Close()
+	if keys := s.Keys(); !reflect.DeepEqual(keys, []string{"whole"}) {
+		t.Errorf("Expected the torn record to be dropped got %v", keys)
+	}
+	s.Put("next", []byte("value"))
+	expect(t, s, "next", "value")
+}
+
+func TestGetChecksum(t *testing.T) {
+	dir := t.TempDir()
+	s := open(t, dir, kv.Options{})
+	defer s.Close()
+	s.Put("a", []byte("value"))
+	path := filepath.Join(dir, "000000000.data")
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path, data, 0600)
+	if _, err := s.Get("a"); err != kv.ErrCorrupt {
+		t.Errorf("Expected a damaged value to fail its checksum got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package lazy
+
+import (
+	"sync"
+	"time"
+)
+
+// Lazy is a value computed on first access and kept until it is invalidated or, optionally, expires.
+// Failed computations are not cached. It is safe for concurrent use; concurrent accesses wait for a
+// single computation.
+type Lazy[T any] struct {
+	fn      func() (T, error)
+	ttl     time.Duration
+	value   T
+	valid   bool
+	expires time.Time
+	mutex   *sync.Mutex
+}
+
+// New creates a Lazy computed by fn. A positive ttl recomputes the value on the first access after it
+// has been held for ttl; zero keeps it until Invalidate.
+func New[T any](fn func() (T, error), ttl time.Duration) *Lazy[T] {
+	return &Lazy[T]{
+		fn:    fn,
+		ttl:   ttl,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Get returns the value, computing it if it is not held or has expired.
+func (l *Lazy[T]) Get() (T, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.valid && (l.ttl <= 0 || time.Now().Before(l.expires)) {
+		return l.value, nil
+	}
+	value, err := l.fn()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	l.value, l.valid = value, true
+	if l.ttl > 0 {
+		l.expires = time.Now().Add(l.ttl)
+	}
+	return value, nil
+}
+
+// Invalidate discards the value so the next Get computes it again.
+func (l *Lazy[T]) Invalidate() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var zero T
+	l.value, l.valid = zero, false
+}
@@ -0,0 +1,73 @@
+package lazy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/lazy"
+)
+
+func TestLazyComputesOnce(t *testing.T) {
+	calls := 0
+	l := lazy.New(func() (int, error) {
+		calls++
+		return 42, nil
+	}, 0)
+	if calls != 0 {
+		t.Error("Expected no computation before access")
+	}
+	for i := 0; i < 3; i++ {
+		if val, err := l.Get(); val != 42 || err != nil {
+			t.Errorf("Expected 42 got %v (%v)", val, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 computation got %d", calls)
+	}
+}
+
+func TestLazyErrorsNotCached(t *testing.T) {
+	calls := 0
+	l := lazy.New(func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errFailed
+		}
+		return 1, nil
+	}, 0)
+	if _, err := l.Get(); err != errFailed {
+		t.Errorf("Expected failure got %v", err)
+	}
+	if val, err := l.Get(); val != 1 || err != nil {
+		t.Errorf("Expected a retry to succeed got %v (%v)", val, err)
+	}
+}
+
+func TestLazyInvalidate(t *testing.T) {
+	calls := 0
+	l := lazy.New(func() (int, error) {
+		calls++
+		return calls, nil
+	}, 0)
+	l.Get()
+	l.Invalidate()
+	if val, _ := l.Get(); val != 2 {
+		t.Errorf("Expected recomputation after invalidate got %d", val)
+	}
+}
+
+func TestLazyTTL(t *testing.T) {
+	calls := 0
+	l := lazy.New(func() (int, error) {
+		calls++
+		return calls, nil
+	}, 5*time.Millisecond)
+	l.Get()
+	if val, _ := l.Get(); val != 1 {
+		t.Errorf("Expected the held value within the TTL got %d", val)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if val, _ := l.Get(); val != 2 {
+		t.Errorf("Expected recomputation after the TTL got %d", val)
+	}
+}
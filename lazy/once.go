@@ -0,0 +1,73 @@
+// Package lazy is a package that defers computing values until they are first needed.
+package lazy
+
+import (
+	"sync"
+)
+
+// Once runs an initialization function until it succeeds, then returns its value from then on.
+// Unlike sync.Once a failed initialization is not remembered: the next Get tries again.
+// It is safe for concurrent use; concurrent calls wait for a single attempt rather than each running one.
+type Once[T any] struct {
+	fn    func() (T, error)
+	value T
+	done  bool
+	mutex *sync.Mutex
+}
+
+// NewOnce creates a Once for fn.
+func NewOnce[T any](fn func() (T, error)) *Once[T] {
+	return &Once[T]{
+		fn:    fn,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Get returns the value, running the initialization function if it has not yet succeeded.
+func (o *Once[T]) Get() (T, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.done {
+		return o.value, nil
+	}
+	value, err := o.fn()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	o.value, o.done = value, true
+	return value, nil
+}
+
+// Done reports whether initialization has succeeded.
+func (o *Once[T]) Done() bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.done
+}
+
+// Reset forgets the value so that the next Get initializes again.
+func (o *Once[T]) Reset() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	var zero T
+	o.value, o.done = zero, false
+}
+
+// OnceValue returns a function that calls fn until it succeeds and returns the successful result on
+// every later call.
+func OnceValue[T any](fn func() (T, error)) func() (T, error) {
+	return NewOnce(fn).Get
+}
+
+// OnceFunc returns a function that calls fn until it returns nil, and returns nil without calling fn
+// from then on.
+func OnceFunc(fn func() error) func() error {
+	once := NewOnce(func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return func() error {
+		_, err := once.Get()
+		return err
+	}
+}
@@ -0,0 +1,92 @@
+package lazy_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cjsaylor/goutil/lazy"
+)
+
+var errFailed = errors.New("failed")
+
+func TestOnceValueRetriesFailures(t *testing.T) {
+	calls := 0
+	get := lazy.OnceValue(func() (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errFailed
+		}
+		return calls, nil
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := get(); err != errFailed {
+			t.Errorf("Expected failure %d got %v", i, err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if val, err := get(); val != 3 || err != nil {
+			t.Errorf("Expected the cached value 3 got %v (%v)", val, err)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls got %d", calls)
+	}
+}
+
+func TestOnceFunc(t *testing.T) {
+	calls := 0
+	init := lazy.OnceFunc(func() error {
+		calls++
+		if calls == 1 {
+			return errFailed
+		}
+		return nil
+	})
+	init()
+	init()
+	init()
+	if calls != 2 {
+		t.Errorf("Expected 2 calls got %d", calls)
+	}
+}
+
+func TestOnceConcurrent(t *testing.T) {
+	var calls atomic.Int32
+	once := lazy.NewOnce(func() (int, error) {
+		calls.Add(1)
+		return 1, nil
+	})
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			once.Get()
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single call got %d", calls.Load())
+	}
+}
+
+func TestOnceReset(t *testing.T) {
+	calls := 0
+	once := lazy.NewOnce(func() (int, error) {
+		calls++
+		return calls, nil
+	})
+	once.Get()
+	if !once.Done() {
+		t.Error("Expected Done after success")
+	}
+	once.Reset()
+	if once.Done() {
+		t.Error("Expected not Done after reset")
+	}
+	if val, _ := once.Get(); val != 2 {
+		t.Errorf("Expected reinitialization got %d", val)
+	}
+}
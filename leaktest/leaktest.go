@@ -0,0 +1,121 @@
+// Package leaktest is a package that fails tests which leave goroutines running.
+//
+// Call it at the start of a test and defer the result:
+//
+//	defer leaktest.Check(t)()
+//
+// Goroutines that exist when Check is called are never reported, so tests that run in parallel
+// with t.Parallel can still report each other's goroutines; use Options.Ignore for those.
+package leaktest
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cjsaylor/goutil/wait"
+)
+
+// TB is the part of testing.TB used to report leaks.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Options configures a leak check.
+type Options struct {
+	// Timeout is how long goroutines are given to exit before they are reported. Defaults to
+	// five seconds.
+	Timeout time.Duration
+	// Ignore lists substrings of stack traces, such as function names, whose goroutines are never
+	// reported. They are added to the runtime and testing goroutines ignored by default.
+	Ignore []string
+}
+
+var defaultIgnore = []string{
+	"testing.tRunner(",
+	"testing.(*M).",
+	"testing.runFuzzing(",
+	"runtime.goexit0(",
+	"os/signal.signal_recv(",
+	"os/signal.loop(",
+	"runtime.ensureSigM(",
+	"runtime/trace.Start",
+}
+
+// Check records the running goroutines and returns a function that fails t if any goroutines
+// started since are still running after the default timeout.
+func Check(t TB) func() {
+	return CheckWith(t, Options{})
+}
+
+// CheckWith is like Check with options.
+func CheckWith(t TB, options Options) func() {
+	if options.Timeout <= 0 {
+		options.Timeout = 5 * time.Second
+	}
+	ignore := append(append([]string(nil), defaultIgnore...), options.Ignore...)
+	before := map[string]bool{}
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+	return func() {
+		t.Helper()
+		var leaked []goroutine
+		ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
+		defer cancel()
+		wait.Backoff(ctx, wait.Options{Initial: time.Millisecond, Max: 100 * time.Millisecond}, wait.Bool(func() bool {
+			leaked = leaked[:0]
+			for _, g := range goroutines() {
+				if !before[g.id] && !g.matches(ignore) {
+					leaked = append(leaked, g)
+				}
+			}
+			return len(leaked) == 0
+		}))
+		for _, g := range leaked {
+			t.Errorf("leaktest: goroutine still running:\n%s", g.stack)
+		}
+	}
+}
+
+type goroutine struct {
+	id    string
+	stack string
+}
+
+func (g goroutine) matches(ignore []string) bool {
+	for _, s := range ignore {
+		if strings.Contains(g.stack, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutines returns every goroutine except the calling one.
+func goroutines() []goroutine {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	blocks := strings.Split(string(buf), "\n\n")
+	// The first trace is always the calling goroutine.
+	var result []goroutine
+	for _, block := range blocks[1:] {
+		header, _, _ := strings.Cut(block, "\n")
+		// The header reads "goroutine 12 [chan receive]:".
+		fields := strings.Fields(header)
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		result = append(result, goroutine{id: fields[1], stack: block})
+	}
+	return result
+}
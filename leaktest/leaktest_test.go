@@ -0,0 +1,73 @@
+package leaktest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/leaktest"
+)
+
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func blockForever(stop chan struct{}) {
+	<-stop
+}
+
+func TestNoLeak(t *testing.T) {
+	r := &recorder{}
+	check := leaktest.Check(r)
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+	}()
+	check()
+	if len(r.errors) != 0 {
+		t.Errorf("Expected a goroutine that exits not to be reported got %v", r.errors)
+	}
+}
+
+func TestLeak(t *testing.T) {
+	r := &recorder{}
+	check := leaktest.CheckWith(r, leaktest.Options{Timeout: 20 * time.Millisecond})
+	stop := make(chan struct{})
+	defer close(stop)
+	go blockForever(stop)
+	check()
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "blockForever") {
+		t.Errorf("Expected the blocked goroutine to be reported got %v", r.errors)
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	r := &recorder{}
+	check := leaktest.CheckWith(r, leaktest.Options{Timeout: 20 * time.Millisecond, Ignore: []string{"blockForever"}})
+	stop := make(chan struct{})
+	defer close(stop)
+	go blockForever(stop)
+	check()
+	if len(r.errors) != 0 {
+		t.Errorf("Expected an ignored goroutine not to be reported got %v", r.errors)
+	}
+}
+
+func TestExisting(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	go blockForever(stop)
+	r := &recorder{}
+	leaktest.CheckWith(r, leaktest.Options{Timeout: 20 * time.Millisecond})()
+	if len(r.errors) != 0 {
+		t.Errorf("Expected goroutines from before the check not to be reported got %v", r.errors)
+	}
+}
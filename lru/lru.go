@@ -3,6 +3,7 @@ package lru
 
 import (
 	"container/list"
+	"iter"
 	"sync"
 )
 
@@ -71,7 +72,7 @@ func (c *Cache) Get(key interface{}) (interface{}, bool) {
 	defer c.mutex.Unlock()
 	if item, ok := c.lookup[key]; ok {
 		c.queue.MoveToFront(item)
-		return item.Value, true
+		return item.Value.(*entry).value, true
 	}
 	return nil, false
 }
@@ -117,3 +118,29 @@ func (c *Cache) ListKeys() []interface{} {
 	}
 	return ret
 }
+
+// All returns a sequence of the entries with the most recent first. Ranging over it does not mark
+// entries as used. It iterates over a snapshot taken when iteration starts, so the cache may be
+// modified while ranging.
+func (c *Cache) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(key, value interface{}) bool) {
+		c.mutex.Lock()
+		entries := make([]entry, 0, c.queue.Len())
+		for item := c.queue.Front(); item != nil; item = item.Next() {
+			entries = append(entries, *item.Value.(*entry))
+		}
+		c.mutex.Unlock()
+		for _, e := range entries {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Insert sets every entry from seq in order, so the last one produced ends up the most recent.
+func (c *Cache) Insert(seq iter.Seq2[interface{}, interface{}]) {
+	for key, value := range seq {
+		c.Set(key, value)
+	}
+}
@@ -37,7 +37,7 @@ func TestGet(t *testing.T) {
 	})
 	cache.Set("a", "foo")
 	cache.Set("b", "foo")
-	if _, ok := cache.Get("a"); !ok {
+	if val, ok := cache.Get("a"); !ok || val.(string) != "foo" {
 		t.Error("Expected 'a' to still exist in the cache")
 	}
 	cache.Set("c", "foo")
@@ -87,3 +87,26 @@ func TestListKeys(t *testing.T) {
 		t.Errorf("Expected %v got %v", expected, cache.ListKeys())
 	}
 }
+
+func TestAllAndInsert(t *testing.T) {
+	cache := lru.NewCache(3, lru.Noop())
+	cache.Insert(func(yield func(key, value interface{}) bool) {
+		for _, k := range []string{"a", "b", "c"} {
+			if !yield(k, k+k) {
+				return
+			}
+		}
+	})
+	var keys []interface{}
+	for key, value := range cache.All() {
+		if value != key.(string)+key.(string) {
+			t.Errorf("Expected %s%s got %v", key, key, value)
+		}
+		keys = append(keys, key)
+		cache.Set("d", "dd")
+	}
+	expected := []interface{}{"c", "b", "a"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected %v got %v", expected, keys)
+	}
+}
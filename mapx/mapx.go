@@ -0,0 +1,102 @@
+// Package mapx is a package of generic helpers for maps that the standard maps package does not
+// provide.
+//
+// Functions never modify their input and always return new maps or slices. Keys and values come
+// back in no particular order; sort them if order matters.
+package mapx
+
+// Keys returns the keys of m.
+func Keys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns the values of m.
+func Values[K comparable, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Merge combines maps from left to right. When a key is in more than one map, resolve is called
+// with the value so far and the new value to decide the result; a nil resolve keeps the last value.
+func Merge[K comparable, V any](resolve func(key K, existing, incoming V) V, maps ...map[K]V) map[K]V {
+	size := 0
+	for _, m := range maps {
+		size += len(m)
+	}
+	merged := make(map[K]V, size)
+	for _, m := range maps {
+		for k, v := range m {
+			if existing, ok := merged[k]; ok && resolve != nil {
+				v = resolve(k, existing, v)
+			}
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// Invert returns a map from m's values to its keys. If several keys share a value, which of them
+// is kept is unspecified.
+func Invert[K, V comparable](m map[K]V) map[V]K {
+	inverted := make(map[V]K, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+	return inverted
+}
+
+// Filter returns the entries of m for which keep returns true.
+func Filter[K comparable, V any](m map[K]V, keep func(K, V) bool) map[K]V {
+	filtered := make(map[K]V)
+	for k, v := range m {
+		if keep(k, v) {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// FilterKeys returns the entries of m whose keys keep returns true for.
+func FilterKeys[K comparable, V any](m map[K]V, keep func(K) bool) map[K]V {
+	return Filter(m, func(k K, _ V) bool { return keep(k) })
+}
+
+// FilterValues returns the entries of m whose values keep returns true for.
+func FilterValues[K comparable, V any](m map[K]V, keep func(V) bool) map[K]V {
+	return Filter(m, func(_ K, v V) bool { return keep(v) })
+}
+
+// MapValues returns a map with m's keys and fn applied to each value.
+func MapValues[K comparable, V, R any](m map[K]V, fn func(V) R) map[K]R {
+	mapped := make(map[K]R, len(m))
+	for k, v := range m {
+		mapped[k] = fn(v)
+	}
+	return mapped
+}
+
+// Equal reports whether a and b hold the same keys with equal values.
+func Equal[K, V comparable](a, b map[K]V) bool {
+	return EqualFunc(a, b, func(x, y V) bool { return x == y })
+}
+
+// EqualFunc reports whether a and b hold the same keys with values that eq considers equal.
+func EqualFunc[K comparable, V1, V2 any](a map[K]V1, b map[K]V2, eq func(V1, V2) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		other, ok := b[k]
+		if !ok || !eq(v, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package mapx_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cjsaylor/goutil/mapx"
+)
+
+func TestKeysAndValues(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2}
+	keys := mapx.Keys(m)
+	sort.Strings(keys)
+	values := mapx.Values(m)
+	sort.Ints(values)
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) || !reflect.DeepEqual(values, []int{1, 2}) {
+		t.Errorf("Expected [a b] and [1 2] got %v and %v", keys, values)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := map[string]int{"x": 1, "y": 2}
+	b := map[string]int{"y": 10, "z": 3}
+	sum := mapx.Merge(func(key string, existing, incoming int) int { return existing + incoming }, a, b)
+	if !reflect.DeepEqual(sum, map[string]int{"x": 1, "y": 12, "z": 3}) {
+		t.Errorf("Expected conflicting values to be summed got %v", sum)
+	}
+	last := mapx.Merge(nil, a, b)
+	if last["y"] != 10 || a["y"] != 2 {
+		t.Errorf("Expected the last value to win without changing the inputs got %v", last)
+	}
+}
+
+func TestInvert(t *testing.T) {
+	inverted := mapx.Invert(map[string]int{"a": 1, "b": 2})
+	if !reflect.DeepEqual(inverted, map[int]string{1: "a", 2: "b"}) {
+		t.Errorf("Expected an inverted map got %v", inverted)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	m := map[string]int{"apple": 1, "avocado": 5, "banana": 3}
+	if got := mapx.FilterKeys(m, func(k string) bool { return strings.HasPrefix(k, "a") }); len(got) != 2 || got["avocado"] != 5 {
+		t.Errorf("Expected the a-words got %v", got)
+	}
+	if got := mapx.FilterValues(m, func(v int) bool { return v > 2 }); len(got) != 2 || got["banana"] != 3 {
+		t.Errorf("Expected values over 2 got %v", got)
+	}
+	if got := mapx.MapValues(m, func(v int) bool { return v%2 == 1 }); !got["apple"] || !got["banana"] {
+		t.Errorf("Expected mapped values got %v", got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := map[string]int{"a": 1}
+	if !mapx.Equal(a, map[string]int{"a": 1}) || mapx.Equal(a, map[string]int{"a": 2}) || mapx.Equal(a, map[string]int{"b": 1}) {
+		t.Error("Expected equality by keys and values")
+	}
+	lengths := map[string]string{"a": "xx"}
+	if !mapx.EqualFunc(map[string]int{"a": 2}, lengths, func(n int, s string) bool { return len(s) == n }) {
+		t.Error("Expected EqualFunc to compare with the given function")
+	}
+}
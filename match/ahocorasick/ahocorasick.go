@@ -0,0 +1,228 @@
+// Package ahocorasick is a package that finds every occurrence of many literal patterns in one pass over the
+// input, using an Aho-Corasick automaton. Matching takes time linear in the input plus the number of matches,
+// however many patterns there are, which suits keyword filtering and routing rules.
+//
+// The automaton is compiled to a transition table over the bytes that appear in the patterns, so matching
+// follows one table entry per input byte. Input can be matched all at once, or streamed through a Stream or
+// Scan with matches reported at their absolute offsets.
+package ahocorasick
+
+import (
+	"io"
+)
+
+// Match is an occurrence of a pattern in the input.
+type Match struct {
+	// Pattern is the index of the pattern in the list the matcher was built from.
+	Pattern int
+	// Start and End are the byte offsets of the match, End being exclusive.
+	Start, End int64
+}
+
+// Options configures a matcher.
+type Options struct {
+	// CaseInsensitive matches ASCII letters regardless of case.
+	CaseInsensitive bool
+}
+
+// Matcher is a compiled set of patterns. It is safe for concurrent use.
+type Matcher struct {
+	patterns []string
+	classes  [256]int32
+	width    int32
+	delta    []int32
+	outputs  [][]int32
+	dict     []int32
+}
+
+// New compiles patterns into a matcher. Empty patterns never match.
+func New(patterns []string) *Matcher {
+	return NewWith(patterns, Options{})
+}
+
+// NewWith is like New with options.
+func NewWith(patterns []string, options Options) *Matcher {
+	m := &Matcher{patterns: append([]string(nil), patterns...)}
+	fold := func(b byte) byte {
+		if options.CaseInsensitive && 'A' <= b && b <= 'Z' {
+			return b + 'a' - 'A'
+		}
+		return b
+	}
+	// Class 0 stands for every byte that appears in no pattern.
+	var seen [256]bool
+	for _, p := range patterns {
+		for i := 0; i < len(p); i++ {
+			seen[fold(p[i])] = true
+		}
+	}
+	m.width = 1
+	for b := 0; b < 256; b++ {
+		if seen[b] {
+			m.classes[b] = m.width
+			m.width++
+		}
+	}
+	for b := 0; b < 256; b++ {
+		m.classes[b] = m.classes[fold(byte(b))]
+	}
+
+	m.addNode()
+	for index, p := range patterns {
+		if p == "" {
+			continue
+		}
+		node := int32(0)
+		for i := 0; i < len(p); i++ {
+			slot := node*m.width + m.classes[p[i]]
+			if m.delta[slot] < 0 {
+				child := m.addNode()
+				m.delta[slot] = child
+			}
+			node = m.delta[slot]
+		}
+		m.outputs[node] = append(m.outputs[node], int32(index))
+	}
+	m.link()
+	return m
+}
+
+func (m *Matcher) addNode() int32 {
+	for i := int32(0); i < m.width; i++ {
+		m.delta = append(m.delta, -1)
+	}
+	m.outputs = append(m.outputs, nil)
+	m.dict = append(m.dict, -1)
+	return int32(len(m.outputs) - 1)
+}
+
+// link computes failure transitions breadth first, folding them into the table so every state has a
+// transition for every class, and links each state to the nearest state on its failure chain with outputs.
+func (m *Matcher) link() {
+	fail := make([]int32, len(m.outputs))
+	queue := []int32{}
+	for c := int32(0); c < m.width; c++ {
+		if child := m.delta[c]; child < 0 {
+			m.delta[c] = 0
+		} else {
+			queue = append(queue, child)
+		}
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		f := fail[node]
+		if len(m.outputs[f]) > 0 {
+			m.dict[node] = f
+		} else {
+			m.dict[node] = m.dict[f]
+		}
+		for c := int32(0); c < m.width; c++ {
+			slot := node*m.width + c
+			if child := m.delta[slot]; child >= 0 {
+				fail[child] = m.delta[f*m.width+c]
+				queue = append(queue, child)
+			} else {
+				m.delta[slot] = m.delta[f*m.width+c]
+			}
+		}
+	}
+}
+
+// Len returns the number of patterns.
+func (m *Matcher) Len() int {
+	return len(m.patterns)
+}
+
+// Pattern returns the pattern at index i.
+func (m *Matcher) Pattern(i int) string {
+	return m.patterns[i]
+}
+
+// FindAll returns every match in text, including overlapping ones, ordered by where they end and then by
+// length, longest first.
+func (m *Matcher) FindAll(text []byte) []Match {
+	var matches []Match
+	m.run(0, 0, text, func(match Match) bool {
+		matches = append(matches, match)
+		return true
+	})
+	return matches
+}
+
+// Contains reports whether any pattern occurs in text.
+func (m *Matcher) Contains(text []byte) bool {
+	found := false
+	m.run(0, 0, text, func(Match) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// Scan streams r through the matcher, calling fn with each match until fn returns false or r is exhausted.
+// Matches spanning reads are found.
+func (m *Matcher) Scan(r io.Reader, fn func(Match) bool) error {
+	buf := make([]byte, 32*1024)
+	state, offset := int32(0), int64(0)
+	for {
+		n, err := r.Read(buf)
+		var ok bool
+		if state, ok = m.run(state, offset, buf[:n], fn); !ok {
+			return nil
+		}
+		offset += int64(n)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// run feeds text to the automaton from state, where text starts at offset in the whole input. It returns
+// the final state, and false if fn stopped the run.
+func (m *Matcher) run(state int32, offset int64, text []byte, fn func(Match) bool) (int32, bool) {
+	for i, b := range text {
+		state = m.delta[state*m.width+m.classes[b]]
+		end := offset + int64(i) + 1
+		for node := state; node > 0; node = m.dict[node] {
+			for _, index := range m.outputs[node] {
+				if !fn(Match{Pattern: int(index), Start: end - int64(len(m.patterns[index])), End: end}) {
+					return state, false
+				}
+			}
+		}
+	}
+	return state, true
+}
+
+// Stream matches input written to it in pieces, calling a function with each match. It is an io.Writer,
+// so it can sit behind io.Copy or io.TeeReader. A Stream is not safe for concurrent use.
+type Stream struct {
+	matcher *Matcher
+	fn      func(Match)
+	state   int32
+	offset  int64
+}
+
+// NewStream returns a stream that calls fn with each match.
+func (m *Matcher) NewStream(fn func(Match)) *Stream {
+	return &Stream{matcher: m, fn: fn}
+}
+
+// Write matches p as the continuation of everything written before. It never fails.
+func (s *Stream) Write(p []byte) (int, error) {
+	s.state, _ = s.matcher.run(s.state, s.offset, p, func(match Match) bool {
+		s.fn(match)
+		return true
+	})
+	s.offset += int64(len(p))
+	return len(p), nil
+}
+
+// Reset starts matching a new input from offset zero.
+func (s *Stream) Reset() {
+	s.state, s.offset = 0, 0
+}
@@ -0,0 +1,96 @@
+package ahocorasick_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/cjsaylor/goutil/match/ahocorasick"
+)
+
+func found(m *ahocorasick.Matcher, matches []ahocorasick.Match) []string {
+	var out []string
+	for _, match := range matches {
+		out = append(out, fmt.Sprintf("%s@%d", m.Pattern(match.Pattern), match.Start))
+	}
+	return out
+}
+
+func TestFindAll(t *testing.T) {
+	m := ahocorasick.New([]string{"he", "she", "his", "hers", ""})
+	got := found(m, m.FindAll([]byte("ushers")))
+	if !reflect.DeepEqual(got, []string{"she@1", "he@2", "hers@2"}) {
+		t.Errorf("Expected overlapping matches got %v", got)
+	}
+	if !m.Contains([]byte("this")) {
+		t.Error("Expected his to be found")
+	}
+	if m.Contains([]byte("xyz")) {
+		t.Error("Expected no match")
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	m := ahocorasick.NewWith([]string{"Error", "WARN"}, ahocorasick.Options{CaseInsensitive: true})
+	got := found(m, m.FindAll([]byte("error: warn ERROR")))
+	if !reflect.DeepEqual(got, []string{"Error@0", "WARN@7", "Error@12"}) {
+		t.Errorf("Expected case insensitive matches got %v", got)
+	}
+	if ahocorasick.New([]string{"Error"}).Contains([]byte("error")) {
+		t.Error("Expected matching to be case sensitive by default")
+	}
+}
+
+func TestManyPatterns(t *testing.T) {
+	patterns := make([]string, 5000)
+	for i := range patterns {
+		patterns[i] = fmt.Sprintf("keyword%d;", i)
+	}
+	m := ahocorasick.New(patterns)
+	text := []byte("prefix keyword42; keyword4999; keyword5000;")
+	got := found(m, m.FindAll(text))
+	if !reflect.DeepEqual(got, []string{"keyword42;@7", "keyword4999;@18"}) {
+		t.Errorf("Expected 2 keywords got %v", got)
+	}
+}
+
+func TestScan(t *testing.T) {
+	m := ahocorasick.New([]string{"needle"})
+	input := strings.Repeat("hay", 20000) + "needle" + strings.Repeat("hay", 10) + "needle"
+	var matches []ahocorasick.Match
+	err := m.Scan(iotest.OneByteReader(strings.NewReader(input)), func(match ahocorasick.Match) bool {
+		matches = append(matches, match)
+		return true
+	})
+	if err != nil || len(matches) != 2 || matches[0].Start != 60000 || matches[1].End != int64(len(input)) {
+		t.Errorf("Expected matches across reads at their absolute offsets got %v (%v)", matches, err)
+	}
+	count := 0
+	m.Scan(strings.NewReader(input), func(ahocorasick.Match) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Errorf("Expected scanning to stop got %d matches", count)
+	}
+}
+
+func TestStream(t *testing.T) {
+	m := ahocorasick.New([]string{"abc"})
+	var matches []ahocorasick.Match
+	s := m.NewStream(func(match ahocorasick.Match) { matches = append(matches, match) })
+	for _, piece := range []string{"xa", "b", "cab", "c"} {
+		s.Write([]byte(piece))
+	}
+	if !reflect.DeepEqual(matches, []ahocorasick.Match{{Start: 1, End: 4}, {Start: 4, End: 7}}) {
+		t.Errorf("Expected matches spanning writes got %v", matches)
+	}
+	s.Reset()
+	matches = nil
+	if _, err := bytes.NewBufferString("abc").WriteTo(s); err != nil || len(matches) != 1 || matches[0].Start != 0 {
+		t.Errorf("Expected a reset stream to count from zero got %v", matches)
+	}
+}
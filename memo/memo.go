@@ -0,0 +1,111 @@
+// Package memo is a package that wraps functions with a cache of their results.
+package memo
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/lru"
+)
+
+// Options configures how results of a memoized function are cached.
+type Options struct {
+	// Capacity is the maximum number of results retained. The least recently used result is evicted first.
+	// Defaults to 1024.
+	Capacity int
+	// TTL is how long a result remains valid. A zero TTL keeps results until they are evicted.
+	TTL time.Duration
+	// CacheErrors caches failed results in addition to successful ones.
+	CacheErrors bool
+	// Clock is used to expire results. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// PanicError is the error returned to callers when the memoized function panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("memo: function panicked: %v", e.Value)
+}
+
+type result[V any] struct {
+	value   V
+	err     error
+	expires time.Time
+}
+
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Func wraps f so that results are cached by argument.
+// Concurrent calls with the same argument share a single invocation of f.
+// The shared invocation does not inherit the cancellation of any one caller,
+// but each caller stops waiting as soon as its own context is done.
+// A panic in f is recovered and returned to every waiting caller as a *PanicError, and is never cached.
+func Func[K comparable, V any](f func(context.Context, K) (V, error), opts Options) func(context.Context, K) (V, error) {
+	if opts.Capacity <= 0 {
+		opts.Capacity = 1024
+	}
+	now := clock.OrReal(opts.Clock).Now
+	cache := lru.NewCache(opts.Capacity, lru.Noop())
+	mutex := &sync.Mutex{}
+	inflight := make(map[K]*call[V])
+	return func(ctx context.Context, key K) (V, error) {
+		if val, ok := cache.Get(key); ok {
+			res := val.(*result[V])
+			if res.expires.IsZero() || now().Before(res.expires) {
+				return res.value, res.err
+			}
+			cache.Remove(key)
+		}
+		mutex.Lock()
+		c, ok := inflight[key]
+		if !ok {
+			c = &call[V]{done: make(chan struct{})}
+			inflight[key] = c
+			go func() {
+				var panicked bool
+				c.value, panicked, c.err = invoke(f, context.WithoutCancel(ctx), key)
+				if !panicked && (c.err == nil || opts.CacheErrors) {
+					res := &result[V]{value: c.value, err: c.err}
+					if opts.TTL > 0 {
+						res.expires = now().Add(opts.TTL)
+					}
+					cache.Set(key, res)
+				}
+				mutex.Lock()
+				delete(inflight, key)
+				mutex.Unlock()
+				close(c.done)
+			}()
+		}
+		mutex.Unlock()
+		select {
+		case <-c.done:
+			return c.value, c.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+}
+
+func invoke[K comparable, V any](f func(context.Context, K) (V, error), ctx context.Context, key K) (value V, panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, panicked = &PanicError{Value: r, Stack: debug.Stack()}, true
+		}
+	}()
+	value, err = f(ctx, key)
+	return value, false, err
+}
@@ -0,0 +1,153 @@
+package memo_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/memo"
+)
+
+func TestFunc(t *testing.T) {
+	calls := 0
+	square := memo.Func(func(ctx context.Context, n int) (int, error) {
+		calls++
+		return n * n, nil
+	}, memo.Options{Capacity: 2})
+	for i := 0; i < 3; i++ {
+		if val, err := square(context.Background(), 3); val != 9 || err != nil {
+			t.Errorf("Expected 9 got %v (%v)", val, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single call got %d", calls)
+	}
+}
+
+func TestFuncDefaultCapacity(t *testing.T) {
+	calls := 0
+	identity := memo.Func(func(ctx context.Context, n int) (int, error) {
+		calls++
+		return n, nil
+	}, memo.Options{})
+	identity(context.Background(), 1)
+	identity(context.Background(), 1)
+	if calls != 1 {
+		t.Errorf("Expected the default options to cache results got %d calls", calls)
+	}
+}
+
+func TestFuncCapacity(t *testing.T) {
+	calls := 0
+	identity := memo.Func(func(ctx context.Context, n int) (int, error) {
+		calls++
+		return n, nil
+	}, memo.Options{Capacity: 1})
+	identity(context.Background(), 1)
+	identity(context.Background(), 2)
+	identity(context.Background(), 1)
+	if calls != 3 {
+		t.Errorf("Expected 1 to be evicted and recomputed, got %d calls", calls)
+	}
+}
+
+func TestFuncTTL(t *testing.T) {
+	calls := 0
+	fake := clock.NewFake(time.Unix(0, 0))
+	identity := memo.Func(func(ctx context.Context, n int) (int, error) {
+		calls++
+		return n, nil
+	}, memo.Options{Capacity: 1, TTL: time.Minute, Clock: fake})
+	identity(context.Background(), 1)
+	fake.Advance(59 * time.Second)
+	identity(context.Background(), 1)
+	if calls != 1 {
+		t.Errorf("Expected a fresh result to be cached, got %d calls", calls)
+	}
+	fake.Advance(time.Second)
+	identity(context.Background(), 1)
+	if calls != 2 {
+		t.Errorf("Expected expired result to be recomputed, got %d calls", calls)
+	}
+}
+
+func TestFuncErrors(t *testing.T) {
+	for _, cacheErrors := range []bool{false, true} {
+		calls := 0
+		fail := memo.Func(func(ctx context.Context, n int) (int, error) {
+			calls++
+			return 0, errors.New("failed")
+		}, memo.Options{Capacity: 1, CacheErrors: cacheErrors})
+		fail(context.Background(), 1)
+		if _, err := fail(context.Background(), 1); err == nil {
+			t.Error("Expected error to be returned")
+		}
+		expected := 2
+		if cacheErrors {
+			expected = 1
+		}
+		if calls != expected {
+			t.Errorf("Expected %d calls with CacheErrors=%v got %d", expected, cacheErrors, calls)
+		}
+	}
+}
+
+func TestFuncSingleflight(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	slow := memo.Func(func(ctx context.Context, n int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return n, nil
+	}, memo.Options{Capacity: 1})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slow(context.Background(), 1)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("Expected concurrent calls to be coalesced, got %d calls", calls)
+	}
+}
+
+func TestFuncContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slow := memo.Func(func(ctx context.Context, n int) (int, error) {
+		<-release
+		return n, nil
+	}, memo.Options{Capacity: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := slow(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+}
+
+func TestFuncPanic(t *testing.T) {
+	var calls atomic.Int32
+	fn := memo.Func(func(ctx context.Context, n int) (int, error) {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		return n, nil
+	}, memo.Options{Capacity: 2, CacheErrors: true})
+	_, err := fn(context.Background(), 1)
+	var panicErr *memo.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("Expected the panic to be returned as an error got %v", err)
+	}
+	if val, err := fn(context.Background(), 1); err != nil || val != 1 {
+		t.Errorf("Expected a panic not to be cached got %v (%v)", val, err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package merkle is a package that implements an append-only Merkle hash tree with inclusion proofs.
+//
+// Trees follow the layout of RFC 6962: leaves and interior nodes are SHA-256 hashes with distinct prefixes,
+// and a tree of n leaves splits at the largest power of two smaller than n. Hashes of complete subtrees
+// are kept as leaves are appended, so roots and proofs never rehash the whole tree.
+package merkle
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/bits"
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// ErrIndex is returned when a proof is requested for a leaf that does not exist.
+var ErrIndex = errors.New("merkle: leaf index out of range")
+
+// Hash is a SHA-256 digest.
+type Hash [sha256.Size]byte
+
+// Tree is an ordered list of leaves with its hash tree. It is not safe for concurrent use.
+type Tree struct {
+	// levels[i][j] is the hash of the complete subtree of 2^i leaves starting at leaf j*2^i.
+	levels [][]Hash
+}
+
+// New creates an empty tree.
+func New() *Tree {
+	return &Tree{levels: [][]Hash{nil}}
+}
+
+// Build creates a tree from leaves in order.
+func Build(leaves [][]byte) *Tree {
+	t := New()
+	for _, leaf := range leaves {
+		t.Append(leaf)
+	}
+	return t
+}
+
+// LeafHash returns the hash of a leaf's data.
+func LeafHash(data []byte) Hash {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	var ret Hash
+	h.Sum(ret[:0])
+	return ret
+}
+
+func nodeHash(left, right Hash) Hash {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left[:])
+	h.Write(right[:])
+	var ret Hash
+	h.Sum(ret[:0])
+	return ret
+}
+
+// Append a leaf to the end of the tree.
+func (t *Tree) Append(data []byte) {
+	hash := LeafHash(data)
+	for level := 0; ; level++ {
+		if level == len(t.levels) {
+			t.levels = append(t.levels, nil)
+		}
+		t.levels[level] = append(t.levels[level], hash)
+		count := len(t.levels[level])
+		if count%2 == 1 {
+			return
+		}
+		hash = nodeHash(t.levels[level][count-2], hash)
+	}
+}
+
+// Len returns the number of leaves.
+func (t *Tree) Len() int {
+	return len(t.levels[0])
+}
+
+// Root returns the root hash. The root of an empty tree is the hash of no data.
+func (t *Tree) Root() Hash {
+	if t.Len() == 0 {
+		return sha256.Sum256(nil)
+	}
+	return t.hashRange(0, t.Len())
+}
+
+// Proof returns the audit path proving the leaf at index is included in the current tree.
+func (t *Tree) Proof(index int) ([]Hash, error) {
+	if index < 0 || index >= t.Len() {
+		return nil, ErrIndex
+	}
+	return t.path(index, 0, t.Len()), nil
+}
+
+// Verify reports whether proof shows that data is the leaf at index of a tree with size leaves and the given root.
+func Verify(data []byte, index, size int, proof []Hash, root Hash) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	fn, sn := uint64(index), uint64(size-1)
+	r := LeafHash(data)
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && r == root
+}
+
+// hashRange returns the hash of the subtree over leaves [lo, hi).
+func (t *Tree) hashRange(lo, hi int) Hash {
+	n := hi - lo
+	if n&(n-1) == 0 {
+		level := bits.TrailingZeros(uint(n))
+		if lo%n == 0 {
+			return t.levels[level][lo/n]
+		}
+	}
+	k := split(n)
+	return nodeHash(t.hashRange(lo, lo+k), t.hashRange(lo+k, hi))
+}
+
+func (t *Tree) path(index, lo, hi int) []Hash {
+	n := hi - lo
+	if n == 1 {
+		return nil
+	}
+	k := split(n)
+	if index < k {
+		return append(t.path(index, lo, lo+k), t.hashRange(lo+k, hi))
+	}
+	return append(t.path(index-k, lo+k, hi), t.hashRange(lo, lo+k))
+}
+
+// split returns the largest power of two smaller than n.
+func split(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
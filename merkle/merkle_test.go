@@ -0,0 +1,59 @@
+package merkle_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/merkle"
+)
+
+func TestProofs(t *testing.T) {
+	for size := 1; size <= 20; size++ {
+		var leaves [][]byte
+		for i := 0; i < size; i++ {
+			leaves = append(leaves, []byte(strconv.Itoa(i)))
+		}
+		tree := merkle.Build(leaves)
+		root := tree.Root()
+		for i, leaf := range leaves {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !merkle.Verify(leaf, i, size, proof, root) {
+				t.Fatalf("Expected proof of leaf %d in tree of %d to verify", i, size)
+			}
+			if merkle.Verify([]byte("tampered"), i, size, proof, root) {
+				t.Fatalf("Expected tampered leaf %d in tree of %d to fail", i, size)
+			}
+		}
+	}
+}
+
+func TestAppendChangesRoot(t *testing.T) {
+	tree := merkle.New()
+	empty := tree.Root()
+	tree.Append([]byte("a"))
+	one := tree.Root()
+	if one == empty || one != merkle.LeafHash([]byte("a")) {
+		t.Error("Expected the root of one leaf to be its leaf hash")
+	}
+	tree.Append([]byte("b"))
+	if tree.Root() == one {
+		t.Error("Expected appending to change the root")
+	}
+	if tree.Root() != merkle.Build([][]byte{[]byte("a"), []byte("b")}).Root() {
+		t.Error("Expected incremental and bulk builds to agree")
+	}
+}
+
+func TestProofIndex(t *testing.T) {
+	tree := merkle.Build([][]byte{[]byte("a")})
+	if _, err := tree.Proof(1); err != merkle.ErrIndex {
+		t.Errorf("Expected index error got %v", err)
+	}
+	proof, _ := tree.Proof(0)
+	if merkle.Verify([]byte("a"), 0, 2, proof, tree.Root()) {
+		t.Error("Expected proof against the wrong size to fail")
+	}
+}
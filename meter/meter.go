@@ -0,0 +1,123 @@
+// Package meter is a package that tracks event rates over rolling windows.
+//
+// Counts are kept per second for the last minute, split across shards so concurrent marks rarely
+// contend on the same lock.
+package meter
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Window is how far back a meter can report rates.
+const Window = time.Minute
+
+const seconds = int64(Window / time.Second)
+
+// Snapshot is a point-in-time view of a meter.
+type Snapshot struct {
+	Count   int64
+	Rate1s  float64
+	Rate10s float64
+	Rate1m  float64
+}
+
+type shard struct {
+	mutex   *sync.Mutex
+	total   int64
+	counts  [seconds]int64
+	seconds [seconds]int64
+}
+
+// Meter counts events and reports their per-second rate. It is safe for concurrent use.
+type Meter struct {
+	start  time.Time
+	shards []shard
+}
+
+// New creates a meter with one shard per processor.
+func New() *Meter {
+	m := &Meter{
+		start:  time.Now(),
+		shards: make([]shard, runtime.GOMAXPROCS(0)),
+	}
+	for i := range m.shards {
+		m.shards[i].mutex = &sync.Mutex{}
+		for j := range m.shards[i].seconds {
+			m.shards[i].seconds[j] = -1
+		}
+	}
+	return m
+}
+
+// Mark records n events.
+func (m *Meter) Mark(n int64) {
+	now := m.second()
+	s := &m.shards[rand.Intn(len(m.shards))]
+	i := now % seconds
+	s.mutex.Lock()
+	if s.seconds[i] != now {
+		s.seconds[i] = now
+		s.counts[i] = 0
+	}
+	s.counts[i] += n
+	s.total += n
+	s.mutex.Unlock()
+}
+
+// Count returns the number of events recorded since the meter was created.
+func (m *Meter) Count() int64 {
+	var total int64
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mutex.Lock()
+		total += s.total
+		s.mutex.Unlock()
+	}
+	return total
+}
+
+// Rate returns the average events per second over the last window, rounded to whole seconds and
+// capped at Window. Only completed seconds are counted, so a meter younger than a second reports
+// zero and one younger than the window averages over the seconds it has.
+func (m *Meter) Rate(window time.Duration) float64 {
+	now := m.second()
+	n := int64(window / time.Second)
+	if n > seconds {
+		n = seconds
+	}
+	if n > now {
+		n = now
+	}
+	if n < 1 {
+		return 0
+	}
+	var total int64
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mutex.Lock()
+		for second := now - n; second < now; second++ {
+			if j := second % seconds; s.seconds[j] == second {
+				total += s.counts[j]
+			}
+		}
+		s.mutex.Unlock()
+	}
+	return float64(total) / float64(n)
+}
+
+// Snapshot returns the total count with the one second, ten second and one minute rates.
+func (m *Meter) Snapshot() Snapshot {
+	return Snapshot{
+		Count:   m.Count(),
+		Rate1s:  m.Rate(time.Second),
+		Rate10s: m.Rate(10 * time.Second),
+		Rate1m:  m.Rate(time.Minute),
+	}
+}
+
+func (m *Meter) second() int64 {
+	return int64(time.Since(m.start) / time.Second)
+}
@@ -0,0 +1,57 @@
+package meter_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/meter"
+)
+
+func TestCount(t *testing.T) {
+	m := meter.New()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				m.Mark(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if m.Count() != 8000 {
+		t.Errorf("Expected 8000 got %d", m.Count())
+	}
+}
+
+func TestRate(t *testing.T) {
+	m := meter.New()
+	if m.Rate(time.Second) != 0 {
+		t.Error("Expected no rate before a second has completed")
+	}
+	m.Mark(60)
+	m.Mark(40)
+	time.Sleep(1100 * time.Millisecond)
+	snapshot := m.Snapshot()
+	if snapshot.Count != 100 {
+		t.Errorf("Expected a count of 100 got %d", snapshot.Count)
+	}
+	if snapshot.Rate1s != 100 {
+		t.Errorf("Expected a one second rate of 100 got %v", snapshot.Rate1s)
+	}
+	// The meter is only a second old, so longer windows average over that second.
+	if snapshot.Rate10s != 100 || snapshot.Rate1m != 100 {
+		t.Errorf("Expected longer rates of 100 got %v and %v", snapshot.Rate10s, snapshot.Rate1m)
+	}
+}
+
+func BenchmarkMark(b *testing.B) {
+	m := meter.New()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			m.Mark(1)
+		}
+	})
+}
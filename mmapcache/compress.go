@@ -0,0 +1,79 @@
+//go:build unix
+
+package mmapcache
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+)
+
+// Compressor compresses and decompresses values. Implementations must be safe for concurrent use.
+// Faster codecs such as snappy or zstd can be plugged in by wrapping their encode and decode functions.
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+type flateCompressor struct {
+	level int
+}
+
+// Flate returns a Compressor using raw DEFLATE at the given compress/flate level.
+func Flate(level int) Compressor {
+	return flateCompressor{level: level}
+}
+
+func (f flateCompressor) Compress(data []byte) ([]byte, error) {
+	buf := bytes.Buffer{}
+	w, err := flate.NewWriter(&buf, f.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (f flateCompressor) Decompress(data []byte) ([]byte, error) {
+	return io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+}
+
+type gzipCompressor struct {
+	level int
+}
+
+// Gzip returns a Compressor using gzip at the given compress/gzip level. It adds a few bytes of
+// framing over Flate in exchange for a checksum that detects corrupted values.
+func Gzip(level int) Compressor {
+	return gzipCompressor{level: level}
+}
+
+func (g gzipCompressor) Compress(data []byte) ([]byte, error) {
+	buf := bytes.Buffer{}
+	w, err := gzip.NewWriterLevel(&buf, g.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (g gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
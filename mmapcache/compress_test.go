@@ -0,0 +1,81 @@
+//go:build unix
+
+package mmapcache_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cjsaylor/goutil/mmapcache"
+)
+
+func TestCompressors(t *testing.T) {
+	value := []byte(strings.Repeat("compressible ", 100))
+	for _, c := range []mmapcache.Compressor{mmapcache.Flate(gzip.BestSpeed), mmapcache.Gzip(gzip.DefaultCompression)} {
+		compressed, err := c.Compress(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(compressed) >= len(value) {
+			t.Errorf("Expected compression to shrink %d bytes got %d", len(value), len(compressed))
+		}
+		if decompressed, err := c.Decompress(compressed); err != nil || !bytes.Equal(decompressed, value) {
+			t.Errorf("Expected a round trip got %v", err)
+		}
+	}
+}
+
+func TestCompressedCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	options := mmapcache.Options{Compressor: mmapcache.Gzip(gzip.BestSpeed), CompressAbove: 16}
+	// Each value is 1KB, so without compression the 2KB file holds at most one of them.
+	cache, err := mmapcache.OpenWith(path, 2048, 10, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string][]byte{}
+	for _, key := range []string{"a", "b", "c", "d"} {
+		values[key] = []byte(strings.Repeat(key, 1024))
+		if err := cache.Set(key, values[key]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache.Set("small", []byte("tiny"))
+	if cache.Len() != 5 {
+		t.Errorf("Expected every compressed entry to fit got %d", cache.Len())
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cache, err = mmapcache.OpenWith(path, 2048, 10, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	for key, value := range values {
+		if got, ok := cache.Get(key); !ok || !bytes.Equal(got, value) {
+			t.Errorf("Expected %q to decompress after reopening", key)
+		}
+	}
+	if got, ok := cache.Get("small"); !ok || string(got) != "tiny" {
+		t.Errorf("Expected a small value to be stored as is got %q", got)
+	}
+}
+
+func TestCompressedWithoutCompressor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	cache, _ := mmapcache.OpenWith(path, 4096, 10, mmapcache.Options{Compressor: mmapcache.Flate(gzip.BestSpeed)})
+	cache.Set("a", []byte(strings.Repeat("a", 100)))
+	cache.Close()
+	cache, _ = mmapcache.Open(path, 4096, 10)
+	defer cache.Close()
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected a compressed value to be unreadable without a compressor")
+	}
+	if cache.Len() != 0 {
+		t.Error("Expected the unreadable entry to be removed")
+	}
+}
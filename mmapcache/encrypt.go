@@ -0,0 +1,86 @@
+//go:build unix
+
+package mmapcache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+)
+
+// ErrKey is returned when a cache file was written with a different encryption key, or without one.
+var ErrKey = errors.New("mmapcache: wrong encryption key")
+
+// ErrKeySize is returned when Options.Key is not 16, 24 or 32 bytes.
+var ErrKeySize = errors.New("mmapcache: encryption key must be 16, 24 or 32 bytes")
+
+// sealer encrypts values with AES-GCM and replaces keys with an HMAC so neither is stored in plaintext.
+type sealer struct {
+	aead  cipher.AEAD
+	index []byte
+}
+
+// newSealer derives separate encryption and index keys from key so the caller's key is never used twice.
+func newSealer(key []byte) (*sealer, error) {
+	if !validKeySize(key) {
+		return nil, ErrKeySize
+	}
+	encryption := derive(key, "mmapcache value encryption")[:len(key)]
+	block, err := aes.NewCipher(encryption)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead, index: derive(key, "mmapcache key index")}, nil
+}
+
+func validKeySize(key []byte) bool {
+	switch len(key) {
+	case 16, 24, 32:
+		return true
+	}
+	return false
+}
+
+func derive(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// indexKey returns the key as stored in the file.
+func (s *sealer) indexKey(key string) string {
+	mac := hmac.New(sha256.New, s.index)
+	mac.Write([]byte(key))
+	return string(mac.Sum(nil))
+}
+
+// seal encrypts value, binding it to its stored key so records cannot be swapped between keys.
+// The nonce is prepended to the ciphertext.
+func (s *sealer) seal(indexKey string, value []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(value)+s.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, value, []byte(indexKey)), nil
+}
+
+// open authenticates and decrypts a value written by seal.
+func (s *sealer) open(indexKey string, data []byte) ([]byte, error) {
+	if len(data) < s.aead.NonceSize() {
+		return nil, ErrKey
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	value, err := s.aead.Open(nil, nonce, ciphertext, []byte(indexKey))
+	if err != nil {
+		return nil, ErrKey
+	}
+	return value, nil
+}
@@ -0,0 +1,95 @@
+//go:build unix
+
+package mmapcache_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cjsaylor/goutil/mmapcache"
+)
+
+func TestEncryptedCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	key := bytes.Repeat([]byte{1}, 32)
+	options := mmapcache.Options{Key: key, Compressor: mmapcache.Flate(gzip.BestSpeed)}
+	cache, err := mmapcache.OpenWith(path, 4096, 10, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("email", []byte("someone@example.com"))
+	cache.Set("notes", []byte(strings.Repeat("private ", 50)))
+	cache.Close()
+	data, _ := os.ReadFile(path)
+	for _, plaintext := range []string{"email", "someone@example.com", "notes", "private"} {
+		if bytes.Contains(data, []byte(plaintext)) {
+			t.Errorf("Expected %q not to be stored in plaintext", plaintext)
+		}
+	}
+	cache, err = mmapcache.OpenWith(path, 4096, 10, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	if got, ok := cache.Get("email"); !ok || string(got) != "someone@example.com" {
+		t.Errorf("Expected the value to decrypt after reopening got %q", got)
+	}
+	if got, ok := cache.Get("notes"); !ok || string(got) != strings.Repeat("private ", 50) {
+		t.Error("Expected a compressed value to decrypt after reopening")
+	}
+	if !cache.Remove("email") || cache.Len() != 1 {
+		t.Error("Expected remove to find the encrypted key")
+	}
+}
+
+func TestEncryptedWrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	cache, _ := mmapcache.OpenWith(path, 4096, 10, mmapcache.Options{Key: bytes.Repeat([]byte{1}, 16)})
+	cache.Set("a", []byte("secret"))
+	cache.Close()
+	if _, err := mmapcache.OpenWith(path, 4096, 10, mmapcache.Options{Key: bytes.Repeat([]byte{2}, 16)}); err != mmapcache.ErrKey {
+		t.Errorf("Expected a wrong key to fail got %v", err)
+	}
+	if _, err := mmapcache.Open(path, 4096, 10); err != mmapcache.ErrKey {
+		t.Errorf("Expected a missing key to fail got %v", err)
+	}
+	for _, size := range []int{5, 48, 64} {
+		_, err := mmapcache.OpenWith(path, 4096, 10, mmapcache.Options{Key: bytes.Repeat([]byte{1}, size)})
+		if err != mmapcache.ErrKeySize {
+			t.Errorf("Expected a %d byte key to fail with ErrKeySize got %v", size, err)
+		}
+	}
+}
+
+func TestEncryptedTamper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	options := mmapcache.Options{Key: bytes.Repeat([]byte{1}, 16)}
+	cache, _ := mmapcache.OpenWith(path, 4096, 10, options)
+	cache.Set("a", []byte("first"))
+	cache.Set("b", []byte("second"))
+	cache.Close()
+	data, _ := os.ReadFile(path)
+	// The header records where the last record ends, which is the authentication tag of "b".
+	end := binary.LittleEndian.Uint64(data[8:])
+	data[end-1] ^= 0xff
+	os.WriteFile(path, data, 0600)
+	cache, err := mmapcache.OpenWith(path, 4096, 10, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected a tampered value to fail authentication")
+	}
+	if cache.Len() != 1 {
+		t.Error("Expected the tampered entry to be removed")
+	}
+	if got, ok := cache.Get("a"); !ok || string(got) != "first" {
+		t.Errorf("Expected the untouched value to remain got %q", got)
+	}
+}
@@ -0,0 +1,496 @@
+//go:build unix
+
+// Package mmapcache is a package that implements a persistent LRU cache whose entries live in a memory-mapped file.
+// The cache can be reopened after a process restart without reloading values onto the heap.
+//
+// Entries are appended to the file as records. Overwritten, removed and evicted records are marked dead in place
+// and their space is reclaimed by compaction, which happens automatically when the file is full. Compaction writes
+// a new file and renames it over the old one, so a crash part way through leaves the old file intact.
+//
+// A cache holds an exclusive lock on its file, so only one cache at a time, in any process, can open it.
+//
+// Values can be compressed transparently with Options.Compressor, which fits more entries in a file of the same
+// size when values are text heavy.
+//
+// Setting Options.Key encrypts values with AES-GCM and stores keys as an HMAC, so the file holds no plaintext.
+// Every value is authenticated when it is read back.
+package mmapcache
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+const (
+	headerSize       = 16
+	recordHeaderSize = 9
+	version          = 1
+
+	// The record state is a set of flags. Any state other than recordDead is live.
+	recordDead       byte = 0
+	recordLive       byte = 1
+	recordCompressed byte = 2
+	recordEncrypted  byte = 4
+)
+
+var magic = []byte("GUMC")
+
+var (
+	// ErrTooLarge is returned when an entry cannot fit in the file even after compaction.
+	ErrTooLarge = errors.New("mmapcache: entry too large")
+	// ErrCorrupt is returned when an existing file is not a valid cache file.
+	ErrCorrupt = errors.New("mmapcache: corrupt cache file")
+	// ErrLocked is returned when opening a file that another cache has open.
+	ErrLocked = errors.New("mmapcache: cache file in use")
+	// ErrClosed is returned when using a closed cache.
+	ErrClosed = errors.New("mmapcache: cache closed")
+)
+
+// Options configures a cache.
+type Options struct {
+	// Compressor compresses values on Set and decompresses them on Get. Nil stores values as given.
+	// A file must be reopened with the compressor it was written with.
+	Compressor Compressor
+	// CompressAbove is the value size in bytes above which values are compressed. Values that do not
+	// shrink are stored as given.
+	CompressAbove int
+	// Key enables AES-GCM encryption of values when set. It must be 16, 24 or 32 bytes to select AES-128,
+	// AES-192 or AES-256. Keys are stored as an HMAC rather than in plaintext.
+	// A file must be reopened with the key it was written with, otherwise opening fails with ErrKey.
+	Key []byte
+}
+
+type entry struct {
+	key    string
+	offset int
+}
+
+// Cache is a key-value store with a fixed number of entries backed by a memory-mapped file.
+// The oldest entry will be evicted when the newest entry is added at the capacity limit.
+type Cache struct {
+	file     *os.File
+	data     []byte
+	end      int
+	live     int
+	closed   bool
+	queue    *list.List
+	lookup   map[string]*list.Element
+	capacity int
+	options  Options
+	sealer   *sealer
+	mutex    *sync.Mutex
+}
+
+// Open maps the cache file at path, creating it with size bytes if it does not exist.
+// Entries persisted by a previous process are restored, oldest first.
+func Open(path string, size int64, capacity int) (*Cache, error) {
+	return OpenWith(path, size, capacity, Options{})
+}
+
+// OpenWith is like Open with options.
+func OpenWith(path string, size int64, capacity int, options Options) (*Cache, error) {
+	if options.Key != nil && !validKeySize(options.Key) {
+		return nil, ErrKeySize
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	// Left over from an interrupted compaction.
+	os.Remove(path + ".tmp")
+	cache, err := open(file, size, capacity, options)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return cache, nil
+}
+
+func open(file *os.File, size int64, capacity int, options Options) (*Cache, error) {
+	var s *sealer
+	if options.Key != nil {
+		var err error
+		if s, err = newSealer(options.Key); err != nil {
+			return nil, err
+		}
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	fresh := info.Size() == 0
+	if info.Size() < size {
+		if err := file.Truncate(size); err != nil {
+			return nil, err
+		}
+	} else {
+		size = info.Size()
+	}
+	if size < headerSize {
+		return nil, ErrCorrupt
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	cache := Cache{
+		file:     file,
+		data:     data,
+		end:      headerSize,
+		queue:    list.New(),
+		lookup:   make(map[string]*list.Element, capacity),
+		capacity: capacity,
+		options:  options,
+		sealer:   s,
+		mutex:    &sync.Mutex{},
+	}
+	if fresh {
+		copy(data, magic)
+		binary.LittleEndian.PutUint32(data[4:], version)
+		cache.writeEnd()
+	} else if err := cache.load(); err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// load rebuilds the in-memory index from the records in the file.
+// When encrypting, the first encrypted value is authenticated to check the key up front.
+func (c *Cache) load() error {
+	checked := false
+	if !bytes.Equal(c.data[:4], magic) || binary.LittleEndian.Uint32(c.data[4:]) != version {
+		return ErrCorrupt
+	}
+	end := int(binary.LittleEndian.Uint64(c.data[8:]))
+	if end < headerSize || end > len(c.data) {
+		return ErrCorrupt
+	}
+	for offset := headerSize; offset < end; {
+		if offset+recordHeaderSize > end {
+			return ErrCorrupt
+		}
+		length := c.recordSize(offset)
+		if offset+length > end {
+			return ErrCorrupt
+		}
+		if state := c.data[offset]; state != recordDead {
+			key := string(c.recordKey(offset))
+			if (state&recordEncrypted != 0) != (c.sealer != nil) {
+				return ErrKey
+			}
+			if c.sealer != nil && !checked {
+				if _, err := c.sealer.open(key, c.recordValue(offset)); err != nil {
+					return err
+				}
+				checked = true
+			}
+			if item, ok := c.lookup[key]; ok {
+				c.remove(item)
+			}
+			c.lookup[key] = c.queue.PushFront(&entry{key: key, offset: offset})
+			c.live += length
+		}
+		offset += length
+	}
+	c.end = end
+	for c.queue.Len() > c.capacity {
+		c.remove(c.queue.Back())
+	}
+	return nil
+}
+
+// Set a key/value into the cache.
+// This will evict the oldest entry if at the capacity limit, and compact the file if it is full. The oldest
+// entries are evicted first if the live entries would not leave room for it.
+func (c *Cache) Set(key string, value []byte) error {
+	key = c.indexKey(key)
+	state := recordLive
+	if c.options.Compressor != nil && len(value) > c.options.CompressAbove {
+		compressed, err := c.options.Compressor.Compress(value)
+		if err != nil {
+			return err
+		}
+		if len(compressed) < len(value) {
+			value = compressed
+			state = recordCompressed
+		}
+	}
+	if c.sealer != nil {
+		sealed, err := c.sealer.seal(key, value)
+		if err != nil {
+			return err
+		}
+		value = sealed
+		state |= recordEncrypted
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	length := recordHeaderSize + len(key) + len(value)
+	if headerSize+length > len(c.data) {
+		return ErrTooLarge
+	}
+	if item, ok := c.lookup[key]; ok {
+		c.remove(item)
+	}
+	if c.end+length > len(c.data) {
+		for headerSize+c.live+length > len(c.data) {
+			c.remove(c.queue.Back())
+		}
+		if err := c.compact(); err != nil {
+			return err
+		}
+	}
+	offset := c.end
+	c.data[offset] = state
+	binary.LittleEndian.PutUint32(c.data[offset+1:], uint32(len(key)))
+	binary.LittleEndian.PutUint32(c.data[offset+5:], uint32(len(value)))
+	copy(c.data[offset+recordHeaderSize:], key)
+	copy(c.data[offset+recordHeaderSize+len(key):], value)
+	c.end += length
+	c.live += length
+	c.writeEnd()
+	c.lookup[key] = c.queue.PushFront(&entry{key: key, offset: offset})
+	if c.queue.Len() > c.capacity {
+		c.remove(c.queue.Back())
+	}
+	return nil
+}
+
+// Get will retrieve a copy of a value by key.
+// This will bump the entry as it was "recently" used.
+// A value that fails to authenticate or decompress is removed and reported as missing, as is every key once
+// the cache is closed.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	key = c.indexKey(key)
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil, false
+	}
+	item, ok := c.lookup[key]
+	if !ok {
+		c.mutex.Unlock()
+		return nil, false
+	}
+	c.queue.MoveToFront(item)
+	offset := item.Value.(*entry).offset
+	state := c.data[offset]
+	value := append([]byte(nil), c.recordValue(offset)...)
+	c.mutex.Unlock()
+	if value, err := c.decode(key, state, value); err == nil {
+		return value, true
+	}
+	c.mutex.Lock()
+	if current, ok := c.lookup[key]; ok && current == item && !c.closed {
+		c.remove(item)
+	}
+	c.mutex.Unlock()
+	return nil, false
+}
+
+// Remove an entry from the cache.
+func (c *Cache) Remove(key string) bool {
+	key = c.indexKey(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return false
+	}
+	if item, ok := c.lookup[key]; ok {
+		c.remove(item)
+		return true
+	}
+	return false
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.queue.Len()
+}
+
+// Compact rewrites the live entries into a new file, reclaiming the space of dead records.
+func (c *Cache) Compact() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	return c.compact()
+}
+
+// Close unmaps and closes the cache file, releasing its lock.
+// Written entries persist in the file once the cache is closed.
+func (c *Cache) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return nil
+	}
+	if err := syscall.Munmap(c.data); err != nil {
+		return err
+	}
+	c.closed = true
+	c.data = nil
+	return c.file.Close()
+}
+
+// indexKey returns the key as it is stored in the file and the index.
+func (c *Cache) indexKey(key string) string {
+	if c.sealer == nil {
+		return key
+	}
+	return c.sealer.indexKey(key)
+}
+
+// decode reverses the encryption and compression recorded in state.
+func (c *Cache) decode(key string, state byte, value []byte) ([]byte, error) {
+	if state&recordEncrypted != 0 {
+		if c.sealer == nil {
+			return nil, ErrKey
+		}
+		var err error
+		if value, err = c.sealer.open(key, value); err != nil {
+			return nil, err
+		}
+	}
+	if state&recordCompressed != 0 {
+		if c.options.Compressor == nil {
+			return nil, ErrCorrupt
+		}
+		return c.options.Compressor.Decompress(value)
+	}
+	return value, nil
+}
+
+// compact writes the live records to a temporary file of the same size, maps it and renames it over the cache
+// file. The cache only switches to the new file once that has all succeeded, so a failure or crash leaves the
+// old file in use and intact.
+func (c *Cache) compact() error {
+	path := c.file.Name()
+	tmp, err := os.OpenFile(path+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	fail := func(err error) error {
+		if data != nil {
+			syscall.Munmap(data)
+		}
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	// The new file is locked before it is renamed into place, so no other cache can open it in between.
+	if err := lock(tmp); err != nil {
+		return fail(err)
+	}
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	binary.LittleEndian.PutUint32(header[4:], version)
+	w := bufio.NewWriter(tmp)
+	w.Write(header)
+	end := headerSize
+	offsets := make(map[string]int, len(c.lookup))
+	for offset := headerSize; offset < c.end; {
+		length := c.recordSize(offset)
+		if c.data[offset] != recordDead {
+			w.Write(c.data[offset : offset+length])
+			offsets[string(c.recordKey(offset))] = end
+			end += length
+		}
+		offset += length
+	}
+	binary.LittleEndian.PutUint64(header[8:], uint64(end))
+	err = w.Flush()
+	if err == nil {
+		_, err = tmp.WriteAt(header, 0)
+	}
+	if err == nil {
+		err = tmp.Truncate(int64(len(c.data)))
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		data, err = syscall.Mmap(int(tmp.Fd()), 0, len(c.data), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	syncDir(filepath.Dir(path))
+	syscall.Munmap(c.data)
+	c.file.Close()
+	c.file, c.data, c.end = tmp, data, end
+	for key, offset := range offsets {
+		c.lookup[key].Value.(*entry).offset = offset
+	}
+	return nil
+}
+
+func (c *Cache) remove(item *list.Element) {
+	e := item.Value.(*entry)
+	c.queue.Remove(item)
+	delete(c.lookup, e.key)
+	c.live -= c.recordSize(e.offset)
+	c.data[e.offset] = recordDead
+}
+
+// lock takes an exclusive lock on file, failing with ErrLocked rather than waiting if another cache holds it.
+func lock(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return ErrLocked
+	}
+	return err
+}
+
+// syncDir makes a rename in dir durable. Errors are ignored since not every platform supports it.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+func (c *Cache) writeEnd() {
+	binary.LittleEndian.PutUint64(c.data[8:], uint64(c.end))
+}
+
+func (c *Cache) recordSize(offset int) int {
+	keyLen := int(binary.LittleEndian.Uint32(c.data[offset+1:]))
+	valueLen := int(binary.LittleEndian.Uint32(c.data[offset+5:]))
+	return recordHeaderSize + keyLen + valueLen
+}
+
+func (c *Cache) recordKey(offset int) []byte {
+	keyLen := int(binary.LittleEndian.Uint32(c.data[offset+1:]))
+	start := offset + recordHeaderSize
+	return c.data[start : start+keyLen]
+}
+
+func (c *Cache) recordValue(offset int) []byte {
+	keyLen := int(binary.LittleEndian.Uint32(c.data[offset+1:]))
+	valueLen := int(binary.LittleEndian.Uint32(c.data[offset+5:]))
+	start := offset + recordHeaderSize + keyLen
+	return c.data[start : start+valueLen]
+}
@@ -0,0 +1,179 @@
+//go:build unix
+
+package mmapcache_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cjsaylor/goutil/mmapcache"
+)
+
+func TestSetGet(t *testing.T) {
+	cache, err := mmapcache.Open(filepath.Join(t.TempDir(), "cache"), 4096, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	cache.Set("a", []byte("foo"))
+	cache.Set("a", []byte("bar"))
+	if val, ok := cache.Get("a"); !ok || string(val) != "bar" {
+		t.Errorf("Expected 'bar' got %s", val)
+	}
+	if !cache.Remove("a") {
+		t.Error("Expected 'a' to be removed")
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected 'a' to be removed")
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	cache, err := mmapcache.Open(filepath.Join(t.TempDir(), "cache"), 4096, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	cache.Set("a", []byte("foo"))
+	cache.Set("b", []byte("foo"))
+	cache.Get("a")
+	cache.Set("c", []byte("foo"))
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected 'b' to be evicted because 'a' was recently used")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 entries got %d", cache.Len())
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	cache, err := mmapcache.Open(path, 4096, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", []byte("foo"))
+	cache.Set("b", []byte("bar"))
+	cache.Remove("b")
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cache, err = mmapcache.Open(path, 4096, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	if val, ok := cache.Get("a"); !ok || string(val) != "foo" {
+		t.Errorf("Expected 'a' to survive reopening got %s", val)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected removed entry to stay removed after reopening")
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	cache, err := mmapcache.Open(filepath.Join(t.TempDir(), "cache"), 128, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	for i := 0; i < 100; i++ {
+		if err := cache.Set("a", []byte(fmt.Sprintf("value-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if val, ok := cache.Get("a"); !ok || string(val) != "value-99" {
+		t.Errorf("Expected 'value-99' got %s", val)
+	}
+	if err := cache.Set("b", make([]byte, 128)); err != mmapcache.ErrTooLarge {
+		t.Errorf("Expected too large error got %v", err)
+	}
+}
+
+func TestEvictWhenFull(t *testing.T) {
+	cache, err := mmapcache.Open(filepath.Join(t.TempDir(), "cache"), 64, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	for _, key := range []string{"a", "b", "c", "d"} {
+		cache.Set(key, []byte("0123456789"))
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected oldest entry to be evicted to make room")
+	}
+	if val, ok := cache.Get("d"); !ok || string(val) != "0123456789" {
+		t.Errorf("Expected 'd' to be stored got %s", val)
+	}
+}
+
+func TestCompactPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	cache, err := mmapcache.Open(path, 4096, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", []byte("foo"))
+	cache.Set("b", []byte("bar"))
+	cache.Remove("a")
+	if err := cache.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("c", []byte("baz"))
+	cache.Close()
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected no temporary file to be left got %v", err)
+	}
+	cache, err = mmapcache.Open(path, 4096, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	if val, ok := cache.Get("b"); !ok || string(val) != "bar" || cache.Len() != 2 {
+		t.Errorf("Expected the compacted entries to survive reopening got %s", val)
+	}
+}
+
+func TestLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	cache, err := mmapcache.Open(path, 4096, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mmapcache.Open(path, 4096, 10); err != mmapcache.ErrLocked {
+		t.Errorf("Expected a second open to fail with ErrLocked got %v", err)
+	}
+	cache.Compact()
+	if _, err := mmapcache.Open(path, 4096, 10); err != mmapcache.ErrLocked {
+		t.Errorf("Expected the compacted file to stay locked got %v", err)
+	}
+	cache.Close()
+	cache, err = mmapcache.Open(path, 4096, 10)
+	if err != nil {
+		t.Fatalf("Expected close to release the lock got %v", err)
+	}
+	cache.Close()
+}
+
+func TestClosed(t *testing.T) {
+	cache, err := mmapcache.Open(filepath.Join(t.TempDir(), "cache"), 4096, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", []byte("foo"))
+	cache.Close()
+	if err := cache.Set("b", []byte("bar")); err != mmapcache.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+	if err := cache.Compact(); err != mmapcache.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+	if _, ok := cache.Get("a"); ok || cache.Remove("a") {
+		t.Error("Expected a closed cache to hold nothing")
+	}
+	if err := cache.Close(); err != nil {
+		t.Errorf("Expected a second close to succeed got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+// Package movavg is a package that provides moving averages safe for concurrent update.
+package movavg
+
+import (
+	"math"
+	"sync"
+)
+
+// Average is a moving average of a stream of samples.
+type Average interface {
+	// Add records a sample.
+	Add(value float64)
+	// Value returns the current average, or zero before any sample.
+	Value() float64
+}
+
+// window is a ring of the most recent samples.
+type window struct {
+	samples []float64
+	next    int
+	full    bool
+}
+
+func newWindow(size int) window {
+	if size < 1 {
+		size = 1
+	}
+	return window{samples: make([]float64, size)}
+}
+
+// add stores value and returns the sample it replaced, if any.
+func (w *window) add(value float64) (float64, bool) {
+	old, evicted := w.samples[w.next], w.full
+	w.samples[w.next] = value
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+	return old, evicted
+}
+
+func (w *window) len() int {
+	if w.full {
+		return len(w.samples)
+	}
+	return w.next
+}
+
+// Simple is the unweighted mean of the last n samples.
+type Simple struct {
+	mutex  *sync.Mutex
+	window window
+	sum    float64
+}
+
+// NewSimple creates a simple moving average over the last size samples.
+func NewSimple(size int) *Simple {
+	return &Simple{mutex: &sync.Mutex{}, window: newWindow(size)}
+}
+
+// Add records a sample.
+func (s *Simple) Add(value float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	old, evicted := s.window.add(value)
+	// Subtracting an infinite or NaN sample cannot undo adding it, and rounding error builds up over
+	// time, so the sum is recomputed when such a sample leaves and once per pass around the window.
+	if s.window.next == 0 || (evicted && (math.IsInf(old, 0) || math.IsNaN(old))) {
+		s.sum = 0
+		for _, sample := range s.window.samples[:s.window.len()] {
+			s.sum += sample
+		}
+		return
+	}
+	s.sum += value
+	if evicted {
+		s.sum -= old
+	}
+}
+
+// Value returns the mean of the samples in the window.
+func (s *Simple) Value() float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := s.window.len()
+	if n == 0 {
+		return 0
+	}
+	return s.sum / float64(n)
+}
+
+// Weighted is a linearly weighted mean of the last n samples: the newest sample has weight n, the
+// one before it n-1, and so on.
+type Weighted struct {
+	mutex  *sync.Mutex
+	window window
+}
+
+// NewWeighted creates a weighted moving average over the last size samples.
+func NewWeighted(size int) *Weighted {
+	return &Weighted{mutex: &sync.Mutex{}, window: newWindow(size)}
+}
+
+// Add records a sample.
+func (w *Weighted) Add(value float64) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.window.add(value)
+}
+
+// Value returns the weighted mean of the samples in the window.
+func (w *Weighted) Value() float64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	n := w.window.len()
+	if n == 0 {
+		return 0
+	}
+	size := len(w.window.samples)
+	var sum, weights float64
+	for i := 1; i <= n; i++ {
+		// Walk back from the newest sample.
+		sample := w.window.samples[(w.window.next-i+size)%size]
+		weight := float64(n - i + 1)
+		sum += sample * weight
+		weights += weight
+	}
+	return sum / weights
+}
+
+// EWMA is an exponentially weighted moving average.
+type EWMA struct {
+	mutex *sync.Mutex
+	alpha float64
+	value float64
+	seen  bool
+}
+
+// NewEWMA creates an exponentially weighted moving average where each sample contributes alpha,
+// between zero and one, of the new value. The first sample sets the average outright. It panics if
+// alpha is not greater than zero and at most one.
+func NewEWMA(alpha float64) *EWMA {
+	if !(alpha > 0 && alpha <= 1) {
+		panic("movavg: alpha must be in (0, 1]")
+	}
+	return &EWMA{mutex: &sync.Mutex{}, alpha: alpha}
+}
+
+// NewEWMASpan creates an exponentially weighted moving average roughly equivalent to a simple
+// moving average over span samples. It panics if span is less than one.
+func NewEWMASpan(span int) *EWMA {
+	return NewEWMA(2 / (float64(span) + 1))
+}
+
+// Add records a sample.
+func (e *EWMA) Add(value float64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !e.seen {
+		e.value = value
+		e.seen = true
+		return
+	}
+	e.value += e.alpha * (value - e.value)
+}
+
+// Value returns the current average.
+func (e *EWMA) Value() float64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.value
+}
+
+// Set replaces the current average, as if it were the first sample.
+func (e *EWMA) Set(value float64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.value = value
+	e.seen = true
+}
@@ -0,0 +1,120 @@
+package movavg_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/movavg"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestSimple(t *testing.T) {
+	s := movavg.NewSimple(3)
+	if s.Value() != 0 {
+		t.Errorf("Expected 0 got %v", s.Value())
+	}
+	s.Add(1)
+	s.Add(2)
+	if !near(s.Value(), 1.5) {
+		t.Errorf("Expected 1.5 got %v", s.Value())
+	}
+	s.Add(3)
+	s.Add(10)
+	if !near(s.Value(), 5) {
+		t.Errorf("Expected 5 got %v", s.Value())
+	}
+}
+
+func TestSimpleRecovers(t *testing.T) {
+	s := movavg.NewSimple(2)
+	s.Add(math.Inf(1))
+	s.Add(1)
+	s.Add(2)
+	s.Add(3)
+	if !near(s.Value(), 2.5) {
+		t.Errorf("Expected the average to recover once the infinite sample left got %v", s.Value())
+	}
+	s.Add(math.NaN())
+	s.Add(4)
+	s.Add(6)
+	if !near(s.Value(), 5) {
+		t.Errorf("Expected the average to recover once the NaN sample left got %v", s.Value())
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	w := movavg.NewWeighted(3)
+	w.Add(1)
+	w.Add(2)
+	// (1*1 + 2*2) / 3
+	if !near(w.Value(), 5.0/3) {
+		t.Errorf("Expected %v got %v", 5.0/3, w.Value())
+	}
+	w.Add(3)
+	w.Add(4)
+	// (2*1 + 3*2 + 4*3) / 6
+	if !near(w.Value(), 20.0/6) {
+		t.Errorf("Expected %v got %v", 20.0/6, w.Value())
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	e := movavg.NewEWMA(0.5)
+	e.Add(10)
+	if e.Value() != 10 {
+		t.Errorf("Expected the first sample to set the average got %v", e.Value())
+	}
+	e.Add(20)
+	e.Add(20)
+	if !near(e.Value(), 17.5) {
+		t.Errorf("Expected 17.5 got %v", e.Value())
+	}
+	e.Set(0)
+	e.Add(4)
+	if !near(e.Value(), 2) {
+		t.Errorf("Expected 2 got %v", e.Value())
+	}
+	if span := movavg.NewEWMASpan(9); span == nil {
+		t.Error("Expected an average")
+	}
+}
+
+func TestEWMAInvalidAlpha(t *testing.T) {
+	for _, alpha := range []float64{0, -0.5, 1.5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected an alpha of %v to panic", alpha)
+				}
+			}()
+			movavg.NewEWMA(alpha)
+		}()
+	}
+}
+
+func TestConcurrentAdd(t *testing.T) {
+	averages := []movavg.Average{movavg.NewSimple(10), movavg.NewWeighted(10), movavg.NewEWMA(0.1)}
+	wg := sync.WaitGroup{}
+	for _, a := range averages {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(a movavg.Average) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					a.Add(7)
+					a.Value()
+				}
+			}(a)
+		}
+	}
+	wg.Wait()
+	for _, a := range averages {
+		if !near(a.Value(), 7) {
+			t.Errorf("Expected 7 got %v", a.Value())
+		}
+	}
+}
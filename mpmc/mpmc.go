@@ -0,0 +1,106 @@
+// Package mpmc is a package that implements a lock-free bounded multi-producer multi-consumer queue.
+//
+// The queue is a ring of cells, each carrying a sequence number that tells producers and consumers
+// whether the cell is ready for them (Dmitry Vyukov's bounded MPMC queue). Neither side ever blocks;
+// callers that need to wait should back off and retry, or use a channel or blockingqueue instead.
+package mpmc
+
+import (
+	"sync/atomic"
+)
+
+const cacheLineSize = 64
+
+type cell[T any] struct {
+	sequence atomic.Uint64
+	value    T
+}
+
+// Queue is a bounded FIFO queue that is safe for concurrent use without locks.
+type Queue[T any] struct {
+	_       [cacheLineSize]byte
+	enqueue atomic.Uint64
+	_       [cacheLineSize - 8]byte
+	dequeue atomic.Uint64
+	_       [cacheLineSize - 8]byte
+	cells   []cell[T]
+	mask    uint64
+}
+
+// New creates an empty queue. The capacity is rounded up to a power of two.
+func New[T any](capacity int) *Queue[T] {
+	size := uint64(2)
+	for size < uint64(capacity) {
+		size <<= 1
+	}
+	q := &Queue[T]{
+		cells: make([]cell[T], size),
+		mask:  size - 1,
+	}
+	for i := range q.cells {
+		q.cells[i].sequence.Store(uint64(i))
+	}
+	return q
+}
+
+// Enqueue adds an item to the back of the queue, reporting false if the queue is full.
+func (q *Queue[T]) Enqueue(item T) bool {
+	pos := q.enqueue.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		diff := int64(c.sequence.Load()) - int64(pos)
+		switch {
+		case diff == 0:
+			if q.enqueue.CompareAndSwap(pos, pos+1) {
+				c.value = item
+				c.sequence.Store(pos + 1)
+				return true
+			}
+			pos = q.enqueue.Load()
+		case diff < 0:
+			return false
+		default:
+			pos = q.enqueue.Load()
+		}
+	}
+}
+
+// Dequeue removes and returns the item at the front of the queue, reporting false if the queue is empty.
+func (q *Queue[T]) Dequeue() (T, bool) {
+	pos := q.dequeue.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		diff := int64(c.sequence.Load()) - int64(pos+1)
+		switch {
+		case diff == 0:
+			if q.dequeue.CompareAndSwap(pos, pos+1) {
+				item := c.value
+				var zero T
+				c.value = zero
+				c.sequence.Store(pos + q.mask + 1)
+				return item, true
+			}
+			pos = q.dequeue.Load()
+		case diff < 0:
+			var zero T
+			return zero, false
+		default:
+			pos = q.dequeue.Load()
+		}
+	}
+}
+
+// Len returns the approximate number of items in the queue.
+func (q *Queue[T]) Len() int {
+	dequeue := q.dequeue.Load()
+	enqueue := q.enqueue.Load()
+	if enqueue < dequeue {
+		return 0
+	}
+	return int(enqueue - dequeue)
+}
+
+// Cap returns the capacity of the queue.
+func (q *Queue[T]) Cap() int {
+	return len(q.cells)
+}
@@ -0,0 +1,111 @@
+package mpmc_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/mpmc"
+)
+
+func TestEnqueueDequeue(t *testing.T) {
+	q := mpmc.New[int](3)
+	if q.Cap() != 4 {
+		t.Errorf("Expected capacity to round up to 4 got %d", q.Cap())
+	}
+	for i := 0; i < 4; i++ {
+		if !q.Enqueue(i) {
+			t.Fatalf("Expected %d to be enqueued", i)
+		}
+	}
+	if q.Enqueue(4) {
+		t.Error("Expected enqueue into a full queue to fail")
+	}
+	for i := 0; i < 4; i++ {
+		if item, ok := q.Dequeue(); !ok || item != i {
+			t.Fatalf("Expected %d got %d", i, item)
+		}
+	}
+	if _, ok := q.Dequeue(); ok {
+		t.Error("Expected dequeue from an empty queue to fail")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	const producers, perProducer = 4, 1000
+	q := mpmc.New[int](64)
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				for !q.Enqueue(p*perProducer + i) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+	seen := make([]bool, producers*perProducer)
+	var mutex sync.Mutex
+	var consumers sync.WaitGroup
+	remaining := producers * perProducer
+	for c := 0; c < 4; c++ {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			for {
+				mutex.Lock()
+				if remaining == 0 {
+					mutex.Unlock()
+					return
+				}
+				mutex.Unlock()
+				item, ok := q.Dequeue()
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				mutex.Lock()
+				if seen[item] {
+					t.Errorf("Expected %d to be dequeued once", item)
+				}
+				seen[item] = true
+				remaining--
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	consumers.Wait()
+	if q.Len() != 0 {
+		t.Errorf("Expected an empty queue got %d", q.Len())
+	}
+}
+
+func BenchmarkQueue(b *testing.B) {
+	q := mpmc.New[int](1024)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			for !q.Enqueue(1) {
+				runtime.Gosched()
+			}
+			for {
+				if _, ok := q.Dequeue(); ok {
+					break
+				}
+				runtime.Gosched()
+			}
+		}
+	})
+}
+
+func BenchmarkChannel(b *testing.B) {
+	ch := make(chan int, 1024)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ch <- 1
+			<-ch
+		}
+	})
+}
@@ -0,0 +1,195 @@
+// Package multimap is a package that implements maps associating each key with multiple values.
+package multimap
+
+import (
+	"github.com/cjsaylor/goutil/set"
+)
+
+// MultiMap associates each key with a collection of values.
+type MultiMap[K comparable, V comparable] interface {
+	// Put associates value with key.
+	Put(key K, value V)
+	// Get returns the values associated with key.
+	Get(key K) []V
+	// Contains reports whether value is associated with key.
+	Contains(key K, value V) bool
+	// RemoveValue removes an association of value with key, reporting whether one existed.
+	RemoveValue(key K, value V) bool
+	// RemoveKey removes key and all of its values, returning the removed values.
+	RemoveKey(key K) []V
+	// Len returns the total number of key/value pairs.
+	Len() int
+	// Keys returns the keys with at least one value.
+	Keys() []K
+	// Each calls fn for every key/value pair. Iteration stops early if fn returns false.
+	Each(fn func(key K, value V) bool)
+}
+
+// Slice is a multimap that keeps values per key in insertion order and allows duplicates.
+// It is not safe for concurrent use.
+type Slice[K comparable, V comparable] struct {
+	values map[K][]V
+	size   int
+}
+
+// NewSlice creates an empty slice-backed multimap.
+func NewSlice[K comparable, V comparable]() *Slice[K, V] {
+	return &Slice[K, V]{values: make(map[K][]V)}
+}
+
+// Put appends value to the values of key.
+func (m *Slice[K, V]) Put(key K, value V) {
+	m.values[key] = append(m.values[key], value)
+	m.size++
+}
+
+// Get returns a copy of the values of key in insertion order.
+func (m *Slice[K, V]) Get(key K) []V {
+	return append([]V(nil), m.values[key]...)
+}
+
+// Contains reports whether value is associated with key.
+func (m *Slice[K, V]) Contains(key K, value V) bool {
+	for _, v := range m.values[key] {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveValue removes the first association of value with key.
+func (m *Slice[K, V]) RemoveValue(key K, value V) bool {
+	values := m.values[key]
+	for i, v := range values {
+		if v == value {
+			values = append(values[:i], values[i+1:]...)
+			if len(values) == 0 {
+				delete(m.values, key)
+			} else {
+				m.values[key] = values
+			}
+			m.size--
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveKey removes key and all of its values.
+func (m *Slice[K, V]) RemoveKey(key K) []V {
+	values := m.values[key]
+	delete(m.values, key)
+	m.size -= len(values)
+	return values
+}
+
+// Len returns the total number of key/value pairs.
+func (m *Slice[K, V]) Len() int {
+	return m.size
+}
+
+// Keys returns the keys with at least one value in no particular order.
+func (m *Slice[K, V]) Keys() []K {
+	ret := make([]K, 0, len(m.values))
+	for key := range m.values {
+		ret = append(ret, key)
+	}
+	return ret
+}
+
+// Each calls fn for every key/value pair. Keys are visited in no particular order.
+func (m *Slice[K, V]) Each(fn func(key K, value V) bool) {
+	for key, values := range m.values {
+		for _, value := range values {
+			if !fn(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// Set is a multimap that keeps a set of distinct values per key.
+// It is not safe for concurrent use.
+type Set[K comparable, V comparable] struct {
+	values map[K]set.Set[V]
+	size   int
+}
+
+// NewSet creates an empty set-backed multimap.
+func NewSet[K comparable, V comparable]() *Set[K, V] {
+	return &Set[K, V]{values: make(map[K]set.Set[V])}
+}
+
+// Put adds value to the values of key. Adding an existing association has no effect.
+func (m *Set[K, V]) Put(key K, value V) {
+	values, ok := m.values[key]
+	if !ok {
+		values = set.New[V]()
+		m.values[key] = values
+	}
+	if !values.Contains(value) {
+		values.Add(value)
+		m.size++
+	}
+}
+
+// Get returns the values of key in no particular order.
+func (m *Set[K, V]) Get(key K) []V {
+	if values, ok := m.values[key]; ok {
+		return values.Items()
+	}
+	return nil
+}
+
+// Contains reports whether value is associated with key.
+func (m *Set[K, V]) Contains(key K, value V) bool {
+	return m.values[key].Contains(value)
+}
+
+// RemoveValue removes the association of value with key.
+func (m *Set[K, V]) RemoveValue(key K, value V) bool {
+	values, ok := m.values[key]
+	if !ok || !values.Contains(value) {
+		return false
+	}
+	values.Remove(value)
+	if values.Len() == 0 {
+		delete(m.values, key)
+	}
+	m.size--
+	return true
+}
+
+// RemoveKey removes key and all of its values.
+func (m *Set[K, V]) RemoveKey(key K) []V {
+	values := m.Get(key)
+	delete(m.values, key)
+	m.size -= len(values)
+	return values
+}
+
+// Len returns the total number of key/value pairs.
+func (m *Set[K, V]) Len() int {
+	return m.size
+}
+
+// Keys returns the keys with at least one value in no particular order.
+func (m *Set[K, V]) Keys() []K {
+	ret := make([]K, 0, len(m.values))
+	for key := range m.values {
+		ret = append(ret, key)
+	}
+	return ret
+}
+
+// Each calls fn for every key/value pair in no particular order.
+func (m *Set[K, V]) Each(fn func(key K, value V) bool) {
+	for key, values := range m.values {
+		for value := range values {
+			if !fn(key, value) {
+				return
+			}
+		}
+	}
+}
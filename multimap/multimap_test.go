@@ -0,0 +1,62 @@
+package multimap_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cjsaylor/goutil/multimap"
+)
+
+func TestSlice(t *testing.T) {
+	m := multimap.NewSlice[string, int]()
+	m.Put("a", 2)
+	m.Put("a", 1)
+	m.Put("a", 2)
+	m.Put("b", 3)
+	if values := m.Get("a"); !reflect.DeepEqual(values, []int{2, 1, 2}) {
+		t.Errorf("Expected [2 1 2] got %v", values)
+	}
+	if !m.RemoveValue("a", 2) || m.Len() != 3 {
+		t.Error("Expected a single association to be removed")
+	}
+	if values := m.Get("a"); !reflect.DeepEqual(values, []int{1, 2}) {
+		t.Errorf("Expected [1 2] got %v", values)
+	}
+	if values := m.RemoveKey("a"); len(values) != 2 || m.Len() != 1 {
+		t.Errorf("Expected key removal to drop 2 values got %v", values)
+	}
+}
+
+func TestSet(t *testing.T) {
+	m := multimap.NewSet[string, int]()
+	m.Put("a", 2)
+	m.Put("a", 1)
+	m.Put("a", 2)
+	values := m.Get("a")
+	sort.Ints(values)
+	if !reflect.DeepEqual(values, []int{1, 2}) || m.Len() != 2 {
+		t.Errorf("Expected [1 2] got %v", values)
+	}
+	m.RemoveValue("a", 1)
+	m.RemoveValue("a", 2)
+	if m.Contains("a", 2) || len(m.Keys()) != 0 {
+		t.Error("Expected key to be dropped once it has no values")
+	}
+}
+
+func TestEach(t *testing.T) {
+	for _, m := range []multimap.MultiMap[string, int]{multimap.NewSlice[string, int](), multimap.NewSet[string, int]()} {
+		m.Put("a", 1)
+		m.Put("b", 2)
+		m.Put("b", 3)
+		pairs := 0
+		m.Each(func(key string, value int) bool {
+			pairs++
+			return true
+		})
+		if pairs != 3 {
+			t.Errorf("Expected 3 pairs from %T got %d", m, pairs)
+		}
+	}
+}
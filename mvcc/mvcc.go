@@ -0,0 +1,169 @@
+// Package mvcc is a package that implements a multi-version key-value store.
+//
+// Every write produces a new revision. Readers open a snapshot at any retained revision and see
+// a consistent view of the store as of that revision, no matter what is written afterwards.
+// Revisions share structure through an immutable map, so retaining history is cheap.
+package mvcc
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/cjsaylor/goutil/immutable"
+)
+
+var (
+	// ErrCompacted is returned when opening a revision that is no longer retained.
+	ErrCompacted = errors.New("mvcc: revision has been compacted")
+	// ErrFutureRevision is returned when opening a revision that has not been written yet.
+	ErrFutureRevision = errors.New("mvcc: revision is in the future")
+)
+
+// Snapshot is a read-only view of the store at a single revision. It is safe for concurrent use.
+type Snapshot[K comparable, V any] struct {
+	revision int64
+	data     *immutable.Map[K, V]
+}
+
+// Revision returns the revision the snapshot was taken at.
+func (s *Snapshot[K, V]) Revision() int64 {
+	return s.revision
+}
+
+// Get will retrieve a value by key.
+func (s *Snapshot[K, V]) Get(key K) (V, bool) {
+	return s.data.Get(key)
+}
+
+// Len returns the number of entries.
+func (s *Snapshot[K, V]) Len() int {
+	return s.data.Len()
+}
+
+// Each calls fn for every entry in no particular order.
+// Iteration stops early if fn returns false.
+func (s *Snapshot[K, V]) Each(fn func(key K, value V) bool) {
+	s.data.Each(fn)
+}
+
+// Txn collects the writes applied as a single revision by Store.Update.
+type Txn[K comparable, V any] struct {
+	data *immutable.Map[K, V]
+}
+
+// Get will retrieve a value by key, including writes made earlier in the transaction.
+func (t *Txn[K, V]) Get(key K) (V, bool) {
+	return t.data.Get(key)
+}
+
+// Set a key/value within the transaction.
+func (t *Txn[K, V]) Set(key K, value V) {
+	t.data = t.data.Set(key, value)
+}
+
+// Delete an entry within the transaction.
+func (t *Txn[K, V]) Delete(key K) {
+	t.data = t.data.Delete(key)
+}
+
+// Store is a versioned key-value store that is safe for concurrent use.
+type Store[K comparable, V any] struct {
+	history []*Snapshot[K, V]
+	retain  int
+	mutex   *sync.RWMutex
+}
+
+// New creates an empty store at revision zero that retains the latest retain revisions.
+// A retain of zero or less keeps all history until Compact is called.
+func New[K comparable, V any](retain int) *Store[K, V] {
+	return &Store[K, V]{
+		history: []*Snapshot[K, V]{{revision: 0, data: immutable.New[K, V]()}},
+		retain:  retain,
+		mutex:   &sync.RWMutex{},
+	}
+}
+
+// Set a key/value, returning the new revision.
+func (s *Store[K, V]) Set(key K, value V) int64 {
+	return s.Update(func(txn *Txn[K, V]) {
+		txn.Set(key, value)
+	})
+}
+
+// Delete an entry, returning the new revision.
+func (s *Store[K, V]) Delete(key K) int64 {
+	return s.Update(func(txn *Txn[K, V]) {
+		txn.Delete(key)
+	})
+}
+
+// Update applies the writes made by fn atomically as a single new revision, which is returned.
+func (s *Store[K, V]) Update(fn func(txn *Txn[K, V])) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	latest := s.history[len(s.history)-1]
+	txn := &Txn[K, V]{data: latest.data}
+	fn(txn)
+	next := &Snapshot[K, V]{revision: latest.revision + 1, data: txn.data}
+	s.history = append(s.history, next)
+	if s.retain > 0 && len(s.history) > s.retain {
+		s.drop(len(s.history) - s.retain)
+	}
+	return next.revision
+}
+
+// Snapshot returns a view of the latest revision.
+func (s *Store[K, V]) Snapshot() *Snapshot[K, V] {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.history[len(s.history)-1]
+}
+
+// SnapshotAt returns a view of the given revision.
+func (s *Store[K, V]) SnapshotAt(revision int64) (*Snapshot[K, V], error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	oldest := s.history[0].revision
+	switch {
+	case revision < oldest:
+		return nil, ErrCompacted
+	case revision > s.history[len(s.history)-1].revision:
+		return nil, ErrFutureRevision
+	}
+	return s.history[revision-oldest], nil
+}
+
+// Revision returns the latest revision.
+func (s *Store[K, V]) Revision() int64 {
+	return s.Snapshot().revision
+}
+
+// OldestRevision returns the oldest revision still retained.
+func (s *Store[K, V]) OldestRevision() int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.history[0].revision
+}
+
+// Compact discards every revision older than revision. The latest revision is always retained.
+// Snapshots that are already open remain readable.
+func (s *Store[K, V]) Compact(revision int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	oldest := s.history[0].revision
+	drop := int(revision - oldest)
+	if drop <= 0 {
+		return
+	}
+	if drop > len(s.history)-1 {
+		drop = len(s.history) - 1
+	}
+	s.drop(drop)
+}
+
+// drop discards the n oldest revisions. Their slots are cleared so the snapshots can be collected, and
+// append copies only the retained revisions once it outgrows the array, so writes stay amortized O(1).
+func (s *Store[K, V]) drop(n int) {
+	clear(s.history[:n])
+	s.history = s.history[n:]
+}
@@ -0,0 +1,76 @@
+package mvcc_test
+
+import (
+	"testing"
+
+	"github.com/cjsaylor/goutil/mvcc"
+)
+
+func TestSnapshots(t *testing.T) {
+	s := mvcc.New[string, int](0)
+	first := s.Set("a", 1)
+	s.Set("a", 2)
+	s.Delete("a")
+	old, err := s.SnapshotAt(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, ok := old.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected 'a' to be 1 at revision %d got %v", first, val)
+	}
+	if _, ok := s.Snapshot().Get("a"); ok {
+		t.Error("Expected 'a' to be deleted at the latest revision")
+	}
+	if s.Revision() != 3 {
+		t.Errorf("Expected revision 3 got %d", s.Revision())
+	}
+	if _, err := s.SnapshotAt(4); err != mvcc.ErrFutureRevision {
+		t.Errorf("Expected future revision error got %v", err)
+	}
+}
+
+func TestUpdateIsOneRevision(t *testing.T) {
+	s := mvcc.New[string, int](0)
+	revision := s.Update(func(txn *mvcc.Txn[string, int]) {
+		txn.Set("a", 1)
+		txn.Set("b", 2)
+		if val, _ := txn.Get("a"); val != 1 {
+			t.Error("Expected the transaction to see its own writes")
+		}
+	})
+	if revision != 1 || s.Snapshot().Len() != 2 {
+		t.Errorf("Expected both writes at revision 1 got revision %d", revision)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	s := mvcc.New[string, int](3)
+	for i := 0; i < 10; i++ {
+		s.Set("a", i)
+	}
+	if s.OldestRevision() != 8 {
+		t.Errorf("Expected oldest retained revision 8 got %d", s.OldestRevision())
+	}
+	if _, err := s.SnapshotAt(7); err != mvcc.ErrCompacted {
+		t.Errorf("Expected compacted error got %v", err)
+	}
+	snapshot, _ := s.SnapshotAt(8)
+	if val, _ := snapshot.Get("a"); val != 7 {
+		t.Errorf("Expected 'a' to be 7 at revision 8 got %d", val)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	s := mvcc.New[string, int](0)
+	for i := 0; i < 5; i++ {
+		s.Set("a", i)
+	}
+	open, _ := s.SnapshotAt(2)
+	s.Compact(100)
+	if s.OldestRevision() != 5 {
+		t.Errorf("Expected only the latest revision to remain got %d", s.OldestRevision())
+	}
+	if val, _ := open.Get("a"); val != 1 {
+		t.Error("Expected an open snapshot to remain readable after compaction")
+	}
+}
@@ -0,0 +1,130 @@
+// Package opt is a package that provides Option and Result types.
+//
+// An Option makes "no value" explicit instead of overloading a nil pointer, and encodes to and
+// from JSON null. A Result pairs a value with the error that may have prevented it.
+package opt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Option holds a value or nothing. The zero value holds nothing.
+type Option[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some returns an Option holding value.
+func Some[T any](value T) Option[T] {
+	return Option[T]{value: value, ok: true}
+}
+
+// None returns an Option holding nothing.
+func None[T any]() Option[T] {
+	return Option[T]{}
+}
+
+// FromPointer returns an Option holding *p, or nothing if p is nil.
+func FromPointer[T any](p *T) Option[T] {
+	if p == nil {
+		return None[T]()
+	}
+	return Some(*p)
+}
+
+// IsSome reports whether o holds a value.
+func (o Option[T]) IsSome() bool {
+	return o.ok
+}
+
+// IsNone reports whether o holds nothing.
+func (o Option[T]) IsNone() bool {
+	return !o.ok
+}
+
+// Get returns the value and whether there is one.
+func (o Option[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// Unwrap returns the value. It panics if o holds nothing.
+func (o Option[T]) Unwrap() T {
+	if !o.ok {
+		panic("opt: Unwrap called on None")
+	}
+	return o.value
+}
+
+// OrElse returns the value, or fallback if o holds nothing.
+func (o Option[T]) OrElse(fallback T) T {
+	if !o.ok {
+		return fallback
+	}
+	return o.value
+}
+
+// OrElseFunc returns the value, or the result of fallback if o holds nothing.
+func (o Option[T]) OrElseFunc(fallback func() T) T {
+	if !o.ok {
+		return fallback()
+	}
+	return o.value
+}
+
+// Pointer returns a pointer to a copy of the value, or nil if o holds nothing.
+func (o Option[T]) Pointer() *T {
+	if !o.ok {
+		return nil
+	}
+	value := o.value
+	return &value
+}
+
+// String formats the value as Some(value) or None.
+func (o Option[T]) String() string {
+	if !o.ok {
+		return "None"
+	}
+	return fmt.Sprintf("Some(%v)", o.value)
+}
+
+// MarshalJSON encodes the value, or null if o holds nothing.
+func (o Option[T]) MarshalJSON() ([]byte, error) {
+	if !o.ok {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON decodes null as nothing and anything else as the value.
+// A field missing from the JSON entirely also leaves the Option holding nothing.
+func (o *Option[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = None[T]()
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*o = Some(value)
+	return nil
+}
+
+// Map applies fn to the value of o, if there is one.
+func Map[T, R any](o Option[T], fn func(T) R) Option[R] {
+	if !o.ok {
+		return None[R]()
+	}
+	return Some(fn(o.value))
+}
+
+// FlatMap applies fn to the value of o, if there is one, and returns its Option.
+func FlatMap[T, R any](o Option[T], fn func(T) Option[R]) Option[R] {
+	if !o.ok {
+		return None[R]()
+	}
+	return fn(o.value)
+}
@@ -0,0 +1,87 @@
+package opt_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/opt"
+)
+
+func TestOption(t *testing.T) {
+	some := opt.Some(42)
+	if v, ok := some.Get(); !ok || v != 42 || !some.IsSome() || some.Unwrap() != 42 {
+		t.Errorf("Expected Some(42) got %v", some)
+	}
+	none := opt.None[int]()
+	if none.IsSome() || !none.IsNone() || none.OrElse(7) != 7 || none.OrElseFunc(func() int { return 8 }) != 8 {
+		t.Errorf("Expected None got %v", none)
+	}
+	var zero opt.Option[int]
+	if zero.IsSome() {
+		t.Error("Expected the zero Option to hold nothing")
+	}
+	if some.String() != "Some(42)" || none.String() != "None" {
+		t.Errorf("Expected Some(42) and None got %s and %s", some, none)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Unwrap on None to panic")
+		}
+	}()
+	none.Unwrap()
+}
+
+func TestPointers(t *testing.T) {
+	n := 5
+	if o := opt.FromPointer(&n); o.Unwrap() != 5 || *o.Pointer() != 5 {
+		t.Errorf("Expected Some(5) got %v", o)
+	}
+	if o := opt.FromPointer[int](nil); o.IsSome() || o.Pointer() != nil {
+		t.Errorf("Expected None got %v", o)
+	}
+}
+
+func TestMap(t *testing.T) {
+	if o := opt.Map(opt.Some(2), strconv.Itoa); o.Unwrap() != "2" {
+		t.Errorf("Expected Some(2) got %v", o)
+	}
+	if o := opt.Map(opt.None[int](), strconv.Itoa); o.IsSome() {
+		t.Errorf("Expected None got %v", o)
+	}
+	half := func(n int) opt.Option[int] {
+		if n%2 != 0 {
+			return opt.None[int]()
+		}
+		return opt.Some(n / 2)
+	}
+	if opt.FlatMap(opt.Some(4), half).Unwrap() != 2 || opt.FlatMap(opt.Some(3), half).IsSome() {
+		t.Error("Expected FlatMap to use the function's Option")
+	}
+}
+
+type profile struct {
+	Name     string             `json:"name"`
+	Nickname opt.Option[string] `json:"nickname"`
+	Age      opt.Option[int]    `json:"age"`
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(profile{Name: "a", Nickname: opt.Some("b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"name":"a","nickname":"b","age":null}` {
+		t.Errorf("Expected None to encode as null got %s", data)
+	}
+	var decoded profile
+	if err := json.Unmarshal([]byte(`{"name":"a","nickname":null,"age":30}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Nickname.IsSome() || decoded.Age.Unwrap() != 30 {
+		t.Errorf("Expected no nickname and an age of 30 got %+v", decoded)
+	}
+	if err := json.Unmarshal([]byte(`{"age":"old"}`), &decoded); err == nil {
+		t.Error("Expected an error for a mistyped value")
+	}
+}
@@ -0,0 +1,84 @@
+package opt
+
+import (
+	"fmt"
+)
+
+// Result holds a value or the error that prevented it.
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Ok returns a successful Result holding value.
+func Ok[T any](value T) Result[T] {
+	return Result[T]{value: value}
+}
+
+// Err returns a failed Result holding err.
+func Err[T any](err error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// Try wraps the usual (value, error) return pair in a Result.
+func Try[T any](value T, err error) Result[T] {
+	if err != nil {
+		return Err[T](err)
+	}
+	return Ok(value)
+}
+
+// IsOk reports whether r succeeded.
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// Err returns the error, or nil if r succeeded.
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Get returns the value and error as a pair.
+func (r Result[T]) Get() (T, error) {
+	return r.value, r.err
+}
+
+// Unwrap returns the value. It panics with the error if r failed.
+func (r Result[T]) Unwrap() T {
+	if r.err != nil {
+		panic(fmt.Sprintf("opt: Unwrap called on Err: %v", r.err))
+	}
+	return r.value
+}
+
+// OrElse returns the value, or fallback if r failed.
+func (r Result[T]) OrElse(fallback T) T {
+	if r.err != nil {
+		return fallback
+	}
+	return r.value
+}
+
+// Option returns the value as an Option, discarding the error.
+func (r Result[T]) Option() Option[T] {
+	if r.err != nil {
+		return None[T]()
+	}
+	return Some(r.value)
+}
+
+// MapResult applies fn to the value of r if it succeeded.
+func MapResult[T, R any](r Result[T], fn func(T) R) Result[R] {
+	if r.err != nil {
+		return Err[R](r.err)
+	}
+	return Ok(fn(r.value))
+}
+
+// AndThen applies fn to the value of r if it succeeded and returns its Result.
+func AndThen[T, R any](r Result[T], fn func(T) (R, error)) Result[R] {
+	if r.err != nil {
+		return Err[R](r.err)
+	}
+	return Try(fn(r.value))
+}
@@ -0,0 +1,42 @@
+package opt_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/opt"
+)
+
+func TestResult(t *testing.T) {
+	ok := opt.Ok(1)
+	if !ok.IsOk() || ok.Err() != nil || ok.Unwrap() != 1 || ok.OrElse(2) != 1 {
+		t.Errorf("Expected Ok(1) got %+v", ok)
+	}
+	failed := errors.New("failed")
+	bad := opt.Err[int](failed)
+	if bad.IsOk() || bad.Err() != failed || bad.OrElse(2) != 2 || bad.Option().IsSome() {
+		t.Errorf("Expected Err(failed) got %+v", bad)
+	}
+	if v, err := opt.Try(strconv.Atoi("12")).Get(); v != 12 || err != nil {
+		t.Errorf("Expected 12 got %v (%v)", v, err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Unwrap on Err to panic")
+		}
+	}()
+	bad.Unwrap()
+}
+
+func TestResultChaining(t *testing.T) {
+	parsed := opt.AndThen(opt.Ok("42"), strconv.Atoi)
+	doubled := opt.MapResult(parsed, func(n int) int { return n * 2 })
+	if doubled.Unwrap() != 84 {
+		t.Errorf("Expected 84 got %v", doubled.Unwrap())
+	}
+	failed := opt.AndThen(opt.Ok("x"), strconv.Atoi)
+	if failed.IsOk() || opt.MapResult(failed, func(n int) int { return n }).Err() == nil {
+		t.Error("Expected the parse error to carry through")
+	}
+}
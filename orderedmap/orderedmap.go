@@ -0,0 +1,229 @@
+// Package orderedmap is a package that implements a generic map that preserves insertion order.
+package orderedmap
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"strconv"
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Map is a key-value store that remembers the order keys were first inserted.
+// It is not safe for concurrent use.
+type Map[K comparable, V any] struct {
+	queue  *list.List
+	lookup map[K]*list.Element
+}
+
+// New creates an empty ordered map.
+func New[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{
+		queue:  list.New(),
+		lookup: make(map[K]*list.Element),
+	}
+}
+
+// Set a key/value into the map.
+// Updating an existing key keeps its original position.
+func (m *Map[K, V]) Set(key K, value V) {
+	m.init()
+	if item, ok := m.lookup[key]; ok {
+		item.Value.(*entry[K, V]).value = value
+		return
+	}
+	m.lookup[key] = m.queue.PushBack(&entry[K, V]{
+		key:   key,
+		value: value,
+	})
+}
+
+// Get will retrieve a value by key.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if item, ok := m.lookup[key]; ok {
+		return item.Value.(*entry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete an entry from the map.
+func (m *Map[K, V]) Delete(key K) (V, bool) {
+	if item, ok := m.lookup[key]; ok {
+		m.queue.Remove(item)
+		delete(m.lookup, key)
+		return item.Value.(*entry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Len returns the number of entries in the map.
+func (m *Map[K, V]) Len() int {
+	return len(m.lookup)
+}
+
+// Keys returns all keys in insertion order.
+func (m *Map[K, V]) Keys() []K {
+	ret := make([]K, 0, m.Len())
+	m.Each(func(key K, value V) bool {
+		ret = append(ret, key)
+		return true
+	})
+	return ret
+}
+
+// Values returns all values in insertion order.
+func (m *Map[K, V]) Values() []V {
+	ret := make([]V, 0, m.Len())
+	m.Each(func(key K, value V) bool {
+		ret = append(ret, value)
+		return true
+	})
+	return ret
+}
+
+// Each calls fn for every entry in insertion order.
+// Iteration stops early if fn returns false.
+func (m *Map[K, V]) Each(fn func(key K, value V) bool) {
+	if m.queue == nil {
+		return
+	}
+	for item := m.queue.Front(); item != nil; item = item.Next() {
+		e := item.Value.(*entry[K, V])
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// All returns a sequence of the entries in insertion order.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.Each(yield)
+	}
+}
+
+// Backward returns a sequence of the entries in reverse insertion order.
+func (m *Map[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.queue == nil {
+			return
+		}
+		for item := m.queue.Back(); item != nil; item = item.Prev() {
+			e := item.Value.(*entry[K, V])
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Insert sets every entry from seq in order.
+func (m *Map[K, V]) Insert(seq iter.Seq2[K, V]) {
+	for key, value := range seq {
+		m.Set(key, value)
+	}
+}
+
+// Collect creates an ordered map of the entries in seq, in the order they are produced.
+func Collect[K comparable, V any](seq iter.Seq2[K, V]) *Map[K, V] {
+	m := New[K, V]()
+	m.Insert(seq)
+	return m
+}
+
+// MarshalJSON encodes the map as a JSON object with keys in insertion order.
+func (m *Map[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	var err error
+	first := true
+	m.Each(func(key K, value V) bool {
+		var encodedKey, encodedValue []byte
+		if encodedKey, err = encodeKey(key); err != nil {
+			return false
+		}
+		if encodedValue, err = json.Marshal(value); err != nil {
+			return false
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object into the map, preserving the order of its keys.
+// Existing entries are kept and decoded keys are set on top of them.
+func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	m.init()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('{') {
+		return fmt.Errorf("orderedmap: expected JSON object, got %v", token)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, err := decodeKey[K](token.(string))
+		if err != nil {
+			return err
+		}
+		var value V
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		m.Set(key, value)
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+func (m *Map[K, V]) init() {
+	if m.queue == nil {
+		m.queue = list.New()
+		m.lookup = make(map[K]*list.Element)
+	}
+}
+
+// encodeKey renders a key as a JSON string, quoting non-string encodings such as numbers.
+func encodeKey[K comparable](key K) ([]byte, error) {
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	if encoded[0] == '"' {
+		return encoded, nil
+	}
+	return []byte(strconv.Quote(string(encoded))), nil
+}
+
+func decodeKey[K comparable](raw string) (K, error) {
+	var key K
+	if err := json.Unmarshal([]byte(strconv.Quote(raw)), &key); err == nil {
+		return key, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &key); err != nil {
+		return key, fmt.Errorf("orderedmap: cannot decode key %q: %w", raw, err)
+	}
+	return key, nil
+}
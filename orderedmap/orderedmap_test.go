@@ -0,0 +1,95 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/cjsaylor/goutil/orderedmap"
+)
+
+func TestSetPreservesOrder(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("c", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	m.Set("c", 4)
+	if keys := m.Keys(); !reflect.DeepEqual(keys, []string{"c", "a", "b"}) {
+		t.Errorf("Expected [c a b] got %v", keys)
+	}
+	if val, ok := m.Get("c"); !ok || val != 4 {
+		t.Errorf("Expected 'c' to be updated to 4 got %v", val)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	if val, ok := m.Delete("a"); !ok || val != 1 {
+		t.Error("Expected to return value deleted")
+	}
+	if _, ok := m.Get("a"); ok || m.Len() != 1 {
+		t.Error("Expected 'a' to be deleted")
+	}
+	m.Set("a", 3)
+	if values := m.Values(); !reflect.DeepEqual(values, []int{2, 3}) {
+		t.Errorf("Expected re-added key at the end got %v", values)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("z", 1)
+	m.Set("a", 2)
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"z":1,"a":2}` {
+		t.Errorf("Expected ordered JSON got %s (%v)", data, err)
+	}
+	var decoded orderedmap.Map[string, int]
+	if err := json.Unmarshal([]byte(`{"y":1,"b":2,"x":3}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if keys := decoded.Keys(); !reflect.DeepEqual(keys, []string{"y", "b", "x"}) {
+		t.Errorf("Expected [y b x] got %v", keys)
+	}
+}
+
+func TestJSONNumericKeys(t *testing.T) {
+	m := orderedmap.New[int, string]()
+	m.Set(2, "b")
+	m.Set(1, "a")
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"2":"b","1":"a"}` {
+		t.Errorf("Expected quoted numeric keys got %s (%v)", data, err)
+	}
+	decoded := orderedmap.New[int, string]()
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if keys := decoded.Keys(); !reflect.DeepEqual(keys, []int{2, 1}) {
+		t.Errorf("Expected [2 1] got %v", keys)
+	}
+}
+
+func TestSeq(t *testing.T) {
+	m := orderedmap.Collect(slices.All([]string{"a", "b"}))
+	m.Insert(slices.All([]string{"x"}))
+	var keys []int
+	var values []string
+	for key, value := range m.All() {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	if !slices.Equal(keys, []int{0, 1}) || !slices.Equal(values, []string{"x", "b"}) {
+		t.Errorf("Expected keys in first-insert order with the latest values got %v and %v", keys, values)
+	}
+	var backward []int
+	for key := range m.Backward() {
+		backward = append(backward, key)
+	}
+	if !slices.Equal(backward, []int{1, 0}) {
+		t.Errorf("Expected [1 0] got %v", backward)
+	}
+}
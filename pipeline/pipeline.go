@@ -0,0 +1,163 @@
+// Package pipeline is a package that connects processing stages with channels.
+//
+// Every function starts goroutines that exit once their input is drained or the context is done, and
+// every returned channel is closed when its goroutines exit. Consumers that stop reading early should
+// cancel the context so upstream stages do not block forever.
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// Item is a value tagged with its position in the stream that FanOut read it from.
+type Item[T any] struct {
+	Seq   uint64
+	Value T
+}
+
+// From returns a channel that yields items in order.
+func From[T any](ctx context.Context, items ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, item := range items {
+			if !send(ctx, out, item) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Stage applies fn to every value from in, in order, on a single goroutine.
+func Stage[In, Out any](ctx context.Context, in <-chan In, fn func(context.Context, In) Out) <-chan Out {
+	out := make(chan Out)
+	go func() {
+		defer close(out)
+		for {
+			value, ok := receive(ctx, in)
+			if !ok || !send(ctx, out, fn(ctx, value)) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Lift adapts fn to operate on tagged items, keeping each item's sequence number, so it can be used
+// as a Stage between FanOut and OrderedFanIn.
+func Lift[In, Out any](fn func(context.Context, In) Out) func(context.Context, Item[In]) Item[Out] {
+	return func(ctx context.Context, item Item[In]) Item[Out] {
+		return Item[Out]{Seq: item.Seq, Value: fn(ctx, item.Value)}
+	}
+}
+
+// FanOut spreads the values from in over n channels. Each value is delivered to exactly one of the
+// channels, whichever is ready first, tagged with its position in in.
+func FanOut[T any](ctx context.Context, in <-chan T, n int) []<-chan Item[T] {
+	if n < 1 {
+		n = 1
+	}
+	source := make(chan Item[T])
+	go func() {
+		defer close(source)
+		var seq uint64
+		for {
+			value, ok := receive(ctx, in)
+			if !ok || !send(ctx, source, Item[T]{Seq: seq, Value: value}) {
+				return
+			}
+			seq++
+		}
+	}()
+	outs := make([]<-chan Item[T], n)
+	for i := range outs {
+		out := make(chan Item[T])
+		outs[i] = out
+		go func() {
+			defer close(out)
+			for {
+				item, ok := receive(ctx, source)
+				if !ok || !send(ctx, out, item) {
+					return
+				}
+			}
+		}()
+	}
+	return outs
+}
+
+// FanIn merges the values from every channel into one, in no particular order.
+func FanIn[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	wg := &sync.WaitGroup{}
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func() {
+			defer wg.Done()
+			for {
+				value, ok := receive(ctx, in)
+				if !ok || !send(ctx, out, value) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// OrderedFanIn merges tagged items from every channel and yields their values in sequence order.
+// Items that arrive early are held until the items before them arrive, so every sequence number from
+// zero must eventually be delivered or later values will never be yielded.
+func OrderedFanIn[T any](ctx context.Context, ins ...<-chan Item[T]) <-chan T {
+	merged := FanIn(ctx, ins...)
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		pending := make(map[uint64]T)
+		var next uint64
+		for {
+			item, ok := receive(ctx, merged)
+			if !ok {
+				return
+			}
+			pending[item.Seq] = item.Value
+			for {
+				value, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				if !send(ctx, out, value) {
+					return
+				}
+				next++
+			}
+		}
+	}()
+	return out
+}
+
+func send[T any](ctx context.Context, out chan<- T, value T) bool {
+	select {
+	case out <- value:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func receive[T any](ctx context.Context, in <-chan T) (T, bool) {
+	select {
+	case value, ok := <-in:
+		return value, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
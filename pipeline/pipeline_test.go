@@ -0,0 +1,90 @@
+package pipeline_test
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/pipeline"
+)
+
+func collect[T any](in <-chan T) []T {
+	var out []T
+	for value := range in {
+		out = append(out, value)
+	}
+	return out
+}
+
+func double(ctx context.Context, n int) int {
+	return n * 2
+}
+
+func TestStage(t *testing.T) {
+	ctx := context.Background()
+	got := collect(pipeline.Stage(ctx, pipeline.From(ctx, 1, 2, 3), double))
+	if len(got) != 3 || got[0] != 2 || got[1] != 4 || got[2] != 6 {
+		t.Errorf("Expected [2 4 6] got %v", got)
+	}
+}
+
+func TestFanOutFanIn(t *testing.T) {
+	ctx := context.Background()
+	outs := pipeline.FanOut(ctx, pipeline.From(ctx, 1, 2, 3, 4, 5, 6, 7, 8), 3)
+	values := make([]<-chan int, len(outs))
+	for i, out := range outs {
+		values[i] = pipeline.Stage(ctx, out, func(ctx context.Context, item pipeline.Item[int]) int {
+			return item.Value
+		})
+	}
+	got := collect(pipeline.FanIn(ctx, values...))
+	sort.Ints(got)
+	if len(got) != 8 || got[0] != 1 || got[7] != 8 {
+		t.Errorf("Expected every value once got %v", got)
+	}
+}
+
+func TestOrderedFanIn(t *testing.T) {
+	ctx := context.Background()
+	input := make([]int, 100)
+	for i := range input {
+		input[i] = i
+	}
+	outs := pipeline.FanOut(ctx, pipeline.From(ctx, input...), 4)
+	stages := make([]<-chan pipeline.Item[int], len(outs))
+	for i, out := range outs {
+		stages[i] = pipeline.Stage(ctx, out, pipeline.Lift(func(ctx context.Context, n int) int {
+			time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+			return n * 2
+		}))
+	}
+	got := collect(pipeline.OrderedFanIn(ctx, stages...))
+	if len(got) != 100 {
+		t.Fatalf("Expected 100 values got %d", len(got))
+	}
+	for i, value := range got {
+		if value != i*2 {
+			t.Fatalf("Expected %d at %d got %d", i*2, i, value)
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	out := pipeline.Stage(ctx, pipeline.From(ctx, 1, 2, 3), double)
+	<-out
+	cancel()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Expected the stage to close after cancellation")
+		}
+	}
+}
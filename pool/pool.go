@@ -0,0 +1,287 @@
+// Package pool is a package that manages a bounded set of reusable, expensive objects such as
+// connections.
+//
+// Unlike sync.Pool, objects are only discarded by the pool's own rules, and every discarded object is
+// closed.
+package pool
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when borrowing from a closed pool.
+var ErrClosed = errors.New("pool: closed")
+
+// Options configures a pool.
+type Options[T any] struct {
+	// New creates an object. It is required.
+	New func(ctx context.Context) (T, error)
+	// Validate reports whether an idle object is still usable before it is lent out. Objects that fail
+	// are closed and another is tried.
+	Validate func(value T) bool
+	// Close releases an object the pool discards.
+	Close func(value T)
+	// MaxSize is the most objects that may exist at once, lent out or idle. Zero means no limit.
+	MaxSize int
+	// MaxIdle is the most idle objects kept. Zero means no limit.
+	MaxIdle int
+	// IdleTimeout closes objects left idle this long. Zero keeps them.
+	IdleTimeout time.Duration
+	// MaxLifetime closes objects this long after they were created, once they are next idle.
+	// Zero keeps them.
+	MaxLifetime time.Duration
+}
+
+// Item is an object lent out by a pool.
+type Item[T any] struct {
+	Value    T
+	created  time.Time
+	returned time.Time
+}
+
+// Stats is a point-in-time view of a pool.
+type Stats struct {
+	Live    int
+	Idle    int
+	Waiting int
+	Created int64
+	Closed  int64
+}
+
+// Pool lends out objects and takes them back for reuse. It is safe for concurrent use.
+type Pool[T any] struct {
+	options Options[T]
+	idle    []*Item[T]
+	live    int
+	waiters *list.List
+	closed  bool
+	created int64
+	closes  int64
+	stop    chan struct{}
+	mutex   *sync.Mutex
+}
+
+// New creates an empty pool. If IdleTimeout or MaxLifetime is set, a background goroutine closes
+// expired idle objects until the pool is closed.
+func New[T any](options Options[T]) *Pool[T] {
+	p := &Pool[T]{
+		options: options,
+		waiters: list.New(),
+		stop:    make(chan struct{}),
+		mutex:   &sync.Mutex{},
+	}
+	if interval := p.sweepInterval(); interval > 0 {
+		go p.janitor(interval)
+	}
+	return p
+}
+
+// Get borrows an object, reusing an idle one if possible and otherwise creating one. When the pool is
+// at MaxSize it waits for an object to be returned or discarded, or for ctx to be done.
+func (p *Pool[T]) Get(ctx context.Context) (*Item[T], error) {
+	for {
+		p.mutex.Lock()
+		if p.closed {
+			p.mutex.Unlock()
+			return nil, ErrClosed
+		}
+		item, expired := p.popIdle()
+		if item != nil {
+			p.mutex.Unlock()
+			p.closeAll(expired)
+			if p.options.Validate == nil || p.options.Validate(item.Value) {
+				return item, nil
+			}
+			p.Discard(item)
+			continue
+		}
+		if p.options.MaxSize <= 0 || p.live < p.options.MaxSize {
+			p.live++
+			p.mutex.Unlock()
+			p.closeAll(expired)
+			value, err := p.options.New(ctx)
+			if err != nil {
+				p.mutex.Lock()
+				p.live--
+				p.wakeOne()
+				p.mutex.Unlock()
+				return nil, err
+			}
+			p.mutex.Lock()
+			p.created++
+			p.mutex.Unlock()
+			return &Item[T]{Value: value, created: time.Now()}, nil
+		}
+		wake := make(chan struct{}, 1)
+		element := p.waiters.PushBack(wake)
+		p.mutex.Unlock()
+		p.closeAll(expired)
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			p.mutex.Lock()
+			p.waiters.Remove(element)
+			select {
+			case <-wake:
+				// Woken just as ctx was done; pass the turn on.
+				p.wakeOne()
+			default:
+			}
+			p.mutex.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Put returns a borrowed object to the pool.
+func (p *Pool[T]) Put(item *Item[T]) {
+	p.mutex.Lock()
+	now := time.Now()
+	if p.closed || p.expired(item, now) || (p.options.MaxIdle > 0 && len(p.idle) >= p.options.MaxIdle) {
+		p.mutex.Unlock()
+		p.Discard(item)
+		return
+	}
+	item.returned = now
+	p.idle = append(p.idle, item)
+	p.wakeOne()
+	p.mutex.Unlock()
+}
+
+// Discard closes a borrowed object instead of returning it, such as when it is known to be broken.
+func (p *Pool[T]) Discard(item *Item[T]) {
+	p.mutex.Lock()
+	p.live--
+	p.closes++
+	p.wakeOne()
+	p.mutex.Unlock()
+	p.close(item)
+}
+
+// Stats returns the current state of the pool.
+func (p *Pool[T]) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return Stats{
+		Live:    p.live,
+		Idle:    len(p.idle),
+		Waiting: p.waiters.Len(),
+		Created: p.created,
+		Closed:  p.closes,
+	}
+}
+
+// Close closes every idle object and fails waiting and later calls to Get with ErrClosed. Objects
+// still lent out are closed when they are returned.
+func (p *Pool[T]) Close() {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.stop)
+	idle := p.idle
+	p.idle = nil
+	p.live -= len(idle)
+	p.closes += int64(len(idle))
+	for p.waiters.Len() > 0 {
+		p.wakeOne()
+	}
+	p.mutex.Unlock()
+	p.closeAll(idle)
+}
+
+// popIdle takes the most recently returned idle object, also removing and returning any expired
+// objects passed over, which the caller must close once it has released the lock. It must be called
+// with the lock held.
+func (p *Pool[T]) popIdle() (*Item[T], []*Item[T]) {
+	now := time.Now()
+	var expired []*Item[T]
+	for len(p.idle) > 0 {
+		item := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if !p.expired(item, now) {
+			return item, expired
+		}
+		p.live--
+		p.closes++
+		expired = append(expired, item)
+	}
+	return nil, expired
+}
+
+func (p *Pool[T]) expired(item *Item[T], now time.Time) bool {
+	if p.options.MaxLifetime > 0 && now.Sub(item.created) >= p.options.MaxLifetime {
+		return true
+	}
+	return p.options.IdleTimeout > 0 && !item.returned.IsZero() && now.Sub(item.returned) >= p.options.IdleTimeout
+}
+
+// wakeOne wakes the longest waiting Get. It must be called with the lock held.
+func (p *Pool[T]) wakeOne() {
+	if front := p.waiters.Front(); front != nil {
+		p.waiters.Remove(front)
+		front.Value.(chan struct{}) <- struct{}{}
+	}
+}
+
+func (p *Pool[T]) close(item *Item[T]) {
+	if p.options.Close != nil {
+		p.options.Close(item.Value)
+	}
+}
+
+func (p *Pool[T]) closeAll(items []*Item[T]) {
+	for _, item := range items {
+		p.close(item)
+	}
+}
+
+func (p *Pool[T]) sweepInterval() time.Duration {
+	interval := p.options.IdleTimeout
+	if p.options.MaxLifetime > 0 && (interval <= 0 || p.options.MaxLifetime < interval) {
+		interval = p.options.MaxLifetime
+	}
+	return interval / 2
+}
+
+func (p *Pool[T]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.sweep()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *Pool[T]) sweep() {
+	p.mutex.Lock()
+	now := time.Now()
+	kept := p.idle[:0]
+	var expired []*Item[T]
+	for _, item := range p.idle {
+		if p.expired(item, now) {
+			expired = append(expired, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	p.idle = kept
+	p.live -= len(expired)
+	p.closes += int64(len(expired))
+	for range expired {
+		p.wakeOne()
+	}
+	p.mutex.Unlock()
+	p.closeAll(expired)
+}
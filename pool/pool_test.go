@@ -0,0 +1,182 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/leaktest"
+	"github.com/cjsaylor/goutil/pool"
+)
+
+type conn struct {
+	id     int
+	broken bool
+}
+
+type tracker struct {
+	mutex   sync.Mutex
+	next    int
+	closed  []int
+	failNew bool
+}
+
+func (tr *tracker) options() pool.Options[*conn] {
+	return pool.Options[*conn]{
+		New: func(ctx context.Context) (*conn, error) {
+			tr.mutex.Lock()
+			defer tr.mutex.Unlock()
+			if tr.failNew {
+				return nil, errors.New("dial failed")
+			}
+			tr.next++
+			return &conn{id: tr.next}, nil
+		},
+		Validate: func(c *conn) bool { return !c.broken },
+		Close: func(c *conn) {
+			tr.mutex.Lock()
+			tr.closed = append(tr.closed, c.id)
+			tr.mutex.Unlock()
+		},
+	}
+}
+
+func (tr *tracker) closedCount() int {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	return len(tr.closed)
+}
+
+func TestReuse(t *testing.T) {
+	tr := &tracker{}
+	p := pool.New(tr.options())
+	defer p.Close()
+	item, _ := p.Get(context.Background())
+	p.Put(item)
+	again, _ := p.Get(context.Background())
+	if again.Value.id != 1 {
+		t.Errorf("Expected the idle object to be reused got %d", again.Value.id)
+	}
+}
+
+func TestValidateOnBorrow(t *testing.T) {
+	tr := &tracker{}
+	p := pool.New(tr.options())
+	defer p.Close()
+	item, _ := p.Get(context.Background())
+	item.Value.broken = true
+	p.Put(item)
+	again, _ := p.Get(context.Background())
+	if again.Value.id != 2 || tr.closedCount() != 1 {
+		t.Errorf("Expected the broken object to be closed and replaced got %d", again.Value.id)
+	}
+}
+
+func TestMaxSizeWaits(t *testing.T) {
+	tr := &tracker{}
+	options := tr.options()
+	options.MaxSize = 1
+	p := pool.New(options)
+	defer p.Close()
+	item, _ := p.Get(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := p.Get(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected a full pool to wait got %v", err)
+	}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		p.Put(item)
+	}()
+	again, err := p.Get(context.Background())
+	if err != nil || again.Value.id != 1 {
+		t.Errorf("Expected the returned object got %v (%v)", again, err)
+	}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		p.Discard(again)
+	}()
+	if fresh, err := p.Get(context.Background()); err != nil || fresh.Value.id != 2 {
+		t.Errorf("Expected a discard to free a slot got %v (%v)", fresh, err)
+	}
+}
+
+func TestMaxIdle(t *testing.T) {
+	tr := &tracker{}
+	options := tr.options()
+	options.MaxIdle = 1
+	p := pool.New(options)
+	defer p.Close()
+	first, _ := p.Get(context.Background())
+	second, _ := p.Get(context.Background())
+	p.Put(first)
+	p.Put(second)
+	if stats := p.Stats(); stats.Idle != 1 || stats.Live != 1 || tr.closedCount() != 1 {
+		t.Errorf("Expected 1 idle object got %+v", stats)
+	}
+}
+
+func TestIdleTimeoutAndLifetime(t *testing.T) {
+	defer leaktest.Check(t)()
+	tr := &tracker{}
+	options := tr.options()
+	options.IdleTimeout = 10 * time.Millisecond
+	p := pool.New(options)
+	defer p.Close()
+	item, _ := p.Get(context.Background())
+	p.Put(item)
+	time.Sleep(30 * time.Millisecond)
+	if stats := p.Stats(); stats.Idle != 0 || tr.closedCount() != 1 {
+		t.Errorf("Expected the janitor to close the idle object got %+v", stats)
+	}
+
+	tr = &tracker{}
+	options = tr.options()
+	options.MaxLifetime = 5 * time.Millisecond
+	p = pool.New(options)
+	defer p.Close()
+	item, _ = p.Get(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	p.Put(item)
+	if tr.closedCount() != 1 {
+		t.Error("Expected an object past its lifetime to be closed on return")
+	}
+}
+
+func TestNewError(t *testing.T) {
+	tr := &tracker{failNew: true}
+	options := tr.options()
+	options.MaxSize = 1
+	p := pool.New(options)
+	defer p.Close()
+	if _, err := p.Get(context.Background()); err == nil {
+		t.Error("Expected the factory error")
+	}
+	if p.Stats().Live != 0 {
+		t.Error("Expected a failed create to free its slot")
+	}
+}
+
+func TestClose(t *testing.T) {
+	tr := &tracker{}
+	options := tr.options()
+	options.MaxSize = 1
+	p := pool.New(options)
+	item, _ := p.Get(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := p.Get(context.Background())
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	p.Close()
+	if err := <-done; err != pool.ErrClosed {
+		t.Errorf("Expected waiting Gets to fail got %v", err)
+	}
+	p.Put(item)
+	if tr.closedCount() != 1 {
+		t.Error("Expected an object returned after close to be closed")
+	}
+}
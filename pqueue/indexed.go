@@ -0,0 +1,154 @@
+package pqueue
+
+type indexedItem[K comparable, P any] struct {
+	key      K
+	priority P
+}
+
+// Indexed is a priority queue of unique keys whose priorities can be changed or removed in O(log n).
+// It is not safe for concurrent use.
+type Indexed[K comparable, P any] struct {
+	items []indexedItem[K, P]
+	index map[K]int
+	less  func(a, b P) bool
+}
+
+// NewIndexed creates an empty indexed priority queue ordered by less.
+func NewIndexed[K comparable, P any](less func(a, b P) bool) *Indexed[K, P] {
+	return &Indexed[K, P]{
+		index: make(map[K]int),
+		less:  less,
+	}
+}
+
+// Push adds key with priority, or updates its priority if key is already queued.
+func (q *Indexed[K, P]) Push(key K, priority P) {
+	if i, ok := q.index[key]; ok {
+		q.items[i].priority = priority
+		q.fix(i)
+		return
+	}
+	q.items = append(q.items, indexedItem[K, P]{key: key, priority: priority})
+	q.index[key] = len(q.items) - 1
+	q.up(len(q.items) - 1)
+}
+
+// Update changes the priority of a queued key, reporting whether key was queued.
+func (q *Indexed[K, P]) Update(key K, priority P) bool {
+	i, ok := q.index[key]
+	if !ok {
+		return false
+	}
+	q.items[i].priority = priority
+	q.fix(i)
+	return true
+}
+
+// Remove a key from the queue, returning its priority.
+func (q *Indexed[K, P]) Remove(key K) (P, bool) {
+	i, ok := q.index[key]
+	if !ok {
+		var zero P
+		return zero, false
+	}
+	return q.removeAt(i).priority, true
+}
+
+// Pop removes and returns the key with the highest priority.
+func (q *Indexed[K, P]) Pop() (K, P, bool) {
+	if len(q.items) == 0 {
+		var key K
+		var priority P
+		return key, priority, false
+	}
+	item := q.removeAt(0)
+	return item.key, item.priority, true
+}
+
+// Peek returns the key with the highest priority without removing it.
+func (q *Indexed[K, P]) Peek() (K, P, bool) {
+	if len(q.items) == 0 {
+		var key K
+		var priority P
+		return key, priority, false
+	}
+	return q.items[0].key, q.items[0].priority, true
+}
+
+// Priority returns the priority of a queued key.
+func (q *Indexed[K, P]) Priority(key K) (P, bool) {
+	if i, ok := q.index[key]; ok {
+		return q.items[i].priority, true
+	}
+	var zero P
+	return zero, false
+}
+
+// Contains reports whether key is queued.
+func (q *Indexed[K, P]) Contains(key K) bool {
+	_, ok := q.index[key]
+	return ok
+}
+
+// Len returns the number of keys in the queue.
+func (q *Indexed[K, P]) Len() int {
+	return len(q.items)
+}
+
+func (q *Indexed[K, P]) removeAt(i int) indexedItem[K, P] {
+	item := q.items[i]
+	last := len(q.items) - 1
+	if i != last {
+		q.swap(i, last)
+	}
+	q.items[last] = indexedItem[K, P]{}
+	q.items = q.items[:last]
+	delete(q.index, item.key)
+	if i != last {
+		q.fix(i)
+	}
+	return item
+}
+
+func (q *Indexed[K, P]) fix(i int) {
+	if !q.up(i) {
+		q.down(i)
+	}
+}
+
+func (q *Indexed[K, P]) swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.index[q.items[i].key] = i
+	q.index[q.items[j].key] = j
+}
+
+func (q *Indexed[K, P]) up(i int) bool {
+	moved := false
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.less(q.items[i].priority, q.items[parent].priority) {
+			break
+		}
+		q.swap(i, parent)
+		i = parent
+		moved = true
+	}
+	return moved
+}
+
+func (q *Indexed[K, P]) down(i int) {
+	for {
+		smallest := i
+		if left := 2*i + 1; left < len(q.items) && q.less(q.items[left].priority, q.items[smallest].priority) {
+			smallest = left
+		}
+		if right := 2*i + 2; right < len(q.items) && q.less(q.items[right].priority, q.items[smallest].priority) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		q.swap(i, smallest)
+		i = smallest
+	}
+}
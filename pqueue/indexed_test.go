@@ -0,0 +1,57 @@
+package pqueue_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cjsaylor/goutil/pqueue"
+)
+
+func TestIndexedUpdate(t *testing.T) {
+	q := pqueue.NewIndexed[string](func(a, b int) bool { return a < b })
+	q.Push("a", 5)
+	q.Push("b", 3)
+	q.Push("c", 4)
+	if !q.Update("a", 1) {
+		t.Error("Expected 'a' to be updated")
+	}
+	if key, priority, _ := q.Peek(); key != "a" || priority != 1 {
+		t.Errorf("Expected 'a' with priority 1 got %v %v", key, priority)
+	}
+	q.Update("a", 10)
+	if key, _, _ := q.Pop(); key != "b" {
+		t.Errorf("Expected 'b' got %v", key)
+	}
+	if q.Update("z", 1) {
+		t.Error("Expected update of unknown key to fail")
+	}
+}
+
+func TestIndexedRemove(t *testing.T) {
+	q := pqueue.NewIndexed[int](func(a, b int) bool { return a < b })
+	r := rand.New(rand.NewSource(1))
+	priorities := make(map[int]int)
+	for i := 0; i < 200; i++ {
+		priorities[i] = r.Intn(1000)
+		q.Push(i, priorities[i])
+	}
+	for i := 0; i < 200; i += 2 {
+		if priority, ok := q.Remove(i); !ok || priority != priorities[i] {
+			t.Fatalf("Expected to remove %d with priority %d", i, priorities[i])
+		}
+	}
+	last := -1
+	for q.Len() > 0 {
+		key, priority, _ := q.Pop()
+		if key%2 == 0 {
+			t.Fatalf("Expected removed key %d to not be popped", key)
+		}
+		if priority < last {
+			t.Fatalf("Expected priorities in order, got %d after %d", priority, last)
+		}
+		last = priority
+	}
+	if q.Contains(1) {
+		t.Error("Expected queue to be empty")
+	}
+}
@@ -0,0 +1,77 @@
+// Package pqueue is a package that implements a generic priority queue backed by a binary heap.
+package pqueue
+
+// Queue returns items in priority order, where an item is higher priority if it is less than another.
+// It is not safe for concurrent use.
+type Queue[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+// New creates an empty priority queue ordered by less.
+func New[T any](less func(a, b T) bool) *Queue[T] {
+	return &Queue[T]{less: less}
+}
+
+// Push adds an item to the queue.
+func (q *Queue[T]) Push(item T) {
+	q.items = append(q.items, item)
+	q.up(len(q.items) - 1)
+}
+
+// Pop removes and returns the highest priority item.
+func (q *Queue[T]) Pop() (T, bool) {
+	var zero T
+	if len(q.items) == 0 {
+		return zero, false
+	}
+	last := len(q.items) - 1
+	item := q.items[0]
+	q.items[0] = q.items[last]
+	q.items[last] = zero
+	q.items = q.items[:last]
+	q.down(0)
+	return item, true
+}
+
+// Peek returns the highest priority item without removing it.
+func (q *Queue[T]) Peek() (T, bool) {
+	if len(q.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.items[0], true
+}
+
+// Len returns the number of items in the queue.
+func (q *Queue[T]) Len() int {
+	return len(q.items)
+}
+
+func (q *Queue[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.less(q.items[i], q.items[parent]) {
+			return
+		}
+		q.items[i], q.items[parent] = q.items[parent], q.items[i]
+		i = parent
+	}
+}
+
+func (q *Queue[T]) down(i int) {
+	for {
+		smallest := i
+		if left := 2*i + 1; left < len(q.items) && q.less(q.items[left], q.items[smallest]) {
+			smallest = left
+		}
+		if right := 2*i + 2; right < len(q.items) && q.less(q.items[right], q.items[smallest]) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		q.items[i], q.items[smallest] = q.items[smallest], q.items[i]
+		i = smallest
+	}
+}
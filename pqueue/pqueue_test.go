@@ -0,0 +1,41 @@
+package pqueue_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/cjsaylor/goutil/pqueue"
+)
+
+func TestPushPop(t *testing.T) {
+	q := pqueue.New(func(a, b int) bool { return a < b })
+	r := rand.New(rand.NewSource(1))
+	expected := make([]int, 100)
+	for i := range expected {
+		expected[i] = r.Intn(1000)
+		q.Push(expected[i])
+	}
+	sort.Ints(expected)
+	for _, want := range expected {
+		if item, ok := q.Pop(); !ok || item != want {
+			t.Fatalf("Expected %d got %d", want, item)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Expected pop from empty queue to fail")
+	}
+}
+
+func TestPeek(t *testing.T) {
+	type task struct {
+		name     string
+		priority int
+	}
+	q := pqueue.New(func(a, b task) bool { return a.priority > b.priority })
+	q.Push(task{"low", 1})
+	q.Push(task{"high", 10})
+	if item, ok := q.Peek(); !ok || item.name != "high" || q.Len() != 2 {
+		t.Errorf("Expected to peek 'high' got %v", item)
+	}
+}
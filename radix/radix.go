@@ -0,0 +1,338 @@
+// Package radix is a package that implements a compressed prefix tree with longest-prefix matching,
+// suitable for routing tables such as URL paths or IP prefixes.
+//
+// Trees are persistent: every write copies the path it modifies and publishes a new immutable Snapshot,
+// so readers never take a lock and never observe a partially applied write.
+package radix
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type node[V any] struct {
+	prefix string
+	edges  []*node[V]
+	value  V
+	leaf   bool
+}
+
+func (n *node[V]) clone() *node[V] {
+	c := *n
+	c.edges = append([]*node[V](nil), n.edges...)
+	return &c
+}
+
+func (n *node[V]) edge(label byte) (int, *node[V]) {
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].prefix[0] >= label })
+	if i < len(n.edges) && n.edges[i].prefix[0] == label {
+		return i, n.edges[i]
+	}
+	return i, nil
+}
+
+func (n *node[V]) addEdge(child *node[V]) {
+	i, _ := n.edge(child.prefix[0])
+	n.edges = append(n.edges, nil)
+	copy(n.edges[i+1:], n.edges[i:])
+	n.edges[i] = child
+}
+
+func (n *node[V]) removeEdge(i int) {
+	n.edges = append(n.edges[:i], n.edges[i+1:]...)
+}
+
+// Snapshot is an immutable view of a tree at a point in time. It is safe for concurrent use.
+type Snapshot[V any] struct {
+	root *node[V]
+	size int
+}
+
+// Tree is a key-value store keyed by strings. Writes are serialized while reads are lock-free.
+type Tree[V any] struct {
+	mutex   *sync.Mutex
+	current atomic.Pointer[Snapshot[V]]
+}
+
+// New creates an empty radix tree.
+func New[V any]() *Tree[V] {
+	t := &Tree[V]{mutex: &sync.Mutex{}}
+	t.current.Store(&Snapshot[V]{root: &node[V]{}})
+	return t
+}
+
+// Snapshot returns the current immutable view of the tree.
+func (t *Tree[V]) Snapshot() *Snapshot[V] {
+	return t.current.Load()
+}
+
+// Insert a key/value into the tree, replacing any existing value.
+func (t *Tree[V]) Insert(key string, value V) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.Snapshot()
+	root, added := insert(s.root, key, value)
+	size := s.size
+	if added {
+		size++
+	}
+	t.current.Store(&Snapshot[V]{root: root, size: size})
+}
+
+// Delete an entry from the tree, reporting whether it existed.
+func (t *Tree[V]) Delete(key string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.Snapshot()
+	root, ok := remove(s.root, key)
+	if !ok {
+		return false
+	}
+	t.current.Store(&Snapshot[V]{root: root, size: s.size - 1})
+	return true
+}
+
+// DeletePrefix removes every key starting with prefix, returning how many were removed.
+func (t *Tree[V]) DeletePrefix(prefix string) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s := t.Snapshot()
+	if prefix == "" {
+		t.current.Store(&Snapshot[V]{root: &node[V]{}})
+		return s.size
+	}
+	root, removed := removePrefix(s.root, prefix)
+	if removed > 0 {
+		t.current.Store(&Snapshot[V]{root: root, size: s.size - removed})
+	}
+	return removed
+}
+
+// Get will retrieve a value by key.
+func (t *Tree[V]) Get(key string) (V, bool) {
+	return t.Snapshot().Get(key)
+}
+
+// LongestPrefix returns the longest key in the tree that is a prefix of key.
+func (t *Tree[V]) LongestPrefix(key string) (string, V, bool) {
+	return t.Snapshot().LongestPrefix(key)
+}
+
+// WalkPrefix calls fn for every key starting with prefix in lexical byte order.
+// Iteration stops early if fn returns false.
+func (t *Tree[V]) WalkPrefix(prefix string, fn func(key string, value V) bool) {
+	t.Snapshot().WalkPrefix(prefix, fn)
+}
+
+// Len returns the number of keys in the tree.
+func (t *Tree[V]) Len() int {
+	return t.Snapshot().Len()
+}
+
+// Get will retrieve a value by key.
+func (s *Snapshot[V]) Get(key string) (V, bool) {
+	n := s.root
+	for key != "" {
+		_, child := n.edge(key[0])
+		if child == nil || !strings.HasPrefix(key, child.prefix) {
+			var zero V
+			return zero, false
+		}
+		key = key[len(child.prefix):]
+		n = child
+	}
+	return n.value, n.leaf
+}
+
+// LongestPrefix returns the longest key in the snapshot that is a prefix of key.
+func (s *Snapshot[V]) LongestPrefix(key string) (string, V, bool) {
+	n := s.root
+	var match *node[V]
+	matchLen := 0
+	if n.leaf {
+		match = n
+	}
+	for consumed := 0; consumed < len(key); {
+		search := key[consumed:]
+		_, child := n.edge(search[0])
+		if child == nil || !strings.HasPrefix(search, child.prefix) {
+			break
+		}
+		consumed += len(child.prefix)
+		n = child
+		if n.leaf {
+			match = n
+			matchLen = consumed
+		}
+	}
+	if match == nil {
+		var zero V
+		return "", zero, false
+	}
+	return key[:matchLen], match.value, true
+}
+
+// WalkPrefix calls fn for every key starting with prefix in lexical byte order.
+// Iteration stops early if fn returns false.
+func (s *Snapshot[V]) WalkPrefix(prefix string, fn func(key string, value V) bool) {
+	n := s.root
+	consumed := ""
+	for search := prefix; search != ""; {
+		_, child := n.edge(search[0])
+		if child == nil {
+			return
+		}
+		if strings.HasPrefix(child.prefix, search) {
+			walk(child, consumed+child.prefix, fn)
+			return
+		}
+		if !strings.HasPrefix(search, child.prefix) {
+			return
+		}
+		consumed += child.prefix
+		search = search[len(child.prefix):]
+		n = child
+	}
+	walk(n, consumed, fn)
+}
+
+// Len returns the number of keys in the snapshot.
+func (s *Snapshot[V]) Len() int {
+	return s.size
+}
+
+func insert[V any](n *node[V], key string, value V) (*node[V], bool) {
+	nc := n.clone()
+	if key == "" {
+		added := !nc.leaf
+		nc.leaf = true
+		nc.value = value
+		return nc, added
+	}
+	i, child := nc.edge(key[0])
+	if child == nil {
+		nc.addEdge(&node[V]{prefix: key, value: value, leaf: true})
+		return nc, true
+	}
+	common := commonPrefixLen(key, child.prefix)
+	if common == len(child.prefix) {
+		newChild, added := insert(child, key[common:], value)
+		nc.edges[i] = newChild
+		return nc, added
+	}
+	split := &node[V]{prefix: key[:common]}
+	existing := child.clone()
+	existing.prefix = child.prefix[common:]
+	split.addEdge(existing)
+	if rest := key[common:]; rest == "" {
+		split.leaf = true
+		split.value = value
+	} else {
+		split.addEdge(&node[V]{prefix: rest, value: value, leaf: true})
+	}
+	nc.edges[i] = split
+	return nc, true
+}
+
+func remove[V any](n *node[V], key string) (*node[V], bool) {
+	if key == "" {
+		if !n.leaf {
+			return n, false
+		}
+		nc := n.clone()
+		var zero V
+		nc.value = zero
+		nc.leaf = false
+		return nc, true
+	}
+	i, child := n.edge(key[0])
+	if child == nil || !strings.HasPrefix(key, child.prefix) {
+		return n, false
+	}
+	newChild, ok := remove(child, key[len(child.prefix):])
+	if !ok {
+		return n, false
+	}
+	nc := n.clone()
+	if newChild = compact(newChild); newChild == nil {
+		nc.removeEdge(i)
+	} else {
+		nc.edges[i] = newChild
+	}
+	return nc, true
+}
+
+func removePrefix[V any](n *node[V], prefix string) (*node[V], int) {
+	i, child := n.edge(prefix[0])
+	if child == nil {
+		return n, 0
+	}
+	if strings.HasPrefix(child.prefix, prefix) {
+		nc := n.clone()
+		nc.removeEdge(i)
+		return nc, count(child)
+	}
+	if !strings.HasPrefix(prefix, child.prefix) {
+		return n, 0
+	}
+	newChild, removed := removePrefix(child, prefix[len(child.prefix):])
+	if removed == 0 {
+		return n, 0
+	}
+	nc := n.clone()
+	if newChild = compact(newChild); newChild == nil {
+		nc.removeEdge(i)
+	} else {
+		nc.edges[i] = newChild
+	}
+	return nc, removed
+}
+
+// compact removes childless non-leaf nodes and merges non-leaf nodes that have a single child.
+func compact[V any](n *node[V]) *node[V] {
+	if n.leaf {
+		return n
+	}
+	switch len(n.edges) {
+	case 0:
+		return nil
+	case 1:
+		merged := n.edges[0].clone()
+		merged.prefix = n.prefix + merged.prefix
+		return merged
+	}
+	return n
+}
+
+func count[V any](n *node[V]) int {
+	total := 0
+	if n.leaf {
+		total++
+	}
+	for _, child := range n.edges {
+		total += count(child)
+	}
+	return total
+}
+
+func walk[V any](n *node[V], key string, fn func(key string, value V) bool) bool {
+	if n.leaf && !fn(key, n.value) {
+		return false
+	}
+	for _, child := range n.edges {
+		if !walk(child, key+child.prefix, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
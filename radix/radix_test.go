@@ -0,0 +1,117 @@
+package radix_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/cjsaylor/goutil/radix"
+)
+
+func TestInsertGetDelete(t *testing.T) {
+	tree := radix.New[int]()
+	tree.Insert("/api", 1)
+	tree.Insert("/api/users", 2)
+	tree.Insert("/apple", 3)
+	tree.Insert("/api", 4)
+	if val, ok := tree.Get("/api"); !ok || val != 4 {
+		t.Errorf("Expected 4 got %v", val)
+	}
+	if _, ok := tree.Get("/ap"); ok {
+		t.Error("Expected '/ap' to not be a key")
+	}
+	if !tree.Delete("/api") || tree.Delete("/api") {
+		t.Error("Expected '/api' to be deleted exactly once")
+	}
+	if val, ok := tree.Get("/api/users"); !ok || val != 2 {
+		t.Errorf("Expected '/api/users' to remain got %v", val)
+	}
+	if tree.Len() != 2 {
+		t.Errorf("Expected 2 keys got %d", tree.Len())
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	tree := radix.New[int]()
+	expected := make(map[string]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		key := strconv.FormatInt(int64(r.Intn(2000)), 3)
+		if r.Intn(3) == 0 {
+			_, existed := expected[key]
+			if tree.Delete(key) != existed {
+				t.Fatalf("Unexpected delete result for %s", key)
+			}
+			delete(expected, key)
+		} else {
+			tree.Insert(key, i)
+			expected[key] = i
+		}
+	}
+	var keys []string
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var walked []string
+	tree.WalkPrefix("", func(key string, value int) bool {
+		walked = append(walked, key)
+		if value != expected[key] {
+			t.Errorf("Expected %d for %s got %d", expected[key], key, value)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(keys, walked) || tree.Len() != len(keys) {
+		t.Error("Expected walked keys to match inserted keys in order")
+	}
+}
+
+func TestLongestPrefix(t *testing.T) {
+	tree := radix.New[string]()
+	tree.Insert("10.", "a")
+	tree.Insert("10.0.", "b")
+	tree.Insert("10.0.0.1", "c")
+	if key, val, ok := tree.LongestPrefix("10.0.1.5"); !ok || key != "10.0." || val != "b" {
+		t.Errorf("Expected '10.0.' got %v", key)
+	}
+	if key, _, ok := tree.LongestPrefix("10.0.0.1"); !ok || key != "10.0.0.1" {
+		t.Errorf("Expected exact match got %v", key)
+	}
+	if _, _, ok := tree.LongestPrefix("192.168.0.1"); ok {
+		t.Error("Expected no match")
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	tree := radix.New[int]()
+	for i, key := range []string{"/a/b", "/a/bc", "/a/c", "/ab", "/b"} {
+		tree.Insert(key, i)
+	}
+	if removed := tree.DeletePrefix("/a/"); removed != 3 {
+		t.Errorf("Expected 3 keys removed got %d", removed)
+	}
+	var keys []string
+	tree.WalkPrefix("/", func(key string, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if !reflect.DeepEqual(keys, []string{"/ab", "/b"}) {
+		t.Errorf("Expected [/ab /b] got %v", keys)
+	}
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	tree := radix.New[int]()
+	tree.Insert("foo", 1)
+	snapshot := tree.Snapshot()
+	tree.Insert("foobar", 2)
+	tree.Delete("foo")
+	if val, ok := snapshot.Get("foo"); !ok || val != 1 {
+		t.Error("Expected snapshot to be unaffected by later writes")
+	}
+	if _, ok := snapshot.Get("foobar"); ok || snapshot.Len() != 1 {
+		t.Error("Expected snapshot to not see later inserts")
+	}
+}
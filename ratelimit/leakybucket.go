@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned by a leaky bucket's Wait when too many callers are already waiting.
+var ErrQueueFull = errors.New("ratelimit: queue full")
+
+// LeakyBucket is a limiter that spaces events evenly, never allowing two closer together than its
+// interval, so bursts are smoothed out rather than let through. It is safe for concurrent use.
+type LeakyBucket struct {
+	interval time.Duration
+	capacity int
+	next     time.Time
+	mutex    *sync.Mutex
+}
+
+// NewLeakyBucket creates a limiter that allows rate events per second, evenly spaced. At most
+// capacity callers may be waiting for their turn at once; zero means Wait never queues. It panics if
+// rate is not positive.
+func NewLeakyBucket(rate float64, capacity int) *LeakyBucket {
+	if !(rate > 0) {
+		panic("ratelimit: rate must be positive")
+	}
+	return &LeakyBucket{
+		interval: time.Duration(float64(time.Second) / rate),
+		capacity: capacity,
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// Allow reports whether an event may happen now without waiting, taking its slot if so.
+func (b *LeakyBucket) Allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := time.Now()
+	if now.Before(b.next) {
+		return false
+	}
+	b.next = now.Add(b.interval)
+	return true
+}
+
+// Wait takes the next free slot and blocks until it arrives or ctx is done. It returns ErrQueueFull
+// without blocking when the slot is more than capacity intervals away, and context.DeadlineExceeded
+// when it is later than ctx's deadline.
+func (b *LeakyBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mutex.Lock()
+	now := time.Now()
+	slot := b.next
+	if slot.Before(now) {
+		slot = now
+	}
+	if slot.Sub(now) > time.Duration(b.capacity)*b.interval {
+		b.mutex.Unlock()
+		return ErrQueueFull
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(slot) {
+		b.mutex.Unlock()
+		return context.DeadlineExceeded
+	}
+	b.next = slot.Add(b.interval)
+	b.mutex.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mutex.Lock()
+		// Hand the slot back only if no one has queued behind it.
+		if b.next.Equal(slot.Add(b.interval)) {
+			b.next = slot
+		}
+		b.mutex.Unlock()
+		return ctx.Err()
+	}
+}
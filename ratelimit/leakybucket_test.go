@@ -0,0 +1,81 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/ratelimit"
+)
+
+var _ ratelimit.Limiter = ratelimit.NewLeakyBucket(1, 1)
+var _ ratelimit.Limiter = ratelimit.NewTokenBucket(1, 1)
+
+func TestLeakyBucketAllow(t *testing.T) {
+	b := ratelimit.NewLeakyBucket(100, 0)
+	if !b.Allow() {
+		t.Error("Expected the first event to be allowed")
+	}
+	if b.Allow() {
+		t.Error("Expected an event within the interval to be denied")
+	}
+	time.Sleep(15 * time.Millisecond)
+	if !b.Allow() {
+		t.Error("Expected an event after the interval to be allowed")
+	}
+}
+
+func TestLeakyBucketWaitSpacing(t *testing.T) {
+	b := ratelimit.NewLeakyBucket(100, 10)
+	var times []time.Time
+	for i := 0; i < 4; i++ {
+		if err := b.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		times = append(times, time.Now())
+	}
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < 8*time.Millisecond {
+			t.Errorf("Expected events about 10ms apart got %v", gap)
+		}
+	}
+}
+
+func TestLeakyBucketQueueFull(t *testing.T) {
+	b := ratelimit.NewLeakyBucket(1, 1)
+	b.Allow()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- b.Wait(ctx) }()
+	time.Sleep(5 * time.Millisecond)
+	if err := b.Wait(context.Background()); err != ratelimit.ErrQueueFull {
+		t.Errorf("Expected queue full got %v", err)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected canceled got %v", err)
+	}
+}
+
+func TestLeakyBucketDeadline(t *testing.T) {
+	b := ratelimit.NewLeakyBucket(1, 5)
+	b.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+}
+
+func TestLeakyBucketInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a rate of %v to panic", rate)
+				}
+			}()
+			ratelimit.NewLeakyBucket(rate, 0)
+		}()
+	}
+}
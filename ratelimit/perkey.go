@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cjsaylor/goutil/lru"
+)
+
+// PerKey keeps a separate limiter for each key, such as a user ID or client address.
+//
+// Limiters are held in an LRU cache, so memory stays bounded however many keys are seen. A key whose
+// limiter is evicted starts over with a fresh limiter the next time it is used, so capacity should
+// comfortably exceed the number of keys active within one limiting period.
+// It is safe for concurrent use.
+type PerKey[K comparable] struct {
+	limiters *lru.Cache
+	create   func(key K) Limiter
+	mutex    *sync.Mutex
+}
+
+// NewPerKey creates a keyed limiter holding at most capacity limiters, built by create on first use.
+func NewPerKey[K comparable](capacity int, create func(key K) Limiter) *PerKey[K] {
+	return &PerKey[K]{
+		limiters: lru.NewCache(capacity, lru.Noop()),
+		create:   create,
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// Limiter returns the limiter for key, creating it if needed.
+func (p *PerKey[K]) Limiter(key K) Limiter {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if val, ok := p.limiters.Get(key); ok {
+		return val.(Limiter)
+	}
+	limiter := p.create(key)
+	p.limiters.Set(key, limiter)
+	return limiter
+}
+
+// Allow reports whether an event for key may happen now.
+func (p *PerKey[K]) Allow(key K) bool {
+	return p.Limiter(key).Allow()
+}
+
+// Wait blocks until an event for key may happen or ctx is done.
+func (p *PerKey[K]) Wait(ctx context.Context, key K) error {
+	return p.Limiter(key).Wait(ctx)
+}
+
+// Remove discards the limiter for key.
+func (p *PerKey[K]) Remove(key K) {
+	p.limiters.Remove(key)
+}
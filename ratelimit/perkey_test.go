@@ -0,0 +1,48 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cjsaylor/goutil/ratelimit"
+)
+
+func newPerKey(capacity int) *ratelimit.PerKey[string] {
+	return ratelimit.NewPerKey(capacity, func(key string) ratelimit.Limiter {
+		return ratelimit.NewTokenBucket(0, 1)
+	})
+}
+
+func TestPerKeyIndependent(t *testing.T) {
+	limiter := newPerKey(10)
+	if !limiter.Allow("a") || !limiter.Allow("b") {
+		t.Error("Expected each key to have its own budget")
+	}
+	if limiter.Allow("a") {
+		t.Error("Expected a spent key to be denied")
+	}
+	if err := limiter.Wait(context.Background(), "a"); err != ratelimit.ErrUnavailable {
+		t.Errorf("Expected the key's limiter to be used got %v", err)
+	}
+}
+
+func TestPerKeyEviction(t *testing.T) {
+	limiter := newPerKey(1)
+	limiter.Allow("a")
+	limiter.Allow("b")
+	if !limiter.Allow("a") {
+		t.Error("Expected an evicted key to start with a fresh limiter")
+	}
+}
+
+func TestPerKeyRemove(t *testing.T) {
+	limiter := newPerKey(10)
+	first := limiter.Limiter("a")
+	if limiter.Limiter("a") != first {
+		t.Error("Expected the same limiter for a key")
+	}
+	limiter.Remove("a")
+	if limiter.Limiter("a") == first {
+		t.Error("Expected a removed key to get a new limiter")
+	}
+}
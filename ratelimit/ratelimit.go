@@ -0,0 +1,18 @@
+// Package ratelimit is a package that limits how often events may happen.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnavailable is returned when waiting for an event the limiter can never allow.
+var ErrUnavailable = errors.New("ratelimit: event can never be allowed")
+
+// Limiter decides whether an event may happen now.
+type Limiter interface {
+	// Allow reports whether an event may happen now, counting it if so.
+	Allow() bool
+	// Wait blocks until an event may happen or ctx is done.
+	Wait(ctx context.Context) error
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SlidingWindow is a limiter that allows a fixed number of events in any rolling window of time.
+//
+// It keeps counts for only the current and previous fixed windows, estimating the rolling count by
+// weighting the previous window by how much of it still overlaps the rolling window. This assumes
+// events in the previous window were evenly spread, in exchange for constant memory.
+// It is safe for concurrent use.
+type SlidingWindow struct {
+	limit    int
+	window   time.Duration
+	start    time.Time
+	current  int
+	previous int
+	mutex    *sync.Mutex
+}
+
+// NewSlidingWindow creates a limiter that allows limit events per rolling window. It panics if window
+// is not positive.
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	if window <= 0 {
+		panic("ratelimit: window must be positive")
+	}
+	return &SlidingWindow{
+		limit:  limit,
+		window: window,
+		start:  time.Now(),
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// Allow reports whether an event may happen now, counting it if so.
+func (w *SlidingWindow) Allow() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	now := time.Now()
+	if w.delay(now) > 0 {
+		return false
+	}
+	w.current++
+	return true
+}
+
+// Wait blocks until an event may happen or ctx is done.
+func (w *SlidingWindow) Wait(ctx context.Context) error {
+	if w.limit < 1 {
+		return ErrUnavailable
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		w.mutex.Lock()
+		now := time.Now()
+		delay := w.delay(now)
+		if delay <= 0 {
+			w.current++
+			w.mutex.Unlock()
+			return nil
+		}
+		w.mutex.Unlock()
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+			return context.DeadlineExceeded
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Count returns the estimated number of events in the rolling window ending now.
+func (w *SlidingWindow) Count() float64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	now := time.Now()
+	w.advance(now)
+	return w.estimate(now)
+}
+
+// delay returns how long until one more event fits in the window, or zero if it fits now.
+func (w *SlidingWindow) delay(now time.Time) time.Duration {
+	w.advance(now)
+	if w.estimate(now)+1 <= float64(w.limit) {
+		return 0
+	}
+	end := w.start.Add(w.window)
+	if w.current+1 > w.limit {
+		// Nothing leaves the current window until it becomes the previous one.
+		return end.Sub(now)
+	}
+	// Wait for enough of the previous window to slide out:
+	// previous * (1 - elapsed/window) + current + 1 <= limit.
+	elapsed := float64(w.window) * (1 - float64(w.limit-w.current-1)/float64(w.previous))
+	if delay := w.start.Add(time.Duration(elapsed)).Sub(now); delay > 0 {
+		return delay
+	}
+	return time.Nanosecond
+}
+
+func (w *SlidingWindow) estimate(now time.Time) float64 {
+	overlap := 1 - float64(now.Sub(w.start))/float64(w.window)
+	return float64(w.previous)*overlap + float64(w.current)
+}
+
+func (w *SlidingWindow) advance(now time.Time) {
+	elapsed := now.Sub(w.start)
+	if elapsed < w.window {
+		return
+	}
+	windows := elapsed / w.window
+	if windows == 1 {
+		w.previous = w.current
+	} else {
+		w.previous = 0
+	}
+	w.current = 0
+	w.start = w.start.Add(windows * w.window)
+}
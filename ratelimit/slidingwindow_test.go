@@ -0,0 +1,80 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/ratelimit"
+)
+
+var _ ratelimit.Limiter = ratelimit.NewSlidingWindow(1, time.Second)
+
+func TestSlidingWindowAllow(t *testing.T) {
+	w := ratelimit.NewSlidingWindow(3, time.Hour)
+	for i := 0; i < 3; i++ {
+		if !w.Allow() {
+			t.Errorf("Expected event %d to be allowed", i)
+		}
+	}
+	if w.Allow() {
+		t.Error("Expected the window limit to deny")
+	}
+	if count := w.Count(); count != 3 {
+		t.Errorf("Expected a count of 3 got %v", count)
+	}
+}
+
+func TestSlidingWindowRolls(t *testing.T) {
+	w := ratelimit.NewSlidingWindow(2, 20*time.Millisecond)
+	w.Allow()
+	w.Allow()
+	time.Sleep(60 * time.Millisecond)
+	if !w.Allow() {
+		t.Error("Expected old events to have left the window")
+	}
+}
+
+func TestSlidingWindowWeightsPrevious(t *testing.T) {
+	w := ratelimit.NewSlidingWindow(4, 40*time.Millisecond)
+	for i := 0; i < 4; i++ {
+		w.Allow()
+	}
+	// Sleep into the next window, where most of the previous window still overlaps.
+	time.Sleep(45 * time.Millisecond)
+	if count := w.Count(); count < 2 || count > 4 {
+		t.Errorf("Expected the previous window to be weighted in got %v", count)
+	}
+}
+
+func TestSlidingWindowWait(t *testing.T) {
+	w := ratelimit.NewSlidingWindow(2, 20*time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := w.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected waits to respect the window got %v", elapsed)
+	}
+}
+
+func TestSlidingWindowWaitDeadline(t *testing.T) {
+	w := ratelimit.NewSlidingWindow(1, time.Hour)
+	w.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+}
+
+func TestSlidingWindowInvalidWindow(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a zero window to panic")
+		}
+	}()
+	ratelimit.NewSlidingWindow(1, 0)
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket is a token bucket limiter. Tokens are added at a steady rate up to the burst size and
+// each event spends one. It is safe for concurrent use.
+type TokenBucket struct {
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+	mutex  *sync.Mutex
+}
+
+// NewTokenBucket creates a limiter that allows rate events per second on average and up to burst
+// events at once. The bucket starts full.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// Allow spends a token if one is available and reports whether it did.
+func (b *TokenBucket) Allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.advance(time.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Reserve spends a token now, borrowing against future refills if the bucket is empty. The returned
+// reservation reports how long to wait before acting on it.
+func (b *TokenBucket) Reserve() *Reservation {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := time.Now()
+	b.advance(now)
+	if b.burst < 1 || (b.tokens < 1 && b.rate <= 0) {
+		return &Reservation{}
+	}
+	b.tokens--
+	r := &Reservation{ok: true, at: now, bucket: b}
+	if b.tokens < 0 {
+		r.at = now.Add(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+	return r
+}
+
+// Wait blocks until a token is available or ctx is done. It returns immediately with
+// context.DeadlineExceeded when ctx's deadline is sooner than the token would be.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r := b.Reserve()
+	if !r.OK() {
+		return ErrUnavailable
+	}
+	return r.wait(ctx)
+}
+
+// SetRate changes the refill rate. Tokens accrued at the old rate are kept.
+func (b *TokenBucket) SetRate(rate float64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.advance(time.Now())
+	b.rate = rate
+}
+
+// SetBurst changes the bucket size, dropping any tokens above the new size.
+func (b *TokenBucket) SetBurst(burst int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.advance(time.Now())
+	b.burst = burst
+	b.tokens = math.Min(b.tokens, float64(burst))
+}
+
+// Tokens returns the number of tokens currently available. It is negative while reservations are
+// borrowing against future refills.
+func (b *TokenBucket) Tokens() float64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.advance(time.Now())
+	return b.tokens
+}
+
+func (b *TokenBucket) advance(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.burst), b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// Reservation is a token taken from a TokenBucket that may not be usable yet.
+type Reservation struct {
+	ok       bool
+	at       time.Time
+	bucket   *TokenBucket
+	canceled bool
+}
+
+// OK reports whether the limiter granted the reservation. A reservation that is not OK can never be
+// acted on.
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay returns how long to wait before acting on the reservation.
+func (r *Reservation) Delay() time.Duration {
+	if !r.ok {
+		return time.Duration(math.MaxInt64)
+	}
+	if delay := time.Until(r.at); delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// Cancel returns the reserved token to the bucket if the reservation has not come due.
+func (r *Reservation) Cancel() {
+	if !r.ok {
+		return
+	}
+	b := r.bucket
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := time.Now()
+	if r.canceled || !now.Before(r.at) {
+		return
+	}
+	r.canceled = true
+	b.advance(now)
+	b.tokens = math.Min(float64(b.burst), b.tokens+1)
+}
+
+func (r *Reservation) wait(ctx context.Context) error {
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(r.at) {
+		r.Cancel()
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,98 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/ratelimit"
+)
+
+func TestTokenBucketAllow(t *testing.T) {
+	b := ratelimit.NewTokenBucket(1, 3)
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Errorf("Expected burst event %d to be allowed", i)
+		}
+	}
+	if b.Allow() {
+		t.Error("Expected an empty bucket to deny")
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	b := ratelimit.NewTokenBucket(100, 1)
+	b.Allow()
+	time.Sleep(15 * time.Millisecond)
+	if !b.Allow() {
+		t.Error("Expected a token to be refilled")
+	}
+}
+
+func TestTokenBucketReserve(t *testing.T) {
+	b := ratelimit.NewTokenBucket(10, 1)
+	b.Allow()
+	r := b.Reserve()
+	if !r.OK() {
+		t.Fatal("Expected the reservation to be granted")
+	}
+	if delay := r.Delay(); delay < 50*time.Millisecond || delay > 100*time.Millisecond {
+		t.Errorf("Expected a delay of about 100ms got %v", delay)
+	}
+	r.Cancel()
+	if tokens := b.Tokens(); tokens < 0 || tokens >= 1 {
+		t.Errorf("Expected cancel to return the token got %v", tokens)
+	}
+}
+
+func TestTokenBucketUnavailable(t *testing.T) {
+	b := ratelimit.NewTokenBucket(0, 1)
+	b.Allow()
+	if b.Reserve().OK() {
+		t.Error("Expected a zero rate to deny reservations once empty")
+	}
+	if err := b.Wait(context.Background()); err != ratelimit.ErrUnavailable {
+		t.Errorf("Expected unavailable got %v", err)
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	b := ratelimit.NewTokenBucket(50, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := b.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected waits to be paced got %v", elapsed)
+	}
+}
+
+func TestTokenBucketWaitDeadline(t *testing.T) {
+	b := ratelimit.NewTokenBucket(1, 1)
+	b.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := b.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+	if time.Since(start) > 5*time.Millisecond {
+		t.Error("Expected Wait to fail without sleeping")
+	}
+}
+
+func TestTokenBucketSetRate(t *testing.T) {
+	b := ratelimit.NewTokenBucket(0, 1)
+	b.Allow()
+	b.SetRate(1000)
+	time.Sleep(5 * time.Millisecond)
+	if !b.Allow() {
+		t.Error("Expected the new rate to refill the bucket")
+	}
+	b.SetBurst(0)
+	if b.Allow() {
+		t.Error("Expected a zero burst to deny")
+	}
+}
@@ -0,0 +1,431 @@
+// Package replicate is a package that streams writes to an LRU cache to peer processes over TCP, so
+// a small fleet keeps roughly warm local caches.
+//
+// Replication is best-effort. Each node dials every peer and streams its Set and Remove operations
+// to it, queueing them while the peer is slow or unreachable and dropping them once the queue is
+// full. Whenever a connection is established the node first sends a snapshot of its cache, so a
+// peer that joins or reconnects catches up on the entries it missed. There is no authentication or
+// encryption, so peers must be on a trusted network.
+//
+// Only entries with string keys and []byte values are replicated. Entries larger than 64MB are not
+// sent; setting one removes the key from peers instead, so they do not keep a stale value.
+package replicate
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cjsaylor/goutil/lru"
+	"github.com/cjsaylor/goutil/wait"
+)
+
+const (
+	opSet    byte = 1
+	opRemove byte = 2
+
+	frameHeaderSize = 9
+	maxFrame        = 1 << 26
+)
+
+// ErrInvalidFrame is reported when a peer sends data that is not a replication operation.
+var ErrInvalidFrame = errors.New("replicate: invalid frame")
+
+// Options configures a node. Zero fields take the defaults noted on each.
+type Options struct {
+	// Addr is the TCP address peers connect to. Defaults to ":0", a random port.
+	Addr string
+	// Peers are the addresses of the other nodes. More can be added with AddPeer.
+	Peers []string
+	// Buffer is the number of operations queued for each peer. Operations beyond it are dropped.
+	// Defaults to 1024.
+	Buffer int
+	// ReconnectDelay is the delay before redialing a peer, doubling after each failure.
+	// Defaults to 100 milliseconds.
+	ReconnectDelay time.Duration
+	// MaxReconnectDelay caps the delay between dials. Defaults to 10 seconds.
+	MaxReconnectDelay time.Duration
+	// Dial connects to a peer. Defaults to a net.Dialer with a 5 second timeout.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	// OnError is called with connection errors, which are otherwise dropped.
+	OnError func(peer string, err error)
+}
+
+// Stats are counters for a node.
+type Stats struct {
+	// Sent is the number of operations written to peers, including snapshot entries.
+	Sent uint64
+	// Received is the number of operations applied from peers.
+	Received uint64
+	// Dropped is the number of operations discarded because a peer's queue was full or the key was
+	// too large to send.
+	Dropped uint64
+	// Connected is the number of peers currently being streamed to.
+	Connected int
+}
+
+type op struct {
+	kind  byte
+	key   string
+	value []byte
+}
+
+type peer struct {
+	addr      string
+	queue     chan op
+	connected atomic.Bool
+}
+
+// Node replicates a cache to its peers and applies the operations they send. It is safe for
+// concurrent use.
+type Node struct {
+	cache    *lru.Cache
+	options  Options
+	listener net.Listener
+	peers    map[string]*peer
+	conns    map[net.Conn]struct{}
+	closed   bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	sent     atomic.Uint64
+	received atomic.Uint64
+	dropped  atomic.Uint64
+	wg       *sync.WaitGroup
+	mutex    *sync.Mutex
+}
+
+// New starts a node replicating cache. It listens on options.Addr and begins dialing options.Peers.
+func New(cache *lru.Cache, options Options) (*Node, error) {
+	if options.Addr == "" {
+		options.Addr = ":0"
+	}
+	if options.Buffer <= 0 {
+		options.Buffer = 1024
+	}
+	if options.ReconnectDelay <= 0 {
+		options.ReconnectDelay = 100 * time.Millisecond
+	}
+	if options.MaxReconnectDelay <= 0 {
+		options.MaxReconnectDelay = 10 * time.Second
+	}
+	if options.Dial == nil {
+		options.Dial = (&net.Dialer{Timeout: 5 * time.Second}).DialContext
+	}
+	listener, err := net.Listen("tcp", options.Addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Node{
+		cache:    cache,
+		options:  options,
+		listener: listener,
+		peers:    make(map[string]*peer),
+		conns:    make(map[net.Conn]struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		wg:       &sync.WaitGroup{},
+		mutex:    &sync.Mutex{},
+	}
+	n.wg.Add(1)
+	go n.accept()
+	for _, addr := range options.Peers {
+		n.AddPeer(addr)
+	}
+	return n, nil
+}
+
+// Addr returns the address the node accepts peers on.
+func (n *Node) Addr() net.Addr {
+	return n.listener.Addr()
+}
+
+// AddPeer starts replicating to the node at addr. Adding a peer twice, or after Close, does
+// nothing.
+func (n *Node) AddPeer(addr string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if _, ok := n.peers[addr]; ok || n.closed {
+		return
+	}
+	p := &peer{addr: addr, queue: make(chan op, n.options.Buffer)}
+	n.peers[addr] = p
+	n.wg.Add(1)
+	go n.replicate(p)
+}
+
+// Set a key/value into the cache and send it to peers.
+func (n *Node) Set(key string, value []byte) {
+	n.cache.Set(key, value)
+	n.publish(op{kind: opSet, key: key, value: value})
+}
+
+// Remove an entry from the cache and from peers.
+func (n *Node) Remove(key string) {
+	n.cache.Remove(key)
+	n.publish(op{kind: opRemove, key: key})
+}
+
+// Stats returns the node's counters.
+func (n *Node) Stats() Stats {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	connected := 0
+	for _, p := range n.peers {
+		if p.connected.Load() {
+			connected++
+		}
+	}
+	return Stats{
+		Sent:      n.sent.Load(),
+		Received:  n.received.Load(),
+		Dropped:   n.dropped.Load(),
+		Connected: connected,
+	}
+}
+
+// Close stops accepting and dialing peers, closes every connection and waits for them to finish.
+// Queued operations that have not been sent are discarded.
+func (n *Node) Close() error {
+	n.mutex.Lock()
+	if n.closed {
+		n.mutex.Unlock()
+		return nil
+	}
+	n.closed = true
+	for conn := range n.conns {
+		conn.Close()
+	}
+	n.mutex.Unlock()
+	n.cancel()
+	err := n.listener.Close()
+	n.wg.Wait()
+	return err
+}
+
+func (n *Node) publish(o op) {
+	if oversize(o) {
+		if len(o.key) > maxFrame {
+			n.dropped.Add(1)
+			return
+		}
+		o = op{kind: opRemove, key: o.key}
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	for _, p := range n.peers {
+		select {
+		case p.queue <- o:
+		default:
+			n.dropped.Add(1)
+		}
+	}
+}
+
+// track registers conn so Close can interrupt it. It returns false if the node is closed.
+func (n *Node) track(conn net.Conn) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.closed {
+		return false
+	}
+	n.conns[conn] = struct{}{}
+	return true
+}
+
+func (n *Node) untrack(conn net.Conn) {
+	n.mutex.Lock()
+	delete(n.conns, conn)
+	n.mutex.Unlock()
+	conn.Close()
+}
+
+func (n *Node) report(addr string, err error) {
+	if n.options.OnError != nil && err != nil && n.ctx.Err() == nil {
+		n.options.OnError(addr, err)
+	}
+}
+
+func (n *Node) accept() {
+	defer n.wg.Done()
+	for {
+		conn, err := n.listener.Accept()
+		if err != nil {
+			return
+		}
+		if !n.track(conn) {
+			conn.Close()
+			return
+		}
+		n.wg.Add(1)
+		go n.receive(conn)
+	}
+}
+
+func (n *Node) receive(conn net.Conn) {
+	defer n.wg.Done()
+	defer n.untrack(conn)
+	r := bufio.NewReader(conn)
+	for {
+		o, err := readOp(r)
+		if err != nil {
+			if err != io.EOF {
+				n.report(conn.RemoteAddr().String(), err)
+			}
+			return
+		}
+		switch o.kind {
+		case opSet:
+			n.cache.Set(o.key, o.value)
+		case opRemove:
+			n.cache.Remove(o.key)
+		}
+		n.received.Add(1)
+	}
+}
+
+// replicate keeps a connection to p open, redialing with backoff, until the node is closed.
+func (n *Node) replicate(p *peer) {
+	defer n.wg.Done()
+	backoff := wait.Options{Initial: n.options.ReconnectDelay, Max: n.options.MaxReconnectDelay}
+	for {
+		var conn net.Conn
+		err := wait.Backoff(n.ctx, backoff, func(ctx context.Context) (bool, error) {
+			c, err := n.options.Dial(ctx, "tcp", p.addr)
+			if err != nil {
+				n.report(p.addr, err)
+				return false, nil
+			}
+			conn = c
+			return true, nil
+		})
+		if err != nil {
+			return
+		}
+		if !n.track(conn) {
+			conn.Close()
+			return
+		}
+		err = n.stream(p, conn)
+		n.untrack(conn)
+		if n.ctx.Err() != nil {
+			return
+		}
+		n.report(p.addr, err)
+	}
+}
+
+// stream sends a snapshot of the cache to p followed by queued operations until a write fails, the
+// peer hangs up or the node is closed.
+func (n *Node) stream(p *peer, conn net.Conn) error {
+	p.connected.Store(true)
+	defer p.connected.Store(false)
+	// Peers never write back, so a finished read means the connection is gone. Without this an idle
+	// connection to a restarted peer would only be noticed on the next failed write.
+	gone := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(gone)
+	}()
+	defer func() {
+		conn.Close()
+		<-gone
+	}()
+	// Operations queued while disconnected are older than the snapshot, and after drops may be
+	// missing some in between, so they are discarded. Anything published from here on is sent after
+	// the snapshot, at worst repeating an entry it already holds.
+	for drained := false; !drained; {
+		select {
+		case <-p.queue:
+		default:
+			drained = true
+		}
+	}
+	w := bufio.NewWriter(conn)
+	for _, o := range n.snapshot() {
+		if err := writeOp(w, o); err != nil {
+			return err
+		}
+		n.sent.Add(1)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-n.ctx.Done():
+			return nil
+		case <-gone:
+			return io.EOF
+		case o := <-p.queue:
+			if err := writeOp(w, o); err != nil {
+				return err
+			}
+			n.sent.Add(1)
+			if len(p.queue) == 0 {
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// snapshot returns the replicable entries of the cache, oldest first so the peer ends with the same
+// recency.
+func (n *Node) snapshot() []op {
+	var ops []op
+	for key, value := range n.cache.All() {
+		k, ok := key.(string)
+		v, isBytes := value.([]byte)
+		if o := (op{kind: opSet, key: k, value: v}); ok && isBytes && !oversize(o) {
+			ops = append(ops, o)
+		}
+	}
+	slices.Reverse(ops)
+	return ops
+}
+
+// oversize reports whether o is larger than a peer accepts.
+func oversize(o op) bool {
+	return len(o.key)+len(o.value) > maxFrame
+}
+
+func writeOp(w *bufio.Writer, o op) error {
+	header := [frameHeaderSize]byte{o.kind}
+	binary.BigEndian.PutUint32(header[1:], uint32(len(o.key)))
+	binary.BigEndian.PutUint32(header[5:], uint32(len(o.value)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(o.key); err != nil {
+		return err
+	}
+	_, err := w.Write(o.value)
+	return err
+}
+
+func readOp(r *bufio.Reader) (op, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return op{}, err
+	}
+	kind := header[0]
+	keyLen := binary.BigEndian.Uint32(header[1:])
+	valueLen := binary.BigEndian.Uint32(header[5:])
+	if (kind != opSet && kind != opRemove) || uint64(keyLen)+uint64(valueLen) > maxFrame {
+		return op{}, ErrInvalidFrame
+	}
+	data := make([]byte, int(keyLen)+int(valueLen))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return op{}, err
+	}
+	return op{kind: kind, key: string(data[:keyLen]), value: data[keyLen:]}, nil
+}
@@ -0,0 +1,174 @@
+package replicate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/leaktest"
+	"github.com/cjsaylor/goutil/lru"
+	"github.com/cjsaylor/goutil/replicate"
+	"github.com/cjsaylor/goutil/wait"
+)
+
+func waitFor(condition func() bool) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return wait.Until(ctx, time.Millisecond, wait.Bool(condition)) == nil
+}
+
+func has(cache *lru.Cache, key, value string) func() bool {
+	return func() bool {
+		got, ok := cache.Get(key)
+		return ok && string(got.([]byte)) == value
+	}
+}
+
+func start(t *testing.T, addr string, cache *lru.Cache) *replicate.Node {
+	t.Helper()
+	node, err := replicate.New(cache, replicate.Options{Addr: addr, ReconnectDelay: time.Millisecond, MaxReconnectDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestReplication(t *testing.T) {
+	defer leaktest.Check(t)()
+	first, second := lru.NewCache(10, lru.Noop()), lru.NewCache(10, lru.Noop())
+	a := start(t, "127.0.0.1:0", first)
+	defer a.Close()
+	a.Set("early", []byte("1"))
+	first.Set(42, "not replicated")
+
+	b := start(t, "127.0.0.1:0", second)
+	defer b.Close()
+	a.AddPeer(b.Addr().String())
+	b.AddPeer(a.Addr().String())
+	if !waitFor(has(second, "early", "1")) {
+		t.Fatal("Expected a joining node to receive a snapshot")
+	}
+	if _, ok := second.Get(42); ok {
+		t.Error("Expected only string keys with byte values to be replicated")
+	}
+
+	a.Set("late", []byte("2"))
+	if !waitFor(has(second, "late", "2")) {
+		t.Error("Expected a set to be streamed to the peer")
+	}
+	b.Remove("early")
+	if !waitFor(func() bool { _, ok := first.Get("early"); return !ok }) {
+		t.Error("Expected a remove to be streamed back")
+	}
+	// The peer's snapshot may include entries it received from this node, so only a lower bound
+	// holds.
+	if stats := a.Stats(); stats.Connected != 1 || stats.Received < 1 {
+		t.Errorf("Expected one connected peer and received operations got %+v", stats)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	defer leaktest.Check(t)()
+	a := start(t, "127.0.0.1:0", lru.NewCache(10, lru.Noop()))
+	defer a.Close()
+	b := start(t, "127.0.0.1:0", lru.NewCache(10, lru.Noop()))
+	addr := b.Addr().String()
+	a.AddPeer(addr)
+	if !waitFor(func() bool { return a.Stats().Connected == 1 }) {
+		t.Fatal("Expected the peer to connect")
+	}
+	b.Close()
+	a.Set("missed", []byte("while down"))
+
+	cache := lru.NewCache(10, lru.Noop())
+	b = start(t, addr, cache)
+	defer b.Close()
+	if !waitFor(has(cache, "missed", "while down")) {
+		t.Error("Expected the restarted peer to catch up after reconnecting")
+	}
+}
+
+func TestSnapshotSupersedesQueue(t *testing.T) {
+	defer leaktest.Check(t)()
+	reserved := start(t, "127.0.0.1:0", lru.NewCache(10, lru.Noop()))
+	addr := reserved.Addr().String()
+	reserved.Close()
+	a, err := replicate.New(lru.NewCache(10, lru.Noop()), replicate.Options{
+		Addr:              "127.0.0.1:0",
+		Buffer:            1,
+		ReconnectDelay:    time.Millisecond,
+		MaxReconnectDelay: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	a.AddPeer(addr)
+	a.Set("k", []byte("old"))
+	a.Set("k", []byte("new"))
+
+	cache := lru.NewCache(10, lru.Noop())
+	b := start(t, addr, cache)
+	defer b.Close()
+	if !waitFor(func() bool { return a.Stats().Connected == 1 }) {
+		t.Fatal("Expected the peer to connect")
+	}
+	a.Set("marker", []byte("1"))
+	if !waitFor(has(cache, "marker", "1")) {
+		t.Fatal("Expected the marker to be replicated")
+	}
+	if !has(cache, "k", "new")() {
+		t.Error("Expected queued operations older than the snapshot not to overwrite it")
+	}
+}
+
+func TestDropWhenFull(t *testing.T) {
+	cache := lru.NewCache(10, lru.Noop())
+	node, err := replicate.New(cache, replicate.Options{Addr: "127.0.0.1:0", Buffer: 1, ReconnectDelay: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer node.Close()
+	// Nothing listens on port 1, so operations queue until the buffer is full.
+	node.AddPeer("127.0.0.1:1")
+	node.Set("a", []byte("1"))
+	node.Set("b", []byte("2"))
+	if stats := node.Stats(); stats.Dropped != 1 {
+		t.Errorf("Expected the second operation to be dropped got %+v", stats)
+	}
+	if _, ok := cache.Get("b"); !ok {
+		t.Error("Expected the local cache to be written regardless")
+	}
+}
+
+func TestOversize(t *testing.T) {
+	defer leaktest.Check(t)()
+	first, second, third := lru.NewCache(10, lru.Noop()), lru.NewCache(10, lru.Noop()), lru.NewCache(10, lru.Noop())
+	a := start(t, "127.0.0.1:0", first)
+	defer a.Close()
+	b := start(t, "127.0.0.1:0", second)
+	defer b.Close()
+	a.AddPeer(b.Addr().String())
+	a.Set("big", []byte("small"))
+	if !waitFor(has(second, "big", "small")) {
+		t.Fatal("Expected the small value to be replicated")
+	}
+	a.Set("big", make([]byte, 1<<26+1))
+	a.Set("after", []byte("1"))
+	if !waitFor(has(second, "after", "1")) {
+		t.Fatal("Expected replication to continue past an oversize value")
+	}
+	if _, ok := second.Get("big"); ok {
+		t.Error("Expected the peer to drop its stale copy of an oversize value")
+	}
+
+	c := start(t, "127.0.0.1:0", third)
+	defer c.Close()
+	a.AddPeer(c.Addr().String())
+	if !waitFor(has(third, "after", "1")) {
+		t.Fatal("Expected a snapshot to skip the oversize value")
+	}
+	if _, ok := third.Get("big"); ok {
+		t.Error("Expected the oversize value to be left out of the snapshot")
+	}
+}
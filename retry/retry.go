@@ -0,0 +1,157 @@
+// Package retry is a package that retries failing operations with exponential backoff.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+// Jitter controls how randomness is applied to backoff delays, which keeps many clients that failed
+// together from retrying in lockstep.
+type Jitter int
+
+const (
+	// NoJitter waits the exact backoff delay.
+	NoJitter Jitter = iota
+	// FullJitter waits a random delay between zero and the backoff delay.
+	FullJitter
+	// EqualJitter waits half the backoff delay plus a random delay up to the other half.
+	EqualJitter
+)
+
+// Options configures retries. Zero fields take the defaults noted on each.
+type Options struct {
+	// MaxAttempts is the most attempts made, including the first. Defaults to 3; negative means no
+	// limit other than MaxElapsed and the context.
+	MaxAttempts int
+	// MaxElapsed stops retrying once this much time has passed since the first attempt. Zero means
+	// no limit.
+	MaxElapsed time.Duration
+	// InitialDelay is the delay before the first retry. Defaults to 100 milliseconds.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts. Defaults to 10 seconds.
+	MaxDelay time.Duration
+	// Multiplier is the factor the delay grows by after each retry. Defaults to 2.
+	Multiplier float64
+	// Jitter selects how delays are randomized.
+	Jitter Jitter
+	// Retryable decides whether an error is worth retrying. Defaults to every error except those
+	// marked Permanent. Context errors are never retried.
+	Retryable func(err error) bool
+	// OnRetry is called after a failed attempt, before waiting delay for the next one.
+	OnRetry func(attempt int, err error, delay time.Duration)
+	// Clock measures elapsed time and waits between attempts. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+type permanent struct {
+	err error
+}
+
+func (p *permanent) Error() string {
+	return p.err.Error()
+}
+
+func (p *permanent) Unwrap() error {
+	return p.err
+}
+
+// Permanent marks err as not worth retrying. Do returns err itself, without the mark.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanent{err: err}
+}
+
+// Do calls fn until it succeeds, returns an error that should not be retried, or runs out of
+// attempts or time. It returns nil on success and otherwise the last error from fn, joined with the
+// context's error if ctx ended the retries.
+func Do(ctx context.Context, fn func(ctx context.Context) error, options Options) error {
+	_, err := Value(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, options)
+	return err
+}
+
+// Value is like Do for functions that return a result.
+func Value[T any](ctx context.Context, fn func(ctx context.Context) (T, error), options Options) (T, error) {
+	options = withDefaults(options)
+	start := options.Clock.Now()
+	delay := options.InitialDelay
+	for attempt := 1; ; attempt++ {
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+		var p *permanent
+		if errors.As(err, &p) {
+			return value, p.err
+		}
+		if ctx.Err() != nil {
+			return value, errors.Join(err, ctx.Err())
+		}
+		if !options.Retryable(err) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return value, err
+		}
+		if options.MaxAttempts > 0 && attempt >= options.MaxAttempts {
+			return value, err
+		}
+		wait := jitter(delay, options.Jitter)
+		if options.MaxElapsed > 0 && options.Clock.Since(start)+wait > options.MaxElapsed {
+			return value, err
+		}
+		if options.OnRetry != nil {
+			options.OnRetry(attempt, err, wait)
+		}
+		timer := options.Clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return value, errors.Join(err, ctx.Err())
+		}
+		delay = time.Duration(float64(delay) * options.Multiplier)
+		if delay > options.MaxDelay || delay <= 0 {
+			delay = options.MaxDelay
+		}
+	}
+}
+
+func withDefaults(options Options) Options {
+	options.Clock = clock.OrReal(options.Clock)
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = 3
+	}
+	if options.InitialDelay <= 0 {
+		options.InitialDelay = 100 * time.Millisecond
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = 10 * time.Second
+	}
+	if options.InitialDelay > options.MaxDelay {
+		options.InitialDelay = options.MaxDelay
+	}
+	if options.Multiplier < 1 {
+		options.Multiplier = 2
+	}
+	if options.Retryable == nil {
+		options.Retryable = func(err error) bool { return true }
+	}
+	return options
+}
+
+func jitter(delay time.Duration, mode Jitter) time.Duration {
+	switch mode {
+	case FullJitter:
+		return time.Duration(rand.Int63n(int64(delay) + 1))
+	case EqualJitter:
+		half := delay / 2
+		return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+	}
+	return delay
+}
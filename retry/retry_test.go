@@ -0,0 +1,169 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/retry"
+)
+
+var errFailed = errors.New("failed")
+
+func TestDoSucceeds(t *testing.T) {
+	attempts := 0
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errFailed
+		}
+		return nil
+	}, retry.Options{InitialDelay: time.Millisecond})
+	if err != nil || attempts != 3 {
+		t.Errorf("Expected success on attempt 3 got %v after %d", err, attempts)
+	}
+}
+
+func TestMaxAttempts(t *testing.T) {
+	attempts := 0
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return errFailed
+	}, retry.Options{MaxAttempts: 4, InitialDelay: time.Millisecond})
+	if err != errFailed || attempts != 4 {
+		t.Errorf("Expected the last error after 4 attempts got %v after %d", err, attempts)
+	}
+}
+
+func TestMaxElapsed(t *testing.T) {
+	start := time.Now()
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		return errFailed
+	}, retry.Options{MaxAttempts: -1, MaxElapsed: 20 * time.Millisecond, InitialDelay: 5 * time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	if err != errFailed {
+		t.Errorf("Expected the last error got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected retries to stop near MaxElapsed got %v", elapsed)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	attempts := 0
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return retry.Permanent(errFailed)
+	}, retry.Options{})
+	if err != errFailed || attempts != 1 {
+		t.Errorf("Expected a single attempt with the unwrapped error got %v after %d", err, attempts)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	attempts := 0
+	retry.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return errFailed
+	}, retry.Options{Retryable: func(err error) bool { return err != errFailed }})
+	if attempts != 1 {
+		t.Errorf("Expected non-retryable errors to stop got %d attempts", attempts)
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := retry.Do(ctx, func(ctx context.Context) error {
+		return errFailed
+	}, retry.Options{MaxAttempts: -1, InitialDelay: time.Hour})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errFailed) {
+		t.Errorf("Expected the last error and the context error got %v", err)
+	}
+}
+
+func TestOnRetryBackoff(t *testing.T) {
+	var delays []time.Duration
+	retry.Do(context.Background(), func(ctx context.Context) error {
+		return errFailed
+	}, retry.Options{
+		MaxAttempts:  5,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     4 * time.Millisecond,
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			delays = append(delays, delay)
+		},
+	})
+	expected := []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 4 * time.Millisecond}
+	if len(delays) != len(expected) {
+		t.Fatalf("Expected %d retries got %d", len(expected), len(delays))
+	}
+	for i := range expected {
+		if delays[i] != expected[i] {
+			t.Errorf("Expected delay %v got %v", expected[i], delays[i])
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for _, mode := range []retry.Jitter{retry.FullJitter, retry.EqualJitter} {
+		retry.Do(context.Background(), func(ctx context.Context) error {
+			return errFailed
+		}, retry.Options{
+			InitialDelay: 2 * time.Millisecond,
+			Jitter:       mode,
+			OnRetry: func(attempt int, err error, delay time.Duration) {
+				low := time.Duration(0)
+				if mode == retry.EqualJitter {
+					low = time.Millisecond << (attempt - 1)
+				}
+				if delay < low || delay > 2*time.Millisecond<<(attempt-1) {
+					t.Errorf("Expected a jittered delay in range got %v", delay)
+				}
+			},
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	attempts := 0
+	val, err := retry.Value(context.Background(), func(ctx context.Context) (int, error) {
+		attempts++
+		if attempts == 1 {
+			return 0, errFailed
+		}
+		return 42, nil
+	}, retry.Options{InitialDelay: time.Millisecond})
+	if val != 42 || err != nil {
+		t.Errorf("Expected 42 got %v (%v)", val, err)
+	}
+}
+
+func TestClock(t *testing.T) {
+	start := time.Unix(0, 0)
+	fake := clock.NewFake(start)
+	done := make(chan error)
+	attempts := 0
+	go func() {
+		done <- retry.Do(context.Background(), func(ctx context.Context) error {
+			attempts++
+			return errFailed
+		}, retry.Options{MaxAttempts: -1, MaxElapsed: time.Minute, InitialDelay: 10 * time.Second, MaxDelay: 10 * time.Second, Clock: fake})
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// Attempts run at 0s, 10s, ... 60s, after which the next wait would pass MaxElapsed.
+	for i := 0; i < 6; i++ {
+		if err := fake.BlockUntil(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		fake.Advance(10 * time.Second)
+	}
+	if err := <-done; !errors.Is(err, errFailed) || attempts != 7 {
+		t.Errorf("Expected 7 attempts got %d (%v)", attempts, err)
+	}
+	if elapsed := fake.Since(start); elapsed != time.Minute {
+		t.Errorf("Expected a minute of fake time got %v", elapsed)
+	}
+}
@@ -0,0 +1,135 @@
+// Package ringbuffer is a package that implements a fixed-capacity circular buffer.
+package ringbuffer
+
+import (
+	"sync"
+)
+
+// Mode controls what happens when an item is pushed into a full buffer.
+type Mode int
+
+const (
+	// Overwrite replaces the oldest item when the buffer is full.
+	Overwrite Mode = iota
+	// Reject refuses new items when the buffer is full.
+	Reject
+)
+
+type noopLocker struct{}
+
+func (noopLocker) Lock()   {}
+func (noopLocker) Unlock() {}
+
+// Buffer is a circular buffer holding at most a fixed number of items.
+type Buffer[T any] struct {
+	items []T
+	head  int
+	size  int
+	mode  Mode
+	mutex sync.Locker
+}
+
+// New creates a buffer with a fixed capacity. The buffer is not safe for concurrent use.
+func New[T any](capacity int, mode Mode) *Buffer[T] {
+	return &Buffer[T]{
+		items: make([]T, capacity),
+		mode:  mode,
+		mutex: noopLocker{},
+	}
+}
+
+// NewConcurrent creates a buffer with a fixed capacity that is safe for concurrent use.
+func NewConcurrent[T any](capacity int, mode Mode) *Buffer[T] {
+	b := New[T](capacity, mode)
+	b.mutex = &sync.Mutex{}
+	return b
+}
+
+// Push adds an item as the newest entry.
+// It reports false if the buffer is full and rejects new items.
+func (b *Buffer[T]) Push(item T) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if len(b.items) == 0 {
+		return false
+	}
+	if b.size == len(b.items) {
+		if b.mode == Reject {
+			return false
+		}
+		b.items[b.head] = item
+		b.head = (b.head + 1) % len(b.items)
+		return true
+	}
+	b.items[(b.head+b.size)%len(b.items)] = item
+	b.size++
+	return true
+}
+
+// Pop removes and returns the oldest item.
+func (b *Buffer[T]) Pop() (T, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var zero T
+	if b.size == 0 {
+		return zero, false
+	}
+	item := b.items[b.head]
+	b.items[b.head] = zero
+	b.head = (b.head + 1) % len(b.items)
+	b.size--
+	return item, true
+}
+
+// Peek returns the oldest item without removing it.
+func (b *Buffer[T]) Peek() (T, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.size == 0 {
+		var zero T
+		return zero, false
+	}
+	return b.items[b.head], true
+}
+
+// Len returns the number of items in the buffer.
+func (b *Buffer[T]) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.size
+}
+
+// Cap returns the capacity of the buffer.
+func (b *Buffer[T]) Cap() int {
+	return len(b.items)
+}
+
+// Snapshot returns a copy of the items, oldest first.
+func (b *Buffer[T]) Snapshot() []T {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	ret := make([]T, b.size)
+	for i := range ret {
+		ret[i] = b.items[(b.head+i)%len(b.items)]
+	}
+	return ret
+}
+
+// Each calls fn for a snapshot of the items, oldest first.
+// Iteration stops early if fn returns false. The buffer may be modified from fn.
+func (b *Buffer[T]) Each(fn func(item T) bool) {
+	for _, item := range b.Snapshot() {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
+// Clear removes every item from the buffer.
+func (b *Buffer[T]) Clear() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	clear(b.items)
+	b.head = 0
+	b.size = 0
+}
@@ -0,0 +1,73 @@
+package ringbuffer_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/ringbuffer"
+)
+
+func TestOverwrite(t *testing.T) {
+	b := ringbuffer.New[int](3, ringbuffer.Overwrite)
+	for i := 1; i <= 5; i++ {
+		if !b.Push(i) {
+			t.Errorf("Expected %d to be pushed", i)
+		}
+	}
+	if snapshot := b.Snapshot(); !reflect.DeepEqual(snapshot, []int{3, 4, 5}) {
+		t.Errorf("Expected [3 4 5] got %v", snapshot)
+	}
+}
+
+func TestReject(t *testing.T) {
+	b := ringbuffer.New[int](2, ringbuffer.Reject)
+	b.Push(1)
+	b.Push(2)
+	if b.Push(3) {
+		t.Error("Expected push into a full buffer to be rejected")
+	}
+	if item, ok := b.Pop(); !ok || item != 1 {
+		t.Errorf("Expected 1 got %v", item)
+	}
+	if !b.Push(3) {
+		t.Error("Expected push to succeed after pop")
+	}
+	if snapshot := b.Snapshot(); !reflect.DeepEqual(snapshot, []int{2, 3}) {
+		t.Errorf("Expected [2 3] got %v", snapshot)
+	}
+}
+
+func TestPopPeek(t *testing.T) {
+	b := ringbuffer.New[string](2, ringbuffer.Overwrite)
+	if _, ok := b.Pop(); ok {
+		t.Error("Expected pop from empty buffer to fail")
+	}
+	b.Push("a")
+	if item, ok := b.Peek(); !ok || item != "a" || b.Len() != 1 {
+		t.Error("Expected peek to return 'a' without removing it")
+	}
+	b.Clear()
+	if b.Len() != 0 {
+		t.Error("Expected buffer to be empty after clear")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	b := ringbuffer.NewConcurrent[int](10, ringbuffer.Overwrite)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				b.Push(i)
+				b.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+	if b.Len() != 10 {
+		t.Errorf("Expected a full buffer got %d", b.Len())
+	}
+}
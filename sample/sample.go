@@ -0,0 +1,161 @@
+// Package sample is a package that keeps fixed-size random samples of unbounded streams.
+package sample
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/pqueue"
+)
+
+func newRandom(random *rand.Rand) *rand.Rand {
+	if random == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return random
+}
+
+// Reservoir keeps a uniform random sample of the items added to it, using Algorithm R: every
+// item seen so far is in the sample with equal probability. It is safe for concurrent use.
+type Reservoir[T any] struct {
+	mutex  *sync.Mutex
+	size   int
+	items  []T
+	seen   int64
+	random *rand.Rand
+}
+
+// New creates a reservoir holding up to size items. A nil random is seeded from the current time;
+// pass a seeded one for reproducible samples.
+func New[T any](size int, random *rand.Rand) *Reservoir[T] {
+	if size < 1 {
+		size = 1
+	}
+	return &Reservoir[T]{
+		mutex:  &sync.Mutex{},
+		size:   size,
+		items:  make([]T, 0, size),
+		random: newRandom(random),
+	}
+}
+
+// Add offers an item to the sample.
+func (r *Reservoir[T]) Add(item T) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.seen++
+	if len(r.items) < r.size {
+		r.items = append(r.items, item)
+		return
+	}
+	if i := r.random.Int63n(r.seen); i < int64(r.size) {
+		r.items[i] = item
+	}
+}
+
+// Sample returns a copy of the current sample.
+func (r *Reservoir[T]) Sample() []T {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]T(nil), r.items...)
+}
+
+// Seen returns the number of items offered since the reservoir was created or reset.
+func (r *Reservoir[T]) Seen() int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.seen
+}
+
+// Reset empties the sample.
+func (r *Reservoir[T]) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.items = r.items[:0]
+	r.seen = 0
+}
+
+type keyed[T any] struct {
+	key  float64
+	item T
+}
+
+// Weighted keeps a random sample where each item's chance of being included is proportional to
+// its weight, using the A-Res algorithm. It is safe for concurrent use.
+type Weighted[T any] struct {
+	mutex  *sync.Mutex
+	size   int
+	heap   *pqueue.Queue[keyed[T]]
+	seen   int64
+	random *rand.Rand
+}
+
+// NewWeighted creates a weighted reservoir holding up to size items. A nil random is seeded from
+// the current time.
+func NewWeighted[T any](size int, random *rand.Rand) *Weighted[T] {
+	if size < 1 {
+		size = 1
+	}
+	return &Weighted[T]{
+		mutex:  &sync.Mutex{},
+		size:   size,
+		heap:   newHeap[T](),
+		random: newRandom(random),
+	}
+}
+
+func newHeap[T any]() *pqueue.Queue[keyed[T]] {
+	return pqueue.New(func(a, b keyed[T]) bool { return a.key < b.key })
+}
+
+// Add offers an item with the given weight. Items with a non-positive weight are never sampled.
+func (w *Weighted[T]) Add(item T, weight float64) {
+	if !(weight > 0) {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.seen++
+	// The A-Res key u^(1/weight), compared in log space to avoid underflow for small weights.
+	key := math.Log(1-w.random.Float64()) / weight
+	if w.heap.Len() < w.size {
+		w.heap.Push(keyed[T]{key: key, item: item})
+		return
+	}
+	if smallest, _ := w.heap.Peek(); key > smallest.key {
+		w.heap.Pop()
+		w.heap.Push(keyed[T]{key: key, item: item})
+	}
+}
+
+// Sample returns a copy of the current sample, in no particular order.
+func (w *Weighted[T]) Sample() []T {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	items := make([]T, 0, w.heap.Len())
+	kept := newHeap[T]()
+	for w.heap.Len() > 0 {
+		k, _ := w.heap.Pop()
+		items = append(items, k.item)
+		kept.Push(k)
+	}
+	w.heap = kept
+	return items
+}
+
+// Seen returns the number of items offered since the reservoir was created or reset.
+func (w *Weighted[T]) Seen() int64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.seen
+}
+
+// Reset empties the sample.
+func (w *Weighted[T]) Reset() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.heap = newHeap[T]()
+	w.seen = 0
+}
@@ -0,0 +1,80 @@
+package sample_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cjsaylor/goutil/sample"
+)
+
+func TestReservoir(t *testing.T) {
+	r := sample.New[int](5, rand.New(rand.NewSource(1)))
+	for i := 0; i < 3; i++ {
+		r.Add(i)
+	}
+	if s := r.Sample(); len(s) != 3 {
+		t.Errorf("Expected every item while under size got %v", s)
+	}
+	for i := 3; i < 1000; i++ {
+		r.Add(i)
+	}
+	if s := r.Sample(); len(s) != 5 || r.Seen() != 1000 {
+		t.Errorf("Expected 5 of 1000 items got %v of %d", s, r.Seen())
+	}
+	r.Reset()
+	if len(r.Sample()) != 0 || r.Seen() != 0 {
+		t.Error("Expected an empty reservoir after reset")
+	}
+}
+
+func TestReservoirUniform(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	counts := make([]int, 10)
+	for trial := 0; trial < 10000; trial++ {
+		r := sample.New[int](2, random)
+		for i := 0; i < 10; i++ {
+			r.Add(i)
+		}
+		for _, item := range r.Sample() {
+			counts[item]++
+		}
+	}
+	// Each item should be sampled in about 2/10 of the trials.
+	for item, count := range counts {
+		if math.Abs(float64(count)-2000) > 200 {
+			t.Errorf("Expected item %d about 2000 times got %d", item, count)
+		}
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	heavy := 0
+	for trial := 0; trial < 2000; trial++ {
+		w := sample.NewWeighted[string](1, random)
+		w.Add("light", 1)
+		w.Add("heavy", 9)
+		w.Add("never", 0)
+		if s := w.Sample(); len(s) == 1 && s[0] == "heavy" {
+			heavy++
+		}
+	}
+	if math.Abs(float64(heavy)-1800) > 100 {
+		t.Errorf("Expected the heavy item about 1800 times got %d", heavy)
+	}
+}
+
+func TestWeightedSize(t *testing.T) {
+	w := sample.NewWeighted[int](3, nil)
+	for i := 0; i < 100; i++ {
+		w.Add(i, float64(i+1))
+	}
+	if len(w.Sample()) != 3 || len(w.Sample()) != 3 || w.Seen() != 100 {
+		t.Errorf("Expected 3 of 100 items got %v", w.Sample())
+	}
+	w.Reset()
+	if len(w.Sample()) != 0 {
+		t.Error("Expected an empty sample after reset")
+	}
+}
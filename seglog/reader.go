@@ -0,0 +1,110 @@
+package seglog
+
+import (
+	"bufio"
+	"os"
+)
+
+// Reader reads records in order from a cursor. It moves on to the next segment when it finishes
+// one, so it keeps reading as the log rotates. A Reader is not safe for concurrent use, but any
+// number of readers can follow one log.
+type Reader struct {
+	log    *Log
+	offset uint64
+	base   uint64
+	file   *os.File
+	r      *bufio.Reader
+	pos    int64
+	closed bool
+}
+
+// NewReader returns a reader whose first record is at offset.
+func (l *Log) NewReader(offset uint64) *Reader {
+	return &Reader{log: l, offset: offset}
+}
+
+// Offset returns the offset of the next record the reader returns.
+func (r *Reader) Offset() uint64 {
+	return r.offset
+}
+
+// Seek moves the cursor to offset.
+func (r *Reader) Seek(offset uint64) {
+	r.release()
+	r.offset = offset
+}
+
+// Next returns the record at the cursor and its offset, then advances the cursor. It returns io.EOF
+// when the reader has caught up with the log; call it again once more records are appended. It
+// returns ErrTruncated when the record at the cursor was deleted, moving the cursor to the oldest
+// remaining record.
+func (r *Reader) Next() ([]byte, uint64, error) {
+	if r.closed {
+		return nil, 0, ErrClosed
+	}
+	base, path, size, err := r.log.locate(r.offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if path == "" {
+		r.Seek(base)
+		return nil, 0, ErrTruncated
+	}
+	if r.file == nil || r.base != base {
+		if err := r.open(base, path, size); err != nil {
+			return nil, 0, err
+		}
+	}
+	data, err := readFrame(r.r, size-r.pos)
+	if err != nil {
+		r.release()
+		return nil, 0, err
+	}
+	r.pos += frameHeaderSize + int64(len(data))
+	r.offset++
+	return data, r.offset - 1, nil
+}
+
+// Close releases the reader's segment file.
+func (r *Reader) Close() error {
+	r.closed = true
+	return r.release()
+}
+
+// open opens the segment at base and skips to the cursor.
+func (r *Reader) open(base uint64, path string, size int64) error {
+	r.release()
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Deleted by retention since it was located.
+			r.offset = r.log.First()
+			return ErrTruncated
+		}
+		return err
+	}
+	r.file, r.base, r.pos = file, base, segmentHeaderSize
+	r.r = bufio.NewReader(file)
+	if _, err := r.r.Discard(segmentHeaderSize); err != nil {
+		r.release()
+		return err
+	}
+	for i := base; i < r.offset; i++ {
+		data, err := readFrame(r.r, size-r.pos)
+		if err != nil {
+			r.release()
+			return err
+		}
+		r.pos += frameHeaderSize + int64(len(data))
+	}
+	return nil
+}
+
+func (r *Reader) release() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file, r.r, r.pos = nil, nil, 0
+	return err
+}
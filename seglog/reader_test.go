@@ -0,0 +1,39 @@
+package seglog_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/cjsaylor/goutil/seglog"
+)
+
+func TestReaderFollowsRotation(t *testing.T) {
+	l := open(t, t.TempDir(), seglog.Options{MaxSegmentBytes: 32})
+	defer l.Close()
+	r := l.NewReader(0)
+	defer r.Close()
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Errorf("Expected EOF on an empty log got %v", err)
+	}
+	appendN(l, 0, 3)
+	for i := 0; i < 3; i++ {
+		data, offset, err := r.Next()
+		if err != nil || offset != uint64(i) || string(data) != fmt.Sprintf("record %d", i) {
+			t.Fatalf("Expected record %d got %q at %d (%v)", i, data, offset, err)
+		}
+	}
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Errorf("Expected EOF once caught up got %v", err)
+	}
+	appendN(l, 3, 4)
+	for i := 3; i < 7; i++ {
+		if data, _, err := r.Next(); err != nil || string(data) != fmt.Sprintf("record %d", i) {
+			t.Fatalf("Expected record %d after rotation got %q (%v)", i, data, err)
+		}
+	}
+	r.Seek(5)
+	if _, offset, _ := r.Next(); offset != 5 {
+		t.Errorf("Expected to seek to 5 got %d", offset)
+	}
+}
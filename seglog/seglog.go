@@ -0,0 +1,392 @@
+// Package seglog is a package that implements an append-only log split into segment files, a
+// building block for queues and replication.
+//
+// Records are numbered with consecutive offsets starting at zero and framed with their length and a
+// CRC-32C checksum. Each segment starts with the time its first record was written, so age limits
+// hold across restarts. The log appends to its newest segment and rolls over to a new one once the
+// segment reaches a size or age limit. Retention policies then delete the oldest segments. Readers
+// follow the log with a cursor that moves across segments as they rotate, so a consumer can tail
+// the log indefinitely.
+package seglog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+const (
+	segmentHeaderSize = 8
+	frameHeaderSize   = 8
+	suffix            = ".seg"
+)
+
+var table = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// ErrClosed is returned when using a closed log or reader.
+	ErrClosed = errors.New("seglog: closed")
+	// ErrCorrupt is returned when a record fails its checksum.
+	ErrCorrupt = errors.New("seglog: corrupt record")
+	// ErrTruncated is returned by a reader whose next record was deleted by retention. The reader
+	// moves to the oldest remaining record, so reading can continue.
+	ErrTruncated = errors.New("seglog: records deleted by retention")
+)
+
+// Options configures a log. Zero fields take the defaults noted on each.
+type Options struct {
+	// MaxSegmentBytes rolls over to a new segment once the current one reaches this size.
+	// Defaults to 64MB.
+	MaxSegmentBytes int64
+	// MaxSegmentAge rolls over to a new segment once the current one's first record is this old.
+	// Zero means segments do not roll over by age.
+	MaxSegmentAge time.Duration
+	// Retention decides which old segments are deleted.
+	Retention Retention
+	// SyncEveryAppend syncs the segment after every append. Otherwise call Sync.
+	SyncEveryAppend bool
+	// Clock measures segment ages. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Retention limits how much of the log is kept. Segments are deleted oldest first once any limit is
+// exceeded. The segment being appended to is never deleted. Zero fields are unlimited.
+type Retention struct {
+	// MaxBytes limits the total size of the segments.
+	MaxBytes int64
+	// MaxSegments limits the number of segments.
+	MaxSegments int
+	// MaxAge deletes segments whose newest record is older than this.
+	MaxAge time.Duration
+}
+
+type segment struct {
+	base     uint64
+	count    uint64
+	size     int64
+	created  time.Time
+	modified time.Time
+	path     string
+}
+
+// Log is a segmented append-only log in a directory. It is safe for concurrent use.
+type Log struct {
+	dir      string
+	options  Options
+	segments []*segment
+	active   *os.File
+	err      error
+	closed   bool
+	mutex    *sync.Mutex
+}
+
+// Open opens the log in dir, creating the directory if needed. A torn or damaged tail in the newest
+// segment is truncated.
+func Open(dir string, options Options) (*Log, error) {
+	if options.MaxSegmentBytes <= 0 {
+		options.MaxSegmentBytes = 64 << 20
+	}
+	options.Clock = clock.OrReal(options.Clock)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	l := &Log{dir: dir, options: options, mutex: &sync.Mutex{}}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) load() error {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		base, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), suffix), 10, 64)
+		if err != nil || !strings.HasSuffix(e.Name(), suffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, &segment{
+			base:     base,
+			modified: info.ModTime(),
+			path:     filepath.Join(l.dir, e.Name()),
+		})
+	}
+	sort.Slice(l.segments, func(i, j int) bool { return l.segments[i].base < l.segments[j].base })
+	for i, s := range l.segments {
+		file, err := os.Open(s.path)
+		if err != nil {
+			return err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return err
+		}
+		// A segment holds its creation time ahead of the records, written along with the first one.
+		header := make([]byte, segmentHeaderSize)
+		_, err = file.ReadAt(header, 0)
+		if err == nil {
+			s.created = time.Unix(0, int64(binary.BigEndian.Uint64(header)))
+			s.size, s.count, err = scan(file, info.Size())
+		} else if err == io.EOF {
+			err = nil
+		}
+		file.Close()
+		if err != nil {
+			return err
+		}
+		if s.count == 0 {
+			s.size = 0
+		}
+		if s.size < info.Size() {
+			if i != len(l.segments)-1 {
+				return ErrCorrupt
+			}
+			if err := os.Truncate(s.path, s.size); err != nil {
+				return err
+			}
+		}
+	}
+	if len(l.segments) == 0 {
+		return l.roll(0)
+	}
+	last := l.segments[len(l.segments)-1]
+	active, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	l.active = active
+	return nil
+}
+
+// Append writes a record and returns its offset. A failed write is cut off again, so later records
+// are not stranded behind a torn one; if that fails too, every later Append returns the error. A
+// record that was written but failed to sync still takes its offset.
+func (l *Log) Append(data []byte) (uint64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return 0, ErrClosed
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	now := l.options.Clock.Now()
+	s := l.segments[len(l.segments)-1]
+	if s.count > 0 && (s.size >= l.options.MaxSegmentBytes ||
+		(l.options.MaxSegmentAge > 0 && now.Sub(s.created) >= l.options.MaxSegmentAge)) {
+		if err := l.roll(s.base + s.count); err != nil {
+			return 0, err
+		}
+		l.retain(now)
+		s = l.segments[len(l.segments)-1]
+	}
+	buf := frame(data)
+	if s.size == 0 {
+		buf = append(binary.BigEndian.AppendUint64(nil, uint64(now.UnixNano())), buf...)
+	}
+	if _, err := l.active.Write(buf); err != nil {
+		if truncateErr := l.active.Truncate(s.size); truncateErr != nil {
+			l.err = err
+		}
+		return 0, err
+	}
+	if s.size == 0 {
+		s.created = now
+	}
+	s.count++
+	s.size += int64(len(buf))
+	s.modified = now
+	offset := s.base + s.count - 1
+	if l.options.SyncEveryAppend {
+		if err := l.active.Sync(); err != nil {
+			return offset, err
+		}
+	}
+	return offset, nil
+}
+
+// Sync flushes the current segment to stable storage.
+func (l *Log) Sync() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	return l.active.Sync()
+}
+
+// First returns the offset of the oldest retained record.
+func (l *Log) First() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.segments[0].base
+}
+
+// Next returns the offset the next appended record will get.
+func (l *Log) Next() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	s := l.segments[len(l.segments)-1]
+	return s.base + s.count
+}
+
+// Segments returns the number of segment files.
+func (l *Log) Segments() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.segments)
+}
+
+// Retain applies the retention policy now. It also runs each time the log rolls over to a new
+// segment.
+func (l *Log) Retain() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	return l.retain(l.options.Clock.Now())
+}
+
+// Close syncs and closes the log. Open readers keep working until they reach the end of what was
+// written.
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	err := l.active.Sync()
+	if closeErr := l.active.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// roll starts a new segment at base. It must be called with the lock held.
+func (l *Log) roll(base uint64) error {
+	path := filepath.Join(l.dir, fmt.Sprintf("%020d%s", base, suffix))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if l.active != nil {
+		l.active.Sync()
+		l.active.Close()
+	}
+	now := l.options.Clock.Now()
+	l.active = file
+	l.segments = append(l.segments, &segment{base: base, created: now, modified: now, path: path})
+	return nil
+}
+
+// retain deletes the oldest segments while any retention limit is exceeded. It must be called with
+// the lock held.
+func (l *Log) retain(now time.Time) error {
+	r := l.options.Retention
+	var total int64
+	for _, s := range l.segments {
+		total += s.size
+	}
+	for len(l.segments) > 1 {
+		oldest := l.segments[0]
+		over := (r.MaxBytes > 0 && total > r.MaxBytes) ||
+			(r.MaxSegments > 0 && len(l.segments) > r.MaxSegments) ||
+			(r.MaxAge > 0 && now.Sub(oldest.modified) > r.MaxAge)
+		if !over {
+			return nil
+		}
+		if err := os.Remove(oldest.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= oldest.size
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+// locate returns the path of the segment holding offset and the size written to it so far.
+// It returns an empty path if offset was deleted, and io.EOF if offset has not been written yet.
+func (l *Log) locate(offset uint64) (base uint64, path string, size int64, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if offset < l.segments[0].base {
+		return l.segments[0].base, "", 0, nil
+	}
+	for _, s := range l.segments {
+		if offset < s.base+s.count {
+			return s.base, s.path, s.size, nil
+		}
+	}
+	return 0, "", 0, io.EOF
+}
+
+// frame prefixes data with its length and a checksum covering both.
+func frame(data []byte) []byte {
+	buf := make([]byte, frameHeaderSize, frameHeaderSize+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	crc := crc32.Update(crc32.Checksum(buf[:4], table), table, data)
+	binary.BigEndian.PutUint32(buf[4:], crc)
+	return append(buf, data...)
+}
+
+// scan reads frames from the first size bytes of the segment r until the end or the first
+// incomplete or damaged one. It returns the length of the valid prefix, including the segment
+// header, and the number of valid records. Any other read error is returned, so it is not mistaken
+// for a torn tail.
+func scan(r io.ReaderAt, size int64) (int64, uint64, error) {
+	br := bufio.NewReader(io.NewSectionReader(r, segmentHeaderSize, size-segmentHeaderSize))
+	valid := int64(segmentHeaderSize)
+	var count uint64
+	for {
+		data, err := readFrame(br, size-valid)
+		if err == io.EOF || err == io.ErrUnexpectedEOF || err == ErrCorrupt {
+			return valid, count, nil
+		}
+		if err != nil {
+			return valid, count, err
+		}
+		valid += frameHeaderSize + int64(len(data))
+		count++
+	}
+}
+
+// readFrame reads one record of at most remaining bytes including its frame.
+func readFrame(r *bufio.Reader, remaining int64) ([]byte, error) {
+	head := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	length := int64(binary.BigEndian.Uint32(head))
+	if frameHeaderSize+length > remaining {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	if crc32.Update(crc32.Checksum(head[:4], table), table, data) != binary.BigEndian.Uint32(head[4:]) {
+		return nil, ErrCorrupt
+	}
+	return data, nil
+}
@@ -0,0 +1,131 @@
+package seglog_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/seglog"
+)
+
+func open(t *testing.T, dir string, options seglog.Options) *seglog.Log {
+	t.Helper()
+	l, err := seglog.Open(dir, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func appendN(l *seglog.Log, from, n int) {
+	for i := from; i < from+n; i++ {
+		l.Append([]byte(fmt.Sprintf("record %d", i)))
+	}
+}
+
+func TestRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	// Each framed record is 16 bytes, so a segment holds two.
+	l := open(t, dir, seglog.Options{MaxSegmentBytes: 32})
+	appendN(l, 0, 5)
+	if l.Segments() != 3 || l.First() != 0 || l.Next() != 5 {
+		t.Errorf("Expected 3 segments with records 0 to 5 got %d segments from %d to %d", l.Segments(), l.First(), l.Next())
+	}
+	l.Close()
+	if _, err := l.Append(nil); err != seglog.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+
+	l = open(t, dir, seglog.Options{MaxSegmentBytes: 32})
+	defer l.Close()
+	if offset, _ := l.Append([]byte("record 5")); offset != 5 || l.Segments() != 3 {
+		t.Errorf("Expected to resume at offset 5 in the last segment got %d", offset)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	l := open(t, t.TempDir(), seglog.Options{MaxSegmentBytes: 32, Retention: seglog.Retention{MaxSegments: 2}})
+	defer l.Close()
+	r := l.NewReader(0)
+	defer r.Close()
+	appendN(l, 0, 7)
+	if l.Segments() != 2 || l.First() != 4 {
+		t.Errorf("Expected the 2 newest segments starting at 4 got %d from %d", l.Segments(), l.First())
+	}
+	if _, _, err := r.Next(); err != seglog.ErrTruncated {
+		t.Errorf("Expected truncated error got %v", err)
+	}
+	if _, offset, err := r.Next(); err != nil || offset != 4 {
+		t.Errorf("Expected the reader to resume at 4 got %d (%v)", offset, err)
+	}
+}
+
+func TestRetentionByAge(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	l := open(t, t.TempDir(), seglog.Options{
+		MaxSegmentAge: time.Hour,
+		Retention:     seglog.Retention{MaxAge: 90 * time.Minute},
+		Clock:         fake,
+	})
+	defer l.Close()
+	appendN(l, 0, 2)
+	fake.Advance(time.Hour)
+	appendN(l, 2, 1)
+	if l.Segments() != 2 {
+		t.Errorf("Expected an hour old segment to roll over got %d segments", l.Segments())
+	}
+	fake.Advance(time.Hour)
+	l.Retain()
+	if l.Segments() != 1 || l.First() != 2 {
+		t.Errorf("Expected the expired segment to be deleted got %d from %d", l.Segments(), l.First())
+	}
+}
+
+func TestSegmentAgeSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+	fake := clock.NewFake(start)
+	options := seglog.Options{MaxSegmentAge: time.Hour, Clock: fake}
+	l := open(t, dir, options)
+	appendN(l, 0, 1)
+	l.Close()
+	// A later write moves the modification time but not the segment's age.
+	path := filepath.Join(dir, fmt.Sprintf("%020d.seg", 0))
+	os.Chtimes(path, start.Add(50*time.Minute), start.Add(50*time.Minute))
+
+	fake.Advance(61 * time.Minute)
+	l = open(t, dir, options)
+	defer l.Close()
+	appendN(l, 1, 1)
+	if l.Segments() != 2 {
+		t.Errorf("Expected the age to be measured from the first record got %d segments", l.Segments())
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	dir := t.TempDir()
+	l := open(t, dir, seglog.Options{})
+	appendN(l, 0, 2)
+	l.Close()
+	path := filepath.Join(dir, fmt.Sprintf("%020d.seg", 0))
+	info, _ := os.Stat(path)
+	os.Truncate(path, info.Size()-1)
+
+	l = open(t, dir, seglog.Options{})
+	defer l.Close()
+	if l.Next() != 1 {
+		t.Errorf("Expected the torn record to be dropped got next %d", l.Next())
+	}
+	l.Close()
+
+	os.Truncate(path, 3)
+	l = open(t, dir, seglog.Options{})
+	defer l.Close()
+	appendN(l, 0, 1)
+	if data, _, err := l.NewReader(0).Next(); err != nil || string(data) != "record 0" {
+		t.Errorf("Expected a torn segment header to be discarded got %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package semaphore is a package that implements a weighted semaphore with FIFO fairness.
+//
+// Waiters are served strictly in arrival order: a large request at the head of the queue holds back
+// smaller requests behind it, so large requests are never starved.
+package semaphore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+type waiter struct {
+	weight int64
+	ready  chan struct{}
+}
+
+// Semaphore limits the total weight held at once. It is safe for concurrent use.
+type Semaphore struct {
+	size    int64
+	current int64
+	waiters *list.List
+	mutex   *sync.Mutex
+}
+
+// New creates a semaphore with the given total weight.
+func New(size int64) *Semaphore {
+	return &Semaphore{
+		size:    size,
+		waiters: list.New(),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Acquire blocks until weight is available or ctx is done. On failure it returns ctx.Err() and
+// holds nothing. A weight larger than the semaphore's size waits until ctx is done.
+func (s *Semaphore) Acquire(ctx context.Context, weight int64) error {
+	s.mutex.Lock()
+	if s.fits(weight) && s.waiters.Len() == 0 {
+		s.take(weight)
+		s.mutex.Unlock()
+		return nil
+	}
+	w := &waiter{weight: weight, ready: make(chan struct{})}
+	item := s.waiters.PushBack(w)
+	s.mutex.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mutex.Lock()
+		select {
+		case <-w.ready:
+			// Acquired just as ctx was done; give it back.
+			s.release(weight)
+		default:
+			isFront := s.waiters.Front() == item
+			s.waiters.Remove(item)
+			if isFront {
+				s.notify()
+			}
+		}
+		s.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires weight without blocking and reports whether it did.
+func (s *Semaphore) TryAcquire(weight int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.fits(weight) && s.waiters.Len() == 0 {
+		s.take(weight)
+		return true
+	}
+	return false
+}
+
+// Release returns weight to the semaphore. It panics if more is released than is held.
+func (s *Semaphore) Release(weight int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.release(weight)
+}
+
+// Current returns the total weight currently held.
+func (s *Semaphore) Current() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.current
+}
+
+// Waiting returns the number of callers blocked in Acquire.
+func (s *Semaphore) Waiting() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.waiters.Len()
+}
+
+func (s *Semaphore) fits(weight int64) bool {
+	return s.size-s.current >= weight
+}
+
+func (s *Semaphore) take(weight int64) {
+	s.current += weight
+}
+
+func (s *Semaphore) release(weight int64) {
+	s.current -= weight
+	if s.current < 0 {
+		panic("semaphore: released more than held")
+	}
+	s.notify()
+}
+
+func (s *Semaphore) notify() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*waiter)
+		if !s.fits(w.weight) {
+			return
+		}
+		s.take(w.weight)
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,117 @@
+package semaphore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/semaphore"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	s := semaphore.New(3)
+	if err := s.Acquire(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	if s.TryAcquire(2) {
+		t.Error("Expected TryAcquire to fail when over the size")
+	}
+	if !s.TryAcquire(1) {
+		t.Error("Expected TryAcquire to succeed within the size")
+	}
+	if s.Current() != 3 {
+		t.Errorf("Expected 3 held got %d", s.Current())
+	}
+	s.Release(2)
+	s.Release(1)
+	if s.Current() != 0 {
+		t.Errorf("Expected nothing held got %d", s.Current())
+	}
+}
+
+func TestContextCancel(t *testing.T) {
+	s := semaphore.New(1)
+	s.Acquire(context.Background(), 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Acquire(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+	if s.Waiting() != 0 {
+		t.Errorf("Expected no waiters got %d", s.Waiting())
+	}
+}
+
+func TestFIFO(t *testing.T) {
+	s := semaphore.New(2)
+	s.Acquire(context.Background(), 2)
+	order := make(chan int, 3)
+	big := make(chan struct{})
+	go func() {
+		s.Acquire(context.Background(), 2)
+		order <- 1
+		close(big)
+	}()
+	for s.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		s.Acquire(context.Background(), 1)
+		order <- 2
+	}()
+	for s.Waiting() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if s.TryAcquire(1) {
+		t.Error("Expected TryAcquire to respect queued waiters")
+	}
+	s.Release(1)
+	select {
+	case n := <-order:
+		t.Errorf("Expected the queue head to block smaller waiters got %d", n)
+	case <-time.After(10 * time.Millisecond):
+	}
+	s.Release(1)
+	<-big
+	if n := <-order; n != 1 {
+		t.Errorf("Expected the first waiter to acquire first got %d", n)
+	}
+	s.Release(2)
+	if n := <-order; n != 2 {
+		t.Errorf("Expected the second waiter to acquire next got %d", n)
+	}
+}
+
+func TestCancelHeadWakesNext(t *testing.T) {
+	s := semaphore.New(2)
+	s.Acquire(context.Background(), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.Acquire(ctx, 2)
+	for s.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		s.Acquire(context.Background(), 1)
+		close(done)
+	}()
+	for s.Waiting() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Expected the next waiter to acquire after the head cancelled")
+	}
+}
+
+func TestOverReleasePanics(t *testing.T) {
+	s := semaphore.New(1)
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+	s.Release(1)
+}
@@ -0,0 +1,135 @@
+// Package set is a package that implements a generic unordered set.
+package set
+
+import (
+	"encoding/json"
+	"iter"
+)
+
+// Set is a collection of unique items. It is not safe for concurrent use.
+type Set[T comparable] map[T]struct{}
+
+// New creates a set containing the given items.
+func New[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Add(items...)
+	return s
+}
+
+// Add items to the set.
+func (s Set[T]) Add(items ...T) {
+	for _, item := range items {
+		s[item] = struct{}{}
+	}
+}
+
+// Remove items from the set.
+func (s Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s, item)
+	}
+}
+
+// Contains reports whether item is in the set.
+func (s Set[T]) Contains(item T) bool {
+	_, ok := s[item]
+	return ok
+}
+
+// Len returns the number of items in the set.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Union returns a new set with the items in either set.
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	result := make(Set[T], len(s)+len(other))
+	for item := range s {
+		result[item] = struct{}{}
+	}
+	for item := range other {
+		result[item] = struct{}{}
+	}
+	return result
+}
+
+// Intersection returns a new set with the items in both sets.
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	small, large := s, other
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	result := make(Set[T])
+	for item := range small {
+		if large.Contains(item) {
+			result[item] = struct{}{}
+		}
+	}
+	return result
+}
+
+// Difference returns a new set with the items in s that are not in other.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	result := make(Set[T])
+	for item := range s {
+		if !other.Contains(item) {
+			result[item] = struct{}{}
+		}
+	}
+	return result
+}
+
+// Each calls fn for every item in the set in no particular order.
+// Iteration stops early if fn returns false.
+func (s Set[T]) Each(fn func(item T) bool) {
+	for item := range s {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
+// All returns a sequence of the items in the set in no particular order.
+func (s Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.Each(yield)
+	}
+}
+
+// Insert adds every item from seq to the set.
+func (s Set[T]) Insert(seq iter.Seq[T]) {
+	for item := range seq {
+		s[item] = struct{}{}
+	}
+}
+
+// Collect creates a set of the items in seq.
+func Collect[T comparable](seq iter.Seq[T]) Set[T] {
+	s := New[T]()
+	s.Insert(seq)
+	return s
+}
+
+// Items returns the items of the set in no particular order.
+func (s Set[T]) Items() []T {
+	ret := make([]T, 0, len(s))
+	for item := range s {
+		ret = append(ret, item)
+	}
+	return ret
+}
+
+// MarshalJSON encodes the set as a JSON array.
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Items())
+}
+
+// UnmarshalJSON decodes a JSON array into the set, replacing its contents.
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*s = New(items...)
+	return nil
+}
@@ -0,0 +1,77 @@
+package set_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/cjsaylor/goutil/set"
+)
+
+func sorted(s set.Set[int]) []int {
+	items := s.Items()
+	sort.Ints(items)
+	return items
+}
+
+func TestAddRemoveContains(t *testing.T) {
+	s := set.New(1, 2)
+	s.Add(3, 3)
+	s.Remove(1)
+	if s.Contains(1) || !s.Contains(3) || s.Len() != 2 {
+		t.Errorf("Expected [2 3] got %v", sorted(s))
+	}
+}
+
+func TestOperations(t *testing.T) {
+	a := set.New(1, 2, 3)
+	b := set.New(2, 3, 4)
+	if result := sorted(a.Union(b)); !reflect.DeepEqual(result, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected union [1 2 3 4] got %v", result)
+	}
+	if result := sorted(a.Intersection(b)); !reflect.DeepEqual(result, []int{2, 3}) {
+		t.Errorf("Expected intersection [2 3] got %v", result)
+	}
+	if result := sorted(a.Difference(b)); !reflect.DeepEqual(result, []int{1}) {
+		t.Errorf("Expected difference [1] got %v", result)
+	}
+}
+
+func TestEach(t *testing.T) {
+	s := set.New(1, 2, 3)
+	visited := 0
+	s.Each(func(item int) bool {
+		visited++
+		return visited < 2
+	})
+	if visited != 2 {
+		t.Errorf("Expected iteration to stop after 2 items got %d", visited)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(set.New(1))
+	if err != nil || string(data) != "[1]" {
+		t.Errorf("Expected [1] got %s (%v)", data, err)
+	}
+	var s set.Set[int]
+	if err := json.Unmarshal([]byte("[3,1,3]"), &s); err != nil {
+		t.Fatal(err)
+	}
+	if result := sorted(s); !reflect.DeepEqual(result, []int{1, 3}) {
+		t.Errorf("Expected [1 3] got %v", result)
+	}
+}
+
+func TestSeq(t *testing.T) {
+	s := set.Collect(slices.Values([]int{1, 2, 2, 3}))
+	s.Insert(slices.Values([]int{4}))
+	if got := slices.Sorted(s.All()); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected [1 2 3 4] got %v", got)
+	}
+	for range s.All() {
+		break
+	}
+}
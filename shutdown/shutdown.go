@@ -0,0 +1,128 @@
+// Package shutdown is a package that coordinates closing an application's components in order.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Closer is a component that can be shut down within a deadline.
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// CloserFunc adapts a function to a Closer, such as a worker pool's or batcher's Shutdown method or a
+// cron scheduler's Stop method.
+type CloserFunc func(ctx context.Context) error
+
+// Close calls f.
+func (f CloserFunc) Close(ctx context.Context) error {
+	return f(ctx)
+}
+
+type hook struct {
+	name    string
+	closer  Closer
+	timeout time.Duration
+}
+
+// Coordinator runs registered shutdown hooks once. It is safe for concurrent use.
+type Coordinator struct {
+	hooks []hook
+	once  *sync.Once
+	done  chan struct{}
+	err   error
+	mutex *sync.Mutex
+}
+
+// New creates a coordinator with no hooks.
+func New() *Coordinator {
+	return &Coordinator{
+		once:  &sync.Once{},
+		done:  make(chan struct{}),
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Register adds a hook. Hooks run one at a time in reverse order of registration, so components
+// registered first, which later ones usually depend on, are closed last. A positive timeout bounds
+// how long the hook may take; zero leaves it bounded only by the context given to Shutdown.
+func (c *Coordinator) Register(name string, closer Closer, timeout time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.hooks = append(c.hooks, hook{name: name, closer: closer, timeout: timeout})
+}
+
+// Shutdown runs every hook and returns their errors joined, each prefixed with its hook's name. A hook
+// that fails or times out does not stop later hooks from running. Only the first call runs the hooks;
+// later calls wait for it and return the same result.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.once.Do(func() {
+		c.mutex.Lock()
+		hooks := make([]hook, len(c.hooks))
+		copy(hooks, c.hooks)
+		c.mutex.Unlock()
+		var errs []error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := run(ctx, hooks[i]); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+			}
+		}
+		c.err = errors.Join(errs...)
+		close(c.done)
+	})
+	<-c.done
+	return c.err
+}
+
+// Done returns a channel that is closed once shutdown has finished.
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait blocks until one of signals is received, or ctx is done, and then shuts down with timeout as
+// the overall deadline. With no signals it listens for SIGINT and SIGTERM.
+func (c *Coordinator) Wait(ctx context.Context, timeout time.Duration, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+	select {
+	case <-received:
+	case <-ctx.Done():
+	}
+	shutdownCtx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, timeout)
+		defer cancel()
+	}
+	return c.Shutdown(shutdownCtx)
+}
+
+func run(ctx context.Context, h hook) error {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- h.closer.Close(ctx)
+	}()
+	// Do not let a hook that ignores its context hold up the rest.
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
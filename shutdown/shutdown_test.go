@@ -0,0 +1,103 @@
+package shutdown_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/batcher"
+	"github.com/cjsaylor/goutil/shutdown"
+	"github.com/cjsaylor/goutil/workerpool"
+)
+
+func TestReverseOrder(t *testing.T) {
+	c := shutdown.New()
+	var order []string
+	for _, name := range []string{"database", "cache", "server"} {
+		c.Register(name, shutdown.CloserFunc(func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}), 0)
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, ",") != "server,cache,database" {
+		t.Errorf("Expected server,cache,database got %v", order)
+	}
+}
+
+func TestHookTimeoutAndErrors(t *testing.T) {
+	c := shutdown.New()
+	failure := errors.New("failure")
+	ran := false
+	c.Register("last", shutdown.CloserFunc(func(ctx context.Context) error {
+		ran = true
+		return nil
+	}), 0)
+	c.Register("failing", shutdown.CloserFunc(func(ctx context.Context) error {
+		return failure
+	}), 0)
+	c.Register("stuck", shutdown.CloserFunc(func(ctx context.Context) error {
+		select {}
+	}), 5*time.Millisecond)
+	err := c.Shutdown(context.Background())
+	if !errors.Is(err, failure) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the failure and the timeout got %v", err)
+	}
+	if !strings.Contains(err.Error(), "stuck:") {
+		t.Errorf("Expected errors to name their hook got %v", err)
+	}
+	if !ran {
+		t.Error("Expected later hooks to run after failures")
+	}
+}
+
+func TestShutdownOnce(t *testing.T) {
+	c := shutdown.New()
+	calls := 0
+	c.Register("counter", shutdown.CloserFunc(func(ctx context.Context) error {
+		calls++
+		return nil
+	}), 0)
+	c.Shutdown(context.Background())
+	c.Shutdown(context.Background())
+	if calls != 1 {
+		t.Errorf("Expected hooks to run once got %d", calls)
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Error("Expected Done to be closed")
+	}
+}
+
+func TestComponents(t *testing.T) {
+	pool := workerpool.New(workerpool.Options{MaxWorkers: 1})
+	b := batcher.New(func(items []int) ([]int, error) { return items, nil }, batcher.Options{})
+	c := shutdown.New()
+	c.Register("pool", shutdown.CloserFunc(pool.Shutdown), time.Second)
+	c.Register("batcher", shutdown.CloserFunc(b.Shutdown), time.Second)
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Go(context.Background(), func(ctx context.Context) error { return nil }); err != workerpool.ErrClosed {
+		t.Errorf("Expected the pool to be closed got %v", err)
+	}
+}
+
+func TestWaitOnContext(t *testing.T) {
+	c := shutdown.New()
+	closed := false
+	c.Register("hook", shutdown.CloserFunc(func(ctx context.Context) error {
+		closed = true
+		return nil
+	}), 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Wait(ctx, time.Second); err != nil || !closed {
+		t.Errorf("Expected Wait to shut down when ctx is done got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Package singleflight is a package that coalesces concurrent calls for the same key into one.
+package singleflight
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicError is the error delivered to callers when fn panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("singleflight: function panicked: %v", e.Value)
+}
+
+// Result is the outcome of a call, shared by every caller that joined it.
+type Result[V any] struct {
+	Value V
+	Err   error
+	// Callers is the number of callers that joined the call, including the one that started it.
+	Callers int
+	// Shared reports whether the result was delivered to more than one caller.
+	Shared bool
+}
+
+type call[V any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	value   V
+	err     error
+	callers int
+	waiting int
+}
+
+// Group tracks calls in flight by key. The zero value is not usable; create groups with New.
+// It is safe for concurrent use.
+type Group[K comparable, V any] struct {
+	calls map[K]*call[V]
+	mutex *sync.Mutex
+}
+
+// New creates an empty group.
+func New[K comparable, V any]() *Group[K, V] {
+	return &Group[K, V]{
+		calls: make(map[K]*call[V]),
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Do runs fn for key unless a call for key is already in flight, in which case it waits for that
+// call's result instead.
+//
+// fn receives a context that carries the values of the starting caller's context but not its
+// cancellation; it is cancelled only once every caller waiting on it has given up. Each caller
+// returns with ctx.Err() as soon as its own ctx is done. A panic in fn is recovered and delivered to
+// every caller as a *PanicError.
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func(context.Context) (V, error)) Result[V] {
+	g.mutex.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call[V]{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		go g.run(callCtx, key, c, fn)
+	}
+	c.callers++
+	c.waiting++
+	g.mutex.Unlock()
+
+	select {
+	case <-c.done:
+		return Result[V]{Value: c.value, Err: c.err, Callers: c.callers, Shared: c.callers > 1}
+	case <-ctx.Done():
+		g.mutex.Lock()
+		c.waiting--
+		if c.waiting == 0 {
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mutex.Unlock()
+		return Result[V]{Err: ctx.Err()}
+	}
+}
+
+// DoChan is like Do but delivers the result on a channel.
+func (g *Group[K, V]) DoChan(ctx context.Context, key K, fn func(context.Context) (V, error)) <-chan Result[V] {
+	out := make(chan Result[V], 1)
+	go func() {
+		out <- g.Do(ctx, key, fn)
+	}()
+	return out
+}
+
+// Forget stops later calls for key from joining the call in flight, so the next Do starts afresh.
+// Callers already waiting still receive the original result.
+func (g *Group[K, V]) Forget(key K) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	delete(g.calls, key)
+}
+
+func (g *Group[K, V]) run(ctx context.Context, key K, c *call[V], fn func(context.Context) (V, error)) {
+	value, err := invoke(ctx, fn)
+	g.mutex.Lock()
+	c.value, c.err = value, err
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	g.mutex.Unlock()
+	c.cancel()
+	close(c.done)
+}
+
+func invoke[V any](ctx context.Context, fn func(context.Context) (V, error)) (value V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
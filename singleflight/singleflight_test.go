@@ -0,0 +1,133 @@
+package singleflight_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/singleflight"
+)
+
+func TestDo(t *testing.T) {
+	g := singleflight.New[string, int]()
+	res := g.Do(context.Background(), "a", func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	if res.Value != 1 || res.Err != nil || res.Callers != 1 || res.Shared {
+		t.Errorf("Expected an unshared result of 1 got %+v", res)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	g := singleflight.New[string, int]()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 7, nil
+	}
+	results := make(chan singleflight.Result[int], 5)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- g.Do(context.Background(), "a", fn)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single call got %d", calls.Load())
+	}
+	for res := range results {
+		if res.Value != 7 || res.Callers != 5 || !res.Shared {
+			t.Errorf("Expected a result shared by 5 callers got %+v", res)
+		}
+	}
+}
+
+func TestCallerContext(t *testing.T) {
+	g := singleflight.New[string, int]()
+	cancelled := make(chan struct{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res := g.Do(ctx, "a", func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return 0, ctx.Err()
+	})
+	if res.Err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", res.Err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected the call to be cancelled once every caller gave up")
+	}
+}
+
+func TestCallOutlivesOneCaller(t *testing.T) {
+	g := singleflight.New[string, int]()
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		select {
+		case <-release:
+			return 3, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	first := g.DoChan(ctx, "a", fn)
+	time.Sleep(5 * time.Millisecond)
+	second := g.DoChan(context.Background(), "a", fn)
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	if res := <-first; res.Err != context.Canceled {
+		t.Errorf("Expected the first caller to be cancelled got %v", res.Err)
+	}
+	close(release)
+	if res := <-second; res.Value != 3 || res.Err != nil {
+		t.Errorf("Expected the second caller to receive 3 got %+v", res)
+	}
+}
+
+func TestForget(t *testing.T) {
+	g := singleflight.New[string, int]()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 1, nil
+	}
+	first := g.DoChan(context.Background(), "a", fn)
+	time.Sleep(5 * time.Millisecond)
+	g.Forget("a")
+	second := g.DoChan(context.Background(), "a", fn)
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+	<-first
+	<-second
+	if calls.Load() != 2 {
+		t.Errorf("Expected a forgotten key to start a new call got %d calls", calls.Load())
+	}
+}
+
+func TestPanic(t *testing.T) {
+	g := singleflight.New[string, int]()
+	res := g.Do(context.Background(), "key", func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	var panicErr *singleflight.PanicError
+	if !errors.As(res.Err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Expected the panic to be returned as an error got %v", res.Err)
+	}
+}
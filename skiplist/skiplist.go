@@ -0,0 +1,228 @@
+// Package skiplist is a package that implements a sorted map backed by a probabilistic skip list.
+// Each link records how many entries it skips, so entries can also be looked up by rank.
+package skiplist
+
+import (
+	"cmp"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	maxLevel    = 32
+	probability = 0.25
+)
+
+type node[K cmp.Ordered, V any] struct {
+	key   K
+	value V
+	next  []*node[K, V]
+	span  []int
+}
+
+// List is a key-value store that keeps keys in ascending order.
+// Lookups, insertions, deletions and rank queries are O(log n) on average. It is safe for concurrent use.
+// Keys are ordered by cmp.Compare, so floating-point NaN keys are equal to each other and sort
+// before every other key.
+type List[K cmp.Ordered, V any] struct {
+	head   *node[K, V]
+	level  int
+	length int
+	random *rand.Rand
+	mutex  *sync.RWMutex
+}
+
+// New creates an empty skip list.
+func New[K cmp.Ordered, V any]() *List[K, V] {
+	return &List[K, V]{
+		head: &node[K, V]{
+			next: make([]*node[K, V], maxLevel),
+			span: make([]int, maxLevel),
+		},
+		level:  1,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		mutex:  &sync.RWMutex{},
+	}
+}
+
+// Set a key/value into the list.
+func (l *List[K, V]) Set(key K, value V) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var update [maxLevel]*node[K, V]
+	var rank [maxLevel]int
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i] != nil && cmp.Less(x.next[i].key, key) {
+			rank[i] += x.span[i]
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	if next := x.next[0]; next != nil && cmp.Compare(next.key, key) == 0 {
+		next.value = value
+		return
+	}
+	level := l.randomLevel()
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = l.head
+			update[i].span[i] = l.length
+		}
+		l.level = level
+	}
+	n := &node[K, V]{
+		key:   key,
+		value: value,
+		next:  make([]*node[K, V], level),
+		span:  make([]int, level),
+	}
+	for i := 0; i < level; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+		n.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	for i := level; i < l.level; i++ {
+		update[i].span[i]++
+	}
+	l.length++
+}
+
+// Get will retrieve a value by key.
+func (l *List[K, V]) Get(key K) (V, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if n := l.seek(key); n != nil && cmp.Compare(n.key, key) == 0 {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete an entry from the list, reporting whether it existed.
+func (l *List[K, V]) Delete(key K) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var update [maxLevel]*node[K, V]
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && cmp.Less(x.next[i].key, key) {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	x = x.next[0]
+	if x == nil || cmp.Compare(x.key, key) != 0 {
+		return false
+	}
+	for i := 0; i < l.level; i++ {
+		if update[i].next[i] == x {
+			update[i].span[i] += x.span[i] - 1
+			update[i].next[i] = x.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+	for l.level > 1 && l.head.next[l.level-1] == nil {
+		l.level--
+	}
+	l.length--
+	return true
+}
+
+// Len returns the number of entries in the list.
+func (l *List[K, V]) Len() int {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.length
+}
+
+// Rank returns the zero-based position of key in ascending order.
+func (l *List[K, V]) Rank(key K) (int, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	rank := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && cmp.Compare(x.next[i].key, key) <= 0 {
+			rank += x.span[i]
+			x = x.next[i]
+		}
+		if x != l.head && cmp.Compare(x.key, key) == 0 {
+			return rank - 1, true
+		}
+	}
+	return 0, false
+}
+
+// ByRank returns the entry at the zero-based position rank in ascending order.
+func (l *List[K, V]) ByRank(rank int) (K, V, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if rank >= 0 && rank < l.length {
+		target := rank + 1
+		traversed := 0
+		x := l.head
+		for i := l.level - 1; i >= 0; i-- {
+			for x.next[i] != nil && traversed+x.span[i] <= target {
+				traversed += x.span[i]
+				x = x.next[i]
+			}
+			if traversed == target {
+				return x.key, x.value, true
+			}
+		}
+	}
+	var key K
+	var value V
+	return key, value, false
+}
+
+// Range calls fn for every entry with a key in [from, to) in ascending order.
+// Iteration stops early if fn returns false. The list must not be modified from fn.
+func (l *List[K, V]) Range(from, to K, fn func(key K, value V) bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	for n := l.seek(from); n != nil && cmp.Less(n.key, to); n = n.next[0] {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// Each calls fn for every entry in ascending key order.
+// Iteration stops early if fn returns false. The list must not be modified from fn.
+func (l *List[K, V]) Each(fn func(key K, value V) bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	for n := l.head.next[0]; n != nil; n = n.next[0] {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// seek returns the first node with a key greater than or equal to key.
+func (l *List[K, V]) seek(key K) *node[K, V] {
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && cmp.Less(x.next[i].key, key) {
+			x = x.next[i]
+		}
+	}
+	return x.next[0]
+}
+
+func (l *List[K, V]) randomLevel() int {
+	level := 1
+	for level < maxLevel && l.random.Float64() < probability {
+		level++
+	}
+	return level
+}
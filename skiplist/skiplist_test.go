@@ -0,0 +1,123 @@
+package skiplist_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/skiplist"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	l := skiplist.New[string, int]()
+	l.Set("b", 1)
+	l.Set("a", 2)
+	l.Set("b", 3)
+	if val, ok := l.Get("b"); !ok || val != 3 {
+		t.Errorf("Expected 3 got %v", val)
+	}
+	if !l.Delete("a") || l.Delete("a") {
+		t.Error("Expected 'a' to be deleted exactly once")
+	}
+	if l.Len() != 1 {
+		t.Errorf("Expected 1 entry got %d", l.Len())
+	}
+}
+
+func TestRank(t *testing.T) {
+	l := skiplist.New[int, int]()
+	expected := make(map[int]bool)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		key := r.Intn(300)
+		if r.Intn(3) == 0 {
+			l.Delete(key)
+			delete(expected, key)
+		} else {
+			l.Set(key, key)
+			expected[key] = true
+		}
+	}
+	keys := make([]int, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	for i, key := range keys {
+		if rank, ok := l.Rank(key); !ok || rank != i {
+			t.Fatalf("Expected rank %d for %d got %d", i, key, rank)
+		}
+		if k, _, ok := l.ByRank(i); !ok || k != key {
+			t.Fatalf("Expected key %d at rank %d got %d", key, i, k)
+		}
+	}
+	if _, _, ok := l.ByRank(len(keys)); ok {
+		t.Error("Expected no entry past the end")
+	}
+}
+
+func TestRange(t *testing.T) {
+	l := skiplist.New[int, int]()
+	for i := 0; i < 10; i++ {
+		l.Set(i, i)
+	}
+	var result []int
+	l.Range(3, 7, func(key, value int) bool {
+		result = append(result, key)
+		return true
+	})
+	if !reflect.DeepEqual(result, []int{3, 4, 5, 6}) {
+		t.Errorf("Expected [3 4 5 6] got %v", result)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	l := skiplist.New[int, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.Set(g*100+i, i)
+				l.Get(i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if l.Len() != 400 {
+		t.Errorf("Expected 400 entries got %d", l.Len())
+	}
+}
+
+func TestNaNKeys(t *testing.T) {
+	l := skiplist.New[float64, string]()
+	for _, key := range []float64{2, 1, 3} {
+		l.Set(key, "number")
+	}
+	l.Set(math.NaN(), "first")
+	l.Set(math.NaN(), "nan")
+	if l.Len() != 4 {
+		t.Errorf("Expected NaN keys to be equal to each other got %d entries", l.Len())
+	}
+	if val, ok := l.Get(math.NaN()); !ok || val != "nan" {
+		t.Errorf("Expected to find the NaN key got %q", val)
+	}
+	if rank, ok := l.Rank(math.NaN()); !ok || rank != 0 {
+		t.Errorf("Expected NaN to sort first got rank %d", rank)
+	}
+	if !l.Delete(math.NaN()) || l.Len() != 3 {
+		t.Error("Expected the NaN key to be deleted")
+	}
+	var keys []float64
+	l.Each(func(key float64, val string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if !reflect.DeepEqual(keys, []float64{1, 2, 3}) {
+		t.Errorf("Expected the remaining keys in order got %v", keys)
+	}
+}
@@ -0,0 +1,124 @@
+// Package slicex is a package of generic helpers for slices that the standard slices package
+// does not provide.
+//
+// Functions never modify their input and always return new slices.
+package slicex
+
+// Chunk splits s into consecutive slices of size items; the last may be shorter. It panics if
+// size is less than one.
+func Chunk[T any](s []T, size int) [][]T {
+	if size < 1 {
+		panic("slicex: chunk size must be positive")
+	}
+	chunks := make([][]T, 0, (len(s)+size-1)/size)
+	for start := 0; start < len(s); start += size {
+		end := min(start+size, len(s))
+		chunks = append(chunks, append([]T(nil), s[start:end]...))
+	}
+	return chunks
+}
+
+// Unique returns the items of s without duplicates, keeping the first occurrence of each.
+func Unique[T comparable](s []T) []T {
+	return UniqueBy(s, func(item T) T { return item })
+}
+
+// UniqueBy returns the items of s without duplicates by key, keeping the first occurrence.
+func UniqueBy[T any, K comparable](s []T, key func(T) K) []T {
+	seen := make(map[K]struct{}, len(s))
+	result := make([]T, 0, len(s))
+	for _, item := range s {
+		k := key(item)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		result = append(result, item)
+	}
+	return result
+}
+
+// Filter returns the items of s for which keep returns true.
+func Filter[T any](s []T, keep func(T) bool) []T {
+	result := make([]T, 0, len(s))
+	for _, item := range s {
+		if keep(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Map returns the result of fn for each item of s.
+func Map[T, R any](s []T, fn func(T) R) []R {
+	result := make([]R, len(s))
+	for i, item := range s {
+		result[i] = fn(item)
+	}
+	return result
+}
+
+// FlatMap returns the concatenated results of fn for each item of s.
+func FlatMap[T, R any](s []T, fn func(T) []R) []R {
+	var result []R
+	for _, item := range s {
+		result = append(result, fn(item)...)
+	}
+	return result
+}
+
+// GroupBy groups the items of s by key, preserving their order within each group.
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, item := range s {
+		k := key(item)
+		groups[k] = append(groups[k], item)
+	}
+	return groups
+}
+
+// Partition splits s into the items for which match returns true and those for which it returns
+// false, preserving order.
+func Partition[T any](s []T, match func(T) bool) (matched, unmatched []T) {
+	for _, item := range s {
+		if match(item) {
+			matched = append(matched, item)
+		} else {
+			unmatched = append(unmatched, item)
+		}
+	}
+	return matched, unmatched
+}
+
+// Reverse returns the items of s in reverse order.
+func Reverse[T any](s []T) []T {
+	result := make([]T, len(s))
+	for i, item := range s {
+		result[len(s)-1-i] = item
+	}
+	return result
+}
+
+// Difference returns the items of a that are not in b, preserving their order.
+func Difference[T comparable](a, b []T) []T {
+	exclude := make(map[T]struct{}, len(b))
+	for _, item := range b {
+		exclude[item] = struct{}{}
+	}
+	return Filter(a, func(item T) bool {
+		_, ok := exclude[item]
+		return !ok
+	})
+}
+
+// Intersect returns the items of a that are also in b, preserving their order.
+func Intersect[T comparable](a, b []T) []T {
+	include := make(map[T]struct{}, len(b))
+	for _, item := range b {
+		include[item] = struct{}{}
+	}
+	return Filter(a, func(item T) bool {
+		_, ok := include[item]
+		return ok
+	})
+}
@@ -0,0 +1,79 @@
+package slicex_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cjsaylor/goutil/slicex"
+)
+
+func TestChunk(t *testing.T) {
+	chunks := slicex.Chunk([]int{1, 2, 3, 4, 5}, 2)
+	expected := [][]int{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("Expected %v got %v", expected, chunks)
+	}
+	if len(slicex.Chunk([]int{}, 3)) != 0 {
+		t.Error("Expected no chunks for an empty slice")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a zero size")
+		}
+	}()
+	slicex.Chunk([]int{1}, 0)
+}
+
+func TestUnique(t *testing.T) {
+	if got := slicex.Unique([]int{3, 1, 3, 2, 1}); !reflect.DeepEqual(got, []int{3, 1, 2}) {
+		t.Errorf("Expected [3 1 2] got %v", got)
+	}
+	got := slicex.UniqueBy([]string{"a", "B", "A", "b"}, strings.ToLower)
+	if !reflect.DeepEqual(got, []string{"a", "B"}) {
+		t.Errorf("Expected [a B] got %v", got)
+	}
+}
+
+func TestFilterAndMap(t *testing.T) {
+	even := slicex.Filter([]int{1, 2, 3, 4}, func(n int) bool { return n%2 == 0 })
+	if !reflect.DeepEqual(even, []int{2, 4}) {
+		t.Errorf("Expected [2 4] got %v", even)
+	}
+	lengths := slicex.Map([]string{"a", "bb"}, func(s string) int { return len(s) })
+	if !reflect.DeepEqual(lengths, []int{1, 2}) {
+		t.Errorf("Expected [1 2] got %v", lengths)
+	}
+	words := slicex.FlatMap([]string{"a b", "c"}, strings.Fields)
+	if !reflect.DeepEqual(words, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c] got %v", words)
+	}
+}
+
+func TestGroupByAndPartition(t *testing.T) {
+	groups := slicex.GroupBy([]string{"apple", "avocado", "banana"}, func(s string) byte { return s[0] })
+	if !reflect.DeepEqual(groups, map[byte][]string{'a': {"apple", "avocado"}, 'b': {"banana"}}) {
+		t.Errorf("Expected groups by first letter got %v", groups)
+	}
+	small, large := slicex.Partition([]int{5, 1, 8, 2}, func(n int) bool { return n < 4 })
+	if !reflect.DeepEqual(small, []int{1, 2}) || !reflect.DeepEqual(large, []int{5, 8}) {
+		t.Errorf("Expected [1 2] and [5 8] got %v and %v", small, large)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	s := []int{1, 2, 3}
+	if got := slicex.Reverse(s); !reflect.DeepEqual(got, []int{3, 2, 1}) || s[0] != 1 {
+		t.Errorf("Expected a reversed copy got %v", got)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a, b := []int{1, 2, 3, 4}, []int{2, 4, 6}
+	if got := slicex.Difference(a, b); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("Expected [1 3] got %v", got)
+	}
+	if got := slicex.Intersect(a, b); !reflect.DeepEqual(got, []int{2, 4}) {
+		t.Errorf("Expected [2 4] got %v", got)
+	}
+}
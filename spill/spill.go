@@ -0,0 +1,217 @@
+// Package spill is a package that extends an LRU cache with a size-bounded on-disk overflow tier.
+// Entries evicted from memory are written to disk and promoted back into memory when accessed.
+package spill
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cjsaylor/goutil/lru"
+)
+
+const fileExtension = ".spill"
+
+// Codec converts cached values to and from their on-disk representation.
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+type bytesCodec struct{}
+
+func (bytesCodec) Marshal(value interface{}) ([]byte, error) {
+	return value.([]byte), nil
+}
+
+func (bytesCodec) Unmarshal(data []byte) (interface{}, error) {
+	return data, nil
+}
+
+// Bytes returns a codec for caches whose values are []byte.
+func Bytes() Codec {
+	return bytesCodec{}
+}
+
+// Cache is a key-value store with a fixed number of entries held in memory,
+// and a fixed number of bytes of overflow entries held on disk.
+type Cache struct {
+	memory *lru.Cache
+	disk   *diskStore
+	codec  Codec
+	mutex  *sync.Mutex
+}
+
+// NewCache creates an LRU cache holding capacity entries in memory and up to diskCapacity bytes in dir.
+// Overflow files left in dir by a previous instance are removed.
+func NewCache(capacity int, dir string, diskCapacity int64, codec Codec) (*Cache, error) {
+	disk, err := newDiskStore(dir, diskCapacity)
+	if err != nil {
+		return nil, err
+	}
+	cache := Cache{
+		disk:  disk,
+		codec: codec,
+		mutex: &sync.Mutex{},
+	}
+	cache.memory = lru.NewCache(capacity, cache.spill)
+	return &cache, nil
+}
+
+// Set a key/value into the cache.
+// This will spill the oldest in-memory entry to disk if at the capacity limit.
+func (c *Cache) Set(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.disk.remove(key)
+	c.memory.Set(key, value)
+}
+
+// Get will retrieve a value by key from memory or disk.
+// Values found on disk are moved back into memory.
+func (c *Cache) Get(key string) (interface{}, bool) {
+	if value, ok := c.memory.Get(key); ok {
+		return value, true
+	}
+	// Promotion holds the lock so a concurrent Set of the same key cannot be overwritten by the
+	// older value read from disk.
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if value, ok := c.memory.Get(key); ok {
+		return value, true
+	}
+	data, ok := c.disk.take(key)
+	if !ok {
+		return nil, false
+	}
+	value, err := c.codec.Unmarshal(data)
+	if err != nil {
+		return nil, false
+	}
+	c.memory.Set(key, value)
+	return value, true
+}
+
+// Remove an entry from both memory and disk.
+func (c *Cache) Remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.memory.Remove(key)
+	c.disk.remove(key)
+}
+
+// DiskSize returns the number of bytes of entries currently spilled to disk.
+func (c *Cache) DiskSize() int64 {
+	return c.disk.usage()
+}
+
+// spill is the eviction callback of the in-memory tier.
+// Entries that cannot be encoded or written are dropped.
+func (c *Cache) spill(key, value interface{}) {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.disk.put(key.(string), data)
+}
+
+type diskEntry struct {
+	key  string
+	size int64
+}
+
+type diskStore struct {
+	dir      string
+	queue    *list.List
+	lookup   map[string]*list.Element
+	capacity int64
+	size     int64
+	mutex    *sync.Mutex
+}
+
+func newDiskStore(dir string, capacity int64) (*diskStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	stale, err := filepath.Glob(filepath.Join(dir, "*"+fileExtension))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return &diskStore{
+		dir:      dir,
+		queue:    list.New(),
+		lookup:   make(map[string]*list.Element),
+		capacity: capacity,
+		mutex:    &sync.Mutex{},
+	}, nil
+}
+
+func (d *diskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+fileExtension)
+}
+
+func (d *diskStore) put(key string, data []byte) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if item, ok := d.lookup[key]; ok {
+		d.removeElement(item)
+	}
+	size := int64(len(data))
+	if size > d.capacity {
+		return
+	}
+	for d.size+size > d.capacity {
+		d.removeElement(d.queue.Back())
+	}
+	if err := os.WriteFile(d.path(key), data, 0600); err != nil {
+		return
+	}
+	d.lookup[key] = d.queue.PushFront(&diskEntry{key: key, size: size})
+	d.size += size
+}
+
+func (d *diskStore) take(key string) ([]byte, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	item, ok := d.lookup[key]
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(d.path(key))
+	d.removeElement(item)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func (d *diskStore) remove(key string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if item, ok := d.lookup[key]; ok {
+		d.removeElement(item)
+	}
+}
+
+func (d *diskStore) usage() int64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.size
+}
+
+func (d *diskStore) removeElement(item *list.Element) {
+	e := item.Value.(*diskEntry)
+	d.queue.Remove(item)
+	delete(d.lookup, e.key)
+	d.size -= e.size
+	os.Remove(d.path(e.key))
+}
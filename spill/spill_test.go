@@ -0,0 +1,124 @@
+package spill_test
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/spill"
+)
+
+func TestSpillAndPromote(t *testing.T) {
+	cache, err := spill.NewCache(1, t.TempDir(), 1024, spill.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", []byte("foo"))
+	cache.Set("b", []byte("bar"))
+	if cache.DiskSize() != 3 {
+		t.Errorf("Expected 'a' to be spilled to disk, got %d bytes", cache.DiskSize())
+	}
+	if val, ok := cache.Get("a"); !ok || string(val.([]byte)) != "foo" {
+		t.Errorf("Expected 'a' to be promoted from disk got %v", val)
+	}
+	if val, ok := cache.Get("b"); !ok || string(val.([]byte)) != "bar" {
+		t.Errorf("Expected 'b' to be promoted from disk got %v", val)
+	}
+}
+
+func TestDiskCapacity(t *testing.T) {
+	cache, err := spill.NewCache(1, t.TempDir(), 6, spill.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c", "d"} {
+		cache.Set(key, []byte("foo"))
+	}
+	if cache.DiskSize() != 6 {
+		t.Errorf("Expected disk to be bounded to 6 bytes got %d", cache.DiskSize())
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected 'a' to be evicted from disk")
+	}
+	for _, key := range []string{"b", "c", "d"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected %v to still be in the cache", key)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := spill.NewCache(1, dir, 1024, spill.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", []byte("foo"))
+	cache.Set("b", []byte("bar"))
+	cache.Remove("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected 'a' to be removed")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected no files on disk got %d", len(files))
+	}
+}
+
+func TestStaleFilesRemoved(t *testing.T) {
+	dir := t.TempDir()
+	cache, _ := spill.NewCache(1, dir, 1024, spill.Bytes())
+	cache.Set("a", []byte("foo"))
+	cache.Set("b", []byte("bar"))
+	if _, err := spill.NewCache(1, dir, 1024, spill.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected stale files to be removed got %d", len(files))
+	}
+}
+
+// pausingCodec blocks the first Unmarshal until released, holding a promotion from disk open.
+type pausingCodec struct {
+	entered, release chan struct{}
+	once             sync.Once
+}
+
+func (c *pausingCodec) Marshal(value interface{}) ([]byte, error) {
+	return value.([]byte), nil
+}
+
+func (c *pausingCodec) Unmarshal(data []byte) (interface{}, error) {
+	c.once.Do(func() {
+		close(c.entered)
+		<-c.release
+	})
+	return data, nil
+}
+
+func TestSetDuringPromote(t *testing.T) {
+	codec := &pausingCodec{entered: make(chan struct{}), release: make(chan struct{})}
+	cache, err := spill.NewCache(1, t.TempDir(), 1024, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", []byte("old"))
+	cache.Set("b", []byte("spills a"))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		cache.Get("a")
+	}()
+	<-codec.entered
+	go func() {
+		defer wg.Done()
+		cache.Set("a", []byte("new"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(codec.release)
+	wg.Wait()
+	if val, ok := cache.Get("a"); !ok || string(val.([]byte)) != "new" {
+		t.Errorf("Expected the value set during promotion to win got %s", val)
+	}
+}
@@ -0,0 +1,80 @@
+// Package stack is a package that implements a generic last-in first-out stack.
+package stack
+
+import (
+	"sync"
+)
+
+type noopLocker struct{}
+
+func (noopLocker) Lock()   {}
+func (noopLocker) Unlock() {}
+
+// Stack is a LIFO collection, optionally bounded to a fixed capacity.
+type Stack[T any] struct {
+	items    []T
+	capacity int
+	mutex    sync.Locker
+}
+
+// New creates an empty stack. A capacity of zero or less creates an unbounded stack.
+// The stack is not safe for concurrent use.
+func New[T any](capacity int) *Stack[T] {
+	return &Stack[T]{
+		capacity: capacity,
+		mutex:    noopLocker{},
+	}
+}
+
+// NewConcurrent creates an empty stack that is safe for concurrent use.
+// A capacity of zero or less creates an unbounded stack.
+func NewConcurrent[T any](capacity int) *Stack[T] {
+	s := New[T](capacity)
+	s.mutex = &sync.Mutex{}
+	return s
+}
+
+// Push adds an item to the top of the stack.
+// It reports false if the stack is bounded and full.
+func (s *Stack[T]) Push(item T) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.capacity > 0 && len(s.items) >= s.capacity {
+		return false
+	}
+	s.items = append(s.items, item)
+	return true
+}
+
+// Pop removes and returns the item at the top of the stack.
+func (s *Stack[T]) Pop() (T, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	last := len(s.items) - 1
+	item := s.items[last]
+	s.items[last] = zero
+	s.items = s.items[:last]
+	return item, true
+}
+
+// Peek returns the item at the top of the stack without removing it.
+func (s *Stack[T]) Peek() (T, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return s.items[len(s.items)-1], true
+}
+
+// Len returns the number of items on the stack.
+func (s *Stack[T]) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.items)
+}
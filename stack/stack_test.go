@@ -0,0 +1,55 @@
+package stack_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/stack"
+)
+
+func TestPushPop(t *testing.T) {
+	s := stack.New[int](0)
+	for i := 0; i < 3; i++ {
+		s.Push(i)
+	}
+	if item, ok := s.Peek(); !ok || item != 2 {
+		t.Errorf("Expected to peek 2 got %v", item)
+	}
+	for i := 2; i >= 0; i-- {
+		if item, ok := s.Pop(); !ok || item != i {
+			t.Errorf("Expected %d got %v", i, item)
+		}
+	}
+	if _, ok := s.Pop(); ok {
+		t.Error("Expected pop from an empty stack to fail")
+	}
+}
+
+func TestBounded(t *testing.T) {
+	s := stack.New[string](1)
+	if !s.Push("a") || s.Push("b") {
+		t.Error("Expected the stack to accept exactly one item")
+	}
+	s.Pop()
+	if !s.Push("b") {
+		t.Error("Expected push to succeed after pop")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	s := stack.NewConcurrent[int](0)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.Push(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 400 {
+		t.Errorf("Expected 400 items got %d", s.Len())
+	}
+}
@@ -0,0 +1,134 @@
+// Package stmtcache is a package that caches database/sql prepared statements by query text.
+// The number of open statement handles is bounded, and evicted statements are closed.
+package stmtcache
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/cjsaylor/goutil/lru"
+)
+
+// Preparer creates prepared statements. It is satisfied by *sql.DB, *sql.Conn and *sql.Tx.
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// Cache holds a fixed number of prepared statements.
+// The least recently used statement is closed when a new statement is prepared at the capacity limit,
+// or once the last caller using it releases it.
+type Cache struct {
+	preparer   Preparer
+	statements *lru.Cache
+	mutex      *sync.Mutex
+}
+
+// entry is a cached statement and the number of callers currently using it.
+// Its fields are guarded by the cache mutex.
+type entry struct {
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// NewCache creates a statement cache that keeps at most capacity statements prepared.
+func NewCache(preparer Preparer, capacity int) *Cache {
+	return &Cache{
+		preparer: preparer,
+		// Evictions happen inside statements.Set, which is only called with the mutex held.
+		statements: lru.NewCache(capacity, func(key, value interface{}) {
+			value.(*entry).evict()
+		}),
+		mutex: &sync.Mutex{},
+	}
+}
+
+// Prepare returns the cached statement for query, preparing it if necessary.
+// The statement is owned by the cache and must not be closed by the caller. It stays open until
+// release is called, even if it is evicted in the meantime, so release must be called exactly once
+// when the caller is done with it.
+func (c *Cache) Prepare(ctx context.Context, query string) (stmt *sql.Stmt, release func(), err error) {
+	c.mutex.Lock()
+	if e, ok := c.statements.Get(query); ok {
+		defer c.mutex.Unlock()
+		return c.lease(e.(*entry))
+	}
+	c.mutex.Unlock()
+	prepared, err := c.preparer.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if existing, ok := c.statements.Get(query); ok {
+		prepared.Close()
+		return c.lease(existing.(*entry))
+	}
+	e := &entry{stmt: prepared}
+	c.statements.Set(query, e)
+	return c.lease(e)
+}
+
+// lease hands out e to a caller. It must be called with the mutex held.
+func (c *Cache) lease(e *entry) (*sql.Stmt, func(), error) {
+	e.refs++
+	var once sync.Once
+	return e.stmt, func() {
+		once.Do(func() {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			e.refs--
+			if e.evicted && e.refs == 0 {
+				e.stmt.Close()
+			}
+		})
+	}, nil
+}
+
+// evict marks e as no longer cached and closes it unless a caller is still using it.
+// It must be called with the mutex held.
+func (e *entry) evict() error {
+	e.evicted = true
+	if e.refs > 0 {
+		return nil
+	}
+	return e.stmt.Close()
+}
+
+// ExecContext executes query with args using a cached prepared statement.
+func (c *Cache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, release, err := c.Prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext executes query with args using a cached prepared statement.
+// The rows remain valid after the statement is evicted.
+func (c *Cache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, release, err := c.Prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return stmt.QueryContext(ctx, args...)
+}
+
+// Close empties the cache and closes every statement that is not in use. Statements in use are
+// closed when they are released.
+func (c *Cache) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var firstErr error
+	for _, query := range c.statements.ListKeys() {
+		if e, ok := c.statements.Remove(query); ok {
+			if err := e.(*entry).evict(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,131 @@
+package stmtcache_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/stmtcache"
+)
+
+type counts struct {
+	mutex    sync.Mutex
+	prepared int
+	closed   int
+}
+
+type fakeDriver struct{ counts *counts }
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ counts *counts }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.counts.mutex.Lock()
+	defer c.counts.mutex.Unlock()
+	c.counts.prepared++
+	return fakeStmt(c), nil
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeStmt struct{ counts *counts }
+
+func (s fakeStmt) Close() error {
+	s.counts.mutex.Lock()
+	defer s.counts.mutex.Unlock()
+	s.counts.closed++
+	return nil
+}
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) { return fakeRows{}, nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return nil }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+func openDB(t *testing.T) (*sql.DB, *counts) {
+	c := &counts{}
+	db := sql.OpenDB(connector{c})
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, c
+}
+
+type connector struct{ counts *counts }
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c connector) Driver() driver.Driver                            { return fakeDriver(c) }
+
+func TestPrepareReusesStatements(t *testing.T) {
+	db, c := openDB(t)
+	cache := stmtcache.NewCache(db, 2)
+	for i := 0; i < 3; i++ {
+		if _, err := cache.ExecContext(context.Background(), "UPDATE a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.prepared != 1 {
+		t.Errorf("Expected a single prepare got %d", c.prepared)
+	}
+}
+
+func TestEvictionClosesStatement(t *testing.T) {
+	db, c := openDB(t)
+	cache := stmtcache.NewCache(db, 1)
+	cache.ExecContext(context.Background(), "UPDATE a")
+	rows, err := cache.QueryContext(context.Background(), "SELECT b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if c.closed != 1 {
+		t.Errorf("Expected evicted statement to be closed got %d closes", c.closed)
+	}
+}
+
+func TestClose(t *testing.T) {
+	db, c := openDB(t)
+	cache := stmtcache.NewCache(db, 2)
+	cache.ExecContext(context.Background(), "UPDATE a")
+	cache.ExecContext(context.Background(), "UPDATE b")
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if c.closed != 2 {
+		t.Errorf("Expected all statements to be closed got %d closes", c.closed)
+	}
+	cache.ExecContext(context.Background(), "UPDATE a")
+	if c.prepared != 3 {
+		t.Errorf("Expected statement to be prepared again after close got %d", c.prepared)
+	}
+}
+
+func TestEvictionWaitsForRelease(t *testing.T) {
+	db, c := openDB(t)
+	cache := stmtcache.NewCache(db, 1)
+	stmt, release, err := cache.Prepare(context.Background(), "UPDATE a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.ExecContext(context.Background(), "UPDATE b")
+	if _, err := stmt.Exec(); err != nil {
+		t.Errorf("Expected an evicted statement to stay usable until released got %v", err)
+	}
+	if c.closed != 0 {
+		t.Errorf("Expected no statement to be closed while in use got %d closes", c.closed)
+	}
+	release()
+	release()
+	if c.closed != 1 {
+		t.Errorf("Expected the statement to be closed once on release got %d closes", c.closed)
+	}
+}
@@ -0,0 +1,287 @@
+// Package stream is a package for lazily evaluated pipelines over large or unbounded inputs.
+//
+// Nothing runs until a terminal operation such as Collect or ForEach is called, and values flow
+// through the pipeline one at a time, so memory stays bounded by the stages rather than the
+// input. Terminal operations that need only part of the input, such as First or Take, stop
+// reading the source as soon as they have it.
+package stream
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"iter"
+)
+
+// Stream is a lazily evaluated sequence of values that may fail. A Stream can be run more than
+// once if its source can.
+type Stream[T any] struct {
+	// run yields values until the source ends or yield returns false. It returns the first error
+	// from the source or a stage.
+	run func(yield func(T) bool) error
+}
+
+// FromSlice returns a stream of the items of s.
+func FromSlice[T any](s []T) Stream[T] {
+	return FromSeq(func(yield func(T) bool) {
+		for _, item := range s {
+			if !yield(item) {
+				return
+			}
+		}
+	})
+}
+
+// FromSeq returns a stream of the values of seq.
+func FromSeq[T any](seq iter.Seq[T]) Stream[T] {
+	return Stream[T]{run: func(yield func(T) bool) error {
+		seq(yield)
+		return nil
+	}}
+}
+
+// FromChannel returns a stream of the values received from ch until it is closed. The stream
+// fails with ctx's error if ctx is done first.
+func FromChannel[T any](ctx context.Context, ch <-chan T) Stream[T] {
+	return Stream[T]{run: func(yield func(T) bool) error {
+		for {
+			select {
+			case value, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				if !yield(value) {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}}
+}
+
+// Lines returns a stream of the lines of r, without line endings. A stream of r can only be run once.
+func Lines(r io.Reader) Stream[string] {
+	return Stream[string]{run: func(yield func(string) bool) error {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if !yield(scanner.Text()) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}}
+}
+
+// Filter returns a stream of the values for which keep returns true.
+func (s Stream[T]) Filter(keep func(T) bool) Stream[T] {
+	return Stream[T]{run: func(yield func(T) bool) error {
+		return s.run(func(value T) bool {
+			return !keep(value) || yield(value)
+		})
+	}}
+}
+
+// Take returns a stream of at most the first n values.
+func (s Stream[T]) Take(n int) Stream[T] {
+	return Stream[T]{run: func(yield func(T) bool) error {
+		if n <= 0 {
+			return nil
+		}
+		taken := 0
+		return s.run(func(value T) bool {
+			taken++
+			return yield(value) && taken < n
+		})
+	}}
+}
+
+// Skip returns a stream without the first n values.
+func (s Stream[T]) Skip(n int) Stream[T] {
+	return Stream[T]{run: func(yield func(T) bool) error {
+		skipped := 0
+		return s.run(func(value T) bool {
+			if skipped < n {
+				skipped++
+				return true
+			}
+			return yield(value)
+		})
+	}}
+}
+
+// Peek returns a stream that calls fn with each value as it passes through.
+func (s Stream[T]) Peek(fn func(T)) Stream[T] {
+	return Stream[T]{run: func(yield func(T) bool) error {
+		return s.run(func(value T) bool {
+			fn(value)
+			return yield(value)
+		})
+	}}
+}
+
+// ForEach runs the stream, calling fn with each value. It stops at the first error from fn or
+// the stream and returns it.
+func (s Stream[T]) ForEach(fn func(T) error) error {
+	var fnErr error
+	err := s.run(func(value T) bool {
+		fnErr = fn(value)
+		return fnErr == nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// Collect runs the stream and returns its values.
+func (s Stream[T]) Collect() ([]T, error) {
+	var values []T
+	err := s.run(func(value T) bool {
+		values = append(values, value)
+		return true
+	})
+	return values, err
+}
+
+// Count runs the stream and returns the number of values.
+func (s Stream[T]) Count() (int, error) {
+	n := 0
+	err := s.run(func(T) bool {
+		n++
+		return true
+	})
+	return n, err
+}
+
+// First returns the first value, and false if the stream is empty.
+func (s Stream[T]) First() (T, bool, error) {
+	var first T
+	found := false
+	err := s.run(func(value T) bool {
+		first, found = value, true
+		return false
+	})
+	return first, found, err
+}
+
+// Any reports whether match returns true for some value, stopping at the first that does.
+func (s Stream[T]) Any(match func(T) bool) (bool, error) {
+	_, found, err := s.Filter(match).First()
+	return found, err
+}
+
+// All reports whether match returns true for every value, stopping at the first that does not.
+func (s Stream[T]) All(match func(T) bool) (bool, error) {
+	found, err := s.Any(func(value T) bool { return !match(value) })
+	return !found, err
+}
+
+// Map returns a stream of fn applied to each value of s.
+func Map[T, R any](s Stream[T], fn func(T) R) Stream[R] {
+	return Stream[R]{run: func(yield func(R) bool) error {
+		return s.run(func(value T) bool {
+			return yield(fn(value))
+		})
+	}}
+}
+
+// MapErr returns a stream of fn applied to each value of s, failing with fn's first error.
+func MapErr[T, R any](s Stream[T], fn func(T) (R, error)) Stream[R] {
+	return Stream[R]{run: func(yield func(R) bool) error {
+		var fnErr error
+		err := s.run(func(value T) bool {
+			var result R
+			result, fnErr = fn(value)
+			return fnErr == nil && yield(result)
+		})
+		if fnErr != nil {
+			return fnErr
+		}
+		return err
+	}}
+}
+
+// Batch returns a stream of slices of size consecutive values; the last may be shorter.
+// It panics if size is less than one.
+func Batch[T any](s Stream[T], size int) Stream[[]T] {
+	if size < 1 {
+		panic("stream: batch size must be positive")
+	}
+	return Stream[[]T]{run: func(yield func([]T) bool) error {
+		batch := make([]T, 0, size)
+		stopped := false
+		err := s.run(func(value T) bool {
+			batch = append(batch, value)
+			if len(batch) < size {
+				return true
+			}
+			full := batch
+			batch = make([]T, 0, size)
+			stopped = !yield(full)
+			return !stopped
+		})
+		if err == nil && !stopped && len(batch) > 0 {
+			yield(batch)
+		}
+		return err
+	}}
+}
+
+// Reduce runs the stream, folding its values into an accumulator starting at initial.
+func Reduce[T, A any](s Stream[T], initial A, fn func(A, T) A) (A, error) {
+	acc := initial
+	err := s.run(func(value T) bool {
+		acc = fn(acc, value)
+		return true
+	})
+	return acc, err
+}
+
+// ParallelMap returns a stream of fn applied to each value of s on up to workers goroutines at
+// once. Results keep the order of the input, and at most workers values are in flight, so a slow
+// value holds back the ones after it rather than letting them pile up. The stream fails with fn's
+// first error, and the context passed to fn is cancelled once the stream stops.
+func ParallelMap[T, R any](s Stream[T], workers int, fn func(ctx context.Context, value T) (R, error)) Stream[R] {
+	if workers < 1 {
+		workers = 1
+	}
+	type result struct {
+		value R
+		err   error
+	}
+	return Stream[R]{run: func(yield func(R) bool) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		pending := make(chan chan result, workers-1)
+		var sourceErr error
+		go func() {
+			defer close(pending)
+			sourceErr = s.run(func(value T) bool {
+				out := make(chan result, 1)
+				select {
+				case pending <- out:
+				case <-ctx.Done():
+					return false
+				}
+				go func() {
+					r, err := fn(ctx, value)
+					out <- result{value: r, err: err}
+				}()
+				return true
+			})
+		}()
+		// Returning cancels ctx, which stops the source at its next value; it is not waited for, so
+		// a source blocked on input cannot hold up the consumer.
+		for out := range pending {
+			r := <-out
+			if r.err != nil {
+				return r.err
+			}
+			if !yield(r.value) {
+				return nil
+			}
+		}
+		return sourceErr
+	}}
+}
@@ -0,0 +1,157 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/stream"
+)
+
+// naturals is an endless stream, so tests hang if an operation is not lazy.
+var naturals = stream.FromSeq(func(yield func(int) bool) {
+	for i := 0; ; i++ {
+		if !yield(i) {
+			return
+		}
+	}
+})
+
+func TestLazy(t *testing.T) {
+	var seen atomic.Int32
+	values, err := naturals.Peek(func(int) { seen.Add(1) }).Filter(func(n int) bool { return n%3 == 0 }).Skip(1).Take(3).Collect()
+	if err != nil || !reflect.DeepEqual(values, []int{3, 6, 9}) {
+		t.Errorf("Expected [3 6 9] got %v (%v)", values, err)
+	}
+	if seen.Load() != 10 {
+		t.Errorf("Expected only 10 values to be read got %d", seen.Load())
+	}
+}
+
+func TestTerminals(t *testing.T) {
+	s := stream.FromSlice([]int{1, 2, 3, 4})
+	if n, _ := s.Count(); n != 4 {
+		t.Errorf("Expected 4 got %d", n)
+	}
+	if first, ok, _ := s.First(); !ok || first != 1 {
+		t.Errorf("Expected 1 got %d", first)
+	}
+	if _, ok, _ := stream.FromSlice([]int{}).First(); ok {
+		t.Error("Expected no first value of an empty stream")
+	}
+	if any, _ := naturals.Any(func(n int) bool { return n > 100 }); !any {
+		t.Error("Expected Any to stop at a match")
+	}
+	if all, _ := naturals.All(func(n int) bool { return n < 100 }); all {
+		t.Error("Expected All to stop at a mismatch")
+	}
+	sum, _ := stream.Reduce(s, 0, func(acc, n int) int { return acc + n })
+	if sum != 10 {
+		t.Errorf("Expected 10 got %d", sum)
+	}
+}
+
+func TestMap(t *testing.T) {
+	words, _ := stream.Map(stream.FromSlice([]int{1, 2}), strconv.Itoa).Collect()
+	if !reflect.DeepEqual(words, []string{"1", "2"}) {
+		t.Errorf("Expected [1 2] got %v", words)
+	}
+	_, err := stream.MapErr(stream.FromSlice([]string{"1", "x", "3"}), strconv.Atoi).Collect()
+	if err == nil {
+		t.Error("Expected the parse error")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	batches, _ := stream.Batch(stream.FromSlice([]int{1, 2, 3, 4, 5}), 2).Collect()
+	if !reflect.DeepEqual(batches, [][]int{{1, 2}, {3, 4}, {5}}) {
+		t.Errorf("Expected [[1 2] [3 4] [5]] got %v", batches)
+	}
+	first, _, _ := stream.Batch(naturals, 3).First()
+	if !reflect.DeepEqual(first, []int{0, 1, 2}) {
+		t.Errorf("Expected [0 1 2] got %v", first)
+	}
+}
+
+func TestSources(t *testing.T) {
+	lines, err := stream.Lines(strings.NewReader("a\nb\nc")).Collect()
+	if err != nil || !reflect.DeepEqual(lines, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c] got %v (%v)", lines, err)
+	}
+	ch := make(chan int, 2)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	values, _ := stream.FromChannel(context.Background(), ch).Collect()
+	if !reflect.DeepEqual(values, []int{1, 2}) {
+		t.Errorf("Expected [1 2] got %v", values)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := stream.FromChannel(ctx, make(chan int)).Collect(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Canceled got %v", err)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	stop := errors.New("stop")
+	var seen []int
+	err := naturals.ForEach(func(n int) error {
+		if n == 3 {
+			return stop
+		}
+		seen = append(seen, n)
+		return nil
+	})
+	if !errors.Is(err, stop) || len(seen) != 3 {
+		t.Errorf("Expected to stop after 3 values got %v (%v)", seen, err)
+	}
+}
+
+func TestParallelMap(t *testing.T) {
+	var running, peak atomic.Int32
+	double := func(ctx context.Context, n int) (int, error) {
+		current := running.Add(1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		// Later values finish sooner, so order is only kept if results are reordered.
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		running.Add(-1)
+		return n * 2, nil
+	}
+	values, err := stream.ParallelMap(stream.FromSlice([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}), 3, double).Collect()
+	if err != nil || !reflect.DeepEqual(values, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}) {
+		t.Errorf("Expected doubled values in order got %v (%v)", values, err)
+	}
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 values in flight got %d", peak.Load())
+	}
+}
+
+func TestParallelMapShortCircuit(t *testing.T) {
+	failed := errors.New("failed")
+	_, err := stream.ParallelMap(naturals, 4, func(ctx context.Context, n int) (int, error) {
+		if n == 5 {
+			return 0, failed
+		}
+		return n, nil
+	}).Collect()
+	if !errors.Is(err, failed) {
+		t.Errorf("Expected the error to stop the stream got %v", err)
+	}
+	first, ok, _ := stream.ParallelMap(naturals, 4, func(ctx context.Context, n int) (int, error) {
+		return n + 1, nil
+	}).First()
+	if !ok || first != 1 {
+		t.Errorf("Expected 1 got %d", first)
+	}
+}
@@ -0,0 +1,94 @@
+// Package syncmap is a package that wraps sync.Map with type parameters.
+package syncmap
+
+import (
+	"sync"
+)
+
+// Map is a typed sync.Map, suited to read-mostly maps and maps whose goroutines work on disjoint keys.
+// The zero value is empty and ready to use. A Map must not be copied after first use.
+type Map[K comparable, V any] struct {
+	m sync.Map
+}
+
+// Load returns the value for key.
+func (m *Map[K, V]) Load(key K) (V, bool) {
+	value, ok := m.m.Load(key)
+	v, _ := value.(V)
+	return v, ok
+}
+
+// Store sets the value for key.
+func (m *Map[K, V]) Store(key K, value V) {
+	m.m.Store(key, value)
+}
+
+// LoadOrStore returns the existing value for key if present. Otherwise it stores value and returns
+// it. The boolean reports whether the value was loaded.
+func (m *Map[K, V]) LoadOrStore(key K, value V) (V, bool) {
+	actual, loaded := m.m.LoadOrStore(key, value)
+	v, _ := actual.(V)
+	return v, loaded
+}
+
+// LoadAndDelete deletes key and returns its previous value.
+func (m *Map[K, V]) LoadAndDelete(key K) (V, bool) {
+	value, loaded := m.m.LoadAndDelete(key)
+	v, _ := value.(V)
+	return v, loaded
+}
+
+// Delete removes key.
+func (m *Map[K, V]) Delete(key K) {
+	m.m.Delete(key)
+}
+
+// Swap stores value for key and returns the previous value.
+func (m *Map[K, V]) Swap(key K, value V) (V, bool) {
+	previous, loaded := m.m.Swap(key, value)
+	v, _ := previous.(V)
+	return v, loaded
+}
+
+// Range calls fn for every entry until fn returns false. See sync.Map.Range for its consistency
+// guarantees.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	m.m.Range(func(key, value interface{}) bool {
+		// Nil interface keys and values come back from sync.Map untyped, so a checked assertion
+		// turns them into the zero value rather than panicking.
+		k, _ := key.(K)
+		v, _ := value.(V)
+		return fn(k, v)
+	})
+}
+
+// Len returns the number of entries. It visits every entry, so it takes time proportional to the size
+// of the map.
+func (m *Map[K, V]) Len() int {
+	n := 0
+	m.m.Range(func(key, value interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Snapshot returns a copy of the entries as a plain map.
+func (m *Map[K, V]) Snapshot() map[K]V {
+	snapshot := make(map[K]V)
+	m.Range(func(key K, value V) bool {
+		snapshot[key] = value
+		return true
+	})
+	return snapshot
+}
+
+// CompareAndSwap stores new for key if its value is currently old, and reports whether it did.
+func CompareAndSwap[K, V comparable](m *Map[K, V], key K, old, new V) bool {
+	return m.m.CompareAndSwap(key, old, new)
+}
+
+// CompareAndDelete deletes key if its value is currently old, and reports whether it did.
+func CompareAndDelete[K, V comparable](m *Map[K, V], key K, old V) bool {
+	return m.m.CompareAndDelete(key, old)
+}
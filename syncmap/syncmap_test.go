@@ -0,0 +1,94 @@
+package syncmap_test
+
+import (
+	"testing"
+
+	"github.com/cjsaylor/goutil/syncmap"
+)
+
+func TestLoadStore(t *testing.T) {
+	var m syncmap.Map[string, int]
+	if val, ok := m.Load("a"); val != 0 || ok {
+		t.Errorf("Expected a miss got %v (%v)", val, ok)
+	}
+	m.Store("a", 1)
+	if val, ok := m.Load("a"); val != 1 || !ok {
+		t.Errorf("Expected 1 got %v (%v)", val, ok)
+	}
+	if val, loaded := m.LoadOrStore("a", 2); val != 1 || !loaded {
+		t.Errorf("Expected the existing 1 got %v (%v)", val, loaded)
+	}
+	if val, loaded := m.Swap("a", 3); val != 1 || !loaded {
+		t.Errorf("Expected the previous 1 got %v (%v)", val, loaded)
+	}
+	if val, loaded := m.LoadAndDelete("a"); val != 3 || !loaded {
+		t.Errorf("Expected to delete 3 got %v (%v)", val, loaded)
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expected an empty map got %d", m.Len())
+	}
+}
+
+func TestNilInterfaceValues(t *testing.T) {
+	var m syncmap.Map[string, error]
+	m.Store("a", nil)
+	if err, ok := m.Load("a"); err != nil || !ok {
+		t.Errorf("Expected a stored nil got %v (%v)", err, ok)
+	}
+	if err, loaded := m.LoadOrStore("b", nil); err != nil || loaded {
+		t.Errorf("Expected nil to be stored got %v (%v)", err, loaded)
+	}
+	if err, loaded := m.Swap("a", nil); err != nil || !loaded {
+		t.Errorf("Expected the previous nil got %v (%v)", err, loaded)
+	}
+	n := 0
+	m.Range(func(key string, err error) bool {
+		if err != nil {
+			t.Errorf("Expected nil for %s got %v", key, err)
+		}
+		n++
+		return true
+	})
+	if n != 2 {
+		t.Errorf("Expected 2 entries got %d", n)
+	}
+	if err, loaded := m.LoadAndDelete("b"); err != nil || !loaded {
+		t.Errorf("Expected to delete nil got %v (%v)", err, loaded)
+	}
+	var keys syncmap.Map[interface{}, int]
+	keys.Store(nil, 1)
+	keys.Range(func(key interface{}, val int) bool {
+		if key != nil || val != 1 {
+			t.Errorf("Expected a nil key got %v", key)
+		}
+		return true
+	})
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	var m syncmap.Map[string, string]
+	m.Store("k", "x")
+	if syncmap.CompareAndSwap(&m, "k", "y", "z") {
+		t.Error("Expected a mismatched swap to fail")
+	}
+	if !syncmap.CompareAndSwap(&m, "k", "x", "z") {
+		t.Error("Expected a matching swap to succeed")
+	}
+	if syncmap.CompareAndDelete(&m, "k", "x") {
+		t.Error("Expected a mismatched delete to fail")
+	}
+	if !syncmap.CompareAndDelete(&m, "k", "z") {
+		t.Error("Expected a matching delete to succeed")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	var m syncmap.Map[int, int]
+	for i := 0; i < 5; i++ {
+		m.Store(i, i*i)
+	}
+	snapshot := m.Snapshot()
+	if len(snapshot) != 5 || snapshot[3] != 9 || m.Len() != 5 {
+		t.Errorf("Expected 5 squares got %v", snapshot)
+	}
+}
@@ -0,0 +1,311 @@
+// Package tdigest is a package that implements the merging t-digest quantile estimator.
+//
+// A digest summarizes a stream as a bounded number of weighted centroids. Centroids near the
+// extremes are kept small, so tail quantiles such as p99 and p999 stay accurate while the digest
+// uses memory proportional to its compression rather than the stream length.
+package tdigest
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultCompression is the compression used when New is given a non-positive one.
+	DefaultCompression = 100
+
+	formatVersion = 1
+	headerSize    = 1 + 8*4
+	centroidSize  = 16
+	// maxCompression bounds the compression accepted when decoding, since compress allocates for it.
+	maxCompression = 1 << 16
+)
+
+// ErrInvalidData is returned when decoding malformed binary data.
+var ErrInvalidData = errors.New("tdigest: invalid data")
+
+// Centroid is a cluster of values summarized by their mean.
+type Centroid struct {
+	Mean   float64
+	Weight float64
+}
+
+// Digest estimates quantiles of the values added to it. It is safe for concurrent use.
+type Digest struct {
+	compression float64
+	centroids   []Centroid
+	buffer      []Centroid
+	count       float64
+	min         float64
+	max         float64
+	mutex       *sync.Mutex
+}
+
+// New creates an empty digest. Higher compression keeps more centroids, trading memory for
+// accuracy; the digest holds roughly compression centroids once compressed.
+func New(compression float64) *Digest {
+	if compression <= 0 {
+		compression = DefaultCompression
+	}
+	return &Digest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+		mutex:       &sync.Mutex{},
+	}
+}
+
+// Add records a value. NaN is ignored.
+func (d *Digest) Add(value float64) {
+	d.AddWeighted(value, 1)
+}
+
+// AddWeighted records a value as if it had been added weight times. NaN and non-positive weights
+// are ignored.
+func (d *Digest) AddWeighted(value, weight float64) {
+	if math.IsNaN(value) || !(weight > 0) {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.add(Centroid{Mean: value, Weight: weight})
+}
+
+// Merge adds every value summarized by other to the digest.
+func (d *Digest) Merge(other *Digest) {
+	if other == d {
+		return
+	}
+	other.mutex.Lock()
+	other.compress()
+	centroids := append([]Centroid(nil), other.centroids...)
+	min, max := other.min, other.max
+	other.mutex.Unlock()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, c := range centroids {
+		d.add(c)
+	}
+	d.min = math.Min(d.min, min)
+	d.max = math.Max(d.max, max)
+}
+
+// Count returns the total weight of the values added.
+func (d *Digest) Count() float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.count
+}
+
+// Min returns the smallest value added, or +Inf if the digest is empty.
+func (d *Digest) Min() float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.min
+}
+
+// Max returns the largest value added, or -Inf if the digest is empty.
+func (d *Digest) Max() float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.max
+}
+
+// Centroids returns a copy of the digest's centroids in order of their means.
+func (d *Digest) Centroids() []Centroid {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.compress()
+	return append([]Centroid(nil), d.centroids...)
+}
+
+// Quantile estimates the value below which the fraction q of the added values fall.
+// It returns NaN if the digest is empty.
+func (d *Digest) Quantile(q float64) float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.compress()
+	if len(d.centroids) == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return d.min
+	}
+	if q >= 1 {
+		return d.max
+	}
+	if len(d.centroids) == 1 {
+		return d.centroids[0].Mean
+	}
+	// Each centroid's mean is taken to sit at the middle of its weight, with min and max at the
+	// ends; quantiles between those points are interpolated linearly.
+	target := q * d.count
+	first := d.centroids[0]
+	if target < first.Weight/2 {
+		return d.min + (first.Mean-d.min)*target/(first.Weight/2)
+	}
+	cumulative := first.Weight / 2
+	for i := 1; i < len(d.centroids); i++ {
+		prev, next := d.centroids[i-1], d.centroids[i]
+		step := (prev.Weight + next.Weight) / 2
+		if target < cumulative+step {
+			return prev.Mean + (next.Mean-prev.Mean)*(target-cumulative)/step
+		}
+		cumulative += step
+	}
+	last := d.centroids[len(d.centroids)-1]
+	return last.Mean + (d.max-last.Mean)*math.Min(1, (target-cumulative)/(last.Weight/2))
+}
+
+// CDF estimates the fraction of the added values that are at most x.
+// It returns NaN if the digest is empty.
+func (d *Digest) CDF(x float64) float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.compress()
+	if len(d.centroids) == 0 {
+		return math.NaN()
+	}
+	if x < d.min {
+		return 0
+	}
+	if x >= d.max {
+		return 1
+	}
+	if len(d.centroids) == 1 {
+		return (x - d.min) / (d.max - d.min)
+	}
+	first := d.centroids[0]
+	if x < first.Mean {
+		return (first.Weight / 2) * (x - d.min) / (first.Mean - d.min) / d.count
+	}
+	cumulative := first.Weight / 2
+	for i := 1; i < len(d.centroids); i++ {
+		prev, next := d.centroids[i-1], d.centroids[i]
+		step := (prev.Weight + next.Weight) / 2
+		if x < next.Mean {
+			return (cumulative + step*(x-prev.Mean)/(next.Mean-prev.Mean)) / d.count
+		}
+		cumulative += step
+	}
+	last := d.centroids[len(d.centroids)-1]
+	return (cumulative + (last.Weight/2)*(x-last.Mean)/(d.max-last.Mean)) / d.count
+}
+
+// MarshalBinary encodes the digest's compression, bounds and centroids.
+func (d *Digest) MarshalBinary() ([]byte, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.compress()
+	data := make([]byte, 0, headerSize+centroidSize*len(d.centroids))
+	data = append(data, formatVersion)
+	data = binary.BigEndian.AppendUint64(data, math.Float64bits(d.compression))
+	data = binary.BigEndian.AppendUint64(data, math.Float64bits(d.min))
+	data = binary.BigEndian.AppendUint64(data, math.Float64bits(d.max))
+	data = binary.BigEndian.AppendUint64(data, uint64(len(d.centroids)))
+	for _, c := range d.centroids {
+		data = binary.BigEndian.AppendUint64(data, math.Float64bits(c.Mean))
+		data = binary.BigEndian.AppendUint64(data, math.Float64bits(c.Weight))
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a digest encoded with MarshalBinary.
+func (d *Digest) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || data[0] != formatVersion {
+		return ErrInvalidData
+	}
+	float := func(offset int) float64 {
+		return math.Float64frombits(binary.BigEndian.Uint64(data[offset:]))
+	}
+	compression, min, max := float(1), float(9), float(17)
+	n := binary.BigEndian.Uint64(data[25:])
+	if !(compression > 0 && compression <= maxCompression) || n != uint64(len(data)-headerSize)/centroidSize ||
+		(len(data)-headerSize)%centroidSize != 0 {
+		return ErrInvalidData
+	}
+	if n > 0 && (!finite(min) || !finite(max) || min > max) {
+		return ErrInvalidData
+	}
+	centroids := make([]Centroid, n)
+	var count float64
+	for i := range centroids {
+		offset := headerSize + i*centroidSize
+		c := Centroid{Mean: float(offset), Weight: float(offset + 8)}
+		if !finite(c.Mean) || !finite(c.Weight) || !(c.Weight > 0) || (i > 0 && c.Mean < centroids[i-1].Mean) {
+			return ErrInvalidData
+		}
+		centroids[i] = c
+		count += c.Weight
+	}
+	if n == 0 {
+		min, max = math.Inf(1), math.Inf(-1)
+	}
+	if d.mutex == nil {
+		d.mutex = &sync.Mutex{}
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.compression, d.min, d.max = compression, min, max
+	d.centroids, d.count = centroids, count
+	d.buffer = d.buffer[:0]
+	return nil
+}
+
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// add buffers a centroid, compressing once the buffer is full. It must be called with the lock held.
+func (d *Digest) add(c Centroid) {
+	d.buffer = append(d.buffer, c)
+	d.count += c.Weight
+	d.min = math.Min(d.min, c.Mean)
+	d.max = math.Max(d.max, c.Mean)
+	if len(d.buffer) >= int(5*d.compression) {
+		d.compress()
+	}
+}
+
+// compress merges the buffer into the centroids, combining neighbours while the combined centroid
+// stays within the size the scale function allows at its quantile. It must be called with the lock held.
+func (d *Digest) compress() {
+	if len(d.buffer) == 0 {
+		return
+	}
+	all := append(d.buffer, d.centroids...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Mean < all[j].Mean })
+	merged := make([]Centroid, 0, int(d.compression))
+	current := all[0]
+	var before float64
+	limit := d.count * d.quantileLimit(0)
+	for _, next := range all[1:] {
+		if before+current.Weight+next.Weight <= limit {
+			weight := current.Weight + next.Weight
+			current.Mean += (next.Mean - current.Mean) * next.Weight / weight
+			current.Weight = weight
+			continue
+		}
+		before += current.Weight
+		merged = append(merged, current)
+		limit = d.count * d.quantileLimit(before/d.count)
+		current = next
+	}
+	d.centroids = append(merged, current)
+	d.buffer = d.buffer[:0]
+}
+
+// quantileLimit returns the quantile a centroid starting at q may extend to, using the
+// k(q) = compression/2π · asin(2q-1) scale function.
+func (d *Digest) quantileLimit(q float64) float64 {
+	k := d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+	k++
+	if k >= d.compression/4 {
+		return 1
+	}
+	return (math.Sin(k*2*math.Pi/d.compression) + 1) / 2
+}
@@ -0,0 +1,155 @@
+package tdigest_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/tdigest"
+)
+
+func exact(values []float64, q float64) float64 {
+	return values[int(q*float64(len(values)-1))]
+}
+
+func TestQuantile(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	d := tdigest.New(100)
+	values := make([]float64, 100000)
+	for i := range values {
+		values[i] = r.ExpFloat64()
+		d.Add(values[i])
+	}
+	sort.Float64s(values)
+	for _, q := range []float64{0.01, 0.5, 0.9, 0.95, 0.99, 0.999} {
+		// Compare ranks rather than values, since the tail is sparse.
+		got := d.Quantile(q)
+		rank := float64(sort.SearchFloat64s(values, got)) / float64(len(values))
+		if math.Abs(rank-q) > 0.01*math.Min(q, 1-q)+0.001 {
+			t.Errorf("Expected quantile %v near %v got %v (rank %v)", q, exact(values, q), got, rank)
+		}
+	}
+	if d.Quantile(0) != values[0] || d.Quantile(1) != values[len(values)-1] {
+		t.Error("Expected the extreme quantiles to be the min and max")
+	}
+	if n := len(d.Centroids()); n > 200 {
+		t.Errorf("Expected the digest to stay small got %d centroids", n)
+	}
+	if d.Count() != float64(len(values)) {
+		t.Errorf("Expected a count of %d got %v", len(values), d.Count())
+	}
+}
+
+func TestCDF(t *testing.T) {
+	d := tdigest.New(100)
+	for i := 0; i < 10000; i++ {
+		d.Add(float64(i))
+	}
+	for _, x := range []float64{100, 5000, 9900} {
+		if got := d.CDF(x); math.Abs(got-x/10000) > 0.005 {
+			t.Errorf("Expected CDF(%v) near %v got %v", x, x/10000, got)
+		}
+	}
+	if d.CDF(-1) != 0 || d.CDF(20000) != 1 {
+		t.Error("Expected the CDF to be 0 below the min and 1 above the max")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	d := tdigest.New(0)
+	if !math.IsNaN(d.Quantile(0.5)) || !math.IsNaN(d.CDF(0)) {
+		t.Error("Expected NaN from an empty digest")
+	}
+	d.Add(math.NaN())
+	d.AddWeighted(1, 0)
+	if d.Count() != 0 {
+		t.Errorf("Expected invalid values to be ignored got count %v", d.Count())
+	}
+	d.Add(42)
+	if d.Quantile(0.5) != 42 {
+		t.Errorf("Expected 42 got %v", d.Quantile(0.5))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	whole := tdigest.New(100)
+	shards := []*tdigest.Digest{tdigest.New(100), tdigest.New(100), tdigest.New(100)}
+	for i := 0; i < 30000; i++ {
+		whole.Add(float64(i))
+		shards[i%3].Add(float64(i))
+	}
+	merged := tdigest.New(100)
+	for _, shard := range shards {
+		merged.Merge(shard)
+	}
+	if merged.Count() != whole.Count() || merged.Min() != 0 || merged.Max() != 29999 {
+		t.Errorf("Expected the merge to cover every value got count %v between %v and %v", merged.Count(), merged.Min(), merged.Max())
+	}
+	for _, q := range []float64{0.5, 0.99} {
+		if math.Abs(merged.Quantile(q)-whole.Quantile(q)) > 100 {
+			t.Errorf("Expected quantile %v near %v got %v", q, whole.Quantile(q), merged.Quantile(q))
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	d := tdigest.New(50)
+	for i := 0; i < 1000; i++ {
+		d.Add(float64(i))
+	}
+	data, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := tdigest.New(0)
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Count() != d.Count() || decoded.Quantile(0.9) != d.Quantile(0.9) {
+		t.Errorf("Expected the decoded digest to match got p90 %v and %v", decoded.Quantile(0.9), d.Quantile(0.9))
+	}
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, tdigest.ErrInvalidData) {
+		t.Errorf("Expected ErrInvalidData got %v", err)
+	}
+	for name, patch := range map[string]func([]byte){
+		"huge compression": func(b []byte) { binary.BigEndian.PutUint64(b[1:], math.Float64bits(1e18)) },
+		"infinite mean":    func(b []byte) { binary.BigEndian.PutUint64(b[33:], math.Float64bits(math.Inf(-1))) },
+		"NaN weight":       func(b []byte) { binary.BigEndian.PutUint64(b[41:], math.Float64bits(math.NaN())) },
+	} {
+		bad := append([]byte(nil), data...)
+		patch(bad)
+		if err := decoded.UnmarshalBinary(bad); !errors.Is(err, tdigest.ErrInvalidData) {
+			t.Errorf("Expected ErrInvalidData for a %s got %v", name, err)
+		}
+	}
+}
+
+func TestUnmarshalConcurrent(t *testing.T) {
+	d := tdigest.New(50)
+	d.Add(1)
+	data, _ := d.MarshalBinary()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			d.Add(float64(i))
+			d.Quantile(0.5)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		d.UnmarshalBinary(data)
+	}
+	wg.Wait()
+}
+
+func BenchmarkAdd(b *testing.B) {
+	d := tdigest.New(100)
+	for i := 0; i < b.N; i++ {
+		d.Add(float64(i))
+	}
+}
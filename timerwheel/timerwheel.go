@@ -0,0 +1,153 @@
+// Package timerwheel is a package that implements a hashed timing wheel for large numbers of timers.
+//
+// A wheel keeps timers in a ring of slots that a single ticker sweeps, so adding and stopping a timer
+// are constant time and no runtime timer is created per item. In exchange timers only fire on tick
+// boundaries: a timer fires between its deadline and one tick after it.
+package timerwheel
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Timer is a pending call scheduled on a wheel.
+type Timer struct {
+	wheel   *Wheel
+	fn      func()
+	rounds  int
+	slot    int
+	element *list.Element
+}
+
+// Stop prevents the timer from firing, reporting whether it was still pending.
+func (t *Timer) Stop() bool {
+	w := t.wheel
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if t.element == nil {
+		return false
+	}
+	w.slots[t.slot].Remove(t.element)
+	t.element = nil
+	w.count--
+	return true
+}
+
+// Wheel schedules timers with a fixed tick resolution. It is safe for concurrent use.
+type Wheel struct {
+	tick   time.Duration
+	slots  []*list.List
+	cursor int
+	last   time.Time
+	count  int
+	mutex  *sync.Mutex
+	stop   chan struct{}
+	once   *sync.Once
+}
+
+// New creates a wheel with the given tick and number of slots and starts it. Timers further out than
+// tick*slots wrap around the wheel, so size the wheel to cover most delays in use. It panics if tick
+// is not positive.
+func New(tick time.Duration, slots int) *Wheel {
+	if tick <= 0 {
+		panic("timerwheel: tick must be positive")
+	}
+	if slots < 1 {
+		slots = 1
+	}
+	w := &Wheel{
+		tick:  tick,
+		slots: make([]*list.List, slots),
+		mutex: &sync.Mutex{},
+		stop:  make(chan struct{}),
+		once:  &sync.Once{},
+		last:  time.Now(),
+	}
+	for i := range w.slots {
+		w.slots[i] = list.New()
+	}
+	go w.run(time.NewTicker(tick))
+	return w
+}
+
+// AfterFunc calls fn in its own goroutine once delay has passed.
+func (w *Wheel) AfterFunc(delay time.Duration, fn func()) *Timer {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	// Ticks are counted from the last tick rather than from now, so the part of the current tick
+	// that has already passed does not count towards the delay.
+	elapsed := time.Since(w.last)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	ticks := int((delay + elapsed + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+	t := &Timer{
+		wheel:  w,
+		fn:     fn,
+		rounds: (ticks - 1) / len(w.slots),
+		slot:   (w.cursor + ticks) % len(w.slots),
+	}
+	t.element = w.slots[t.slot].PushBack(t)
+	w.count++
+	return t
+}
+
+// Schedule calls fn in its own goroutine at the given time.
+func (w *Wheel) Schedule(at time.Time, fn func()) *Timer {
+	return w.AfterFunc(time.Until(at), fn)
+}
+
+// Len returns the number of pending timers.
+func (w *Wheel) Len() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.count
+}
+
+// Stop halts the wheel. Pending timers never fire.
+func (w *Wheel) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+}
+
+func (w *Wheel) run(ticker *time.Ticker) {
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			w.advance(now)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *Wheel) advance(now time.Time) {
+	w.mutex.Lock()
+	w.cursor = (w.cursor + 1) % len(w.slots)
+	w.last = now
+	slot := w.slots[w.cursor]
+	var due []func()
+	for e := slot.Front(); e != nil; {
+		next := e.Next()
+		t := e.Value.(*Timer)
+		if t.rounds > 0 {
+			t.rounds--
+		} else {
+			slot.Remove(e)
+			t.element = nil
+			w.count--
+			due = append(due, t.fn)
+		}
+		e = next
+	}
+	w.mutex.Unlock()
+	for _, fn := range due {
+		go fn()
+	}
+}
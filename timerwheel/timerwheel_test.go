@@ -0,0 +1,98 @@
+package timerwheel_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/timerwheel"
+)
+
+func TestAfterFunc(t *testing.T) {
+	w := timerwheel.New(time.Millisecond, 16)
+	defer w.Stop()
+	fired := make(chan time.Time, 1)
+	start := time.Now()
+	w.AfterFunc(10*time.Millisecond, func() { fired <- time.Now() })
+	select {
+	case at := <-fired:
+		if at.Sub(start) < 10*time.Millisecond {
+			t.Errorf("Expected the timer to fire after 10ms got %v", at.Sub(start))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the timer to fire")
+	}
+}
+
+func TestNeverEarly(t *testing.T) {
+	w := timerwheel.New(5*time.Millisecond, 16)
+	defer w.Stop()
+	for i := 0; i < 10; i++ {
+		// Scheduling part way through a tick must not count the part already passed.
+		time.Sleep(3 * time.Millisecond)
+		fired := make(chan time.Time, 1)
+		start := time.Now()
+		w.AfterFunc(5*time.Millisecond, func() { fired <- time.Now() })
+		if elapsed := (<-fired).Sub(start); elapsed < 5*time.Millisecond {
+			t.Fatalf("Expected the timer not to fire before 5ms got %v", elapsed)
+		}
+	}
+}
+
+func TestInvalidTick(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a non-positive tick to panic")
+		}
+	}()
+	timerwheel.New(0, 8)
+}
+
+func TestWrapsAround(t *testing.T) {
+	w := timerwheel.New(time.Millisecond, 4)
+	defer w.Stop()
+	fired := make(chan time.Time, 1)
+	start := time.Now()
+	w.AfterFunc(15*time.Millisecond, func() { fired <- time.Now() })
+	select {
+	case at := <-fired:
+		if at.Sub(start) < 15*time.Millisecond {
+			t.Errorf("Expected rounds to delay the timer past 15ms got %v", at.Sub(start))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the timer to fire")
+	}
+}
+
+func TestStopTimer(t *testing.T) {
+	w := timerwheel.New(time.Millisecond, 8)
+	defer w.Stop()
+	var fired atomic.Bool
+	timer := w.AfterFunc(5*time.Millisecond, func() { fired.Store(true) })
+	if !timer.Stop() {
+		t.Error("Expected a pending timer to stop")
+	}
+	if timer.Stop() {
+		t.Error("Expected a second stop to report false")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if fired.Load() || w.Len() != 0 {
+		t.Error("Expected a stopped timer not to fire")
+	}
+}
+
+func TestManyTimers(t *testing.T) {
+	w := timerwheel.New(time.Millisecond, 64)
+	defer w.Stop()
+	var count atomic.Int32
+	for i := 0; i < 10000; i++ {
+		w.Schedule(time.Now().Add(time.Duration(i%20)*time.Millisecond), func() { count.Add(1) })
+	}
+	deadline := time.Now().Add(time.Second)
+	for count.Load() < 10000 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if count.Load() != 10000 {
+		t.Errorf("Expected every timer to fire got %d", count.Load())
+	}
+}
@@ -0,0 +1,300 @@
+// Package treemap is a package that implements a sorted map backed by a left-leaning red-black tree.
+package treemap
+
+import (
+	"cmp"
+)
+
+type node[K cmp.Ordered, V any] struct {
+	key   K
+	value V
+	left  *node[K, V]
+	right *node[K, V]
+	red   bool
+}
+
+// Map is a key-value store that keeps keys in ascending order.
+// Lookups, insertions and deletions are O(log n). It is not safe for concurrent use.
+// Keys are ordered by cmp.Compare, so floating-point NaN keys are equal to each other and sort
+// before every other key.
+type Map[K cmp.Ordered, V any] struct {
+	root *node[K, V]
+	size int
+}
+
+// New creates an empty tree map.
+func New[K cmp.Ordered, V any]() *Map[K, V] {
+	return &Map[K, V]{}
+}
+
+// Set a key/value into the map.
+func (m *Map[K, V]) Set(key K, value V) {
+	m.root = m.insert(m.root, key, value)
+	m.root.red = false
+}
+
+// Get will retrieve a value by key.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	for n := m.root; n != nil; {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete an entry from the map, reporting whether it existed.
+func (m *Map[K, V]) Delete(key K) bool {
+	if _, ok := m.Get(key); !ok {
+		return false
+	}
+	if !isRed(m.root.left) && !isRed(m.root.right) {
+		m.root.red = true
+	}
+	m.root = remove(m.root, key)
+	if m.root != nil {
+		m.root.red = false
+	}
+	m.size--
+	return true
+}
+
+// Len returns the number of entries in the map.
+func (m *Map[K, V]) Len() int {
+	return m.size
+}
+
+// Min returns the entry with the smallest key.
+func (m *Map[K, V]) Min() (K, V, bool) {
+	if m.root == nil {
+		return zeroEntry[K, V]()
+	}
+	n := minNode(m.root)
+	return n.key, n.value, true
+}
+
+// Max returns the entry with the largest key.
+func (m *Map[K, V]) Max() (K, V, bool) {
+	if m.root == nil {
+		return zeroEntry[K, V]()
+	}
+	n := m.root
+	for n.right != nil {
+		n = n.right
+	}
+	return n.key, n.value, true
+}
+
+// Floor returns the entry with the largest key less than or equal to key.
+func (m *Map[K, V]) Floor(key K) (K, V, bool) {
+	var found *node[K, V]
+	for n := m.root; n != nil; {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			found = n
+			n = n.right
+		default:
+			return n.key, n.value, true
+		}
+	}
+	if found == nil {
+		return zeroEntry[K, V]()
+	}
+	return found.key, found.value, true
+}
+
+// Ceiling returns the entry with the smallest key greater than or equal to key.
+func (m *Map[K, V]) Ceiling(key K) (K, V, bool) {
+	var found *node[K, V]
+	for n := m.root; n != nil; {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			found = n
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.key, n.value, true
+		}
+	}
+	if found == nil {
+		return zeroEntry[K, V]()
+	}
+	return found.key, found.value, true
+}
+
+// Range calls fn for every entry with a key in [from, to) in ascending order.
+// Iteration stops early if fn returns false.
+func (m *Map[K, V]) Range(from, to K, fn func(key K, value V) bool) {
+	walk(m.root, &from, &to, fn)
+}
+
+// Each calls fn for every entry in ascending key order.
+// Iteration stops early if fn returns false.
+func (m *Map[K, V]) Each(fn func(key K, value V) bool) {
+	walk(m.root, nil, nil, fn)
+}
+
+// Keys returns all keys in ascending order.
+func (m *Map[K, V]) Keys() []K {
+	ret := make([]K, 0, m.size)
+	m.Each(func(key K, value V) bool {
+		ret = append(ret, key)
+		return true
+	})
+	return ret
+}
+
+func (m *Map[K, V]) insert(h *node[K, V], key K, value V) *node[K, V] {
+	if h == nil {
+		m.size++
+		return &node[K, V]{key: key, value: value, red: true}
+	}
+	switch c := cmp.Compare(key, h.key); {
+	case c < 0:
+		h.left = m.insert(h.left, key, value)
+	case c > 0:
+		h.right = m.insert(h.right, key, value)
+	default:
+		h.value = value
+	}
+	return fixUp(h)
+}
+
+// walk visits nodes in order, bounded below by from (inclusive) and above by to (exclusive) when set.
+func walk[K cmp.Ordered, V any](n *node[K, V], from, to *K, fn func(key K, value V) bool) bool {
+	if n == nil {
+		return true
+	}
+	aboveFrom := from == nil || cmp.Compare(n.key, *from) >= 0
+	belowTo := to == nil || cmp.Less(n.key, *to)
+	if aboveFrom && !walk(n.left, from, to, fn) {
+		return false
+	}
+	if aboveFrom && belowTo && !fn(n.key, n.value) {
+		return false
+	}
+	if belowTo {
+		return walk(n.right, from, to, fn)
+	}
+	return true
+}
+
+func remove[K cmp.Ordered, V any](h *node[K, V], key K) *node[K, V] {
+	if cmp.Less(key, h.key) {
+		if !isRed(h.left) && !isRed(h.left.left) {
+			h = moveRedLeft(h)
+		}
+		h.left = remove(h.left, key)
+	} else {
+		if isRed(h.left) {
+			h = rotateRight(h)
+		}
+		if cmp.Compare(key, h.key) == 0 && h.right == nil {
+			return nil
+		}
+		if !isRed(h.right) && !isRed(h.right.left) {
+			h = moveRedRight(h)
+		}
+		if cmp.Compare(key, h.key) == 0 {
+			successor := minNode(h.right)
+			h.key, h.value = successor.key, successor.value
+			h.right = removeMin(h.right)
+		} else {
+			h.right = remove(h.right, key)
+		}
+	}
+	return fixUp(h)
+}
+
+func removeMin[K cmp.Ordered, V any](h *node[K, V]) *node[K, V] {
+	if h.left == nil {
+		return nil
+	}
+	if !isRed(h.left) && !isRed(h.left.left) {
+		h = moveRedLeft(h)
+	}
+	h.left = removeMin(h.left)
+	return fixUp(h)
+}
+
+func minNode[K cmp.Ordered, V any](h *node[K, V]) *node[K, V] {
+	for h.left != nil {
+		h = h.left
+	}
+	return h
+}
+
+func isRed[K cmp.Ordered, V any](n *node[K, V]) bool {
+	return n != nil && n.red
+}
+
+func rotateLeft[K cmp.Ordered, V any](h *node[K, V]) *node[K, V] {
+	x := h.right
+	h.right = x.left
+	x.left = h
+	x.red = h.red
+	h.red = true
+	return x
+}
+
+func rotateRight[K cmp.Ordered, V any](h *node[K, V]) *node[K, V] {
+	x := h.left
+	h.left = x.right
+	x.right = h
+	x.red = h.red
+	h.red = true
+	return x
+}
+
+func flipColors[K cmp.Ordered, V any](h *node[K, V]) {
+	h.red = !h.red
+	h.left.red = !h.left.red
+	h.right.red = !h.right.red
+}
+
+func moveRedLeft[K cmp.Ordered, V any](h *node[K, V]) *node[K, V] {
+	flipColors(h)
+	if isRed(h.right.left) {
+		h.right = rotateRight(h.right)
+		h = rotateLeft(h)
+		flipColors(h)
+	}
+	return h
+}
+
+func moveRedRight[K cmp.Ordered, V any](h *node[K, V]) *node[K, V] {
+	flipColors(h)
+	if isRed(h.left.left) {
+		h = rotateRight(h)
+		flipColors(h)
+	}
+	return h
+}
+
+func fixUp[K cmp.Ordered, V any](h *node[K, V]) *node[K, V] {
+	if isRed(h.right) && !isRed(h.left) {
+		h = rotateLeft(h)
+	}
+	if isRed(h.left) && isRed(h.left.left) {
+		h = rotateRight(h)
+	}
+	if isRed(h.left) && isRed(h.right) {
+		flipColors(h)
+	}
+	return h
+}
+
+func zeroEntry[K cmp.Ordered, V any]() (K, V, bool) {
+	var key K
+	var value V
+	return key, value, false
+}
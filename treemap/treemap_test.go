@@ -0,0 +1,123 @@
+package treemap_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cjsaylor/goutil/treemap"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	m := treemap.New[int, string]()
+	m.Set(2, "b")
+	m.Set(1, "a")
+	m.Set(2, "c")
+	if val, ok := m.Get(2); !ok || val != "c" {
+		t.Errorf("Expected 'c' got %v", val)
+	}
+	if !m.Delete(1) || m.Delete(1) {
+		t.Error("Expected 1 to be deleted exactly once")
+	}
+	if m.Len() != 1 {
+		t.Errorf("Expected 1 entry got %d", m.Len())
+	}
+}
+
+func TestNaNKeys(t *testing.T) {
+	m := treemap.New[float64, string]()
+	for _, key := range []float64{2, 1, 3} {
+		m.Set(key, "number")
+	}
+	m.Set(math.NaN(), "first")
+	m.Set(math.NaN(), "nan")
+	if m.Len() != 4 {
+		t.Errorf("Expected NaN keys to be equal to each other got %d entries", m.Len())
+	}
+	if key, val, _ := m.Min(); !math.IsNaN(key) || val != "nan" {
+		t.Errorf("Expected NaN to sort first got %v", key)
+	}
+	if !m.Delete(math.NaN()) || m.Len() != 3 {
+		t.Error("Expected the NaN key to be deleted")
+	}
+	if keys := m.Keys(); !reflect.DeepEqual(keys, []float64{1, 2, 3}) {
+		t.Errorf("Expected the remaining keys in order got %v", keys)
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	m := treemap.New[int, int]()
+	expected := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		key := r.Intn(500)
+		if r.Intn(3) == 0 {
+			_, existed := expected[key]
+			if m.Delete(key) != existed {
+				t.Fatalf("Unexpected delete result for %d", key)
+			}
+			delete(expected, key)
+		} else {
+			m.Set(key, i)
+			expected[key] = i
+		}
+	}
+	keys := make([]int, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	if !reflect.DeepEqual(keys, m.Keys()) {
+		t.Error("Expected keys to match in sorted order")
+	}
+	for key, value := range expected {
+		if val, ok := m.Get(key); !ok || val != value {
+			t.Errorf("Expected %d for key %d got %d", value, key, val)
+		}
+	}
+}
+
+func TestFloorCeiling(t *testing.T) {
+	m := treemap.New[int, string]()
+	for _, key := range []int{10, 20, 30} {
+		m.Set(key, "")
+	}
+	if key, _, ok := m.Floor(25); !ok || key != 20 {
+		t.Errorf("Expected floor of 25 to be 20 got %d", key)
+	}
+	if key, _, ok := m.Floor(20); !ok || key != 20 {
+		t.Errorf("Expected floor of 20 to be 20 got %d", key)
+	}
+	if _, _, ok := m.Floor(5); ok {
+		t.Error("Expected no floor for 5")
+	}
+	if key, _, ok := m.Ceiling(25); !ok || key != 30 {
+		t.Errorf("Expected ceiling of 25 to be 30 got %d", key)
+	}
+	if _, _, ok := m.Ceiling(35); ok {
+		t.Error("Expected no ceiling for 35")
+	}
+	if key, _, _ := m.Min(); key != 10 {
+		t.Errorf("Expected min 10 got %d", key)
+	}
+	if key, _, _ := m.Max(); key != 30 {
+		t.Errorf("Expected max 30 got %d", key)
+	}
+}
+
+func TestRange(t *testing.T) {
+	m := treemap.New[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	var result []int
+	m.Range(3, 7, func(key, value int) bool {
+		result = append(result, key)
+		return true
+	})
+	if !reflect.DeepEqual(result, []int{3, 4, 5, 6}) {
+		t.Errorf("Expected [3 4 5 6] got %v", result)
+	}
+}
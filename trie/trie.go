@@ -0,0 +1,125 @@
+// Package trie is a package that implements a prefix tree keyed by strings.
+package trie
+
+import (
+	"sort"
+)
+
+type node[V any] struct {
+	children map[byte]*node[V]
+	value    V
+	terminal bool
+}
+
+// Trie is a key-value store that supports efficient prefix queries. It is not safe for concurrent use.
+type Trie[V any] struct {
+	root *node[V]
+	size int
+}
+
+// New creates an empty trie.
+func New[V any]() *Trie[V] {
+	return &Trie[V]{root: &node[V]{}}
+}
+
+// Insert a key/value into the trie, replacing any existing value.
+func (t *Trie[V]) Insert(key string, value V) {
+	n := t.root
+	for i := 0; i < len(key); i++ {
+		if n.children == nil {
+			n.children = make(map[byte]*node[V])
+		}
+		child, ok := n.children[key[i]]
+		if !ok {
+			child = &node[V]{}
+			n.children[key[i]] = child
+		}
+		n = child
+	}
+	if !n.terminal {
+		t.size++
+	}
+	n.value = value
+	n.terminal = true
+}
+
+// Get will retrieve a value by key.
+func (t *Trie[V]) Get(key string) (V, bool) {
+	if n := t.find(key); n != nil && n.terminal {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete an entry from the trie, reporting whether it existed.
+// Nodes that no longer lead to any key are pruned.
+func (t *Trie[V]) Delete(key string) bool {
+	path := make([]*node[V], 0, len(key)+1)
+	n := t.root
+	path = append(path, n)
+	for i := 0; i < len(key); i++ {
+		if n = n.children[key[i]]; n == nil {
+			return false
+		}
+		path = append(path, n)
+	}
+	if !n.terminal {
+		return false
+	}
+	var zero V
+	n.value = zero
+	n.terminal = false
+	t.size--
+	for i := len(key); i > 0; i-- {
+		if path[i].terminal || len(path[i].children) > 0 {
+			break
+		}
+		delete(path[i-1].children, key[i-1])
+	}
+	return true
+}
+
+// HasPrefix reports whether any key in the trie starts with prefix.
+func (t *Trie[V]) HasPrefix(prefix string) bool {
+	n := t.find(prefix)
+	return n != nil && (n.terminal || len(n.children) > 0)
+}
+
+// WalkPrefix calls fn for every key starting with prefix in lexical byte order.
+// Iteration stops early if fn returns false.
+func (t *Trie[V]) WalkPrefix(prefix string, fn func(key string, value V) bool) {
+	if n := t.find(prefix); n != nil {
+		walk(n, []byte(prefix), fn)
+	}
+}
+
+// Len returns the number of keys in the trie.
+func (t *Trie[V]) Len() int {
+	return t.size
+}
+
+func (t *Trie[V]) find(key string) *node[V] {
+	n := t.root
+	for i := 0; i < len(key) && n != nil; i++ {
+		n = n.children[key[i]]
+	}
+	return n
+}
+
+func walk[V any](n *node[V], key []byte, fn func(key string, value V) bool) bool {
+	if n.terminal && !fn(string(key), n.value) {
+		return false
+	}
+	edges := make([]byte, 0, len(n.children))
+	for edge := range n.children {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i] < edges[j] })
+	for _, edge := range edges {
+		if !walk(n.children[edge], append(key, edge), fn) {
+			return false
+		}
+	}
+	return true
+}
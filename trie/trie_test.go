@@ -0,0 +1,57 @@
+package trie_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cjsaylor/goutil/trie"
+)
+
+func TestInsertGet(t *testing.T) {
+	tr := trie.New[int]()
+	tr.Insert("foo", 1)
+	tr.Insert("foobar", 2)
+	tr.Insert("foo", 3)
+	if val, ok := tr.Get("foo"); !ok || val != 3 {
+		t.Errorf("Expected 3 got %v", val)
+	}
+	if _, ok := tr.Get("fo"); ok {
+		t.Error("Expected 'fo' to not be a key")
+	}
+	if tr.Len() != 2 {
+		t.Errorf("Expected 2 keys got %d", tr.Len())
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tr := trie.New[int]()
+	tr.Insert("foo", 1)
+	tr.Insert("foobar", 2)
+	if !tr.Delete("foobar") || tr.Delete("foobar") {
+		t.Error("Expected 'foobar' to be deleted exactly once")
+	}
+	if tr.HasPrefix("foob") {
+		t.Error("Expected 'foob' branch to be pruned")
+	}
+	if _, ok := tr.Get("foo"); !ok {
+		t.Error("Expected 'foo' to remain")
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	tr := trie.New[int]()
+	for i, key := range []string{"team", "tea", "ten", "to", "inn"} {
+		tr.Insert(key, i)
+	}
+	if !tr.HasPrefix("te") || tr.HasPrefix("tx") {
+		t.Error("Expected 'te' to be a prefix and 'tx' to not be")
+	}
+	var keys []string
+	tr.WalkPrefix("te", func(key string, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if !reflect.DeepEqual(keys, []string{"tea", "team", "ten"}) {
+		t.Errorf("Expected [tea team ten] got %v", keys)
+	}
+}
@@ -0,0 +1,184 @@
+// Package tsbuffer is a package that implements an in-memory time-series store.
+//
+// Each series keeps its most recent points in a fixed-size ring buffer and drops points older than
+// the retention period. The number of series is bounded by an LRU cache, so the least recently
+// written or queried series is discarded when a new series is added at the limit.
+package tsbuffer
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/lru"
+	"github.com/cjsaylor/goutil/ringbuffer"
+)
+
+// Point is a single timestamped value.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Aggregator combines the values of a downsampling bucket into one value.
+type Aggregator func(values []float64) float64
+
+// Options configures a store. Zero fields take the defaults noted on each.
+type Options struct {
+	// Clock decides when points fall out of the retention period. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Store holds time series by name. It is safe for concurrent use.
+// Points are expected to be added to a series in time order.
+type Store struct {
+	series          *lru.Cache
+	pointsPerSeries int
+	retention       time.Duration
+	clock           clock.Clock
+	mutex           *sync.Mutex
+}
+
+// New creates a store of at most maxSeries series, each holding at most pointsPerSeries points
+// no older than retention. A retention of zero keeps points until they are overwritten.
+func New(maxSeries, pointsPerSeries int, retention time.Duration, options Options) *Store {
+	return &Store{
+		series:          lru.NewCache(maxSeries, lru.Noop()),
+		pointsPerSeries: pointsPerSeries,
+		retention:       retention,
+		clock:           clock.OrReal(options.Clock),
+		mutex:           &sync.Mutex{},
+	}
+}
+
+// Add a point to a series, creating the series if necessary.
+func (s *Store) Add(name string, t time.Time, value float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	buffer, ok := s.buffer(name)
+	if !ok {
+		buffer = ringbuffer.New[Point](s.pointsPerSeries, ringbuffer.Overwrite)
+		s.series.Set(name, buffer)
+	}
+	buffer.Push(Point{Time: t, Value: value})
+	s.expire(buffer)
+}
+
+// Range returns the points of a series with times in [from, to), oldest first.
+func (s *Store) Range(name string, from, to time.Time) []Point {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	buffer, ok := s.buffer(name)
+	if !ok {
+		return nil
+	}
+	s.expire(buffer)
+	var ret []Point
+	buffer.Each(func(p Point) bool {
+		if !p.Time.Before(from) && p.Time.Before(to) {
+			ret = append(ret, p)
+		}
+		return true
+	})
+	return ret
+}
+
+// Downsample groups the points of a series in [from, to) into buckets of width step,
+// returning one aggregated point per non-empty bucket stamped with the bucket's start time.
+// It returns nil if step is not positive.
+func (s *Store) Downsample(name string, from, to time.Time, step time.Duration, aggregate Aggregator) []Point {
+	if step <= 0 {
+		return nil
+	}
+	var ret []Point
+	var values []float64
+	var bucket time.Time
+	for _, p := range s.Range(name, from, to) {
+		start := from.Add(p.Time.Sub(from) / step * step)
+		if len(values) > 0 && !start.Equal(bucket) {
+			ret = append(ret, Point{Time: bucket, Value: aggregate(values)})
+			values = values[:0]
+		}
+		bucket = start
+		values = append(values, p.Value)
+	}
+	if len(values) > 0 {
+		ret = append(ret, Point{Time: bucket, Value: aggregate(values)})
+	}
+	return ret
+}
+
+// Remove a series.
+func (s *Store) Remove(name string) {
+	s.series.Remove(name)
+}
+
+// Series returns the names of all series, most recently used first.
+func (s *Store) Series() []string {
+	keys := s.series.ListKeys()
+	ret := make([]string, len(keys))
+	for i, key := range keys {
+		ret[i] = key.(string)
+	}
+	return ret
+}
+
+func (s *Store) buffer(name string) (*ringbuffer.Buffer[Point], bool) {
+	value, ok := s.series.Get(name)
+	if !ok {
+		return nil, false
+	}
+	return value.(*ringbuffer.Buffer[Point]), true
+}
+
+func (s *Store) expire(buffer *ringbuffer.Buffer[Point]) {
+	if s.retention <= 0 {
+		return
+	}
+	cutoff := s.clock.Now().Add(-s.retention)
+	for {
+		oldest, ok := buffer.Peek()
+		if !ok || !oldest.Time.Before(cutoff) {
+			return
+		}
+		buffer.Pop()
+	}
+}
+
+// Mean aggregates values by their arithmetic mean.
+func Mean(values []float64) float64 {
+	return Sum(values) / float64(len(values))
+}
+
+// Sum aggregates values by their total.
+func Sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// Min aggregates values by their smallest value.
+func Min(values []float64) float64 {
+	ret := math.Inf(1)
+	for _, v := range values {
+		ret = math.Min(ret, v)
+	}
+	return ret
+}
+
+// Max aggregates values by their largest value.
+func Max(values []float64) float64 {
+	ret := math.Inf(-1)
+	for _, v := range values {
+		ret = math.Max(ret, v)
+	}
+	return ret
+}
+
+// Last aggregates values by the most recent value.
+func Last(values []float64) float64 {
+	return values[len(values)-1]
+}
@@ -0,0 +1,76 @@
+package tsbuffer_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/tsbuffer"
+)
+
+func TestRange(t *testing.T) {
+	s := tsbuffer.New(10, 3, 0, tsbuffer.Options{})
+	base := time.Unix(1000, 0)
+	for i := 0; i < 5; i++ {
+		s.Add("cpu", base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	points := s.Range("cpu", base, base.Add(time.Hour))
+	if len(points) != 3 || points[0].Value != 2 {
+		t.Errorf("Expected the 3 newest points got %v", points)
+	}
+	points = s.Range("cpu", base.Add(3*time.Second), base.Add(4*time.Second))
+	if len(points) != 1 || points[0].Value != 3 {
+		t.Errorf("Expected a single point at 3s got %v", points)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	s := tsbuffer.New(10, 100, time.Minute, tsbuffer.Options{Clock: fake})
+	now := fake.Now()
+	s.Add("cpu", now.Add(-2*time.Minute), 1)
+	s.Add("cpu", now, 2)
+	points := s.Range("cpu", now.Add(-time.Hour), now.Add(time.Hour))
+	if len(points) != 1 || points[0].Value != 2 {
+		t.Errorf("Expected the expired point to be dropped got %v", points)
+	}
+	fake.Advance(time.Minute + time.Second)
+	if points := s.Range("cpu", now.Add(-time.Hour), now.Add(time.Hour)); len(points) != 0 {
+		t.Errorf("Expected every point to expire with the clock got %v", points)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	s := tsbuffer.New(10, 100, 0, tsbuffer.Options{})
+	base := time.Unix(1000, 0)
+	for i := 0; i < 6; i++ {
+		s.Add("cpu", base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	points := s.Downsample("cpu", base, base.Add(time.Minute), 2*time.Second, tsbuffer.Mean)
+	var values []float64
+	for _, p := range points {
+		values = append(values, p.Value)
+	}
+	if !reflect.DeepEqual(values, []float64{0.5, 2.5, 4.5}) {
+		t.Errorf("Expected [0.5 2.5 4.5] got %v", values)
+	}
+	if !points[1].Time.Equal(base.Add(2 * time.Second)) {
+		t.Errorf("Expected bucket to start at 2s got %v", points[1].Time)
+	}
+	if points := s.Downsample("cpu", base, base.Add(time.Minute), 0, tsbuffer.Mean); points != nil {
+		t.Errorf("Expected no points for a zero step got %v", points)
+	}
+}
+
+func TestSeriesBound(t *testing.T) {
+	s := tsbuffer.New(2, 10, 0, tsbuffer.Options{})
+	now := time.Now()
+	s.Add("a", now, 1)
+	s.Add("b", now, 1)
+	s.Range("a", now, now.Add(time.Second))
+	s.Add("c", now, 1)
+	if series := s.Series(); !reflect.DeepEqual(series, []string{"c", "a"}) {
+		t.Errorf("Expected 'b' to be evicted got %v", series)
+	}
+}
@@ -0,0 +1,93 @@
+package ttlmap
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+type sweeper interface {
+	Sweep() int
+}
+
+// Janitor periodically removes expired entries from every map registered with it, so any number of maps
+// share one goroutine. The goroutine only runs while maps are registered.
+type Janitor struct {
+	interval time.Duration
+	clock    clock.Clock
+	sweepers map[sweeper]struct{}
+	running  bool
+	stopped  bool
+	done     chan struct{}
+	mutex    *sync.Mutex
+}
+
+var shared = NewJanitor(time.Second, nil)
+
+// NewJanitor creates a janitor that sweeps every interval, as measured by c. A nil clock uses the real clock.
+// It panics if interval is not positive.
+func NewJanitor(interval time.Duration, c clock.Clock) *Janitor {
+	if interval <= 0 {
+		panic("ttlmap: interval must be positive")
+	}
+	return &Janitor{
+		interval: interval,
+		clock:    clock.OrReal(c),
+		sweepers: make(map[sweeper]struct{}),
+		done:     make(chan struct{}),
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// Stop ends sweeping for good. Registered maps keep expiring entries lazily when they are read.
+func (j *Janitor) Stop() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if !j.stopped {
+		j.stopped = true
+		close(j.done)
+	}
+}
+
+func (j *Janitor) add(s sweeper) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.sweepers[s] = struct{}{}
+	if !j.running && !j.stopped {
+		j.running = true
+		go j.run()
+	}
+}
+
+func (j *Janitor) remove(s sweeper) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	delete(j.sweepers, s)
+}
+
+func (j *Janitor) run() {
+	ticker := j.clock.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+		case <-j.done:
+			return
+		}
+		j.mutex.Lock()
+		if len(j.sweepers) == 0 {
+			j.running = false
+			j.mutex.Unlock()
+			return
+		}
+		sweepers := make([]sweeper, 0, len(j.sweepers))
+		for s := range j.sweepers {
+			sweepers = append(sweepers, s)
+		}
+		j.mutex.Unlock()
+		for _, s := range sweepers {
+			s.Sweep()
+		}
+	}
+}
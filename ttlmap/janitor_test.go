@@ -0,0 +1,49 @@
+package ttlmap_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/leaktest"
+	"github.com/cjsaylor/goutil/ttlmap"
+	"github.com/cjsaylor/goutil/wait"
+)
+
+func TestJanitorSweepsSharedMaps(t *testing.T) {
+	defer leaktest.Check(t)()
+	fake := clock.NewFake(time.Now())
+	janitor := ttlmap.NewJanitor(time.Second, fake)
+	first := ttlmap.New(ttlmap.Options[string, int]{TTL: time.Second, Janitor: janitor, Clock: fake})
+	second := ttlmap.New(ttlmap.Options[int, string]{TTL: time.Second, Janitor: janitor, Clock: fake})
+	first.Set("a", 1)
+	second.Set(1, "a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := fake.BlockUntil(ctx, 1); err != nil {
+		t.Fatal("Expected one janitor ticker for both maps")
+	}
+	fake.Advance(time.Second)
+	swept := wait.Until(ctx, time.Millisecond, wait.Bool(func() bool {
+		return first.Len() == 0 && second.Len() == 0
+	}))
+	if swept != nil {
+		t.Errorf("Expected the janitor to sweep both maps got %d and %d", first.Len(), second.Len())
+	}
+
+	first.Close()
+	second.Close()
+	fake.Advance(time.Second)
+	// With no maps left the janitor's goroutine exits, which leaktest checks.
+}
+
+func TestJanitorInvalidInterval(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a zero interval to panic")
+		}
+	}()
+	ttlmap.NewJanitor(0, nil)
+}
@@ -0,0 +1,194 @@
+// Package ttlmap is a package that implements a map whose entries expire, for when expiry is wanted without
+// LRU eviction.
+//
+// Expired entries are removed lazily when they are read, and in the background by a Janitor. One janitor can
+// serve many maps; maps without one share a package-wide janitor that sweeps every second.
+package ttlmap
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/pqueue"
+)
+
+// Reason tells an eviction callback why an entry was removed.
+type Reason int
+
+const (
+	// Expired means the entry outlived its TTL.
+	Expired Reason = iota
+	// Evicted means the entry was removed early to make room in a full map.
+	Evicted
+)
+
+// Options configures a map. Zero fields take the defaults noted on each.
+type Options[K comparable, V any] struct {
+	// TTL is how long entries stored with Set live. Defaults to one minute.
+	TTL time.Duration
+	// Capacity bounds the number of entries. When a full map needs room, expired entries are removed first
+	// and then the entry closest to expiring. Zero means unbounded.
+	Capacity int
+	// OnEvict is called outside the map's lock with each expired or evicted entry. It is not called for
+	// entries removed with Delete or replaced with Set.
+	OnEvict func(key K, value V, reason Reason)
+	// Janitor sweeps the map in the background. Defaults to the shared janitor.
+	Janitor *Janitor
+	// Clock decides when entries expire. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+type eviction[K comparable, V any] struct {
+	key    K
+	value  V
+	reason Reason
+}
+
+// Map is a map whose entries expire. It is safe for concurrent use.
+type Map[K comparable, V any] struct {
+	options Options[K, V]
+	items   map[K]V
+	expiry  *pqueue.Indexed[K, time.Time]
+	mutex   *sync.Mutex
+}
+
+// New creates a map and registers it with its janitor. Call Close once the map is no longer needed so the
+// janitor lets go of it.
+func New[K comparable, V any](options Options[K, V]) *Map[K, V] {
+	if options.TTL <= 0 {
+		options.TTL = time.Minute
+	}
+	if options.Janitor == nil {
+		options.Janitor = shared
+	}
+	options.Clock = clock.OrReal(options.Clock)
+	m := &Map[K, V]{
+		options: options,
+		items:   make(map[K]V),
+		expiry:  pqueue.NewIndexed[K](func(a, b time.Time) bool { return a.Before(b) }),
+		mutex:   &sync.Mutex{},
+	}
+	options.Janitor.add(m)
+	return m
+}
+
+// Set stores value for key with the map's TTL.
+func (m *Map[K, V]) Set(key K, value V) {
+	m.SetTTL(key, value, m.options.TTL)
+}
+
+// SetTTL stores value for key, expiring after ttl.
+func (m *Map[K, V]) SetTTL(key K, value V, ttl time.Duration) {
+	m.mutex.Lock()
+	now := m.options.Clock.Now()
+	var evicted []eviction[K, V]
+	if _, ok := m.items[key]; !ok && m.options.Capacity > 0 && len(m.items) >= m.options.Capacity {
+		evicted = m.expire(now)
+		if len(m.items) >= m.options.Capacity {
+			victim, _, _ := m.expiry.Pop()
+			evicted = append(evicted, eviction[K, V]{key: victim, value: m.items[victim], reason: Evicted})
+			delete(m.items, victim)
+		}
+	}
+	m.items[key] = value
+	m.expiry.Push(key, now.Add(ttl))
+	m.mutex.Unlock()
+	m.notify(evicted)
+}
+
+// Get returns the value for key if it has not expired.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	return m.get(key, 0)
+}
+
+// GetAndExtend returns the value for key if it has not expired and pushes its expiry to ttl from now.
+func (m *Map[K, V]) GetAndExtend(key K, ttl time.Duration) (V, bool) {
+	return m.get(key, ttl)
+}
+
+// Expires returns when the entry for key expires.
+func (m *Map[K, V]) Expires(key K) (time.Time, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.expiry.Priority(key)
+}
+
+// Delete removes key and returns its value.
+func (m *Map[K, V]) Delete(key K) (V, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	value, ok := m.items[key]
+	if ok {
+		delete(m.items, key)
+		m.expiry.Remove(key)
+	}
+	return value, ok
+}
+
+// Len returns the number of entries, including expired ones that have not been removed yet.
+func (m *Map[K, V]) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.items)
+}
+
+// Sweep removes the expired entries now and returns how many there were.
+func (m *Map[K, V]) Sweep() int {
+	m.mutex.Lock()
+	evicted := m.expire(m.options.Clock.Now())
+	m.mutex.Unlock()
+	m.notify(evicted)
+	return len(evicted)
+}
+
+// Close unregisters the map from its janitor. The map remains usable, expiring entries only when read.
+func (m *Map[K, V]) Close() {
+	m.options.Janitor.remove(m)
+}
+
+func (m *Map[K, V]) get(key K, ttl time.Duration) (V, bool) {
+	m.mutex.Lock()
+	value, ok := m.items[key]
+	if !ok {
+		m.mutex.Unlock()
+		return value, false
+	}
+	now := m.options.Clock.Now()
+	if expires, _ := m.expiry.Priority(key); !now.Before(expires) {
+		delete(m.items, key)
+		m.expiry.Remove(key)
+		m.mutex.Unlock()
+		m.notify([]eviction[K, V]{{key: key, value: value, reason: Expired}})
+		var zero V
+		return zero, false
+	}
+	if ttl > 0 {
+		m.expiry.Update(key, now.Add(ttl))
+	}
+	m.mutex.Unlock()
+	return value, true
+}
+
+// expire removes the entries that expired by now. It must be called with the lock held.
+func (m *Map[K, V]) expire(now time.Time) []eviction[K, V] {
+	var evicted []eviction[K, V]
+	for {
+		key, expires, ok := m.expiry.Peek()
+		if !ok || now.Before(expires) {
+			return evicted
+		}
+		m.expiry.Pop()
+		evicted = append(evicted, eviction[K, V]{key: key, value: m.items[key], reason: Expired})
+		delete(m.items, key)
+	}
+}
+
+func (m *Map[K, V]) notify(evicted []eviction[K, V]) {
+	if m.options.OnEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		m.options.OnEvict(e.key, e.value, e.reason)
+	}
+}
@@ -0,0 +1,120 @@
+package ttlmap_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/ttlmap"
+)
+
+type recorder struct {
+	mutex   sync.Mutex
+	evicted map[string]ttlmap.Reason
+}
+
+func (r *recorder) onEvict(key string, value int, reason ttlmap.Reason) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.evicted[key] = reason
+}
+
+func (r *recorder) reason(key string) (ttlmap.Reason, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	reason, ok := r.evicted[key]
+	return reason, ok
+}
+
+func newMap(t *testing.T, fake *clock.Fake, r *recorder, capacity int) *ttlmap.Map[string, int] {
+	janitor := ttlmap.NewJanitor(time.Hour, fake)
+	t.Cleanup(janitor.Stop)
+	return ttlmap.New(ttlmap.Options[string, int]{
+		TTL:      time.Minute,
+		Capacity: capacity,
+		OnEvict:  r.onEvict,
+		Janitor:  janitor,
+		Clock:    fake,
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	r := &recorder{evicted: map[string]ttlmap.Reason{}}
+	m := newMap(t, fake, r, 0)
+	defer m.Close()
+	m.Set("a", 1)
+	m.SetTTL("b", 2, time.Hour)
+	if val, ok := m.Get("a"); !ok || val != 1 {
+		t.Errorf("Expected 1 got %v (%v)", val, ok)
+	}
+	fake.Advance(time.Minute)
+	if _, ok := m.Get("a"); ok {
+		t.Error("Expected a to expire after its TTL")
+	}
+	if reason, ok := r.reason("a"); !ok || reason != ttlmap.Expired {
+		t.Error("Expected the expiry callback for a")
+	}
+	if _, ok := m.Get("b"); !ok {
+		t.Error("Expected b to outlive a")
+	}
+	if _, ok := m.Delete("b"); !ok || m.Len() != 0 {
+		t.Error("Expected b to be deleted")
+	}
+	if _, ok := r.reason("b"); ok {
+		t.Error("Expected no callback for a deleted entry")
+	}
+}
+
+func TestGetAndExtend(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	m := newMap(t, fake, &recorder{evicted: map[string]ttlmap.Reason{}}, 0)
+	defer m.Close()
+	m.Set("a", 1)
+	fake.Advance(50 * time.Second)
+	if _, ok := m.GetAndExtend("a", time.Minute); !ok {
+		t.Fatal("Expected a to be present")
+	}
+	if expires, _ := m.Expires("a"); !expires.Equal(fake.Now().Add(time.Minute)) {
+		t.Errorf("Expected the expiry to move to a minute from now got %v", expires)
+	}
+	fake.Advance(50 * time.Second)
+	if _, ok := m.Get("a"); !ok {
+		t.Error("Expected the extended entry to still be present")
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	r := &recorder{evicted: map[string]ttlmap.Reason{}}
+	m := newMap(t, fake, r, 2)
+	defer m.Close()
+	m.SetTTL("short", 1, time.Second)
+	m.SetTTL("long", 2, time.Hour)
+	m.Set("new", 3)
+	if _, ok := m.Get("short"); ok || m.Len() != 2 {
+		t.Error("Expected the entry closest to expiring to be evicted")
+	}
+	if reason, _ := r.reason("short"); reason != ttlmap.Evicted {
+		t.Errorf("Expected an eviction got %v", reason)
+	}
+	fake.Advance(2 * time.Minute)
+	m.Set("newer", 4)
+	if reason, _ := r.reason("new"); reason != ttlmap.Expired || m.Len() != 2 {
+		t.Error("Expected expired entries to make room before evicting live ones")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	m := newMap(t, fake, &recorder{evicted: map[string]ttlmap.Reason{}}, 0)
+	defer m.Close()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.SetTTL("c", 3, time.Hour)
+	fake.Advance(time.Minute)
+	if n := m.Sweep(); n != 2 || m.Len() != 1 {
+		t.Errorf("Expected 2 entries swept got %d", n)
+	}
+}
@@ -0,0 +1,96 @@
+// Package wait is a package that polls a condition until it holds.
+package wait
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+// Condition reports whether the wait is over. A non-nil error stops the wait and is returned.
+type Condition func(ctx context.Context) (bool, error)
+
+// Bool adapts a condition that cannot fail.
+func Bool(condition func() bool) Condition {
+	return func(ctx context.Context) (bool, error) {
+		return condition(), nil
+	}
+}
+
+// Options configures polling. Zero fields take the defaults noted on each.
+type Options struct {
+	// Initial is the delay before the second check. Defaults to 10 milliseconds.
+	Initial time.Duration
+	// Max caps the delay between checks. Defaults to one second.
+	Max time.Duration
+	// Multiplier is the factor the delay grows by after each check. Defaults to 2; use 1 for a
+	// fixed interval.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction of it in either direction, so many
+	// pollers started together drift apart. Zero means no jitter.
+	Jitter float64
+	// Clock is used for the delays. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Until checks condition immediately and then every interval until it holds, it fails, or ctx is
+// done, in which case ctx's error is returned.
+func Until(ctx context.Context, interval time.Duration, condition Condition) error {
+	return Backoff(ctx, Options{Initial: interval, Max: interval, Multiplier: 1}, condition)
+}
+
+// Backoff checks condition immediately and then with growing delays until it holds, it fails, or
+// ctx is done, in which case ctx's error is returned.
+func Backoff(ctx context.Context, options Options, condition Condition) error {
+	options = withDefaults(options)
+	delay := options.Initial
+	for {
+		done, err := condition(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		timer := options.Clock.NewTimer(jitter(delay, options.Jitter))
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		delay = time.Duration(float64(delay) * options.Multiplier)
+		if delay > options.Max || delay <= 0 {
+			delay = options.Max
+		}
+	}
+}
+
+func withDefaults(options Options) Options {
+	if options.Initial <= 0 {
+		options.Initial = 10 * time.Millisecond
+	}
+	if options.Max <= 0 {
+		options.Max = time.Second
+	}
+	if options.Max < options.Initial {
+		options.Max = options.Initial
+	}
+	if options.Multiplier <= 0 {
+		options.Multiplier = 2
+	}
+	options.Clock = clock.OrReal(options.Clock)
+	return options
+}
+
+func jitter(delay time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return delay
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	return time.Duration(float64(delay) * (1 + fraction*(2*rand.Float64()-1)))
+}
@@ -0,0 +1,68 @@
+package wait_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/wait"
+)
+
+func TestUntil(t *testing.T) {
+	var checks atomic.Int32
+	err := wait.Until(context.Background(), time.Millisecond, wait.Bool(func() bool {
+		return checks.Add(1) == 3
+	}))
+	if err != nil || checks.Load() != 3 {
+		t.Errorf("Expected 3 checks got %d (%v)", checks.Load(), err)
+	}
+}
+
+func TestUntilError(t *testing.T) {
+	failed := errors.New("failed")
+	err := wait.Until(context.Background(), time.Millisecond, func(ctx context.Context) (bool, error) {
+		return false, failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("Expected the condition's error got %v", err)
+	}
+}
+
+func TestUntilContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := wait.Until(ctx, time.Millisecond, wait.Bool(func() bool { return false }))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	var checks atomic.Int32
+	done := make(chan error)
+	go func() {
+		done <- wait.Backoff(context.Background(), wait.Options{Initial: time.Second, Max: 4 * time.Second, Clock: fake}, wait.Bool(func() bool {
+			return checks.Add(1) == 5
+		}))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// Delays of 1s, 2s, 4s and then 4s again once capped.
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if err := fake.BlockUntil(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		fake.Advance(delay - time.Millisecond)
+		if fake.Waiters() != 1 {
+			t.Fatalf("Expected the %v delay not to have passed", delay)
+		}
+		fake.Advance(time.Millisecond)
+	}
+	if err := <-done; err != nil || checks.Load() != 5 {
+		t.Errorf("Expected 5 checks got %d (%v)", checks.Load(), err)
+	}
+}
@@ -0,0 +1,135 @@
+// Package waitmap is a package that implements a map whose readers can wait for a key to be set.
+//
+// It suits correlating responses with requests by ID: the requester waits on the ID and whichever
+// goroutine receives the response sets it.
+package waitmap
+
+import (
+	"context"
+	"sync"
+)
+
+type waiters struct {
+	ready chan struct{}
+	count int
+}
+
+// Map is a map whose Get blocks until the key is set. It is safe for concurrent use.
+type Map[K comparable, V any] struct {
+	items   map[K]V
+	waiting map[K]*waiters
+	mutex   *sync.Mutex
+}
+
+// New creates an empty map.
+func New[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{
+		items:   make(map[K]V),
+		waiting: make(map[K]*waiters),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Set stores value for key and wakes every goroutine waiting for it. The value stays until it is
+// taken or deleted; use TrySet for values nobody may be waiting for.
+func (m *Map[K, V]) Set(key K, value V) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.set(key, value)
+}
+
+// TrySet is like Set but only stores value if a goroutine is waiting for key, reporting whether it
+// did. A late response for a request whose waiter gave up is then dropped rather than kept forever.
+func (m *Map[K, V]) TrySet(key K, value V) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.waiting[key]; !ok {
+		return false
+	}
+	m.set(key, value)
+	return true
+}
+
+// Get returns the value for key, waiting until it is set or ctx is done.
+func (m *Map[K, V]) Get(ctx context.Context, key K) (V, error) {
+	return m.get(ctx, key, false)
+}
+
+// Take is like Get but also removes key, so each value set is delivered to a single taker.
+func (m *Map[K, V]) Take(ctx context.Context, key K) (V, error) {
+	return m.get(ctx, key, true)
+}
+
+// Load returns the value for key without waiting.
+func (m *Map[K, V]) Load(key K) (V, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	value, ok := m.items[key]
+	return value, ok
+}
+
+// Delete removes key. Goroutines waiting for key keep waiting.
+func (m *Map[K, V]) Delete(key K) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.items, key)
+}
+
+// Len returns the number of keys set.
+func (m *Map[K, V]) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.items)
+}
+
+// Waiting returns the number of keys that goroutines are waiting on.
+func (m *Map[K, V]) Waiting() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.waiting)
+}
+
+// set stores value and wakes its waiters. It must be called with the lock held.
+func (m *Map[K, V]) set(key K, value V) {
+	m.items[key] = value
+	if w, ok := m.waiting[key]; ok {
+		close(w.ready)
+		delete(m.waiting, key)
+	}
+}
+
+func (m *Map[K, V]) get(ctx context.Context, key K, remove bool) (V, error) {
+	for {
+		m.mutex.Lock()
+		if value, ok := m.items[key]; ok {
+			if remove {
+				delete(m.items, key)
+			}
+			m.mutex.Unlock()
+			return value, nil
+		}
+		w, ok := m.waiting[key]
+		if !ok {
+			w = &waiters{ready: make(chan struct{})}
+			m.waiting[key] = w
+		}
+		w.count++
+		m.mutex.Unlock()
+
+		select {
+		case <-w.ready:
+			// The key may have been taken or deleted again before this goroutine woke, so check again.
+		case <-ctx.Done():
+			m.mutex.Lock()
+			if current, ok := m.waiting[key]; ok && current == w {
+				w.count--
+				if w.count == 0 {
+					delete(m.waiting, key)
+				}
+			}
+			m.mutex.Unlock()
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package waitmap_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/waitmap"
+)
+
+func TestGetWaitsForSet(t *testing.T) {
+	m := waitmap.New[string, int]()
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		m.Set("a", 1)
+	}()
+	if val, err := m.Get(context.Background(), "a"); val != 1 || err != nil {
+		t.Errorf("Expected 1 got %v (%v)", val, err)
+	}
+	if val, err := m.Get(context.Background(), "a"); val != 1 || err != nil {
+		t.Errorf("Expected Get to leave the value got %v (%v)", val, err)
+	}
+}
+
+func TestGetContext(t *testing.T) {
+	m := waitmap.New[string, int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := m.Get(ctx, "a"); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+	if m.Waiting() != 0 {
+		t.Errorf("Expected abandoned waits to be cleaned up got %d", m.Waiting())
+	}
+}
+
+func TestTake(t *testing.T) {
+	m := waitmap.New[int, string]()
+	results := make(chan string, 2)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if val, err := m.Take(ctx, 1); err == nil {
+				results <- val
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	m.Set(1, "reply")
+	wg.Wait()
+	close(results)
+	count := 0
+	for range results {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("Expected a single taker to receive the value got %d", count)
+	}
+	if _, ok := m.Load(1); ok || m.Len() != 0 {
+		t.Error("Expected Take to remove the key")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	m := waitmap.New[string, int]()
+	m.Set("a", 1)
+	m.Delete("a")
+	if _, ok := m.Load("a"); ok {
+		t.Error("Expected a to be deleted")
+	}
+}
+
+func TestTrySet(t *testing.T) {
+	m := waitmap.New[int, string]()
+	if m.TrySet(1, "late") || m.Len() != 0 {
+		t.Error("Expected a value nobody waits for to be dropped")
+	}
+	done := make(chan string)
+	go func() {
+		val, _ := m.Take(context.Background(), 1)
+		done <- val
+	}()
+	for m.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if !m.TrySet(1, "reply") {
+		t.Error("Expected a value to be stored for a waiter")
+	}
+	if val := <-done; val != "reply" || m.Len() != 0 {
+		t.Errorf("Expected the waiter to take the reply got %q", val)
+	}
+}
@@ -0,0 +1,394 @@
+// Package wal is a package that implements a write-ahead log: an append-only file of records that
+// can be replayed in order after a crash.
+//
+// Each record is framed with its length and a CRC-32C checksum. Records are numbered with
+// consecutive sequence numbers starting at one. A record torn by a crash, or any damage after it,
+// is cut off when the log is opened, so replay only ever sees whole records. Once records are no
+// longer needed, such as after a checkpoint, TruncateFront discards them.
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+)
+
+const (
+	headerSize      = 13
+	frameHeaderSize = 8
+	formatVersion   = 1
+)
+
+var (
+	magic = []byte("GUWL")
+	table = crc32.MakeTable(crc32.Castagnoli)
+)
+
+var (
+	// ErrClosed is returned when using a closed log.
+	ErrClosed = errors.New("wal: log closed")
+	// ErrCorrupt is returned when an existing file is not a valid log.
+	ErrCorrupt = errors.New("wal: corrupt log file")
+)
+
+// SyncPolicy decides when appended records are flushed to stable storage.
+type SyncPolicy int
+
+const (
+	// SyncAlways syncs after every append, so Append returns only once the record is durable.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval syncs in the background every Options.Interval. A crash loses at most that much.
+	SyncInterval
+	// SyncNever leaves flushing to the operating system and explicit calls to Sync.
+	SyncNever
+)
+
+// Options configures a log. Zero fields take the defaults noted on each.
+type Options struct {
+	// Sync is the sync policy. Defaults to SyncAlways.
+	Sync SyncPolicy
+	// Interval is how often SyncInterval syncs. Defaults to one second.
+	Interval time.Duration
+	// Clock drives SyncInterval. Defaults to the real clock.
+	Clock clock.Clock
+}
+
+// Log is a write-ahead log backed by a single file. It is safe for concurrent use.
+type Log struct {
+	file    *os.File
+	path    string
+	options Options
+	first   uint64
+	next    uint64
+	size    int64
+	err     error
+	dirty   bool
+	closed  bool
+	done    chan struct{}
+	stopped chan struct{}
+	mutex   *sync.Mutex
+}
+
+// Open opens the log at path, creating it if it does not exist. A torn or damaged tail is
+// truncated.
+func Open(path string, options Options) (*Log, error) {
+	if options.Interval <= 0 {
+		options.Interval = time.Second
+	}
+	options.Clock = clock.OrReal(options.Clock)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{
+		file:    file,
+		path:    path,
+		options: options,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		mutex:   &sync.Mutex{},
+	}
+	if err := l.recover(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if options.Sync == SyncInterval {
+		go l.syncLoop()
+	} else {
+		close(l.stopped)
+	}
+	return l, nil
+}
+
+// recover reads the header, counts the valid records and cuts off anything after them.
+func (l *Log) recover() error {
+	info, err := l.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		l.first, l.next, l.size = 1, 1, headerSize
+		if _, err := l.file.Write(header(1)); err != nil {
+			return err
+		}
+		return l.file.Sync()
+	}
+	buf := make([]byte, headerSize)
+	if _, err := l.file.ReadAt(buf, 0); err != nil || !bytes.Equal(buf[:4], magic) || buf[4] != formatVersion {
+		return ErrCorrupt
+	}
+	l.first = binary.BigEndian.Uint64(buf[5:])
+	valid, count, err := scan(io.NewSectionReader(l.file, headerSize, info.Size()-headerSize), nil)
+	if err != nil {
+		return err
+	}
+	l.next = l.first + count
+	l.size = headerSize + valid
+	if l.size < info.Size() {
+		if err := l.file.Truncate(headerSize + valid); err != nil {
+			return err
+		}
+		return l.file.Sync()
+	}
+	return nil
+}
+
+// Append writes a record and returns its sequence number. A failed write is cut off again so later
+// records are not stranded behind a torn one; if that fails too, every later Append returns the
+// error.
+func (l *Log) Append(data []byte) (uint64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return 0, ErrClosed
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	record := frame(data)
+	if _, err := l.file.Write(record); err != nil {
+		if truncateErr := l.file.Truncate(l.size); truncateErr != nil {
+			l.err = err
+		}
+		return 0, err
+	}
+	l.size += int64(len(record))
+	seq := l.next
+	l.next++
+	l.dirty = true
+	if l.options.Sync == SyncAlways {
+		if err := l.sync(); err != nil {
+			return 0, err
+		}
+	}
+	return seq, nil
+}
+
+// Sync flushes appended records to stable storage.
+func (l *Log) Sync() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	return l.sync()
+}
+
+// First returns the sequence number of the oldest record in the log.
+func (l *Log) First() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.first
+}
+
+// Next returns the sequence number the next appended record will get. The log is empty when it
+// equals First.
+func (l *Log) Next() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.next
+}
+
+// Replay calls fn with every record from sequence number from onwards, in order, stopping at the
+// first error fn returns. Appends wait until Replay finishes, so fn must not append to the log.
+func (l *Log) Replay(from uint64, fn func(seq uint64, data []byte) error) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	info, err := l.file.Stat()
+	if err != nil {
+		return err
+	}
+	seq := l.first
+	_, _, err = scan(io.NewSectionReader(l.file, headerSize, info.Size()-headerSize), func(data []byte) error {
+		defer func() { seq++ }()
+		if seq < from {
+			return nil
+		}
+		return fn(seq, data)
+	})
+	return err
+}
+
+// TruncateFront discards the records before sequence number seq, so First becomes seq. Truncating
+// past the end empties the log, and the next record keeps counting from where it left off. The
+// remaining records are copied to a new file which replaces the old one atomically.
+func (l *Log) TruncateFront(seq uint64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if seq <= l.first {
+		return nil
+	}
+	if seq > l.next {
+		seq = l.next
+	}
+	info, err := l.file.Stat()
+	if err != nil {
+		return err
+	}
+	// The new file is opened for appending up front, so once it is renamed into place nothing is
+	// left that can fail and leave the log writing to the replaced file.
+	tmp, err := os.OpenFile(l.path+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	w.Write(header(seq))
+	current, size := l.first, int64(headerSize)
+	_, _, err = scan(io.NewSectionReader(l.file, headerSize, info.Size()-headerSize), func(data []byte) error {
+		defer func() { current++ }()
+		if current < seq {
+			return nil
+		}
+		n, err := w.Write(frame(data))
+		size += int64(n)
+		return err
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), l.path)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	syncDir(filepath.Dir(l.path))
+	l.file.Close()
+	l.file = tmp
+	l.first = seq
+	l.size = size
+	l.dirty = false
+	return nil
+}
+
+// Close syncs and closes the log.
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return nil
+	}
+	l.closed = true
+	close(l.done)
+	err := l.sync()
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.mutex.Unlock()
+	<-l.stopped
+	return err
+}
+
+func (l *Log) sync() error {
+	if !l.dirty {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.dirty = false
+	return nil
+}
+
+func (l *Log) syncLoop() {
+	defer close(l.stopped)
+	ticker := l.options.Clock.NewTicker(l.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			l.mutex.Lock()
+			if !l.closed {
+				l.sync()
+			}
+			l.mutex.Unlock()
+		case <-l.done:
+			return
+		}
+	}
+}
+
+func header(first uint64) []byte {
+	buf := append([]byte(nil), magic...)
+	buf = append(buf, formatVersion)
+	return binary.BigEndian.AppendUint64(buf, first)
+}
+
+// frame prefixes data with its length and a checksum covering both.
+func frame(data []byte) []byte {
+	buf := make([]byte, frameHeaderSize, frameHeaderSize+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	crc := crc32.Update(crc32.Checksum(buf[:4], table), table, data)
+	binary.BigEndian.PutUint32(buf[4:], crc)
+	return append(buf, data...)
+}
+
+// scan reads frames from r until the end or the first incomplete or damaged one, calling fn with
+// each record if it is not nil. It returns the length and count of the valid records, and either
+// fn's error or a read error other than running out of data, which must not be mistaken for a torn
+// tail.
+func scan(r *io.SectionReader, fn func(data []byte) error) (int64, uint64, error) {
+	br := bufio.NewReader(r)
+	var valid int64
+	var count uint64
+	head := make([]byte, frameHeaderSize)
+	for {
+		if _, err := io.ReadFull(br, head); err != nil {
+			return valid, count, readErr(err)
+		}
+		length := int64(binary.BigEndian.Uint32(head))
+		if valid+frameHeaderSize+length > r.Size() {
+			return valid, count, nil
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return valid, count, readErr(err)
+		}
+		if crc32.Update(crc32.Checksum(head[:4], table), table, data) != binary.BigEndian.Uint32(head[4:]) {
+			return valid, count, nil
+		}
+		if fn != nil {
+			if err := fn(data); err != nil {
+				return valid, count, err
+			}
+		}
+		valid += frameHeaderSize + length
+		count++
+	}
+}
+
+// readErr drops the errors that mean the log simply ended.
+func readErr(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	return err
+}
+
+// syncDir makes a rename in dir durable. Errors are ignored since not every platform supports it.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
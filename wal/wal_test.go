@@ -0,0 +1,144 @@
+package wal_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/clock"
+	"github.com/cjsaylor/goutil/wal"
+)
+
+func open(t *testing.T, path string) *wal.Log {
+	t.Helper()
+	l, err := wal.Open(path, wal.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func replay(t *testing.T, l *wal.Log, from uint64) []string {
+	t.Helper()
+	var records []string
+	err := l.Replay(from, func(seq uint64, data []byte) error {
+		records = append(records, fmt.Sprintf("%d:%s", seq, data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestAppendReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	l := open(t, path)
+	for _, record := range []string{"a", "b", "c"} {
+		l.Append([]byte(record))
+	}
+	l.Close()
+	if _, err := l.Append([]byte("d")); err != wal.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+
+	l = open(t, path)
+	defer l.Close()
+	if got := replay(t, l, 0); !reflect.DeepEqual(got, []string{"1:a", "2:b", "3:c"}) {
+		t.Errorf("Expected the records in order got %v", got)
+	}
+	if got := replay(t, l, 3); !reflect.DeepEqual(got, []string{"3:c"}) {
+		t.Errorf("Expected replay from 3 got %v", got)
+	}
+	if seq, _ := l.Append([]byte("d")); seq != 4 {
+		t.Errorf("Expected numbering to continue at 4 got %d", seq)
+	}
+	stop := errors.New("stop")
+	if err := l.Replay(0, func(seq uint64, data []byte) error { return stop }); err != stop {
+		t.Errorf("Expected the callback error got %v", err)
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	l := open(t, path)
+	l.Append([]byte("whole"))
+	l.Append([]byte("torn record"))
+	l.Close()
+	info, _ := os.Stat(path)
+	os.Truncate(path, info.Size()-3)
+
+	l = open(t, path)
+	if got := replay(t, l, 0); !reflect.DeepEqual(got, []string{"1:whole"}) {
+		t.Errorf("Expected the torn record to be dropped got %v", got)
+	}
+	l.Append([]byte("after"))
+	l.Close()
+
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path, data, 0600)
+	l = open(t, path)
+	defer l.Close()
+	if got := replay(t, l, 0); !reflect.DeepEqual(got, []string{"1:whole"}) {
+		t.Errorf("Expected the damaged record to fail its checksum got %v", got)
+	}
+}
+
+func TestTruncateFront(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	l := open(t, path)
+	for i := 0; i < 5; i++ {
+		l.Append([]byte{byte('a' + i)})
+	}
+	if err := l.TruncateFront(4); err != nil {
+		t.Fatal(err)
+	}
+	if l.First() != 4 || l.Next() != 6 {
+		t.Errorf("Expected records 4 to 5 got %d to %d", l.First(), l.Next())
+	}
+	l.Append([]byte("f"))
+	l.Close()
+
+	l = open(t, path)
+	defer l.Close()
+	if got := replay(t, l, 0); !reflect.DeepEqual(got, []string{"4:d", "5:e", "6:f"}) {
+		t.Errorf("Expected the truncated log to survive reopening got %v", got)
+	}
+	l.TruncateFront(100)
+	if l.First() != 7 || l.Next() != 7 || len(replay(t, l, 0)) != 0 {
+		t.Errorf("Expected an empty log at 7 got %d to %d", l.First(), l.Next())
+	}
+}
+
+func TestCorruptHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	os.WriteFile(path, []byte("not a log file"), 0600)
+	if _, err := wal.Open(path, wal.Options{}); err != wal.ErrCorrupt {
+		t.Errorf("Expected corrupt error got %v", err)
+	}
+}
+
+func TestSyncInterval(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	l, err := wal.Open(filepath.Join(t.TempDir(), "log"), wal.Options{Sync: wal.SyncInterval, Interval: time.Second, Clock: fake})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := fake.BlockUntil(ctx, 1); err != nil {
+		t.Fatal("Expected a sync ticker")
+	}
+	l.Append([]byte("a"))
+	fake.Advance(time.Second)
+	if err := l.Sync(); err != nil {
+		t.Error(err)
+	}
+}
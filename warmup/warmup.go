@@ -0,0 +1,198 @@
+// Package warmup is a package that repopulates an LRU cache after a restart from a profile of which
+// keys were popular, rather than from a dump of possibly stale values.
+//
+// A Profile is fed every cache access with Touch. It counts keys in a count-min sketch and
+// remembers only the most popular ones, so it stays small enough to save on shutdown with
+// MarshalBinary. On startup Warm fetches fresh values for the saved keys through a bulk loader,
+// most popular first, so an interrupted warm-up still holds the keys that matter most.
+package warmup
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/cjsaylor/goutil/cmsketch"
+	"github.com/cjsaylor/goutil/lru"
+)
+
+const formatVersion = 2
+
+// ErrInvalidData is returned when decoding malformed binary data.
+var ErrInvalidData = errors.New("warmup: invalid data")
+
+// Loader fetches the current values of keys. Keys it has no value for are left out of the result.
+type Loader func(ctx context.Context, keys []string) (map[string]interface{}, error)
+
+// Profile tracks the most frequently accessed keys. It is safe for concurrent use.
+type Profile struct {
+	sketch *cmsketch.Sketch
+	top    map[string]uint32
+	size   int
+	floor  uint32
+	mutex  *sync.Mutex
+}
+
+// NewProfile creates a profile that remembers the size most popular keys. Size should not exceed
+// the capacity of the cache being warmed, otherwise the least popular keys would evict the most
+// popular.
+func NewProfile(size int) *Profile {
+	if size < 1 {
+		size = 1
+	}
+	return &Profile{
+		sketch: cmsketch.NewWithError(0.001, 0.01),
+		top:    make(map[string]uint32),
+		size:   size,
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// Touch records an access of key.
+func (p *Profile) Touch(key string) {
+	p.sketch.Add([]byte(key))
+	count := p.sketch.Estimate([]byte(key))
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.top[key]; ok || len(p.top) < p.size {
+		p.top[key] = count
+		return
+	}
+	// Tracked counts only grow, so floor is at most the smallest of them and most touches of
+	// unpopular keys return without scanning.
+	if count <= p.floor {
+		return
+	}
+	least, lowest, next := "", uint32(math.MaxUint32), uint32(math.MaxUint32)
+	for k, c := range p.top {
+		if c < lowest {
+			least, lowest, next = k, c, lowest
+		} else if c < next {
+			next = c
+		}
+	}
+	if count <= lowest {
+		p.floor = lowest
+		return
+	}
+	delete(p.top, least)
+	p.top[key] = count
+	p.floor = next
+	if count < next {
+		p.floor = count
+	}
+}
+
+// Keys returns the tracked keys, most popular first.
+func (p *Profile) Keys() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.keys()
+}
+
+func (p *Profile) keys() []string {
+	keys := make([]string, 0, len(p.top))
+	for key := range p.top {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if p.top[keys[i]] != p.top[keys[j]] {
+			return p.top[keys[i]] > p.top[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// MarshalBinary encodes the sketch along with the tracked keys and their counts, so a restored
+// profile keeps the counts of keys just below the most popular ones too.
+func (p *Profile) MarshalBinary() ([]byte, error) {
+	sketch, err := p.sketch.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	data := []byte{formatVersion}
+	data = binary.BigEndian.AppendUint32(data, uint32(p.size))
+	data = binary.BigEndian.AppendUint32(data, uint32(len(sketch)))
+	data = append(data, sketch...)
+	for _, key := range p.keys() {
+		data = binary.BigEndian.AppendUint32(data, p.top[key])
+		data = binary.BigEndian.AppendUint32(data, uint32(len(key)))
+		data = append(data, key...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a profile encoded with MarshalBinary, replacing its size, sketch and
+// tracked keys. A restored profile keeps learning from where it left off. The profile must have been
+// created with NewProfile.
+func (p *Profile) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 || data[0] != formatVersion {
+		return ErrInvalidData
+	}
+	size := int(binary.BigEndian.Uint32(data[1:]))
+	sketchLen := int(binary.BigEndian.Uint32(data[5:]))
+	if size < 1 || sketchLen > len(data)-9 {
+		return ErrInvalidData
+	}
+	sketch := data[9 : 9+sketchLen]
+	top := make(map[string]uint32)
+	for offset := 9 + sketchLen; offset < len(data); {
+		if offset+8 > len(data) {
+			return ErrInvalidData
+		}
+		count := binary.BigEndian.Uint32(data[offset:])
+		length := int(binary.BigEndian.Uint32(data[offset+4:]))
+		offset += 8
+		if length > len(data)-offset || len(top) == size {
+			return ErrInvalidData
+		}
+		top[string(data[offset:offset+length])] = count
+		offset += length
+	}
+	// The sketch is decoded in place rather than replaced, since Touch uses it without holding the
+	// mutex. It is left unchanged if the data is invalid.
+	if err := p.sketch.UnmarshalBinary(sketch); err != nil {
+		return ErrInvalidData
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.top, p.size, p.floor = top, size, 0
+	return nil
+}
+
+// Warm loads keys into cache in batches of batchSize, in the order given, and returns the number of
+// entries set. It stops at the first loader error or when ctx is done, keeping what was loaded so
+// far. Once loading ends the entries are reordered so the first key is the most recently used.
+func Warm(ctx context.Context, cache *lru.Cache, keys []string, batchSize int, load Loader) (int, error) {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	var loaded []string
+	var err error
+	for start := 0; start < len(keys); start += batchSize {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		batch := keys[start:min(start+batchSize, len(keys))]
+		var values map[string]interface{}
+		if values, err = load(ctx, batch); err != nil {
+			break
+		}
+		for _, key := range batch {
+			if value, ok := values[key]; ok {
+				cache.Set(key, value)
+				loaded = append(loaded, key)
+			}
+		}
+	}
+	for i := len(loaded) - 1; i >= 0; i-- {
+		cache.Get(loaded[i])
+	}
+	return len(loaded), err
+}
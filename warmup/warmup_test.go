@@ -0,0 +1,122 @@
+package warmup_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/cjsaylor/goutil/lru"
+	"github.com/cjsaylor/goutil/warmup"
+)
+
+func touch(p *warmup.Profile, key string, n int) {
+	for i := 0; i < n; i++ {
+		p.Touch(key)
+	}
+}
+
+func TestProfileKeepsPopularKeys(t *testing.T) {
+	p := warmup.NewProfile(3)
+	touch(p, "a", 10)
+	touch(p, "b", 5)
+	touch(p, "c", 1)
+	for i := 0; i < 20; i++ {
+		p.Touch(fmt.Sprintf("rare%d", i))
+	}
+	touch(p, "d", 7)
+	if keys := p.Keys(); !reflect.DeepEqual(keys, []string{"a", "d", "b"}) {
+		t.Errorf("Expected the 3 most popular keys got %v", keys)
+	}
+}
+
+func TestProfileRoundTrip(t *testing.T) {
+	p := warmup.NewProfile(2)
+	touch(p, "a", 3)
+	touch(p, "b", 2)
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := warmup.NewProfile(1)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if keys := restored.Keys(); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("Expected the saved keys got %v", keys)
+	}
+	touch(restored, "b", 2)
+	if keys := restored.Keys(); keys[0] != "b" {
+		t.Errorf("Expected the restored profile to keep counting got %v", keys)
+	}
+	if err := restored.UnmarshalBinary(data[:len(data)-1]); err != warmup.ErrInvalidData {
+		t.Errorf("Expected invalid data got %v", err)
+	}
+}
+
+func TestProfileRoundTripKeepsSketch(t *testing.T) {
+	p := warmup.NewProfile(1)
+	touch(p, "a", 3)
+	touch(p, "b", 2)
+	data, _ := p.MarshalBinary()
+	restored := warmup.NewProfile(1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		touch(restored, "c", 10)
+	}()
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	restored.UnmarshalBinary(data)
+	touch(restored, "b", 2)
+	if keys := restored.Keys(); !reflect.DeepEqual(keys, []string{"b"}) {
+		t.Errorf("Expected counts of untracked keys to be restored got %v", keys)
+	}
+}
+
+func TestWarm(t *testing.T) {
+	cache := lru.NewCache(10, lru.Noop())
+	var batches [][]string
+	load := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		batches = append(batches, keys)
+		values := map[string]interface{}{}
+		for _, key := range keys {
+			if key != "gone" {
+				values[key] = "value of " + key
+			}
+		}
+		return values, nil
+	}
+	n, err := warmup.Warm(context.Background(), cache, []string{"hot", "warm", "gone", "cold"}, 2, load)
+	if err != nil || n != 3 {
+		t.Errorf("Expected 3 entries loaded got %d (%v)", n, err)
+	}
+	if len(batches) != 2 || batches[0][0] != "hot" {
+		t.Errorf("Expected 2 batches starting with the hottest key got %v", batches)
+	}
+	if keys := cache.ListKeys(); !reflect.DeepEqual(keys, []interface{}{"hot", "warm", "cold"}) {
+		t.Errorf("Expected the hottest key to be the most recent got %v", keys)
+	}
+}
+
+func TestWarmStopsOnError(t *testing.T) {
+	cache := lru.NewCache(10, lru.Noop())
+	failure := errors.New("backend down")
+	calls := 0
+	load := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		calls++
+		if calls > 1 {
+			return nil, failure
+		}
+		return map[string]interface{}{keys[0]: 1}, nil
+	}
+	n, err := warmup.Warm(context.Background(), cache, []string{"a", "b", "c"}, 1, load)
+	if err != failure || n != 1 || len(cache.ListKeys()) != 1 {
+		t.Errorf("Expected the first batch to be kept got %d (%v)", n, err)
+	}
+}
@@ -0,0 +1,275 @@
+// Package workerpool is a package that runs tasks on a bounded set of goroutines.
+//
+// Pools are fixed when MinWorkers equals MaxWorkers, or elastic otherwise: workers are added while
+// every worker is busy, up to MaxWorkers, and workers above MinWorkers exit after sitting idle.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed is returned when submitting to a pool that has been closed.
+var ErrClosed = errors.New("workerpool: pool closed")
+
+// PanicError is the error of a task that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("workerpool: task panicked: %v", e.Value)
+}
+
+// Options configures a pool.
+type Options struct {
+	// MinWorkers is the number of workers kept running even when idle.
+	MinWorkers int
+	// MaxWorkers is the largest number of workers. It is raised to MinWorkers, and to one, if smaller.
+	MaxWorkers int
+	// QueueSize is the number of submitted tasks that may wait for a worker before Submit blocks.
+	QueueSize int
+	// IdleTimeout is how long a worker above MinWorkers waits for a task before exiting.
+	// A zero timeout keeps every started worker running until the pool is closed.
+	IdleTimeout time.Duration
+}
+
+// Stats is a point-in-time view of a pool's activity.
+type Stats struct {
+	Workers   int
+	Busy      int
+	Queued    int
+	Completed int64
+	Panicked  int64
+}
+
+type job struct {
+	ctx context.Context
+	run func(ctx context.Context)
+}
+
+// Pool runs submitted tasks on worker goroutines. It is safe for concurrent use.
+type Pool struct {
+	options   Options
+	queue     chan job
+	closing   chan struct{}
+	drain     chan struct{}
+	closed    bool
+	closeOnce *sync.Once
+	submit    *sync.RWMutex
+	state     *sync.Mutex
+	workers   int
+	busy      int
+	running   *sync.WaitGroup
+	completed atomic.Int64
+	panicked  atomic.Int64
+}
+
+// New creates a pool and starts its minimum number of workers.
+func New(options Options) *Pool {
+	if options.MinWorkers < 0 {
+		options.MinWorkers = 0
+	}
+	if options.MaxWorkers < options.MinWorkers {
+		options.MaxWorkers = options.MinWorkers
+	}
+	if options.MaxWorkers < 1 {
+		options.MaxWorkers = 1
+	}
+	p := &Pool{
+		options:   options,
+		queue:     make(chan job, options.QueueSize),
+		closing:   make(chan struct{}),
+		drain:     make(chan struct{}),
+		closeOnce: &sync.Once{},
+		submit:    &sync.RWMutex{},
+		state:     &sync.Mutex{},
+		running:   &sync.WaitGroup{},
+	}
+	p.state.Lock()
+	for i := 0; i < options.MinWorkers; i++ {
+		p.spawn()
+	}
+	p.state.Unlock()
+	return p
+}
+
+// Handle is the pending result of a submitted task.
+type Handle[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// Done returns a channel that is closed once the task has finished.
+func (h *Handle[T]) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the task has finished or ctx is done.
+func (h *Handle[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-h.done:
+		return h.value, h.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Submit queues task to run on the pool, waiting for queue space if necessary.
+// The task receives ctx, and is skipped with ctx's error if ctx is done before a worker starts it.
+// A panic in the task is recovered and reported as a *PanicError.
+func Submit[T any](ctx context.Context, p *Pool, task func(ctx context.Context) (T, error)) (*Handle[T], error) {
+	h := &Handle[T]{done: make(chan struct{})}
+	run := func(ctx context.Context) {
+		defer close(h.done)
+		if err := ctx.Err(); err != nil {
+			h.err = err
+			return
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				p.panicked.Add(1)
+				h.err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		h.value, h.err = task(ctx)
+	}
+	if err := p.enqueue(job{ctx: ctx, run: run}); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Go queues a task that only reports an error. See Submit.
+func (p *Pool) Go(ctx context.Context, task func(ctx context.Context) error) (*Handle[struct{}], error) {
+	return Submit(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, task(ctx)
+	})
+}
+
+// Stats returns the current activity of the pool.
+func (p *Pool) Stats() Stats {
+	p.state.Lock()
+	defer p.state.Unlock()
+	return Stats{
+		Workers:   p.workers,
+		Busy:      p.busy,
+		Queued:    len(p.queue),
+		Completed: p.completed.Load(),
+		Panicked:  p.panicked.Load(),
+	}
+}
+
+// Close stops accepting tasks, runs every task already queued, and waits for the workers to exit.
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+		p.submit.Lock()
+		p.closed = true
+		p.submit.Unlock()
+		close(p.drain)
+	})
+	p.running.Wait()
+}
+
+// Shutdown closes the pool like Close, but stops waiting for queued tasks when ctx is done.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) enqueue(j job) error {
+	p.submit.RLock()
+	defer p.submit.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	p.state.Lock()
+	if p.workers == p.busy && p.workers < p.options.MaxWorkers {
+		p.spawn()
+	}
+	p.state.Unlock()
+	select {
+	case p.queue <- j:
+		// A worker may have exited idle between the check above and the send.
+		p.state.Lock()
+		if len(p.queue) > 0 && p.workers == p.busy && p.workers < p.options.MaxWorkers {
+			p.spawn()
+		}
+		p.state.Unlock()
+		return nil
+	case <-j.ctx.Done():
+		return j.ctx.Err()
+	case <-p.closing:
+		return ErrClosed
+	}
+}
+
+// spawn starts a worker. It must be called with the state lock held.
+func (p *Pool) spawn() {
+	p.workers++
+	p.running.Add(1)
+	go p.work()
+}
+
+func (p *Pool) work() {
+	defer p.running.Done()
+	var idle <-chan time.Time
+	for {
+		if p.options.IdleTimeout > 0 {
+			idle = time.After(p.options.IdleTimeout)
+		}
+		select {
+		case j := <-p.queue:
+			p.run(j)
+		case <-idle:
+			p.state.Lock()
+			if p.workers > p.options.MinWorkers && len(p.queue) == 0 {
+				p.workers--
+				p.state.Unlock()
+				return
+			}
+			p.state.Unlock()
+		case <-p.drain:
+			for {
+				select {
+				case j := <-p.queue:
+					p.run(j)
+				default:
+					p.state.Lock()
+					p.workers--
+					p.state.Unlock()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *Pool) run(j job) {
+	p.state.Lock()
+	p.busy++
+	p.state.Unlock()
+	j.run(j.ctx)
+	p.completed.Add(1)
+	p.state.Lock()
+	p.busy--
+	p.state.Unlock()
+}
@@ -0,0 +1,125 @@
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cjsaylor/goutil/leaktest"
+	"github.com/cjsaylor/goutil/workerpool"
+)
+
+func TestSubmit(t *testing.T) {
+	p := workerpool.New(workerpool.Options{MinWorkers: 2, MaxWorkers: 2})
+	defer p.Close()
+	h, err := workerpool.Submit(context.Background(), p, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, err := h.Wait(context.Background()); val != 42 || err != nil {
+		t.Errorf("Expected 42 got %v (%v)", val, err)
+	}
+}
+
+func TestPanicCapture(t *testing.T) {
+	p := workerpool.New(workerpool.Options{MaxWorkers: 1})
+	defer p.Close()
+	h, _ := p.Go(context.Background(), func(ctx context.Context) error {
+		panic("boom")
+	})
+	_, err := h.Wait(context.Background())
+	var panicErr *workerpool.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("Expected a panic error got %v", err)
+	}
+	if p.Stats().Panicked != 1 {
+		t.Errorf("Expected 1 panic got %d", p.Stats().Panicked)
+	}
+}
+
+func TestCancelledBeforeStart(t *testing.T) {
+	p := workerpool.New(workerpool.Options{MaxWorkers: 1, QueueSize: 1})
+	defer p.Close()
+	release := make(chan struct{})
+	p.Go(context.Background(), func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := false
+	h, err := p.Go(ctx, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	close(release)
+	<-h.Done()
+	if _, err := h.Wait(context.Background()); err != context.Canceled || ran {
+		t.Errorf("Expected the task to be skipped got %v", err)
+	}
+}
+
+func TestCloseDrainsQueue(t *testing.T) {
+	defer leaktest.Check(t)()
+	p := workerpool.New(workerpool.Options{MaxWorkers: 1, QueueSize: 10})
+	var count atomic.Int32
+	for i := 0; i < 10; i++ {
+		p.Go(context.Background(), func(ctx context.Context) error {
+			time.Sleep(time.Millisecond)
+			count.Add(1)
+			return nil
+		})
+	}
+	p.Close()
+	if count.Load() != 10 {
+		t.Errorf("Expected all queued tasks to run got %d", count.Load())
+	}
+	if _, err := p.Go(context.Background(), func(ctx context.Context) error { return nil }); err != workerpool.ErrClosed {
+		t.Errorf("Expected closed error got %v", err)
+	}
+}
+
+func TestElastic(t *testing.T) {
+	p := workerpool.New(workerpool.Options{MinWorkers: 1, MaxWorkers: 4, IdleTimeout: 10 * time.Millisecond})
+	defer p.Close()
+	release := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		p.Go(context.Background(), func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+	}
+	if stats := p.Stats(); stats.Workers != 4 {
+		t.Errorf("Expected the pool to grow to 4 workers got %d", stats.Workers)
+	}
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for p.Stats().Workers > 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if stats := p.Stats(); stats.Workers != 1 || stats.Completed != 4 {
+		t.Errorf("Expected idle workers to exit down to 1 got %+v", stats)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	p := workerpool.New(workerpool.Options{MaxWorkers: 1})
+	release := make(chan struct{})
+	defer close(release)
+	p.Go(context.Background(), func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded got %v", err)
+	}
+}
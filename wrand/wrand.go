@@ -0,0 +1,246 @@
+// Package wrand is a package that picks items at random in proportion to their weights.
+//
+// Picker supports changing weights at any time with O(log n) updates and picks. Alias is fixed
+// once built but picks in constant time.
+package wrand
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLength is returned when items and weights have different lengths.
+	ErrLength = errors.New("wrand: items and weights differ in length")
+	// ErrWeight is returned when a weight is negative or every weight is zero.
+	ErrWeight = errors.New("wrand: weights must be non-negative with a positive total")
+)
+
+func newRandom(random *rand.Rand) *rand.Rand {
+	if random == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return random
+}
+
+// Picker picks keys in proportion to weights that may be changed at any time.
+// Weights are kept in a Fenwick tree of prefix sums. It is safe for concurrent use.
+type Picker[K comparable] struct {
+	mutex   *sync.Mutex
+	random  *rand.Rand
+	keys    []K
+	weights []float64
+	tree    []float64
+	index   map[K]int
+	total   float64
+}
+
+// New creates an empty picker. A nil random is seeded from the current time; pass a seeded one
+// for reproducible picks.
+func New[K comparable](random *rand.Rand) *Picker[K] {
+	return &Picker[K]{
+		mutex:  &sync.Mutex{},
+		random: newRandom(random),
+		tree:   []float64{0},
+		index:  make(map[K]int),
+	}
+}
+
+// Set adds key or changes its weight. A zero weight keeps the key but it is never picked.
+func (p *Picker[K]) Set(key K, weight float64) error {
+	if !(weight >= 0) {
+		return ErrWeight
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	i, ok := p.index[key]
+	if !ok {
+		i = len(p.keys)
+		p.index[key] = i
+		p.keys = append(p.keys, key)
+		p.weights = append(p.weights, 0)
+		p.grow()
+	}
+	p.update(i, weight-p.weights[i])
+	p.weights[i] = weight
+	return nil
+}
+
+// Remove deletes key and reports whether it was present.
+func (p *Picker[K]) Remove(key K) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	i, ok := p.index[key]
+	if !ok {
+		return false
+	}
+	last := len(p.keys) - 1
+	// Move the last key into the removed slot so the tree only ever shrinks from the end.
+	p.update(i, p.weights[last]-p.weights[i])
+	p.update(last, -p.weights[last])
+	p.keys[i], p.weights[i] = p.keys[last], p.weights[last]
+	p.index[p.keys[i]] = i
+	delete(p.index, key)
+	p.keys = p.keys[:last]
+	p.weights = p.weights[:last]
+	p.tree = p.tree[:last+1]
+	if len(p.keys) == 0 {
+		// Reset accumulated floating point error.
+		p.total = 0
+	}
+	return true
+}
+
+// Weight returns key's weight and whether it is present.
+func (p *Picker[K]) Weight(key K) (float64, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	i, ok := p.index[key]
+	if !ok {
+		return 0, false
+	}
+	return p.weights[i], true
+}
+
+// Total returns the sum of every weight.
+func (p *Picker[K]) Total() float64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.total
+}
+
+// Len returns the number of keys.
+func (p *Picker[K]) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.keys)
+}
+
+// Pick returns a key chosen with probability proportional to its weight. It returns false if
+// there are no keys with a positive weight.
+func (p *Picker[K]) Pick() (K, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var zero K
+	if !(p.total > 0) {
+		return zero, false
+	}
+	target := p.random.Float64() * p.total
+	// Descend the tree to the first slot whose prefix sum exceeds target.
+	pos := 0
+	step := 1
+	for step*2 < len(p.tree) {
+		step *= 2
+	}
+	for ; step > 0; step /= 2 {
+		if next := pos + step; next < len(p.tree) && p.tree[next] <= target {
+			pos = next
+			target -= p.tree[next]
+		}
+	}
+	// Rounding can land on a zero weight slot or past the end; step back to a pickable one.
+	for i := pos; i >= 0; i-- {
+		if i < len(p.keys) && p.weights[i] > 0 {
+			return p.keys[i], true
+		}
+	}
+	for i := pos; i < len(p.keys); i++ {
+		if p.weights[i] > 0 {
+			return p.keys[i], true
+		}
+	}
+	return zero, false
+}
+
+// grow extends the tree by one slot for a new zero weight, filling the node from its children.
+func (p *Picker[K]) grow() {
+	n := len(p.tree)
+	var sum float64
+	for child := n - 1; child > n-(n&-n); child -= child & -child {
+		sum += p.tree[child]
+	}
+	p.tree = append(p.tree, sum)
+}
+
+// update adds delta to the weight in slot i.
+func (p *Picker[K]) update(i int, delta float64) {
+	p.total += delta
+	for i++; i < len(p.tree); i += i & -i {
+		p.tree[i] += delta
+	}
+}
+
+// Alias picks from a fixed set of weighted items in constant time using Vose's alias method.
+// It is safe for concurrent use.
+type Alias[T any] struct {
+	mutex  *sync.Mutex
+	random *rand.Rand
+	items  []T
+	prob   []float64
+	alias  []int
+}
+
+// NewAlias builds an alias table for items with the matching weights. A nil random is seeded from
+// the current time.
+func NewAlias[T any](items []T, weights []float64, random *rand.Rand) (*Alias[T], error) {
+	if len(items) != len(weights) {
+		return nil, ErrLength
+	}
+	var total float64
+	for _, w := range weights {
+		if !(w >= 0) {
+			return nil, ErrWeight
+		}
+		total += w
+	}
+	if !(total > 0) {
+		return nil, ErrWeight
+	}
+	n := len(items)
+	a := &Alias[T]{
+		mutex:  &sync.Mutex{},
+		random: newRandom(random),
+		items:  append([]T(nil), items...),
+		prob:   make([]float64, n),
+		alias:  make([]int, n),
+	}
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / total
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		a.prob[s] = scaled[s]
+		a.alias[s] = l
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// Whatever remains is full up to rounding error.
+	for _, i := range append(small, large...) {
+		a.prob[i] = 1
+	}
+	return a, nil
+}
+
+// Pick returns an item chosen with probability proportional to its weight.
+func (a *Alias[T]) Pick() T {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	i := a.random.Intn(len(a.items))
+	if a.random.Float64() < a.prob[i] {
+		return a.items[i]
+	}
+	return a.items[a.alias[i]]
+}
@@ -0,0 +1,118 @@
+package wrand_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cjsaylor/goutil/wrand"
+)
+
+func near(count, expected int) bool {
+	return math.Abs(float64(count-expected)) <= float64(expected)/10+20
+}
+
+func TestPicker(t *testing.T) {
+	p := wrand.New[string](rand.New(rand.NewSource(1)))
+	if _, ok := p.Pick(); ok {
+		t.Error("Expected no pick from an empty picker")
+	}
+	p.Set("a", 1)
+	p.Set("b", 3)
+	p.Set("c", 0)
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		key, _ := p.Pick()
+		counts[key]++
+	}
+	if !near(counts["a"], 2500) || !near(counts["b"], 7500) || counts["c"] != 0 {
+		t.Errorf("Expected a 1:3 split without c got %v", counts)
+	}
+	if p.Total() != 4 || p.Len() != 3 {
+		t.Errorf("Expected a total of 4 over 3 keys got %v over %d", p.Total(), p.Len())
+	}
+	if err := p.Set("d", -1); !errors.Is(err, wrand.ErrWeight) {
+		t.Errorf("Expected ErrWeight got %v", err)
+	}
+}
+
+func TestPickerUpdates(t *testing.T) {
+	p := wrand.New[int](rand.New(rand.NewSource(1)))
+	for i := 0; i < 10; i++ {
+		p.Set(i, 1)
+	}
+	for i := 0; i < 9; i++ {
+		if !p.Remove(i) {
+			t.Fatalf("Expected %d to be removed", i)
+		}
+	}
+	if p.Remove(0) {
+		t.Error("Expected a second removal to fail")
+	}
+	for i := 0; i < 100; i++ {
+		if key, ok := p.Pick(); !ok || key != 9 {
+			t.Fatalf("Expected only 9 to remain got %d", key)
+		}
+	}
+	p.Set(9, 0)
+	p.Set(20, 2)
+	p.Set(21, 2)
+	p.Set(21, 6)
+	if w, ok := p.Weight(21); !ok || w != 6 {
+		t.Errorf("Expected a weight of 6 got %v", w)
+	}
+	counts := map[int]int{}
+	for i := 0; i < 8000; i++ {
+		key, _ := p.Pick()
+		counts[key]++
+	}
+	if !near(counts[20], 2000) || !near(counts[21], 6000) || counts[9] != 0 {
+		t.Errorf("Expected a 1:3 split got %v", counts)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	a, err := wrand.NewAlias([]string{"a", "b", "c", "d"}, []float64{1, 2, 3, 4}, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[a.Pick()]++
+	}
+	for key, expected := range map[string]int{"a": 1000, "b": 2000, "c": 3000, "d": 4000} {
+		if !near(counts[key], expected) {
+			t.Errorf("Expected %s about %d times got %d", key, expected, counts[key])
+		}
+	}
+}
+
+func TestAliasErrors(t *testing.T) {
+	if _, err := wrand.NewAlias([]int{1}, nil, nil); !errors.Is(err, wrand.ErrLength) {
+		t.Errorf("Expected ErrLength got %v", err)
+	}
+	if _, err := wrand.NewAlias([]int{1, 2}, []float64{0, 0}, nil); !errors.Is(err, wrand.ErrWeight) {
+		t.Errorf("Expected ErrWeight got %v", err)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	pick := func() []int {
+		p := wrand.New[int](rand.New(rand.NewSource(42)))
+		for i := 0; i < 5; i++ {
+			p.Set(i, float64(i+1))
+		}
+		picks := make([]int, 20)
+		for i := range picks {
+			picks[i], _ = p.Pick()
+		}
+		return picks
+	}
+	a, b := pick(), pick()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Expected the same seed to give the same picks got %v and %v", a, b)
+		}
+	}
+}